@@ -0,0 +1,42 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EnforcementOverrideSpec defines the desired state of EnforcementOverride.
+type EnforcementOverrideSpec struct {
+	// reason explains why enforcement has been suspended, e.g. a link to the incident.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.spec.reason`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:resource:categories={rancher-security},singular="enforcementoverride",path="enforcementoverrides",scope="Cluster",shortName={eo}
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// EnforcementOverride is an emergency switch: while at least one exists in the cluster,
+// every agent enforces all the policies in monitor mode, so that no executable is blocked.
+// Deleting all of them restores the mode of each policy.
+type EnforcementOverride struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec EnforcementOverrideSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// EnforcementOverrideList contains a list of EnforcementOverride.
+type EnforcementOverrideList struct {
+	metav1.TypeMeta `json:",inline"`
+
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []EnforcementOverride `json:"items"`
+}
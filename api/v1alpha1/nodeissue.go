@@ -9,6 +9,9 @@ const (
 	NodeIssueMissingPolicy NodeIssueCode = "MissingPolicy"
 	NodeIssuePolicyFailed  NodeIssueCode = "PolicyFailed"
 	NodeIssueMaxReached    NodeIssueCode = "MaxReached"
+	// NodeIssueCgroupIncompatible is reported when the agent keeps failing to resolve the cgroups
+	// of the containers, e.g. because of the cgroup driver or version of the node.
+	NodeIssueCgroupIncompatible NodeIssueCode = "CgroupIncompatible"
 
 	TruncationNodeString = "..."
 )
@@ -37,6 +37,8 @@ func addKnownTypes(s *runtime.Scheme) error {
 		&WorkloadPolicyList{},
 		&WorkloadPolicyProposal{},
 		&WorkloadPolicyProposalList{},
+		&EnforcementOverride{},
+		&EnforcementOverrideList{},
 	)
 	metav1.AddToGroupVersion(s, GroupVersion)
 	return nil
@@ -20,6 +20,34 @@ func TestWorkloadPolicyNamespacedName(t *testing.T) {
 	require.Equal(t, expected, wp.NamespacedName())
 }
 
+func TestWorkloadPolicyAllowedExecutablesCount(t *testing.T) {
+	wp := &v1alpha1.WorkloadPolicy{}
+	require.Zero(t, wp.AllowedExecutablesCount(nil))
+
+	wp.Spec.RulesByContainer = map[string]*v1alpha1.WorkloadPolicyRules{
+		"main":    {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh", "/bin/ls"}}},
+		"sidecar": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+		"empty":   nil,
+	}
+	require.Equal(t, 3, wp.AllowedExecutablesCount(nil))
+
+	wp.Spec.RulesByContainer["main"].Executables.AllowedPrefixes = []string{"/usr/local/bin/"}
+	require.Equal(t, 4, wp.AllowedExecutablesCount(nil))
+
+	// the containers of the base policy are inherited, unless the policy defines them.
+	base := &v1alpha1.WorkloadPolicy{Spec: v1alpha1.WorkloadPolicySpec{
+		RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+			"sidecar": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/a", "/bin/b"}}},
+			"init":    {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/init"}}},
+		},
+	}}
+	require.Equal(t, 5, wp.AllowedExecutablesCount(base))
+
+	// the rules of a base policy with a higher priority win.
+	base.Spec.Priority = 1
+	require.Equal(t, 6, wp.AllowedExecutablesCount(base))
+}
+
 func TestAddNodeIssue(t *testing.T) {
 	wp := &v1alpha1.WorkloadPolicy{
 		Status: v1alpha1.WorkloadPolicyStatus{},
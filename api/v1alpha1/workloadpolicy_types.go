@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	"maps"
 	"slices"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -13,6 +14,21 @@ const (
 	// workloads that are already protected by an existing policy.
 	PromotedFromLabelKey = "workloadpolicy.security.rancher.io/promoted-from"
 
+	// ApprovedAtAnnotationKey is set on a promoted WorkloadPolicy to the time, in RFC 3339,
+	// its proposal was labeled with ApprovalLabelKey.
+	ApprovedAtAnnotationKey = "workloadpolicy.security.rancher.io/approved-at"
+	// ApprovedByAnnotationKey is set on a promoted WorkloadPolicy to the field manager,
+	// e.g. kubectl-label, that labeled its proposal with ApprovalLabelKey.
+	ApprovedByAnnotationKey = "workloadpolicy.security.rancher.io/approved-by"
+
+	// DefaultPolicyAnnotationKey is set on a Namespace to the name of a WorkloadPolicy
+	// enforced on all the pods of the namespace without the PolicyLabelKey label.
+	DefaultPolicyAnnotationKey = "security.rancher.io/default-policy"
+
+	// EnforcementSummaryAnnotationKey is set by the agents, when enabled, on the pods of their node to a JSON
+	// summary of how the pod is enforced: its policy, the enforced mode and the enforced containers.
+	EnforcementSummaryAnnotationKey = "security.rancher.io/enforcement-summary"
+
 	// MaxNodesWithIssues is the maximum number of nodes with issues to report.
 	// we don't want to overwhelm the user with too much information.
 	MaxNodesWithIssues = 20
@@ -20,6 +36,32 @@ const (
 	MaxTransitioningNodes = 20
 )
 
+const (
+	// UnlistedContainerAllow leaves the containers without rules unenforced.
+	UnlistedContainerAllow = "allow"
+	// UnlistedContainerDeny blocks every executable in the containers without rules.
+	UnlistedContainerDeny = "deny"
+)
+
+const (
+	// LoadFailureFailOpen leaves the containers of a policy that fails to load on a node unenforced.
+	LoadFailureFailOpen = "fail-open"
+	// LoadFailureFailClosed blocks every executable in the containers of a policy that fails to load on a node.
+	LoadFailureFailClosed = "fail-closed"
+)
+
+const (
+	// PolicyLabelConflictCondition is true when the pods of a workload controller reference
+	// this policy and other policies, e.g. because its pod template was changed, so its replicas
+	// are not all enforced by the same rules.
+	PolicyLabelConflictCondition = "PolicyLabelConflict"
+
+	// PolicyLabelConflictReason is the reason of the PolicyLabelConflictCondition when a conflict is found.
+	PolicyLabelConflictReason = "ConflictingPolicyLabels"
+	// PolicyLabelNoConflictReason is the reason of the PolicyLabelConflictCondition when no conflict is found.
+	PolicyLabelNoConflictReason = "NoConflict"
+)
+
 // Phase represents the current phase of the workload policy.
 // Possible values are:
 // - "Transitioning": the policy is in the process of changing its enforcement mode.
@@ -41,6 +83,54 @@ type WorkloadPolicyExecutables struct {
 	// +kubebuilder:validation:items:Pattern=`^/.*$`
 	// +optional
 	Allowed []string `json:"allowed,omitempty"`
+	// allowedPrefixes defines path prefixes under which every executable is allowed to run,
+	// e.g. "/opt/app/" allows /opt/app/v1.2.3/bin/worker. A prefix is matched as a string,
+	// so it should end with "/" to only match the executables of a directory tree.
+	// +kubebuilder:validation:items:Pattern=`^/.*$`
+	// +kubebuilder:validation:items:MaxLength=256
+	// +optional
+	AllowedPrefixes []string `json:"allowedPrefixes,omitempty"`
+	// allowedWithParent defines executables that are allowed to run only
+	// when they are executed by one of the given parent executables.
+	// The parent condition is evaluated on the reported violations, so it
+	// only applies to policies in "monitor" mode: the policies in "protect"
+	// mode or with autoProtect are rejected when they have these rules, their
+	// own or inherited. The parent of a script is its interpreter.
+	// +optional
+	AllowedWithParent []ExecutableWithParent `json:"allowedWithParent,omitempty"`
+	// allowedHashes defines executables that are allowed to run only when the
+	// content of the file matches the given SHA-256 digest. The files are hashed
+	// in each container and only the matching files are allowed, as long as
+	// they are not modified or replaced.
+	// Hash matching must be enabled, otherwise the policies with allowedHashes
+	// are rejected.
+	// +optional
+	AllowedHashes []ExecutableHash `json:"allowedHashes,omitempty"`
+}
+
+// ExecutableHash is an executable allowed only with a specific content.
+type ExecutableHash struct {
+	// path is the executable allowed to run.
+	// +kubebuilder:validation:Pattern=`^/.*$`
+	// +kubebuilder:validation:Required
+	Path string `json:"path"`
+	// sha256 is the hex encoded SHA-256 digest of the executable.
+	// +kubebuilder:validation:Pattern=`^[a-f0-9]{64}$`
+	// +kubebuilder:validation:Required
+	SHA256 string `json:"sha256"`
+}
+
+// ExecutableWithParent is an executable allowed only under specific parent executables.
+type ExecutableWithParent struct {
+	// path is the executable allowed to run.
+	// +kubebuilder:validation:Pattern=`^/.*$`
+	// +kubebuilder:validation:Required
+	Path string `json:"path"`
+	// parents is the list of executables allowed to run path.
+	// +kubebuilder:validation:items:Pattern=`^/.*$`
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:Required
+	Parents []string `json:"parents"`
 }
 
 type WorkloadPolicyRules struct {
@@ -60,6 +150,97 @@ type WorkloadPolicySpec struct {
 
 	// rulesByContainer specifies for each container the list of rules to apply.
 	RulesByContainer map[string]*WorkloadPolicyRules `json:"rulesByContainer,omitempty"`
+
+	// basePolicy is the name of a WorkloadPolicy in the same namespace whose
+	// rulesByContainer are inherited by this policy. Containers defined in this
+	// policy override the rules of the same container in the base policy.
+	// The basePolicy of the base policy itself is not followed.
+	// +optional
+	BasePolicy string `json:"basePolicy,omitempty"`
+
+	// priority orders this policy and its basePolicy. For a container defined in both,
+	// the rules of the policy with the higher priority are enforced. On a tie, the rules
+	// of this policy override the inherited ones.
+	// When empty, the priority is 0.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// unlistedContainerPolicy defines how containers of the pod that are not
+	// listed in rulesByContainer are handled. With "allow" (the default)
+	// they are not enforced, with "deny" no executable is allowed to run in them.
+	// The ephemeral containers, e.g. attached with kubectl debug, are still not enforced
+	// with "deny" unless the agent runs with --enforce-ephemeral-containers.
+	// +kubebuilder:validation:Enum=allow;deny
+	// +optional
+	UnlistedContainerPolicy string `json:"unlistedContainerPolicy,omitempty"`
+
+	// onLoadFailure defines how the containers enforced by this policy are handled on the nodes
+	// where the policy fails to load, e.g. because the agent cannot populate its maps or attach a starting pod to them.
+	// With "fail-open" (the default) they are not enforced until the policy loads, with "fail-closed"
+	// no executable is allowed in them, except the agent global allow list, following the mode and
+	// the active windows of the policy: in monitor mode the executions are reported instead of blocked.
+	// +kubebuilder:validation:Enum=fail-open;fail-closed
+	// +optional
+	OnLoadFailure string `json:"onLoadFailure,omitempty"`
+
+	// activeWindows restricts the "protect" mode to the given time windows.
+	// Outside of every window, the policy only reports violations as in "monitor" mode.
+	// When empty, the mode applies at any time.
+	// +optional
+	ActiveWindows []PolicyActiveWindow `json:"activeWindows,omitempty"`
+
+	// podIndexes restricts the policy to the pods whose "apps.kubernetes.io/pod-index"
+	// label, set by StatefulSets, is one of the given ordinals. The other pods
+	// referencing this policy are not enforced.
+	// When empty, the policy applies to every pod referencing it.
+	// +kubebuilder:validation:items:Minimum=0
+	// +optional
+	PodIndexes []int32 `json:"podIndexes,omitempty"`
+
+	// severity is the severity of the violations of this policy. Agents can be configured
+	// to not export the violations below a minimum severity.
+	// When empty, the severity is "medium".
+	// +kubebuilder:validation:Enum=low;medium;high;critical
+	// +optional
+	Severity string `json:"severity,omitempty"`
+
+	// autoProtect switches the policy from "monitor" to "protect" once the WorkloadPolicyProposal
+	// learning its workload stopped changing. While the policy is in "monitor" mode, the executables
+	// of the pods bound to it keep being learned into the proposal. Once the proposal is unchanged
+	// for stableObservations consecutive observations, its executables are added to the rules of
+	// the policy, the mode is set to "protect" and the proposal is deleted.
+	// +optional
+	AutoProtect *WorkloadPolicyAutoProtect `json:"autoProtect,omitempty"`
+}
+
+// WorkloadPolicyAutoProtect links a policy in "monitor" mode to the proposal learning its workload.
+type WorkloadPolicyAutoProtect struct {
+	// proposal is the name of the WorkloadPolicyProposal of the workload, in the namespace of the policy.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Required
+	Proposal string `json:"proposal"`
+	// stableObservations is the number of consecutive observations of the proposal, made by the controller
+	// at a regular interval, without a new learned executable before the policy is switched to "protect".
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Required
+	StableObservations int32 `json:"stableObservations"`
+}
+
+// PolicyActiveWindow is a daily time window, evaluated in UTC, during which a policy is enforced.
+type PolicyActiveWindow struct {
+	// start is the beginning of the window in the 24-hour "HH:MM" format.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	// +kubebuilder:validation:Required
+	Start string `json:"start"`
+	// end is the end of the window in the 24-hour "HH:MM" format, excluded from the window.
+	// When end is earlier than start, the window goes past midnight.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	// +kubebuilder:validation:Required
+	End string `json:"end"`
+	// days are the days of the week on which the window starts. When empty, the window starts every day.
+	// +kubebuilder:validation:items:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+	// +optional
+	Days []string `json:"days,omitempty"`
 }
 
 const MaxViolationRecords = 100
@@ -94,8 +275,26 @@ type WorkloadPolicyStatus struct {
 	TransitioningNodes int `json:"transitioningNodes,omitempty"`
 	// nodesTransitioning contains the names of the nodes that are transitioning.
 	NodesTransitioning []string `json:"nodesTransitioning,omitempty"`
+	// pendingNodes is the number of nodes where the policy is not loaded yet, e.g. during a rollout.
+	// They are reported as failed once the policy stays missing longer than the grace period of the controller.
+	// +optional
+	PendingNodes int `json:"pendingNodes,omitempty"`
 	// phase indicates the current phase of the workload policy.
 	Phase Phase `json:"phase,omitempty"`
+	// allowedExecutables is the number of executable rules enforced by the policy, summed over all the containers,
+	// including the rules inherited from its base policy.
+	// +optional
+	AllowedExecutables int `json:"allowedExecutables,omitempty"`
+	// matchedContainers is the number of containers of the pods bound to the policy, summed over all the nodes.
+	// +optional
+	MatchedContainers int `json:"matchedContainers,omitempty"`
+	// enforcedContainers is the number of matched containers with the policy applied,
+	// i.e. with a resolved cgroup on a node where the policy is loaded.
+	// +optional
+	EnforcedContainers int `json:"enforcedContainers,omitempty"`
+	// enforcement summarizes the enforced containers, e.g. "48/50 (96%)". It is empty when no container is matched.
+	// +optional
+	Enforcement string `json:"enforcement,omitempty"`
 	// violationCount is the total number of violation records,
 	// including those no longer retained in violations.
 	//
@@ -109,6 +308,11 @@ type WorkloadPolicyStatus struct {
 	// Oldest entries are dropped when the limit is reached.
 	// +optional
 	Violations []ViolationRecord `json:"violations,omitempty"`
+	// conditions are the observations of the state of the policy, see PolicyLabelConflictCondition.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 func (s *WorkloadPolicyStatus) AddNodeIssue(nodeName string, issue NodeIssue) {
@@ -162,6 +366,8 @@ func (s *WorkloadPolicyStatus) AddTransitioningNode(nodeName string) {
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
 // +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Allowed",type=integer,JSONPath=`.status.allowedExecutables`
+// +kubebuilder:printcolumn:name="Enforcing",type=string,JSONPath=`.status.enforcement`
 // +kubebuilder:resource:categories={rancher-security},singular="workloadpolicy",path="workloadpolicies",scope="Namespaced",shortName={wp}
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -175,6 +381,38 @@ type WorkloadPolicy struct {
 	Status WorkloadPolicyStatus `json:"status,omitempty"`
 }
 
+// EffectiveRulesByContainer returns the rules enforced for the policy once the ones of its base policy are
+// merged: a container defined in the policy with the higher priority replaces the whole rules of the same
+// container in the other one, the child policy wins on a tie. A nil base policy is not inherited.
+func (wp *WorkloadPolicy) EffectiveRulesByContainer(base *WorkloadPolicy) map[string]*WorkloadPolicyRules {
+	if base == nil {
+		return wp.Spec.RulesByContainer
+	}
+	lower, higher := base.Spec.RulesByContainer, wp.Spec.RulesByContainer
+	if base.Spec.Priority > wp.Spec.Priority {
+		lower, higher = higher, lower
+	}
+	merged := make(map[string]*WorkloadPolicyRules, len(lower)+len(higher))
+	maps.Copy(merged, lower)
+	maps.Copy(merged, higher)
+	return merged
+}
+
+// AllowedExecutablesCount returns the number of executable rules enforced by the policy in all its containers,
+// the ones inherited from the given base policy included. A nil base policy is not inherited.
+func (wp *WorkloadPolicy) AllowedExecutablesCount(base *WorkloadPolicy) int {
+	count := 0
+	for _, rules := range wp.EffectiveRulesByContainer(base) {
+		if rules == nil {
+			continue
+		}
+		executables := rules.Executables
+		count += len(executables.Allowed) + len(executables.AllowedPrefixes) +
+			len(executables.AllowedWithParent) + len(executables.AllowedHashes)
+	}
+	return count
+}
+
 // NamespacedName returns a string in the form "<namespace>/<name>".
 //
 // This is useful when storing/retrieving WorkloadPolicy-related state in maps.
@@ -68,6 +68,7 @@ func TestWorkloadPolicyProposalAddProcess(t *testing.T) {
 
 	tests := []struct {
 		name                        string
+		existing                    map[string]*v1alpha1.WorkloadPolicyRules
 		calls                       []addProcessCall
 		expectedContainers          int
 		expectedAllowedPerContainer map[string][]string
@@ -114,11 +115,25 @@ func TestWorkloadPolicyProposalAddProcess(t *testing.T) {
 				"container2": {"/bin/bash"},
 			},
 		},
+		{
+			name:     "replaces a null container entry",
+			existing: map[string]*v1alpha1.WorkloadPolicyRules{"container1": nil},
+			calls: []addProcessCall{
+				{"container1", "/bin/sh"},
+			},
+			expectedContainers: 1,
+			expectedAllowedPerContainer: map[string][]string{
+				"container1": {"/bin/sh"},
+			},
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			p := &v1alpha1.WorkloadPolicyProposal{}
+			if tc.existing != nil {
+				p.Spec.RulesByContainer = tc.existing
+			}
 			for _, call := range tc.calls {
 				p.AddProcess(call.containerName, call.executable)
 			}
@@ -129,3 +144,51 @@ func TestWorkloadPolicyProposalAddProcess(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkloadPolicyProposalUpdateProcessCounts(t *testing.T) {
+	p := &v1alpha1.WorkloadPolicyProposal{}
+	require.False(t, p.UpdateProcessCounts(), "empty proposal has nothing to count")
+
+	p.AddProcess("main", "/usr/bin/sleep")
+	p.AddProcess("main", "/usr/bin/cat")
+	p.AddProcess("sidecar", "/usr/bin/ls")
+	require.True(t, p.UpdateProcessCounts())
+	require.Equal(t, map[string]int{"main": 2, "sidecar": 1}, p.Status.ProcessCountByContainer)
+
+	// learning an already known executable doesn't change the counts.
+	p.AddProcess("main", "/usr/bin/sleep")
+	require.False(t, p.UpdateProcessCounts())
+
+	p.AddProcess("sidecar", "/usr/bin/echo")
+	require.True(t, p.UpdateProcessCounts())
+	require.Equal(t, map[string]int{"main": 2, "sidecar": 2}, p.Status.ProcessCountByContainer)
+}
+
+func TestWorkloadPolicyProposalUpdateCommonExecutables(t *testing.T) {
+	p := &v1alpha1.WorkloadPolicyProposal{}
+	p.AddProcess("main", "/usr/bin/sleep")
+	require.False(t, p.UpdateCommonExecutables(), "a single container has nothing in common")
+
+	p.AddProcess("sidecar", "/usr/bin/ls")
+	require.False(t, p.UpdateCommonExecutables())
+	p.AddProcess("main", "/usr/bin/ls")
+	p.AddProcess("sidecar", "/usr/bin/sleep")
+	p.AddProcess("init", "/usr/bin/sleep")
+	require.True(t, p.UpdateCommonExecutables())
+	require.Equal(t, []string{"/usr/bin/sleep"}, p.Status.CommonExecutables)
+
+	p.AddProcess("init", "/usr/bin/ls")
+	require.True(t, p.UpdateCommonExecutables())
+	require.Equal(t, []string{"/usr/bin/ls", "/usr/bin/sleep"}, p.Status.CommonExecutables)
+	require.False(t, p.UpdateCommonExecutables())
+}
+
+func TestWorkloadPolicyProposalAddContributingNode(t *testing.T) {
+	p := &v1alpha1.WorkloadPolicyProposal{}
+	require.False(t, p.AddContributingNode(""))
+	require.True(t, p.AddContributingNode("node-b"))
+	require.True(t, p.AddContributingNode("node-a"))
+	require.True(t, p.AddContributingNode("node-c"))
+	require.False(t, p.AddContributingNode("node-b"))
+	require.Equal(t, []string{"node-a", "node-b", "node-c"}, p.Status.ContributingNodes)
+}
@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	"maps"
 	"slices"
 
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
@@ -22,6 +23,38 @@ type WorkloadPolicyProposalSpec struct {
 	RulesByContainer map[string]*WorkloadPolicyRules `json:"rulesByContainer,omitempty"`
 }
 
+// WorkloadPolicyProposalStatus defines the observed state of WorkloadPolicyProposal.
+type WorkloadPolicyProposalStatus struct {
+	// processCountByContainer is the number of distinct executables learned for each container.
+	// +optional
+	ProcessCountByContainer map[string]int `json:"processCountByContainer,omitempty"`
+
+	// contributingNodes are the nodes whose agent learned executables into this proposal, sorted.
+	// A workload running on several nodes is fully learned once each of its nodes is listed.
+	// +listType=set
+	// +optional
+	ContributingNodes []string `json:"contributingNodes,omitempty"`
+
+	// commonExecutables are the learned executables, sorted, common to all the containers of the proposal
+	// when it has at least two: the candidates for a set of rules shared by the containers.
+	// +listType=set
+	// +optional
+	CommonExecutables []string `json:"commonExecutables,omitempty"`
+
+	// stableObservations is the number of consecutive observations, made for the WorkloadPolicy
+	// referencing this proposal in its autoProtect, without a new learned executable.
+	// +optional
+	StableObservations int32 `json:"stableObservations,omitempty"`
+
+	// observedGeneration is the generation of the proposal at the last of these observations.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// lastObservationTime is the time of the last of these observations.
+	// +optional
+	LastObservationTime *metav1.Time `json:"lastObservationTime,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:categories={rancher-security},singular="workloadpolicyproposal",path="workloadpolicyproposals",scope="Namespaced",shortName={wpp}
@@ -33,7 +66,8 @@ type WorkloadPolicyProposal struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec WorkloadPolicyProposalSpec `json:"spec,omitempty"`
+	Spec   WorkloadPolicyProposalSpec   `json:"spec,omitempty"`
+	Status WorkloadPolicyProposalStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -77,8 +111,9 @@ func (p *WorkloadPolicyProposal) AddProcess(containerName string, executable str
 		p.Spec.RulesByContainer = make(map[string]*WorkloadPolicyRules)
 	}
 
-	rules, ok := p.Spec.RulesByContainer[containerName]
-	if !ok {
+	rules := p.Spec.RulesByContainer[containerName]
+	// a null entry, e.g. written by hand, is replaced with the learned rules.
+	if rules == nil {
 		p.Spec.RulesByContainer[containerName] = &WorkloadPolicyRules{
 			Executables: WorkloadPolicyExecutables{
 				Allowed: []string{executable},
@@ -94,6 +129,69 @@ func (p *WorkloadPolicyProposal) AddProcess(containerName string, executable str
 	rules.Executables.Allowed = append(rules.Executables.Allowed, executable)
 }
 
+// UpdateProcessCounts refreshes the number of learned executables per container in the status.
+// It returns true if the status changed.
+func (p *WorkloadPolicyProposal) UpdateProcessCounts() bool {
+	counts := make(map[string]int, len(p.Spec.RulesByContainer))
+	for containerName, rules := range p.Spec.RulesByContainer {
+		if rules == nil {
+			continue
+		}
+		counts[containerName] = len(rules.Executables.Allowed)
+	}
+
+	if maps.Equal(counts, p.Status.ProcessCountByContainer) {
+		return false
+	}
+	p.Status.ProcessCountByContainer = counts
+	return true
+}
+
+// UpdateCommonExecutables refreshes the executables learned in every container in the status.
+// It returns true if the status changed.
+func (p *WorkloadPolicyProposal) UpdateCommonExecutables() bool {
+	var common []string
+	if len(p.Spec.RulesByContainer) > 1 {
+		first := true
+		for _, rules := range p.Spec.RulesByContainer {
+			var allowed []string
+			if rules != nil {
+				allowed = rules.Executables.Allowed
+			}
+			if first {
+				common = slices.Clone(allowed)
+				first = false
+				continue
+			}
+			common = slices.DeleteFunc(common, func(executable string) bool {
+				return !slices.Contains(allowed, executable)
+			})
+		}
+		slices.Sort(common)
+		common = slices.Compact(common)
+	}
+
+	if slices.Equal(common, p.Status.CommonExecutables) {
+		return false
+	}
+	p.Status.CommonExecutables = common
+	return true
+}
+
+// AddContributingNode records the node of an agent learning into the proposal.
+// It returns true if the status changed.
+func (p *WorkloadPolicyProposal) AddContributingNode(nodeName string) bool {
+	if nodeName == "" {
+		return false
+	}
+	index, found := slices.BinarySearch(p.Status.ContributingNodes, nodeName)
+	if found {
+		return false
+	}
+	p.Status.ContributingNodes = slices.Insert(p.Status.ContributingNodes, index, nodeName)
+	return true
+}
+
 func (p *WorkloadPolicyProposal) AddPartialOwnerReferenceDetails(workloadKind string, workload string) {
 	p.OwnerReferences = []metav1.OwnerReference{
 		{
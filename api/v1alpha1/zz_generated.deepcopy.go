@@ -5,9 +5,118 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnforcementOverride) DeepCopyInto(out *EnforcementOverride) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnforcementOverride.
+func (in *EnforcementOverride) DeepCopy() *EnforcementOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(EnforcementOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EnforcementOverride) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnforcementOverrideList) DeepCopyInto(out *EnforcementOverrideList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EnforcementOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnforcementOverrideList.
+func (in *EnforcementOverrideList) DeepCopy() *EnforcementOverrideList {
+	if in == nil {
+		return nil
+	}
+	out := new(EnforcementOverrideList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EnforcementOverrideList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnforcementOverrideSpec) DeepCopyInto(out *EnforcementOverrideSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnforcementOverrideSpec.
+func (in *EnforcementOverrideSpec) DeepCopy() *EnforcementOverrideSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EnforcementOverrideSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutableHash) DeepCopyInto(out *ExecutableHash) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutableHash.
+func (in *ExecutableHash) DeepCopy() *ExecutableHash {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutableHash)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutableWithParent) DeepCopyInto(out *ExecutableWithParent) {
+	*out = *in
+	if in.Parents != nil {
+		in, out := &in.Parents, &out.Parents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutableWithParent.
+func (in *ExecutableWithParent) DeepCopy() *ExecutableWithParent {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutableWithParent)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeIssue) DeepCopyInto(out *NodeIssue) {
 	*out = *in
@@ -23,6 +132,26 @@ func (in *NodeIssue) DeepCopy() *NodeIssue {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyActiveWindow) DeepCopyInto(out *PolicyActiveWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyActiveWindow.
+func (in *PolicyActiveWindow) DeepCopy() *PolicyActiveWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyActiveWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ViolationRecord) DeepCopyInto(out *ViolationRecord) {
 	*out = *in
@@ -66,6 +195,21 @@ func (in *WorkloadPolicy) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadPolicyAutoProtect) DeepCopyInto(out *WorkloadPolicyAutoProtect) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadPolicyAutoProtect.
+func (in *WorkloadPolicyAutoProtect) DeepCopy() *WorkloadPolicyAutoProtect {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadPolicyAutoProtect)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadPolicyExecutables) DeepCopyInto(out *WorkloadPolicyExecutables) {
 	*out = *in
@@ -74,6 +218,23 @@ func (in *WorkloadPolicyExecutables) DeepCopyInto(out *WorkloadPolicyExecutables
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.AllowedPrefixes != nil {
+		in, out := &in.AllowedPrefixes, &out.AllowedPrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedWithParent != nil {
+		in, out := &in.AllowedWithParent, &out.AllowedWithParent
+		*out = make([]ExecutableWithParent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AllowedHashes != nil {
+		in, out := &in.AllowedHashes, &out.AllowedHashes
+		*out = make([]ExecutableHash, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadPolicyExecutables.
@@ -124,6 +285,7 @@ func (in *WorkloadPolicyProposal) DeepCopyInto(out *WorkloadPolicyProposal) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadPolicyProposal.
@@ -207,6 +369,42 @@ func (in *WorkloadPolicyProposalSpec) DeepCopy() *WorkloadPolicyProposalSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadPolicyProposalStatus) DeepCopyInto(out *WorkloadPolicyProposalStatus) {
+	*out = *in
+	if in.ProcessCountByContainer != nil {
+		in, out := &in.ProcessCountByContainer, &out.ProcessCountByContainer
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ContributingNodes != nil {
+		in, out := &in.ContributingNodes, &out.ContributingNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CommonExecutables != nil {
+		in, out := &in.CommonExecutables, &out.CommonExecutables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastObservationTime != nil {
+		in, out := &in.LastObservationTime, &out.LastObservationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadPolicyProposalStatus.
+func (in *WorkloadPolicyProposalStatus) DeepCopy() *WorkloadPolicyProposalStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadPolicyProposalStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadPolicyRules) DeepCopyInto(out *WorkloadPolicyRules) {
 	*out = *in
@@ -242,6 +440,23 @@ func (in *WorkloadPolicySpec) DeepCopyInto(out *WorkloadPolicySpec) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.ActiveWindows != nil {
+		in, out := &in.ActiveWindows, &out.ActiveWindows
+		*out = make([]PolicyActiveWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodIndexes != nil {
+		in, out := &in.PodIndexes, &out.PodIndexes
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.AutoProtect != nil {
+		in, out := &in.AutoProtect, &out.AutoProtect
+		*out = new(WorkloadPolicyAutoProtect)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadPolicySpec.
@@ -276,6 +491,13 @@ func (in *WorkloadPolicyStatus) DeepCopyInto(out *WorkloadPolicyStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadPolicyStatus.
@@ -5,11 +5,41 @@
 
 package v1alpha1
 
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in EnforcementOverride) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.EnforcementOverride"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in EnforcementOverrideList) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.EnforcementOverrideList"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in EnforcementOverrideSpec) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.EnforcementOverrideSpec"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in ExecutableHash) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ExecutableHash"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in ExecutableWithParent) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ExecutableWithParent"
+}
+
 // OpenAPIModelName returns the OpenAPI model name for this type.
 func (in NodeIssue) OpenAPIModelName() string {
 	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.NodeIssue"
 }
 
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in PolicyActiveWindow) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.PolicyActiveWindow"
+}
+
 // OpenAPIModelName returns the OpenAPI model name for this type.
 func (in ViolationRecord) OpenAPIModelName() string {
 	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ViolationRecord"
@@ -20,6 +50,11 @@ func (in WorkloadPolicy) OpenAPIModelName() string {
 	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicy"
 }
 
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in WorkloadPolicyAutoProtect) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyAutoProtect"
+}
+
 // OpenAPIModelName returns the OpenAPI model name for this type.
 func (in WorkloadPolicyExecutables) OpenAPIModelName() string {
 	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyExecutables"
@@ -45,6 +80,11 @@ func (in WorkloadPolicyProposalSpec) OpenAPIModelName() string {
 	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyProposalSpec"
 }
 
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in WorkloadPolicyProposalStatus) OpenAPIModelName() string {
+	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyProposalStatus"
+}
+
 // OpenAPIModelName returns the OpenAPI model name for this type.
 func (in WorkloadPolicyRules) OpenAPIModelName() string {
 	return "com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyRules"
@@ -8,6 +8,8 @@
 #include "debug.h"
 #include "helpers.h"
 #include "string_maps.h"
+#include "prefix_maps.h"
+#include "pinned_exec.h"
 #include "d_path_resolution.h"
 
 // cspell:ignore kconfig
@@ -229,6 +231,9 @@ static __always_inline __u64 tg_get_current_cgroup_id(void) {
 	return get_cgroup_id(cgrp);
 }
 
+// The exec is attributed to a container through the cgroup of the task, not its PID namespace:
+// containers of a pod with shareProcessNamespace see each other processes but keep distinct cgroups,
+// so every exec is still matched against the policy of the container running it.
 static __always_inline __u64 get_tracker_id_from_curr_task() {
 	__u64 cgroupid = tg_get_current_cgroup_id();
 	if(!cgroupid) {
@@ -363,15 +368,32 @@ struct {
 	__uint(max_entries, BUF_DIM);
 } ringbuf_execve SEC(".maps");
 
+// MAX_ANCESTRY_DEPTH is the number of ancestors of the task calling the exec reported in the
+// monitoring events. It must match MaxAncestryDepth in internal/bpf.
+#define MAX_ANCESTRY_DEPTH 8
+
 struct process_evt {
 	u64 cg_tracker_id;
 	u16 path_len;
 	u8 mode;  // enforce or protect, todo!: this information is not needed by the learning event so
 	          // we can also decide to split the event structures
+	// number of valid entries in `ancestors`. It is only populated for monitoring events.
+	u8 ancestors_len;
+	// length of the parent executable path, written in `path` right after the executable path.
+	// It is only populated for monitoring events.
+	u16 parent_path_len;
+	// length of the path passed to execve, e.g. a symlink to the executable, written in `path`
+	// right after the executable path. It is only populated for learning events.
+	u16 invoked_path_len;
+	// tgids of the ancestors of the task calling the exec, closest first: the userspace resolves
+	// their executables.
+	u32 ancestors[MAX_ANCESTRY_DEPTH];
 	// MAX_PATH_LEN for the final path +
 	// MAX_PATH_LEN for storing the progressive path +
 	// MAX_PATH_LEN of empty space for padding when we do the string map lookups
 	char path[MAX_PATH_LEN * 3];
+	// scratch buffer used to resolve the parent executable path, same layout as `path`.
+	char parent_path[MAX_PATH_LEN * 3];
 	// todo!: we need to add the atomic value for concurrency, see
 	// https://github.com/falcosecurity/libs/issues/2719
 };
@@ -408,6 +430,55 @@ static __always_inline u32 populate_evt_with_path(struct process_evt *evt,
 	return current_offset;
 }
 
+#define PROCESS_EVT_HEADER_LEN (__builtin_offsetof(struct process_evt, path))
+
+// populate_evt_with_parent_path appends the path of the binary currently run by the task to the
+// executable path already copied at the beginning of `evt->path`.
+// During the exec the task still runs the binary of the process that required it, e.g. the shell
+// that spawned the new command. On failures the parent path is left empty.
+static __always_inline void populate_evt_with_parent_path(struct process_evt *evt) {
+	evt->parent_path_len = 0;
+
+	struct task_struct *task = (struct task_struct *)bpf_get_current_task();
+	struct file *exe_file = BPF_CORE_READ(task, mm, exe_file);
+	if(exe_file == NULL) {
+		return;
+	}
+	u32 offset = bpf_d_path_approx(&exe_file->f_path, evt->parent_path);
+	if(offset == 0 || offset == MAX_PATH_LEN * 2) {
+		return;
+	}
+	u16 parent_path_len = MAX_PATH_LEN * 2 - offset;
+	// the parent path is written after the executable path
+	long err = bpf_probe_read_kernel(&evt->path[SAFE_PATH_LEN(evt->path_len)],
+	                                 SAFE_PATH_LEN(parent_path_len),
+	                                 &evt->parent_path[SAFE_PATH_ACCESS(offset)]);
+	if(err != 0) {
+		return;
+	}
+	evt->parent_path_len = parent_path_len;
+}
+
+// populate_evt_with_ancestors records the tgids of the ancestors of the task calling the exec,
+// starting from its parent, until the idle task is reached.
+static __always_inline void populate_evt_with_ancestors(struct process_evt *evt) {
+	evt->ancestors_len = 0;
+
+	struct task_struct *task = (struct task_struct *)bpf_get_current_task();
+	for(int i = 0; i < MAX_ANCESTRY_DEPTH; ++i) {
+		task = BPF_CORE_READ(task, real_parent);
+		if(task == NULL) {
+			return;
+		}
+		u32 tgid = BPF_CORE_READ(task, tgid);
+		if(tgid == 0) {
+			return;
+		}
+		evt->ancestors[i] = tgid;
+		evt->ancestors_len = i + 1;
+	}
+}
+
 static __always_inline struct process_evt *get_process_evt() {
 	int zero = 0;
 	struct process_evt *evt =
@@ -524,6 +595,9 @@ int BPF_PROG(enforce_cgroup_policy, struct linux_binprm *bprm) {
 		}
 		levt->cg_tracker_id = cg_tracker_id;
 		levt->mode = 0;
+		levt->ancestors_len = 0;
+		levt->parent_path_len = 0;
+		levt->invoked_path_len = 0;
 
 		u32 loffset = populate_evt_with_path(levt, bprm);
 		if(loffset == 0) {
@@ -538,12 +612,25 @@ int BPF_PROG(enforce_cgroup_policy, struct linux_binprm *bprm) {
 			return 0;
 		}
 
+		// The invoked path is best effort, on failures only the executable path is learned.
+		long invoked_len = bpf_probe_read_kernel_str(&levt->path[SAFE_PATH_LEN(levt->path_len)],
+		                                             MAX_PATH_LEN,
+		                                             BPF_CORE_READ(bprm, filename));
+		if(invoked_len > 1) {
+			// invoked_len counts the string terminator `\0`, the userspace doesn't need it.
+			levt->invoked_path_len = invoked_len - 1;
+		}
+
 		// this is used for debug during development is never used in production
 		bpf_printk("sent execve event, path: %s, cg_tracker_id: %d",
 		           levt->path,
 		           levt->cg_tracker_id);
 
-		lerr = bpf_ringbuf_output(&ringbuf_execve, levt, 19 + SAFE_PATH_LEN(levt->path_len), 0);
+		lerr = bpf_ringbuf_output(&ringbuf_execve,
+		                          levt,
+		                          PROCESS_EVT_HEADER_LEN + SAFE_PATH_LEN(levt->path_len) +
+		                                  SAFE_PATH_LEN(levt->invoked_path_len),
+		                          0);
 		if(lerr != 0) {
 			emit_log_event(LOG_DROP_EXEC_EVENT);
 		}
@@ -556,6 +643,7 @@ int BPF_PROG(enforce_cgroup_policy, struct linux_binprm *bprm) {
 	}
 
 	evt->cg_tracker_id = cg_tracker_id;
+	evt->invoked_path_len = 0;
 
 	u32 current_offset = populate_evt_with_path(evt, bprm);
 	if(current_offset == 0) {
@@ -600,6 +688,18 @@ int BPF_PROG(enforce_cgroup_policy, struct linux_binprm *bprm) {
 		return 0;
 	}
 
+	// The binary may still be under one of the prefixes allowed by the policy.
+	if(path_has_allowed_prefix(policy_id,
+	                           &evt->path[SAFE_PATH_ACCESS(current_offset)],
+	                           evt->path_len)) {
+		return 0;
+	}
+
+	// The binary may be the file of an allowedHashes rule whose content was verified in this container.
+	if(exec_is_pinned(cg_tracker_id, bprm)) {
+		return 0;
+	}
+
 	///////////////////////////////
 	// We send the event
 	///////////////////////////////
@@ -619,6 +719,11 @@ int BPF_PROG(enforce_cgroup_policy, struct linux_binprm *bprm) {
 		return 0;
 	}
 
+	// The binary is not allowed: we report the binary of the task calling the exec, so that the
+	// userspace can match the rules conditioned on the parent executable.
+	populate_evt_with_parent_path(evt);
+	populate_evt_with_ancestors(evt);
+
 	// We check if we are in monitoring or enforcing mode for this policy
 	__u8 *mode = bpf_map_lookup_elem(&policy_mode_map, policy_id);
 	if(!mode) {
@@ -629,7 +734,11 @@ int BPF_PROG(enforce_cgroup_policy, struct linux_binprm *bprm) {
 	bpf_printk("Mode %d for policy id %d", *mode, *policy_id);
 	evt->mode = *mode;
 
-	err = bpf_ringbuf_output(&ringbuf_monitoring, evt, 19 + SAFE_PATH_LEN(evt->path_len), 0);
+	err = bpf_ringbuf_output(&ringbuf_monitoring,
+	                         evt,
+	                         PROCESS_EVT_HEADER_LEN + SAFE_PATH_LEN(evt->path_len) +
+	                                 SAFE_PATH_LEN(evt->parent_path_len),
+	                         0);
 	if(err != 0) {
 		emit_log_event_2(LOG_DROP_VIOLATION, *policy_id, evt->mode);
 	}
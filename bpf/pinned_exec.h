@@ -0,0 +1,66 @@
+#pragma once
+
+// The executables of the allowedHashes rules are not in the string maps of the policies: they are only
+// allowed in the containers where the userspace verified their content. The userspace pins each verified
+// file of a container by inode and change time, so that a file written or replaced after the verification
+// is not allowed anymore, its change time or inode differs.
+#define PINNED_EXEC_MAX_ENTRIES 65536
+
+struct pinned_exec_key {
+	__u64 cg_tracker_id;
+	__u64 ino;
+	__s64 ctime_sec;
+	__u32 ctime_nsec;
+	__u32 pad;
+};
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, PINNED_EXEC_MAX_ENTRIES);
+	__uint(map_flags, BPF_F_NO_PREALLOC);
+	__type(key, struct pinned_exec_key);
+	__type(value, __u8);
+} pinned_exec_map SEC(".maps");
+
+/* inode definition before the change time was split in i_ctime_sec and i_ctime_nsec (6.11) */
+struct inode___pre6_11 {
+	struct timespec64 __i_ctime;
+} __attribute__((preserve_access_index));
+
+/* inode definition before the change time was renamed __i_ctime (6.6) */
+struct inode___pre6_6 {
+	struct timespec64 i_ctime;
+} __attribute__((preserve_access_index));
+
+static __always_inline void read_inode_ctime(struct inode *inode, struct pinned_exec_key *key) {
+	if(bpf_core_field_exists(inode->i_ctime_sec)) {
+		key->ctime_sec = BPF_CORE_READ(inode, i_ctime_sec);
+		key->ctime_nsec = BPF_CORE_READ(inode, i_ctime_nsec);
+		return;
+	}
+
+	struct inode___pre6_11 *inode_pre6_11 = (void *)inode;
+	if(bpf_core_field_exists(inode_pre6_11->__i_ctime)) {
+		key->ctime_sec = BPF_CORE_READ(inode_pre6_11, __i_ctime.tv_sec);
+		key->ctime_nsec = BPF_CORE_READ(inode_pre6_11, __i_ctime.tv_nsec);
+		return;
+	}
+
+	struct inode___pre6_6 *inode_pre6_6 = (void *)inode;
+	key->ctime_sec = BPF_CORE_READ(inode_pre6_6, i_ctime.tv_sec);
+	key->ctime_nsec = BPF_CORE_READ(inode_pre6_6, i_ctime.tv_nsec);
+}
+
+// exec_is_pinned returns whether the executed file is one the userspace verified in the container.
+static __always_inline bool exec_is_pinned(__u64 cg_tracker_id, struct linux_binprm *bprm) {
+	struct inode *inode = BPF_CORE_READ(bprm, file, f_inode);
+	if(!inode) {
+		return false;
+	}
+
+	struct pinned_exec_key key = {};
+	key.cg_tracker_id = cg_tracker_id;
+	key.ino = BPF_CORE_READ(inode, i_ino);
+	read_inode_ctime(inode, &key);
+	return bpf_map_lookup_elem(&pinned_exec_map, &key) != NULL;
+}
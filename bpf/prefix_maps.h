@@ -0,0 +1,63 @@
+#pragma once
+
+// The prefixes allowed by a policy are stored in a LPM trie, so that a single lookup tells whether
+// one of them is a prefix of the executable path. The LPM tries don't support keys with more than
+// 256 bytes of data: the prefixes are at most 256 bytes long and the paths are matched on their
+// first 256 bytes.
+#define POLICY_PREFIX_MAX_LEN 256
+#define POLICY_PREFIX_OUTER_MAX_ENTRIES 65536
+#define POLICY_PREFIX_INNER_MAX_ENTRIES 1
+
+struct policy_prefix_key {
+	__u32 prefixlen;  // number of bits of data to match
+	__u8 data[POLICY_PREFIX_MAX_LEN];
+};
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH_OF_MAPS);
+	__uint(max_entries, POLICY_PREFIX_OUTER_MAX_ENTRIES);
+	__uint(map_flags, BPF_F_NO_PREALLOC);
+	__type(key, __u64);
+	__array(values, struct {
+		__uint(type, BPF_MAP_TYPE_LPM_TRIE);
+		__uint(max_entries, POLICY_PREFIX_INNER_MAX_ENTRIES);
+		__uint(map_flags, BPF_F_NO_PREALLOC);
+		__type(key, struct policy_prefix_key);
+		__type(value, __u8);
+	});
+} pol_prefix_maps SEC(".maps");
+
+// The lookup key doesn't fit in the stack of the program.
+struct {
+	__uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+	__uint(max_entries, 1);
+	__type(key, int);
+	__type(value, struct policy_prefix_key);
+} pol_prefix_key_storage_map SEC(".maps");
+
+// path_has_allowed_prefix returns whether one of the prefixes allowed by the policy is a prefix of
+// the path. `path` must be followed by at least POLICY_PREFIX_MAX_LEN readable bytes.
+static __always_inline bool path_has_allowed_prefix(__u64 *policy_id,
+                                                    const char *path,
+                                                    u16 path_len) {
+	// if the map is missing the userspace never populated prefixes for this policy.
+	void *prefix_map = bpf_map_lookup_elem(&pol_prefix_maps, policy_id);
+	if(!prefix_map) {
+		return false;
+	}
+
+	int zero = 0;
+	struct policy_prefix_key *key = bpf_map_lookup_elem(&pol_prefix_key_storage_map, &zero);
+	if(!key) {
+		return false;
+	}
+	if(bpf_probe_read_kernel(key->data, POLICY_PREFIX_MAX_LEN, path) != 0) {
+		return false;
+	}
+	u32 len = path_len;
+	if(len > POLICY_PREFIX_MAX_LEN) {
+		len = POLICY_PREFIX_MAX_LEN;
+	}
+	key->prefixlen = len * 8;
+	return bpf_map_lookup_elem(prefix_map, key) != NULL;
+}
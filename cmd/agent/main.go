@@ -10,6 +10,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
@@ -18,14 +19,22 @@ import (
 
 	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/cgroups"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/enforcementgap"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/eventhandler"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/events"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/eventscraper"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/flagutil"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/grpcexporter"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/nri"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/podannotator"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/podworkload"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/tlsutil"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/loglevel"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/severity"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/workloadpolicyhandler"
+	pb "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 
@@ -33,41 +42,103 @@ import (
 	otellog "go.opentelemetry.io/otel/log"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	toolscache "k8s.io/client-go/tools/cache"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 const wpSyncInProgressMsg = "waiting for WorkloadPolicy synchronization to complete"
 
+const (
+	eventSinkOTLP   = "otlp"
+	eventSinkStdout = "stdout"
+	eventSinkUnix   = "unix"
+)
+
 type Config struct {
 	learningNamespaceSelector string
+	learningStabilization     time.Duration
+	learningChannelOverflow   string
+	learningRedactedPaths     string
+	learningScripts           string
+	learningInvokedPaths      bool
+	approvalLabelKey          string
 	nriSocketPath             string
 	nriPluginIdx              string
+	nriMaxResolutions         int
+	nriCgroupCacheTTL         time.Duration
+	nriCgroupMoveInterval     time.Duration
+	nriRetryInitialDelay      time.Duration
+	nriRetryMaxDelay          time.Duration
+	nriLivenessInterval       time.Duration
+	nriLivenessTimeout        time.Duration
+	nriTrackSandboxCgroups    bool
+	nriResolveWorkloadOwners  bool
+	cgroupResolveStrategy     string
 	probeAddr                 string
 	grpcConf                  grpcexporter.Config
 	logLevel                  string
+	resolverLogLevel          string
 	otlpEndpoint              string
 	otlpProtocol              string
 	otlpCACert                string
 	otlpClientCert            string
 	otlpClientKey             string
+	otlpHeaders               string
+	otlpTraces                bool
+	eventPodLabels            string
+	eventPodAnnotations       string
+	eventSink                 string
+	eventSocketPath           string
+	minExportSeverity         string
+	eventDedupWindow          time.Duration
+	eventAncestryDepth        int
+	globalAllowList           string
+	excludeOwnCgroup          bool
+	enableHashMatching        bool
+	enforceEphemeral          bool
+	maxPolicies               int
+	grpcTLSMinVersion         string
+	grpcTLSCipherSuites       string
+	execBypassIdentities      string
+	unresolvedThreshold       time.Duration
+	deletedRetention          time.Duration
+	deletedCompaction         time.Duration
+	unresolvedEvents          bool
+	annotatePods              bool
+	annotatePodsQPS           float64
 	nodeName                  string
 	violationLogger           otellog.Logger
+	resolverLogger            *slog.Logger
 }
 
 func (c Config) learningEnabled() bool {
 	return strings.TrimSpace(c.learningNamespaceSelector) != ""
 }
 
-func newControllerManager(config Config) (manager.Manager, error) {
+// newControllerManager creates the manager. A value is sent on watchErrors, without blocking,
+// each time the watch of an informer breaks, so that the state built from it can be re-synchronized.
+func newControllerManager(config Config, watchErrors chan<- struct{}) (manager.Manager, error) {
 	scheme := runtime.NewScheme()
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(securityv1alpha1.AddToScheme(scheme))
 	controllerOptions := ctrl.Options{
 		Scheme:                 scheme,
 		HealthProbeBindAddress: config.probeAddr,
+		Cache: cache.Options{
+			DefaultWatchErrorHandler: func(ctx context.Context, r *toolscache.Reflector, err error) {
+				toolscache.DefaultWatchErrorHandler(ctx, r, err)
+				select {
+				case watchErrors <- struct{}{}:
+				default:
+				}
+			},
+		},
 	}
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), controllerOptions)
 	if err != nil {
@@ -82,8 +153,43 @@ func setupGRPCExporter(
 	conf *grpcexporter.Config,
 	r *resolver.Resolver,
 	violationBuffer *violationbuf.Buffer,
+	kernelFeatures []bpf.KernelFeature,
+	loadTimeConfig bpf.LoadTimeConfig,
+	nriHandler *nri.Handler,
 ) error {
-	exporter, err := grpcexporter.New(logger, conf, r, violationBuffer)
+	pbKernelFeatures := make([]*pb.KernelFeature, 0, len(kernelFeatures))
+	for _, f := range kernelFeatures {
+		pbKernelFeatures = append(pbKernelFeatures, &pb.KernelFeature{
+			Name:      f.Name,
+			Supported: f.Supported,
+			Error:     f.Error,
+		})
+	}
+	bpfLoadConfig := &pb.BpfLoadConfig{
+		CgroupFsMagic:     loadTimeConfig.CgroupFsMagic,
+		Cgroupv1SubsysIdx: loadTimeConfig.CgroupV1SubsysIdx,
+		DebugMode:         loadTimeConfig.DebugMode,
+		LearningEnabled:   loadTimeConfig.LearningEnabled,
+	}
+	containerRuntime := func() *pb.ContainerRuntime {
+		runtime := nriHandler.RuntimeInfo()
+		return &pb.ContainerRuntime{
+			Endpoint: runtime.Endpoint,
+			Name:     runtime.Name,
+			Version:  runtime.Version,
+		}
+	}
+	cgroupResolution := func() *pb.CgroupResolution {
+		resolution := nriHandler.CgroupResolution()
+		return &pb.CgroupResolution{
+			ConsecutiveFailures: resolution.ConsecutiveFailures,
+			LastError:           resolution.LastError,
+		}
+	}
+	exporter, err := grpcexporter.New(
+		logger, conf, r, violationBuffer, pbKernelFeatures, bpfLoadConfig, containerRuntime, cgroupResolution,
+		ctrlMgr.GetEventRecorder("runtime-enforcer-agent"),
+	)
 	if err != nil {
 		return fmt.Errorf("failed to create gRPC exporter: %w", err)
 	}
@@ -97,8 +203,14 @@ func setupWorkloadPolicyHandler(
 	ctrlMgr manager.Manager,
 	logger *slog.Logger,
 	resolver *resolver.Resolver,
+	watchErrors <-chan struct{},
 ) (*workloadpolicyhandler.WorkloadPolicyHandler, error) {
-	wpHandler := workloadpolicyhandler.NewWorkloadPolicyHandler(ctrlMgr.GetClient(), logger, resolver)
+	wpHandler := workloadpolicyhandler.NewWorkloadPolicyHandler(
+		ctrlMgr.GetClient(),
+		logger,
+		resolver,
+		workloadpolicyhandler.WithResyncRequests(watchErrors),
+	)
 	err := wpHandler.SetupWithManager(ctrlMgr)
 	if err != nil {
 		return nil, fmt.Errorf("unable to set up WorkloadPolicy handler: %w", err)
@@ -189,12 +301,39 @@ func setupLearningReconciler(
 	}
 	nsSelector = selector
 
+	overflowPolicy, err := eventhandler.ParseChannelOverflowPolicy(config.learningChannelOverflow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid channel-overflow: %w", err)
+	}
+
+	if errs := validation.IsQualifiedName(config.approvalLabelKey); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid approval-label-key %q: %s", config.approvalLabelKey, strings.Join(errs, "; "))
+	}
+
+	opts := []eventhandler.Option{
+		eventhandler.WithProposalStabilizationWindow(config.learningStabilization),
+		eventhandler.WithChannelOverflowPolicy(overflowPolicy),
+		eventhandler.WithApprovalLabelKey(config.approvalLabelKey),
+		eventhandler.WithNodeName(config.nodeName),
+	}
+	if config.learningRedactedPaths != "" {
+		redactedPaths, compileErr := regexp.Compile(config.learningRedactedPaths)
+		if compileErr != nil {
+			return nil, fmt.Errorf("invalid learning-redacted-paths %q: %w", config.learningRedactedPaths, compileErr)
+		}
+		opts = append(opts, eventhandler.WithRedactedPaths(redactedPaths))
+	}
+
 	// Wait until mutating admission webhook is ready.
 	if err = waitForMutatingAdmissionWebhook(ctx); err != nil {
 		return nil, err
 	}
 
-	learningReconciler := eventhandler.NewLearningReconciler(ctrlMgr.GetClient(), nsSelector)
+	learningReconciler := eventhandler.NewLearningReconciler(
+		ctrlMgr.GetClient(),
+		nsSelector,
+		opts...,
+	)
 	if err = learningReconciler.SetupWithManager(ctrlMgr); err != nil {
 		return nil, fmt.Errorf("unable to create learning reconciler: %w", err)
 	}
@@ -202,13 +341,25 @@ func setupLearningReconciler(
 	return learningReconciler.EnqueueEvent, nil
 }
 
+// kubebuilder annotations for resolving the workload of the pods from their owner references.
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get
+
+// The permission to patch the pods for the enforcement summary annotation is granted by a hand-written
+// role of the chart, rendered only when the annotation is enabled.
+
+// kubebuilder annotations for reporting the containers that are never enforced.
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
+
 func startAgent(ctx context.Context, logger *slog.Logger, config Config) error {
 	var err error
 
 	//////////////////////
 	// Create controller manager
 	//////////////////////
-	ctrlMgr, err := newControllerManager(config)
+	watchErrors := make(chan struct{}, 1)
+	ctrlMgr, err := newControllerManager(config, watchErrors)
 	if err != nil {
 		return fmt.Errorf("cannot create manager: %w", err)
 	}
@@ -223,6 +374,12 @@ func startAgent(ctx context.Context, logger *slog.Logger, config Config) error {
 	if err = ctrlMgr.Add(bpfManager); err != nil {
 		return fmt.Errorf("failed to add BPF manager to controller manager: %w", err)
 	}
+	if err = metrics.Registry.Register(bpf.NewKernelFeaturesGauge(bpfManager.KernelFeatures())); err != nil {
+		return fmt.Errorf("failed to register kernel features metrics: %w", err)
+	}
+	if err = metrics.Registry.Register(bpfManager.MapMemoryCollector()); err != nil {
+		return fmt.Errorf("failed to register BPF map memory metrics: %w", err)
+	}
 
 	//////////////////////
 	// Create Learning Reconciler if learning is enabled
@@ -236,27 +393,124 @@ func startAgent(ctx context.Context, logger *slog.Logger, config Config) error {
 	// Create the resolver
 	//////////////////////
 	resolver, err := resolver.NewResolver(
-		logger,
+		config.resolverLogger,
 		bpfManager.GetCgroupTrackerUpdateFunc(),
 		bpfManager.GetCgroupPolicyUpdateFunc(),
 		bpfManager.GetPolicyUpdateBinariesFunc(),
+		bpfManager.GetPolicyUpdatePrefixesFunc(),
 		bpfManager.GetPolicyModeUpdateFunc(),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create resolver: %w", err)
 	}
+	if err = resolver.SetGlobalAllowList(flagutil.ParseList(config.globalAllowList)); err != nil {
+		return fmt.Errorf("failed to set global allow list: %w", err)
+	}
+	if config.enableHashMatching {
+		resolver.EnableHashMatching(bpfManager.GetExecPinsUpdateFunc())
+	}
+	if config.enforceEphemeral {
+		resolver.EnforceEphemeralContainers()
+	}
+	if config.maxPolicies < 0 {
+		return fmt.Errorf("invalid max-policies: %d, it must not be negative", config.maxPolicies)
+	}
+	resolver.SetMaxPolicies(config.maxPolicies)
+	if config.deletedRetention > 0 && config.deletedCompaction <= 0 {
+		return fmt.Errorf("invalid deleted-container-compaction-interval: %v, it must be positive",
+			config.deletedCompaction)
+	}
+	resolver.SetDeletedContainerRetention(config.deletedRetention, config.deletedCompaction)
+	if config.excludeOwnCgroup {
+		var selfCgroupID uint64
+		if selfCgroupID, err = cgroups.GetSelfCgroupID(); err != nil {
+			return fmt.Errorf("failed to detect the cgroup of the agent: %w", err)
+		}
+		logger.InfoContext(ctx, "the cgroup of the agent is excluded from the policies", "cgroupID", selfCgroupID)
+		resolver.SetSelfCgroupID(selfCgroupID)
+	}
+	config.grpcConf.ExecBypassIdentities = flagutil.ParseList(config.execBypassIdentities)
+	if config.grpcConf.TLS, err = tlsutil.ParseOptions(config.grpcTLSMinVersion, config.grpcTLSCipherSuites); err != nil {
+		return fmt.Errorf("invalid gRPC TLS options: %w", err)
+	}
+	if err = metrics.Registry.Register(resolver.TrackedPodsCollector()); err != nil {
+		return fmt.Errorf("failed to register tracked pods metrics: %w", err)
+	}
+	if err = metrics.Registry.Register(resolver.PolicyCoverageCollector()); err != nil {
+		return fmt.Errorf("failed to register policy coverage metrics: %w", err)
+	}
+	if err = metrics.Registry.Register(resolver.UnresolvedContainersCollector(config.unresolvedThreshold)); err != nil {
+		return fmt.Errorf("failed to register unresolved containers metrics: %w", err)
+	}
+	if err = ctrlMgr.Add(manager.RunnableFunc(resolver.RunActiveWindows)); err != nil {
+		return fmt.Errorf("failed to add resolver's active windows to controller manager: %w", err)
+	}
+	if err = ctrlMgr.Add(manager.RunnableFunc(resolver.RunExecBypassExpiry)); err != nil {
+		return fmt.Errorf("failed to add resolver's exec bypass expiry to controller manager: %w", err)
+	}
+	if err = ctrlMgr.Add(manager.RunnableFunc(resolver.RunDeletedContainerCompaction)); err != nil {
+		return fmt.Errorf("failed to add resolver's deleted container compaction to controller manager: %w", err)
+	}
+	if config.annotatePods {
+		if config.annotatePodsQPS <= 0 {
+			return fmt.Errorf("invalid annotate-pods-qps: %v, it must be positive", config.annotatePodsQPS)
+		}
+		annotator := podannotator.NewAnnotator(ctrlMgr.GetClient(), logger, resolver.PodEnforcements,
+			podannotator.WithQPS(config.annotatePodsQPS))
+		if err = ctrlMgr.Add(annotator); err != nil {
+			return fmt.Errorf("failed to add pod annotator to controller manager: %w", err)
+		}
+	}
+
+	if config.unresolvedEvents {
+		reporter := enforcementgap.NewReporter(ctrlMgr.GetEventRecorder("runtime-enforcer-agent"),
+			resolver.UnresolvedContainers, enforcementgap.WithThreshold(config.unresolvedThreshold))
+		if err = ctrlMgr.Add(reporter); err != nil {
+			return fmt.Errorf("failed to add unresolved containers reporter to controller manager: %w", err)
+		}
+	}
 
-	wpHandler, err := setupWorkloadPolicyHandler(ctrlMgr, logger, resolver)
+	wpHandler, err := setupWorkloadPolicyHandler(ctrlMgr, logger, resolver, watchErrors)
 	if err != nil {
 		return err
 	}
+	if err = workloadpolicyhandler.NewNamespaceHandler(
+		ctrlMgr.GetClient(),
+		logger,
+		resolver,
+	).SetupWithManager(ctrlMgr); err != nil {
+		return fmt.Errorf("unable to set up Namespace handler: %w", err)
+	}
 
+	cgroupResolveStrategies, err := nri.ParseCgroupResolveStrategies(config.cgroupResolveStrategy)
+	if err != nil {
+		return fmt.Errorf("invalid cgroup-resolve-strategy: %w", err)
+	}
+	nriOpts := []nri.Option{
+		nri.WithMaxConcurrentResolutions(config.nriMaxResolutions),
+		nri.WithCgroupCacheTTL(config.nriCgroupCacheTTL),
+		nri.WithCgroupMoveDetection(config.nriCgroupMoveInterval),
+		nri.WithRetryBackoff(config.nriRetryInitialDelay, config.nriRetryMaxDelay),
+		nri.WithLivenessProbe(config.nriLivenessInterval, config.nriLivenessTimeout),
+		nri.WithPodAnnotations(flagutil.ParseList(config.eventPodAnnotations)),
+		nri.WithSandboxCgroupTracking(config.nriTrackSandboxCgroups),
+		nri.WithCgroupResolveStrategies(cgroupResolveStrategies),
+	}
+	if config.nriResolveWorkloadOwners {
+		// the pods are read once when their containers start, they are not worth caching.
+		nriOpts = append(nriOpts, nri.WithWorkloadOwnerResolver(podworkload.NewOwnerResolver(ctrlMgr.GetAPIReader())))
+	}
+	if !config.enforceEphemeral {
+		// the ephemeral containers only need to be told apart to be exempted.
+		nriOpts = append(nriOpts, nri.WithEphemeralContainerLookup(ctrlMgr.GetAPIReader()))
+	}
 	var nriHandler *nri.Handler
 	nriHandler, err = nri.NewNRIHandler(
 		config.nriSocketPath,
 		config.nriPluginIdx,
 		logger,
 		resolver,
+		nriOpts...,
 	)
 
 	if err != nil {
@@ -271,6 +525,9 @@ func startAgent(ctx context.Context, logger *slog.Logger, config Config) error {
 	if err = ctrlMgr.AddReadyzCheck("resolver readyz", resolver.Ping); err != nil {
 		return fmt.Errorf("failed to add resolver's readiness probe: %w", err)
 	}
+	if err = ctrlMgr.AddReadyzCheck("nri readyz", nriHandler.Ping); err != nil {
+		return fmt.Errorf("failed to add NRI handler's readiness probe: %w", err)
+	}
 
 	//////////////////////
 	// Create the violation buffer
@@ -280,11 +537,35 @@ func startAgent(ctx context.Context, logger *slog.Logger, config Config) error {
 	//////////////////////
 	// Create the scraper
 	//////////////////////
+	minExportSeverity, err := severity.Parse(config.minExportSeverity)
+	if err != nil {
+		return fmt.Errorf("invalid min-export-severity: %w", err)
+	}
+	if config.eventDedupWindow < 0 {
+		return fmt.Errorf("invalid event-dedup-window: %v", config.eventDedupWindow)
+	}
+	if config.eventAncestryDepth < 0 || config.eventAncestryDepth > bpf.MaxAncestryDepth {
+		return fmt.Errorf("invalid event-ancestry-depth: %d, it must be between 0 and %d",
+			config.eventAncestryDepth, bpf.MaxAncestryDepth)
+	}
+	scriptLearning, err := eventscraper.ParseScriptLearning(config.learningScripts)
+	if err != nil {
+		return fmt.Errorf("invalid learning-scripts: %w", err)
+	}
 	var scraperOpts []eventscraper.Option
 	if config.violationLogger != nil {
 		scraperOpts = append(scraperOpts, eventscraper.WithViolationLogger(config.violationLogger, config.nodeName))
 	}
-	scraperOpts = append(scraperOpts, eventscraper.WithViolationBuffer(violationBuffer, config.nodeName))
+	scraperOpts = append(scraperOpts,
+		eventscraper.WithViolationBuffer(violationBuffer, config.nodeName),
+		eventscraper.WithPodAttributes(flagutil.ParseList(config.eventPodLabels), flagutil.ParseList(config.eventPodAnnotations)),
+		eventscraper.WithMinExportSeverity(minExportSeverity),
+		eventscraper.WithDedupWindow(config.eventDedupWindow),
+		eventscraper.WithProcessAncestry(config.eventAncestryDepth),
+		eventscraper.WithScriptLearning(scriptLearning),
+		eventscraper.WithInvokedPathLearning(config.learningInvokedPaths),
+		eventscraper.WithAutoProtectLearning(config.learningEnabled()),
+	)
 	evtScraper := eventscraper.NewEventScraper(
 		bpfManager.GetLearningChannel(),
 		bpfManager.GetMonitoringChannel(),
@@ -300,7 +581,10 @@ func startAgent(ctx context.Context, logger *slog.Logger, config Config) error {
 	//////////////////////
 	// Add GRPC exporter
 	//////////////////////
-	if err = setupGRPCExporter(ctrlMgr, logger, &config.grpcConf, resolver, violationBuffer); err != nil {
+	if err = setupGRPCExporter(
+		ctrlMgr, logger, &config.grpcConf, resolver, violationBuffer,
+		bpfManager.KernelFeatures(), bpfManager.LoadTimeConfig(), nriHandler,
+	); err != nil {
 		return err
 	}
 
@@ -349,20 +633,127 @@ func parseFlags() Config {
 		"",
 		"Namespace selector for learning. Accepts a JSON LabelSelector",
 	)
+	flag.DurationVar(
+		&config.learningStabilization,
+		"learning-stabilization-window",
+		eventhandler.DefaultProposalStabilizationWindow,
+		"How long a WorkloadPolicyProposal can keep learning new executables before it is reported as never stabilized",
+	)
+	flag.StringVar(
+		&config.learningChannelOverflow,
+		"channel-overflow",
+		string(eventhandler.ChannelOverflowDrop),
+		"What to do with learning events when the learning channel is full: "+
+			"drop (drop and count them) or block (wait, no event is lost)",
+	)
+	flag.StringVar(
+		&config.learningRedactedPaths,
+		"learning-redacted-paths",
+		"",
+		"Regular expression matching the executable paths that are never learned, e.g. paths containing tokens",
+	)
+	flag.StringVar(
+		&config.learningScripts,
+		"learning-scripts",
+		string(eventscraper.ScriptLearningScript),
+		"What is learned when a script with a shebang is executed: script, interpreter or both. "+
+			"Policies are enforced on the script path, so they don't allow scripts learned as interpreter only",
+	)
+	flag.BoolVar(
+		&config.learningInvokedPaths,
+		"learning-invoked-paths",
+		false,
+		"Also learn the path used to execute a binary when it differs from the resolved one, e.g. a symlink",
+	)
+	flag.StringVar(
+		&config.approvalLabelKey,
+		"approval-label-key",
+		securityv1alpha1.ApprovalLabelKey,
+		"Label marking a WorkloadPolicyProposal as approved, learning stops on it. "+
+			"It must match the approval-label-key of the controller",
+	)
 	flag.StringVar(&config.nriSocketPath, "nri-socket-path", "/var/run/nri/nri.sock", "NRI socket path")
 	flag.StringVar(&config.nriPluginIdx, "nri-plugin-index", "00", "NRI plugin index")
+	flag.IntVar(&config.nriMaxResolutions, "nri-max-concurrent-resolutions", nri.DefaultMaxConcurrentResolutions,
+		"Maximum number of containers whose cgroup is resolved at the same time (0 = unlimited)")
+	flag.DurationVar(&config.nriCgroupCacheTTL, "nri-cgroup-cache-ttl", nri.DefaultCgroupCacheTTL,
+		"How long the resolved cgroup of a container is reused after an NRI reconnection (0 = disabled)")
+	flag.DurationVar(&config.nriCgroupMoveInterval, "nri-cgroup-move-check-interval", nri.DefaultCgroupMoveCheckInterval,
+		"How often the cgroup of the running containers is resolved again to enforce the cgroups recreated "+
+			"by systemd, e.g. during a reload (0 = disabled)")
+	flag.DurationVar(&config.nriRetryInitialDelay, "nri-retry-initial-delay", nri.DefaultRetryInitialDelay,
+		"Delay before the first reconnection to the container runtime, doubled at each failed attempt")
+	flag.DurationVar(&config.nriRetryMaxDelay, "nri-retry-max-delay", nri.DefaultRetryMaxDelay,
+		"Maximum delay between two reconnections to the container runtime")
+	flag.DurationVar(&config.nriLivenessInterval, "nri-liveness-interval", 0,
+		"How often an empty container update is sent to the container runtime to detect a stalled NRI connection "+
+			"(0 = disabled)")
+	flag.DurationVar(&config.nriLivenessTimeout, "nri-liveness-timeout", nri.DefaultLivenessTimeout,
+		"Reconnect to the container runtime when it doesn't answer the NRI liveness probe within this duration")
+	flag.BoolVar(&config.nriTrackSandboxCgroups, "nri-track-sandbox-cgroups", false,
+		"Resolve and keep the cgroup of the pause container of each pod")
+	flag.BoolVar(&config.nriResolveWorkloadOwners, "nri-resolve-workload-owners", false,
+		"Find the workload of a pod from its owner references on the API server instead of its name. "+
+			"The name is still used when the owners can't be resolved")
+	flag.StringVar(&config.cgroupResolveStrategy, "cgroup-resolve-strategy", nri.DefaultCgroupResolveStrategies,
+		"Comma separated strategies tried in order to find the cgroup of a container, the first success wins. "+
+			"nri resolves the cgroups path reported by the runtime, fs searches the cgroup filesystem for the container ID")
+	flag.StringVar(&config.globalAllowList, "global-allow-list", "",
+		"Comma separated executables allowed in every container enforced by a policy, e.g. \"/pause,/sbin/tini\"")
+	flag.BoolVar(&config.excludeOwnCgroup, "exclude-own-cgroup", true,
+		"Never attach the cgroup of the agent container to a policy, so that the agent cannot block its own executables")
+	flag.BoolVar(&config.enableHashMatching, "enable-hash-matching", false,
+		"Allow the executables of the allowedHashes rules only in the containers where their SHA-256 digest matches. "+
+			"Without it they are never allowed")
+	flag.BoolVar(&config.enforceEphemeral, "enforce-ephemeral-containers", false,
+		"Deny the unlisted ephemeral containers, e.g. the ones attached by kubectl debug, like the other unlisted "+
+			"containers. By default they are only enforced when the policy has rules for them")
+	flag.IntVar(&config.maxPolicies, "max-policies", 0,
+		"Maximum number of policies loaded by the agent, the policies beyond it are rejected with an error status. "+
+			"0 means unlimited")
+	flag.DurationVar(&config.unresolvedThreshold, "unresolved-container-threshold", enforcementgap.DefaultThreshold,
+		"How long a container can be created without a resolved cgroup before it is reported as not enforced")
+	flag.DurationVar(&config.deletedRetention, "deleted-container-retention", resolver.DefaultDeletedContainerRetention,
+		"How long a removed container is still resolved, e.g. for its violations read after its removal. 0 disables it")
+	flag.DurationVar(&config.deletedCompaction, "deleted-container-compaction-interval",
+		resolver.DefaultDeletedContainerCompactionInterval,
+		"How often the removed containers whose retention expired are dropped")
+	flag.BoolVar(&config.unresolvedEvents, "unresolved-container-events", false,
+		"Emit a warning Event on the pods whose containers are reported as not enforced")
+	flag.BoolVar(&config.annotatePods, "annotate-pods", false,
+		"Maintain on the pods of the node an annotation summarizing how the agent enforces them")
+	flag.Float64Var(&config.annotatePodsQPS, "annotate-pods-qps", podannotator.DefaultQPS,
+		"Maximum number of pod annotation updates per second")
 	flag.StringVar(&config.probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.IntVar(&config.grpcConf.Port, "grpc-port", 50051, "gRPC server port")
 	flag.BoolVar(&config.grpcConf.MTLSEnabled, "grpc-mtls-enabled", true,
 		"Enable mutual TLS between the agent server and clients")
 	flag.StringVar(&config.grpcConf.CertDirPath, "grpc-mtls-cert-dir", "",
 		"Path to the directory containing the server and ca TLS certificate")
+	flag.BoolVar(&config.grpcConf.ReflectionEnabled, "enable-grpc-reflection", false,
+		"Register the gRPC reflection service on the agent server, for debugging purposes")
+	flag.StringVar(&config.grpcTLSMinVersion, "grpc-tls-min-version", tlsutil.DefaultMinVersion,
+		"Minimum TLS version accepted by the agent gRPC server with mTLS (1.2, 1.3)")
+	flag.StringVar(&config.grpcTLSCipherSuites, "grpc-tls-cipher-suites", "",
+		"Comma separated list of the TLS 1.2 cipher suites offered by the agent gRPC server, "+
+			"e.g. TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384. Empty means the Go defaults. "+
+			"Requires --grpc-tls-min-version=1.2, the TLS 1.3 cipher suites are not configurable")
+	flag.StringVar(&config.execBypassIdentities, "exec-bypass-identities", "",
+		"Comma separated URI or DNS SANs of the mTLS client certificates allowed to grant exec bypasses, "+
+			"e.g. spiffe://cluster.local/ns/ops/sa/debugger. Empty means no client is allowed")
 	flag.StringVar(
 		&config.logLevel,
 		"log-level",
 		"info",
 		"agent logger level (debug, info, warn, error)",
 	)
+	flag.StringVar(
+		&config.resolverLogLevel,
+		"resolver-log-level",
+		"",
+		"resolver logger level (debug, info, warn, error), the resolver logs every pod and policy change at debug. "+
+			"Defaults to --log-level",
+	)
 	flag.StringVar(
 		&config.otlpEndpoint,
 		"otlp-endpoint",
@@ -391,10 +782,110 @@ func parseFlags() Config {
 		"Node name for violation reporting (defaults to NODE_NAME env var)")
 	flag.StringVar(&config.otlpProtocol, "otlp-protocol", os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"),
 		"OTLP protocol (defaults to OTEL_EXPORTER_OTLP_PROTOCOL env var)")
+	flag.StringVar(&config.otlpHeaders, "otlp-headers", os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"),
+		"Comma separated key=value headers sent to the OTLP collector, e.g. for authentication "+
+			"(defaults to OTEL_EXPORTER_OTLP_HEADERS env var)")
+	flag.BoolVar(&config.otlpTraces, "otlp-traces", false,
+		"Export to --otlp-endpoint the traces of the pod enforcement setup, e.g. the cgroup resolution and "+
+			"the policy application at pod start, for performance investigations. Requires the grpc protocol")
+	flag.StringVar(&config.eventSink, "event-sink", eventSinkOTLP,
+		"Where violation events are exported: \"otlp\" sends them to --otlp-endpoint, "+
+			"\"stdout\" writes them as JSON lines on the agent stdout and moves the logs to stderr, "+
+			"\"unix\" writes them as JSON lines to the Unix socket at --event-socket-path")
+	flag.StringVar(&config.eventSocketPath, "event-socket-path", "",
+		"Unix socket served by a node-local collector, the violation events are written to it with --event-sink=unix")
+	flag.StringVar(&config.eventPodLabels, "event-pod-labels", "",
+		"Comma separated pod labels added to the violation events, e.g. \"team,env\"")
+	flag.StringVar(&config.eventPodAnnotations, "event-pod-annotations", "",
+		"Comma separated pod annotations added to the violation events")
+	flag.StringVar(&config.minExportSeverity, "min-export-severity", severity.LowString,
+		"Minimum severity of the policies whose violation events are exported: low, medium, high or critical")
+	flag.DurationVar(&config.eventDedupWindow, "event-dedup-window", 0,
+		"Window within which the identical violation events, same pod, executable and action, are exported "+
+			"as a single event with a violation.count attribute. 0 disables the deduplication")
+	flag.IntVar(&config.eventAncestryDepth, "event-ancestry-depth", 0,
+		"Number of ancestors of the parent executable, e.g. 1 for the grandparent, whose executables are added "+
+			"to the violation events as proc.aexepath[N] attributes. 0 disables the process ancestry")
 	flag.Parse()
 	return config
 }
 
+// setupViolationLogger creates the logger violation events are emitted to, according to the configured sink.
+// A nil logger is returned when violation events are not exported.
+func setupViolationLogger(
+	ctx context.Context,
+	logger *slog.Logger,
+	config Config,
+) (otellog.Logger, func(context.Context) error, error) {
+	switch config.eventSink {
+	case eventSinkStdout:
+		violationLogger, shutdown := events.InitStdout(os.Stdout)
+		logger.InfoContext(ctx, "violation events are written to stdout")
+		return violationLogger, shutdown, nil
+	case eventSinkUnix:
+		if config.eventSocketPath == "" {
+			return nil, nil, fmt.Errorf("--event-socket-path is required with --event-sink=%s", eventSinkUnix)
+		}
+		violationLogger, shutdown := events.InitUnixSocket(config.eventSocketPath)
+		logger.InfoContext(ctx, "violation events are written to a Unix socket", "path", config.eventSocketPath)
+		return violationLogger, shutdown, nil
+	case eventSinkOTLP:
+		if config.otlpEndpoint == "" {
+			return nil, nil, nil
+		}
+		headers, err := events.ParseHeaders(config.otlpHeaders)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse OTLP headers: %w", err)
+		}
+		violationLogger, shutdown, err := events.Init(
+			ctx,
+			config.otlpEndpoint,
+			config.otlpCACert,
+			config.otlpClientCert,
+			config.otlpClientKey,
+			config.otlpProtocol,
+			headers,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		logger.InfoContext(ctx, "OTLP telemetry enabled", "endpoint", config.otlpEndpoint)
+		return violationLogger, shutdown, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported event sink %q: must be %q, %q or %q",
+			config.eventSink, eventSinkOTLP, eventSinkStdout, eventSinkUnix)
+	}
+}
+
+// setupTraces registers the tracer provider exporting the spans to the OTLP endpoint.
+// A nil shutdown function is returned when tracing is disabled.
+func setupTraces(ctx context.Context, logger *slog.Logger, config Config) (func(context.Context) error, error) {
+	if !config.otlpTraces {
+		return nil, nil
+	}
+	if config.otlpEndpoint == "" {
+		return nil, errors.New("--otlp-endpoint is required with --otlp-traces")
+	}
+	headers, err := events.ParseHeaders(config.otlpHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OTLP headers: %w", err)
+	}
+	shutdown, err := events.InitTraces(
+		ctx,
+		config.otlpEndpoint,
+		config.otlpCACert,
+		config.otlpClientCert,
+		config.otlpClientKey,
+		config.otlpProtocol,
+		headers,
+	)
+	if err != nil {
+		return nil, err
+	}
+	logger.InfoContext(ctx, "OTLP tracing enabled", "endpoint", config.otlpEndpoint)
+	return shutdown, nil
+}
+
 func main() {
 	var err error
 	config := parseFlags()
@@ -407,28 +898,41 @@ func main() {
 		os.Exit(1)
 	}
 
-	slogHandler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel})
+	// With the stdout sink the violation events own stdout, the logs go to stderr so that
+	// the log shippers can tell the two streams apart.
+	logOutput := os.Stdout
+	if config.eventSink == eventSinkStdout {
+		logOutput = os.Stderr
+	}
+	slogHandler := slog.NewJSONHandler(logOutput, &slog.HandlerOptions{Level: logLevel})
 	slogger := slog.New(slogHandler).With("component", "agent")
 	slog.SetDefault(slogger)
 	ctrl.SetLogger(logr.FromSlogHandler(slogger.Handler()))
 
-	var eventShutdown func(context.Context) error
-	if config.otlpEndpoint != "" {
-		var violationLogger otellog.Logger
-		violationLogger, eventShutdown, err = events.Init(
-			ctx,
-			config.otlpEndpoint,
-			config.otlpCACert,
-			config.otlpClientCert,
-			config.otlpClientKey,
-			config.otlpProtocol,
-		)
-		if err != nil {
-			slogger.ErrorContext(ctx, "failed to initiate violation event pipeline", "error", err)
+	config.resolverLogger = slogger
+	if config.resolverLogLevel != "" {
+		// the resolver gets its own handler: its level can be lower than the one of the other components.
+		resolverLogLevel := slog.LevelInfo
+		if err = resolverLogLevel.UnmarshalText([]byte(config.resolverLogLevel)); err != nil {
+			slogger.ErrorContext(ctx, "failed to parse resolver log level", "error", err)
 			os.Exit(1)
 		}
-		config.violationLogger = violationLogger
-		slogger.InfoContext(ctx, "OTLP telemetry enabled", "endpoint", config.otlpEndpoint)
+		config.resolverLogger = slog.New(
+			slog.NewJSONHandler(logOutput, &slog.HandlerOptions{Level: resolverLogLevel}),
+		).With("component", "agent")
+	}
+
+	violationLogger, eventShutdown, err := setupViolationLogger(ctx, slogger, config)
+	if err != nil {
+		slogger.ErrorContext(ctx, "failed to initiate violation event pipeline", "error", err)
+		os.Exit(1)
+	}
+	config.violationLogger = violationLogger
+
+	traceShutdown, err := setupTraces(ctx, slogger, config)
+	if err != nil {
+		slogger.ErrorContext(ctx, "failed to initiate trace pipeline", "error", err)
+		os.Exit(1)
 	}
 
 	// This function blocks if everything is alright.
@@ -442,4 +946,9 @@ func main() {
 			slogger.ErrorContext(ctx, "failed to shutdown violation event pipeline", "error", err)
 		}
 	}
+	if traceShutdown != nil {
+		if err = traceShutdown(ctx); err != nil {
+			slogger.ErrorContext(ctx, "failed to shutdown trace pipeline", "error", err)
+		}
+	}
 }
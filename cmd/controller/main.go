@@ -7,18 +7,23 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -30,7 +35,9 @@ import (
 	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/controller"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/customloggers/httpserverlogger"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/flagutil"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/grpcexporter"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/kernels"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -45,6 +52,12 @@ type Config struct {
 	tlsOpts                                          []func(*tls.Config)
 	wpStatusSyncConfig                               controller.WorkloadPolicyStatusSyncConfig
 	logLevel                                         string
+	enablePodPolicyLabelWebhook                      bool
+	approvalLabelKey                                 string
+	restrictedNamespaces                             string
+	minKernelVersion                                 string
+	enableHashMatching                               bool
+	autoProtectObservationInterval                   time.Duration
 }
 
 func parseFlags() Config {
@@ -76,10 +89,23 @@ func parseFlags() Config {
 		"wp-status-reconciler-update-interval",
 		0,
 		"The interval at which the workload policy status reconciler updates the status of WorkloadPolicy resources.")
+	flag.DurationVar(&config.wpStatusSyncConfig.MissingPolicyGracePeriod,
+		"wp-status-reconciler-missing-policy-grace-period",
+		0,
+		"How long a policy can be missing on a node, e.g. during a rollout, before the node is reported as failed. "+
+			"The node is reported as pending in the meantime (0 = no grace period).")
+	flag.IntVar(&config.wpStatusSyncConfig.AgentConcurrency,
+		"wp-status-reconciler-agent-concurrency",
+		controller.DefaultAgentConcurrency,
+		"The number of agents queried in parallel by the workload policy status reconciler.")
 	flag.StringVar(&config.wpStatusSyncConfig.AgentPoolConf.LabelSelectorString,
 		"wp-status-reconciler-agent-label-selector",
 		grpcexporter.DefaultAgentLabelSelectorString,
 		"The label selector for the agent pods as a comma concatenated string.")
+	flag.StringVar(&config.wpStatusSyncConfig.AgentPoolConf.Namespace,
+		"wp-status-reconciler-agent-namespace",
+		"",
+		"The namespace of the agent pods. Defaults to the namespace of the controller.")
 	flag.BoolVar(&config.wpStatusSyncConfig.AgentPoolConf.MTLSEnabled,
 		"wp-status-reconciler-agent-grpc-mtls-enabled",
 		true,
@@ -88,6 +114,49 @@ func parseFlags() Config {
 		"wp-status-reconciler-agent-grpc-mtls-cert-dir",
 		grpcexporter.DefaultCertDirPath,
 		"Path to the directory containing the client and ca TLS certificate.")
+	flag.DurationVar(&config.wpStatusSyncConfig.AgentPoolConf.IdleTimeout,
+		"wp-status-reconciler-agent-grpc-idle-timeout",
+		grpcexporter.DefaultAgentIdleTimeout,
+		"Close the connections to the agents unused for this duration, they are reopened on demand (0 = never).")
+	flag.IntVar(&config.wpStatusSyncConfig.AgentPoolConf.MaxRecvMsgSize,
+		"wp-status-reconciler-agent-grpc-max-recv-msg-size",
+		grpcexporter.DefaultAgentMaxRecvMsgSize,
+		"Largest response accepted from an agent, in bytes.")
+	flag.StringVar(&config.wpStatusSyncConfig.AgentPoolConf.ExpectedIdentity,
+		"wp-status-reconciler-agent-identity",
+		"",
+		"URI or DNS SAN the agent certificates must have, e.g. "+
+			"spiffe://cluster.local/ns/{namespace}/sa/runtime-enforcer-agent. {namespace}, {pod} and {node} are "+
+			"replaced with the ones of the agent pod. The agents presenting another identity are not trusted. "+
+			"Empty disables the check.")
+	flag.BoolVar(&config.enablePodPolicyLabelWebhook,
+		"enable-pod-policy-label-webhook",
+		false,
+		"Enforce the immutability of the pod policy label with a validating webhook. "+
+			"Use it on clusters where ValidatingAdmissionPolicy is not available.")
+	flag.StringVar(&config.approvalLabelKey,
+		"approval-label-key",
+		securityv1alpha1.ApprovalLabelKey,
+		"Label promoting a WorkloadPolicyProposal to a WorkloadPolicy when set to true. "+
+			"It must match the approval-label-key of the agents")
+	flag.StringVar(&config.restrictedNamespaces,
+		"restricted-namespaces",
+		"",
+		"Comma separated namespaces where the creation of WorkloadPolicies is rejected, e.g. \"kube-system\"")
+	flag.StringVar(&config.minKernelVersion,
+		"min-kernel-version",
+		controller.DefaultMinKernelVersion,
+		"Kernel version below which the nodes cannot enforce WorkloadPolicies, e.g. \"5.8\". "+
+			"Applying a WorkloadPolicy warns when some nodes run an older kernel. Empty disables the check")
+	flag.BoolVar(&config.enableHashMatching,
+		"enable-hash-matching",
+		false,
+		"Accept the WorkloadPolicies with allowedHashes rules. It must match the enable-hash-matching of the agents, "+
+			"without it they never allow the executables of these rules")
+	flag.DurationVar(&config.autoProtectObservationInterval,
+		"auto-protect-observation-interval",
+		controller.DefaultAutoProtectObservationInterval,
+		"Time between two observations of the WorkloadPolicyProposal referenced by the autoProtect of a WorkloadPolicy")
 	flag.StringVar(
 		&config.logLevel,
 		"log-level",
@@ -104,6 +173,8 @@ func SetupControllers(logger logr.Logger,
 	metricsCertWatcher *certwatcher.CertWatcher,
 	webhookCertWatcher *certwatcher.CertWatcher,
 	wpStatusSyncConf *controller.WorkloadPolicyStatusSyncConfig,
+	approvalLabelKey string,
+	autoProtectObservationInterval time.Duration,
 ) error {
 	var err error
 
@@ -111,19 +182,44 @@ func SetupControllers(logger logr.Logger,
 		"config", wpStatusSyncConf)
 
 	var wpStatusSync *controller.WorkloadPolicyStatusSync
-	if wpStatusSync, err = controller.NewWorkloadPolicyStatusSync(mgr.GetClient(), wpStatusSyncConf); err != nil {
+	if wpStatusSync, err = controller.NewWorkloadPolicyStatusSync(
+		mgr.GetClient(),
+		mgr.GetEventRecorder("workloadpolicy-status-sync"),
+		wpStatusSyncConf,
+	); err != nil {
 		return fmt.Errorf("unable to create WorkloadPolicyStatusSync: %w", err)
 	}
 	if err = mgr.Add(wpStatusSync); err != nil {
 		return fmt.Errorf("failed to add WorkloadPolicyStatusSync to controller: %w", err)
 	}
+	if err = metrics.Registry.Register(wpStatusSync.ModeMismatchCollector()); err != nil {
+		return fmt.Errorf("failed to register WorkloadPolicyStatusSync metrics: %w", err)
+	}
+	if err = metrics.Registry.Register(wpStatusSync.BpfConfigMismatchCollector()); err != nil {
+		return fmt.Errorf("failed to register WorkloadPolicyStatusSync metrics: %w", err)
+	}
 
 	if err = (&controller.WorkloadPolicyProposalReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		Recorder:         mgr.GetEventRecorder("workloadpolicyproposal-controller"),
+		ApprovalLabelKey: approvalLabelKey,
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create WorkloadPolicyProposalReconciler controller: %w", err)
 	}
+	if err = (&controller.WorkloadPolicyAutoProtectReconciler{
+		Client:              mgr.GetClient(),
+		Recorder:            mgr.GetEventRecorder("workloadpolicy-autoprotect"),
+		ObservationInterval: autoProtectObservationInterval,
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create WorkloadPolicyAutoProtectReconciler controller: %w", err)
+	}
+	if err = mgr.AddMetricsServerExtraHandler(
+		controller.ProposalSummaryPath,
+		&controller.ProposalSummaryHandler{Client: mgr.GetClient(), ApprovalLabelKey: approvalLabelKey},
+	); err != nil {
+		return fmt.Errorf("unable to add the proposal summary to the metrics server: %w", err)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -260,6 +356,22 @@ func getMetricsServerOptions(logger *slog.Logger, config *Config) (*certwatcher.
 func main() {
 	var err error
 	config := parseFlags()
+	if err = config.wpStatusSyncConfig.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid WorkloadPolicy status reconciler configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if config.autoProtectObservationInterval <= 0 {
+		fmt.Fprintf(os.Stderr, "invalid auto-protect-observation-interval: %v\n", config.autoProtectObservationInterval)
+		os.Exit(1)
+	}
+	if config.minKernelVersion != "" && kernels.KernelStringToNumeric(config.minKernelVersion) == 0 {
+		fmt.Fprintf(os.Stderr, "invalid min-kernel-version: %q\n", config.minKernelVersion)
+		os.Exit(1)
+	}
+	if errs := validation.IsQualifiedName(config.approvalLabelKey); len(errs) > 0 {
+		fmt.Fprintf(os.Stderr, "invalid approval-label-key %q: %s\n", config.approvalLabelKey, strings.Join(errs, "; "))
+		os.Exit(1)
+	}
 
 	ctx := ctrl.SetupSignalHandler()
 
@@ -308,7 +420,8 @@ func main() {
 
 	config.wpStatusSyncConfig.AgentPoolConf.Logger = slog.New(slogHandler).With("component", "agent-pool")
 	if err = SetupControllers(
-		ctrlLogger, mgr, metricsCertWatcher, webhookCertWatcher, &config.wpStatusSyncConfig,
+		ctrlLogger, mgr, metricsCertWatcher, webhookCertWatcher, &config.wpStatusSyncConfig, config.approvalLabelKey,
+		config.autoProtectObservationInterval,
 	); err != nil {
 		setupLog.Error(err, "unable to setup controllers")
 		os.Exit(1)
@@ -323,13 +436,28 @@ func main() {
 	}
 
 	err = builder.WebhookManagedBy(mgr, &securityv1alpha1.WorkloadPolicy{}).
-		WithValidator(&controller.PolicyCustomValidator{Client: mgr.GetClient()}).
+		WithValidator(&controller.PolicyCustomValidator{
+			Client:               mgr.GetClient(),
+			RestrictedNamespaces: flagutil.ParseList(config.restrictedNamespaces),
+			MinKernelVersion:     config.minKernelVersion,
+			HashMatching:         config.enableHashMatching,
+		}).
 		Complete()
 	if err != nil {
 		setupLog.Error(err, "unable to create WorkloadPolicy webhook")
 		os.Exit(1)
 	}
 
+	if config.enablePodPolicyLabelWebhook {
+		err = builder.WebhookManagedBy(mgr, &corev1.Pod{}).
+			WithValidator(&controller.PodPolicyLabelValidator{}).
+			Complete()
+		if err != nil {
+			setupLog.Error(err, "unable to create Pod policy label webhook")
+			os.Exit(1)
+		}
+	}
+
 	setupLog.Info("starting manager")
 	if err = mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
@@ -7,6 +7,14 @@ import (
 	"github.com/rancher-sandbox/runtime-enforcer/internal/cgroups"
 )
 
+// LoadTimeConfig is the configuration the eBPF programs were loaded with.
+type LoadTimeConfig struct {
+	CgroupFsMagic     uint64
+	CgroupV1SubsysIdx uint32
+	DebugMode         bool
+	LearningEnabled   bool
+}
+
 func getLoadTimeConfig(logger *slog.Logger, enableLearning bool) (*bpfLoadConf, error) {
 	cgInfo, err := cgroups.GetCgroupInfo()
 	if err != nil {
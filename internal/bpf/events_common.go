@@ -94,8 +94,15 @@ func (m *Manager) processRingbufEvents(ctx context.Context, rd *ringbuf.Reader,
 
 		// 4096 is the maximum supported path size in the eBPF program.
 		const maxPathLen = 4096
-		if header.PathLen > maxPathLen {
-			m.logger.ErrorContext(ctx, "invalid path length in ringbuf event", "length", header.PathLen)
+		if header.PathLen > maxPathLen || header.ParentPathLen > maxPathLen || header.InvokedPathLen > maxPathLen {
+			m.logger.ErrorContext(ctx, "invalid path length in ringbuf event",
+				"length", header.PathLen,
+				"parentLength", header.ParentPathLen,
+				"invokedLength", header.InvokedPathLen)
+			continue
+		}
+		if header.AncestorsLen > MaxAncestryDepth {
+			m.logger.ErrorContext(ctx, "invalid ancestors length in ringbuf event", "length", header.AncestorsLen)
 			continue
 		}
 
@@ -105,16 +112,37 @@ func (m *Manager) processRingbufEvents(ctx context.Context, rd *ringbuf.Reader,
 			m.logger.ErrorContext(ctx, "reading path bytes", "error", err)
 			continue
 		}
+		// the parent path immediately follows the executable path.
+		parentPathBytes := make([]byte, header.ParentPathLen)
+		if _, err = buf.Read(parentPathBytes); err != nil {
+			m.logger.ErrorContext(ctx, "reading parent path bytes", "error", err)
+			continue
+		}
+		// learning events carry the invoked path instead of the parent one.
+		invokedPathBytes := make([]byte, header.InvokedPathLen)
+		if _, err = buf.Read(invokedPathBytes); err != nil {
+			m.logger.ErrorContext(ctx, "reading invoked path bytes", "error", err)
+			continue
+		}
 
 		modeString := ""
 		// 0 is the value we receive in learning mode, meaning "not set".
 		if header.Mode != 0 {
 			modeString = policymode.FromUint8(header.Mode).String()
 		}
+		var ancestorPIDs []uint32
+		if header.AncestorsLen > 0 {
+			// the header is allocated for each record, the ancestors can be referenced.
+			ancestorPIDs = header.Ancestors[:header.AncestorsLen]
+		}
+		// the paths are compared with the policy values, which never keep a trailing terminator.
 		out <- ProcessEvent{
-			CgTrackerID: header.CgTrackerID,
-			Mode:        modeString,
-			ExePath:     string(pathBytes),
+			CgTrackerID:   header.CgTrackerID,
+			Mode:          modeString,
+			ExePath:       trimNul(string(pathBytes)),
+			ParentExePath: trimNul(string(parentPathBytes)),
+			InvokedPath:   trimNul(string(invokedPathBytes)),
+			AncestorPIDs:  ancestorPIDs,
 		}
 	}
 }
@@ -0,0 +1,76 @@
+package bpf
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cilium/ebpf"
+)
+
+// ExecPin identifies an executable file whose content was verified in a container. It is only allowed by the
+// allowedHashes rules while the executed file has the same inode and change time: writing or replacing the file
+// changes one of them.
+type ExecPin struct {
+	Inode     uint64
+	CtimeSec  int64
+	CtimeNsec uint32
+}
+
+// execPinKey must match struct pinned_exec_key in bpf/pinned_exec.h.
+type execPinKey struct {
+	CgTrackerID uint64
+	Inode       uint64
+	CtimeSec    int64
+	CtimeNsec   uint32
+	_           uint32
+}
+
+// replaceExecPins replaces the executables pinned in the cgroup, the new ones are pinned before the stale ones
+// are removed so that the executables verified in both stay allowed.
+func (m *Manager) replaceExecPins(cgroupID uint64, pins []ExecPin) error {
+	pinnedExecs := m.objs.PinnedExecMap
+	if pinnedExecs == nil {
+		return errors.New("pinned exec map is nil")
+	}
+
+	current := make(map[execPinKey]struct{}, len(pins))
+	one := uint8(1)
+	for _, pin := range pins {
+		key := execPinKey{
+			CgTrackerID: cgroupID,
+			Inode:       pin.Inode,
+			CtimeSec:    pin.CtimeSec,
+			CtimeNsec:   pin.CtimeNsec,
+		}
+		if err := pinnedExecs.Update(&key, one, ebpf.UpdateAny); err != nil {
+			return fmt.Errorf("failed to pin inode %d in cgroup %d: %w", pin.Inode, cgroupID, err)
+		}
+		current[key] = struct{}{}
+	}
+
+	var key execPinKey
+	var value uint8
+	var stale []execPinKey
+	iter := pinnedExecs.Iterate()
+	for iter.Next(&key, &value) {
+		if _, ok := current[key]; key.CgTrackerID == cgroupID && !ok {
+			stale = append(stale, key)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to iterate pinned exec map: %w", err)
+	}
+	for _, k := range stale {
+		if err := pinnedExecs.Delete(&k); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+			return fmt.Errorf("failed to unpin inode %d in cgroup %d: %w", k.Inode, cgroupID, err)
+		}
+	}
+	return nil
+}
+
+// GetExecPinsUpdateFunc exposes a function replacing the executables pinned in a cgroup, nil pins unpin them all.
+func (m *Manager) GetExecPinsUpdateFunc() func(cgroupID uint64, pins []ExecPin) error {
+	return func(cgroupID uint64, pins []ExecPin) error {
+		return m.handleErrOnShutdown(m.replaceExecPins(cgroupID, pins))
+	}
+}
@@ -0,0 +1,57 @@
+package bpf
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+	"unsafe"
+
+	"github.com/cilium/ebpf"
+	"github.com/stretchr/testify/require"
+)
+
+func dumpExecPins(t *testing.T, m *ebpf.Map) map[uint64][]ExecPin {
+	t.Helper()
+	iter := m.Iterate()
+	dump := make(map[uint64][]ExecPin)
+	var key execPinKey
+	var value uint8
+	for iter.Next(&key, &value) {
+		dump[key.CgTrackerID] = append(dump[key.CgTrackerID], ExecPin{
+			Inode:     key.Inode,
+			CtimeSec:  key.CtimeSec,
+			CtimeNsec: key.CtimeNsec,
+		})
+	}
+	require.NoError(t, iter.Err())
+	for _, pins := range dump {
+		slices.SortFunc(pins, func(a, b ExecPin) int { return cmp.Compare(a.Inode, b.Inode) })
+	}
+	return dump
+}
+
+func TestReplaceExecPins(t *testing.T) {
+	pinnedExecs, err := ebpf.NewMap(&ebpf.MapSpec{
+		Type:       ebpf.Hash,
+		KeySize:    uint32(unsafe.Sizeof(execPinKey{})),
+		ValueSize:  1,
+		MaxEntries: 100,
+	})
+	require.NoError(t, err, "Failed to create test map")
+	m := &Manager{objs: &bpfObjects{bpfMaps: bpfMaps{PinnedExecMap: pinnedExecs}}}
+
+	pin1 := ExecPin{Inode: 10, CtimeSec: 1000, CtimeNsec: 1}
+	pin2 := ExecPin{Inode: 11, CtimeSec: 1000, CtimeNsec: 2}
+	pin3 := ExecPin{Inode: 12, CtimeSec: 2000, CtimeNsec: 3}
+
+	require.NoError(t, m.replaceExecPins(100, []ExecPin{pin1, pin2}))
+	require.NoError(t, m.replaceExecPins(101, []ExecPin{pin1}))
+	require.Equal(t, map[uint64][]ExecPin{100: {pin1, pin2}, 101: {pin1}}, dumpExecPins(t, pinnedExecs))
+
+	// The pins of the other cgroups are untouched.
+	require.NoError(t, m.replaceExecPins(100, []ExecPin{pin2, pin3}))
+	require.Equal(t, map[uint64][]ExecPin{100: {pin2, pin3}, 101: {pin1}}, dumpExecPins(t, pinnedExecs))
+
+	require.NoError(t, m.replaceExecPins(100, nil))
+	require.Equal(t, map[uint64][]ExecPin{101: {pin1}}, dumpExecPins(t, pinnedExecs))
+}
@@ -0,0 +1,119 @@
+package bpf
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/cilium/ebpf/features"
+	"github.com/cilium/ebpf/link"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	ringBufFeatureName      = "BPF_MAP_TYPE_RINGBUF"
+	modifyReturnFeatureName = "BPF_MODIFY_RETURN"
+)
+
+// KernelFeature is the result of the probe of an eBPF feature required by the agent.
+type KernelFeature struct {
+	Name      string
+	Supported bool
+	// Error is the reason why the feature is not supported, it is empty otherwise.
+	Error string
+}
+
+type featureProbe struct {
+	name  string
+	probe func() error
+}
+
+// kernelFeatureProbes returns the probes of the features required by the agent.
+// For now known requirements are:
+// - BPF_MAP_TYPE_RINGBUF
+// - tracing prog with attach type BPF_MODIFY_RETURN.
+func kernelFeatureProbes() []featureProbe {
+	return []featureProbe{
+		{
+			name:  ringBufFeatureName,
+			probe: func() error { return features.HaveMapType(ebpf.RingBuf) },
+		},
+		{
+			name:  modifyReturnFeatureName,
+			probe: probeModifyReturn,
+		},
+	}
+}
+
+// probeModifyReturn checks for the BPF_MODIFY_RETURN attach type.
+// Today there is no an helper function for attach type BPF_MODIFY_RETURN so we do it by hand.
+func probeModifyReturn() error {
+	prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+		Name: "probe_fmodret",
+		Type: ebpf.Tracing,
+		Instructions: asm.Instructions{
+			asm.Mov.Imm(asm.R0, 0),
+			asm.Return(),
+		},
+		AttachType: ebpf.AttachModifyReturn,
+		License:    "MIT",
+		AttachTo:   "security_bprm_creds_for_exec",
+	})
+	if err != nil {
+		return err
+	}
+	defer prog.Close()
+
+	link, err := link.AttachTracing(link.TracingOptions{
+		Program: prog,
+	})
+	if err != nil {
+		return err
+	}
+	return link.Close()
+}
+
+// runFeatureProbes runs all the probes, even after a failure, so that the whole feature matrix
+// can be reported when the agent runs degraded.
+func runFeatureProbes(probes []featureProbe) ([]KernelFeature, error) {
+	results := make([]KernelFeature, 0, len(probes))
+	var errs []error
+	for _, p := range probes {
+		result := KernelFeature{Name: p.name, Supported: true}
+		if err := p.probe(); err != nil {
+			result.Supported = false
+			result.Error = err.Error()
+			errs = append(errs, fmt.Errorf("%s not supported: %w", p.name, err))
+		}
+		results = append(results, result)
+	}
+	return results, errors.Join(errs...)
+}
+
+func logKernelFeatures(logger *slog.Logger, kernelFeatures []KernelFeature) {
+	for _, f := range kernelFeatures {
+		if f.Supported {
+			logger.Info("eBPF feature probed", "feature", f.Name, "supported", true)
+			continue
+		}
+		logger.Error("eBPF feature probed", "feature", f.Name, "supported", false, "error", f.Error)
+	}
+}
+
+// NewKernelFeaturesGauge returns a gauge reporting which of the given features are supported.
+func NewKernelFeaturesGauge(kernelFeatures []KernelFeature) *prometheus.GaugeVec {
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "runtime_enforcer_kernel_feature_supported",
+		Help: "Set to 1 when the eBPF feature probed at startup is supported by the kernel.",
+	}, []string{"feature"})
+	for _, f := range kernelFeatures {
+		value := 0.0
+		if f.Supported {
+			value = 1
+		}
+		gauge.WithLabelValues(f.Name).Set(value)
+	}
+	return gauge
+}
@@ -0,0 +1,30 @@
+package bpf
+
+import (
+	"errors"
+	"testing"
+
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFeatureProbes(t *testing.T) {
+	probes := []featureProbe{
+		{name: "SUPPORTED", probe: func() error { return nil }},
+		{name: "MISSING", probe: func() error { return errors.New("not available") }},
+		{name: "SUPPORTED_AFTER_FAILURE", probe: func() error { return nil }},
+	}
+
+	kernelFeatures, err := runFeatureProbes(probes)
+	require.ErrorContains(t, err, "MISSING not supported: not available")
+	// all the probes run even if one of them fails.
+	require.Equal(t, []KernelFeature{
+		{Name: "SUPPORTED", Supported: true},
+		{Name: "MISSING", Supported: false, Error: "not available"},
+		{Name: "SUPPORTED_AFTER_FAILURE", Supported: true},
+	}, kernelFeatures)
+
+	gauge := NewKernelFeaturesGauge(kernelFeatures)
+	require.InDelta(t, 1, promtestutil.ToFloat64(gauge.WithLabelValues("SUPPORTED")), 0)
+	require.InDelta(t, 0, promtestutil.ToFloat64(gauge.WithLabelValues("MISSING")), 0)
+}
@@ -6,13 +6,11 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 	"sync"
 	"sync/atomic"
 
 	"github.com/cilium/ebpf"
-	"github.com/cilium/ebpf/asm"
-	"github.com/cilium/ebpf/features"
-	"github.com/cilium/ebpf/link"
 	"github.com/cilium/ebpf/rlimit"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/kernels"
 
@@ -38,13 +36,30 @@ const (
 type ProcessEvent struct {
 	CgTrackerID uint64
 	ExePath     string
-	Mode        string
+	// ParentExePath is the binary that required the execution.
+	// It is only reported in monitoring events and can be empty if it couldn't be resolved.
+	ParentExePath string
+	// InvokedPath is the path passed to execve, it differs from ExePath when a symlink is executed.
+	// It is only reported in learning events and can be relative to the working directory of the process.
+	InvokedPath string
+	// AncestorPIDs are the pids of the ancestors of the process that required the execution, closest first.
+	// It is only reported in monitoring events, without the ancestors beyond MaxAncestryDepth.
+	AncestorPIDs []uint32
+	Mode         string
 }
 
+// MaxAncestryDepth is the maximum number of ancestors reported in the monitoring events.
+// It must match MAX_ANCESTRY_DEPTH in bpf/main.c.
+const MaxAncestryDepth = 8
+
 type bpfEventHeader struct {
-	CgTrackerID uint64
-	PathLen     uint16
-	Mode        uint8
+	CgTrackerID    uint64
+	PathLen        uint16
+	Mode           uint8
+	AncestorsLen   uint8
+	ParentPathLen  uint16
+	InvokedPathLen uint16
+	Ancestors      [MaxAncestryDepth]uint32
 }
 
 type Manager struct {
@@ -63,48 +78,9 @@ type Manager struct {
 	// Kernel version check cache
 	kernelCheckOnce sync.Once
 	isPre5_9        bool
-}
-
-func probeEbpfFeatures() error {
-	// For now known requirements are:
-	// - BPF_MAP_TYPE_RINGBUF
-	// - tracing prog with attach type BPF_MODIFY_RETURN
-
-	// Check for BPF_MAP_TYPE_RINGBUF
-	if err := features.HaveMapType(ebpf.RingBuf); err != nil {
-		return fmt.Errorf("BPF_MAP_TYPE_RINGBUF not supported: %w", err)
-	}
-
-	// Check for BPF_MODIFY_RETURN attach type
-	// Today there is no an helper function for attach type BPF_MODIFY_RETURN so we do it by hand.
-	prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
-		Name: "probe_fmodret",
-		Type: ebpf.Tracing,
-		Instructions: asm.Instructions{
-			asm.Mov.Imm(asm.R0, 0),
-			asm.Return(),
-		},
-		AttachType: ebpf.AttachModifyReturn,
-		License:    "MIT",
-		AttachTo:   "security_bprm_creds_for_exec",
-	})
-	if err != nil {
-		return err
-	}
-	defer prog.Close()
-
-	link, err := link.AttachTracing(link.TracingOptions{
-		Program: prog,
-	})
-	if err != nil {
-		return err
-	}
-	err = link.Close()
-	if err != nil {
-		return err
-	}
 
-	return nil
+	kernelFeatures []KernelFeature
+	loadTimeConfig LoadTimeConfig
 }
 
 func loadEbpfObjects(spec *ebpf.CollectionSpec, level ebpf.LogLevel) (*bpfObjects, error) {
@@ -136,8 +112,12 @@ func NewManager(logger *slog.Logger, enableLearning bool) (*Manager, error) {
 	newLogger.Info("Detected kernel version", "version", kernels.GetCurrKernelVersionStr())
 
 	newLogger.Info("Probing eBPF features")
-	if err := probeEbpfFeatures(); err != nil {
-		return nil, fmt.Errorf("failure during eBPF feature probing: %w", err)
+	kernelFeatures, err := runFeatureProbes(kernelFeatureProbes())
+	logKernelFeatures(newLogger, kernelFeatures)
+	if err != nil {
+		// the unsupported features are reported by the runtime_enforcer_kernel_feature_supported metric
+		// and the agent info, the agent keeps running degraded rather than crash looping.
+		newLogger.Warn("eBPF feature probing failed, running degraded", "error", err)
 	}
 
 	spec, err := loadBpf()
@@ -191,6 +171,13 @@ func NewManager(logger *slog.Logger, enableLearning bool) (*Manager, error) {
 		enableLearning:      enableLearning,
 		learningEventChan:   make(chan ProcessEvent, learningEventChanSize),
 		monitoringEventChan: make(chan ProcessEvent, monitorEventChanSize),
+		kernelFeatures:      kernelFeatures,
+		loadTimeConfig: LoadTimeConfig{
+			CgroupFsMagic:     conf.CgrpFsMagic,
+			CgroupV1SubsysIdx: conf.Cgrpv1SubsysIdx,
+			DebugMode:         conf.DebugMode != 0,
+			LearningEnabled:   conf.LearningEnabled != 0,
+		},
 		policyStringMaps: []*ebpf.Map{
 			objs.PolStrMaps0,
 			objs.PolStrMaps1,
@@ -207,6 +194,16 @@ func NewManager(logger *slog.Logger, enableLearning bool) (*Manager, error) {
 	}, nil
 }
 
+// KernelFeatures returns the eBPF features probed at startup.
+func (m *Manager) KernelFeatures() []KernelFeature {
+	return slices.Clone(m.kernelFeatures)
+}
+
+// LoadTimeConfig returns the configuration the eBPF programs were loaded with.
+func (m *Manager) LoadTimeConfig() LoadTimeConfig {
+	return m.loadTimeConfig
+}
+
 func (m *Manager) isKernelPre5_9() bool {
 	m.kernelCheckOnce.Do(func() {
 		m.isPre5_9 = kernels.CurrVersionIsLowerThan("5.9")
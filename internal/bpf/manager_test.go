@@ -219,6 +219,48 @@ func TestReplaceValuesNewSizeBucket(t *testing.T) {
 	}), "disallowed binary must be blocked after policy replacement")
 }
 
+func TestAllowedPrefixes(t *testing.T) {
+	runner, err := newCgroupRunner(t)
+	require.NoError(t, err, "Failed to create cgroup runner")
+	defer runner.close()
+
+	tmpPath, remove, err := generateScriptWithLen(1)
+	require.NoError(t, err, "Failed to generate temporary script")
+	defer remove()
+
+	// the interpreter of the script is allowed by path, the script by prefix.
+	mockPolicyID := uint64(46)
+	err = runner.populatePolicyForRunnerCgroup(mockPolicyID, policymode.Protect, []string{"/usr/bin/true"})
+	require.NoError(t, err, "Failed to populate policy for runner cgroup")
+	err = runner.manager.GetPolicyUpdatePrefixesFunc()(mockPolicyID, []string{"/tmp/"}, AddValuesToPolicy)
+	require.NoError(t, err, "Failed to add policy prefixes")
+
+	t.Log("Trying binary under an allowed prefix")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         tmpPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: false,
+	}), "binary under an allowed prefix must pass")
+
+	t.Log("Trying binary outside of the allowed prefixes")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         "/usr/bin/mkdir",
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}), "binary outside of the allowed prefixes must be blocked")
+
+	// once the prefixes are removed the script is blocked.
+	err = runner.manager.GetPolicyUpdatePrefixesFunc()(mockPolicyID, nil, ReplaceValuesInPolicy)
+	require.NoError(t, err, "Failed to remove policy prefixes")
+	require.NoError(t, runner.runAndFindCommand(&runCommandArgs{
+		command:         tmpPath,
+		channel:         monitoringChannel,
+		shouldFindEvent: true,
+		shouldEPERM:     true,
+	}), "binary must be blocked once the prefixes are removed")
+}
+
 func TestManagerShutdown(t *testing.T) {
 	runner, err := newCgroupRunner(t)
 	require.NoError(t, err, "Failed to create cgroup runner")
@@ -0,0 +1,135 @@
+package bpf
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+
+	"github.com/cilium/ebpf"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// htabElemOverhead approximates the size of the kernel struct htab_elem without its key and value.
+	htabElemOverhead = 48
+	// htabBucketSize is the size of the kernel struct bucket.
+	htabBucketSize = 16
+)
+
+// hashMapBytes estimates the memory used by a BPF hash map from its buckets and elements.
+// Preallocated maps reserve all their elements at creation, the other ones only hold their entries.
+func hashMapBytes(info *ebpf.MapInfo, entries uint32) uint64 {
+	elems := uint64(info.MaxEntries)
+	if info.Flags&BPFFNoPrealloc != 0 {
+		elems = uint64(entries)
+	}
+	buckets := uint64(1)
+	if info.MaxEntries > 1 {
+		buckets <<= bits.Len32(info.MaxEntries - 1)
+	}
+	elemSize := htabElemOverhead + roundUp8(info.KeySize) + roundUp8(info.ValueSize)
+	return buckets*htabBucketSize + elems*elemSize
+}
+
+func roundUp8(size uint32) uint64 {
+	return (uint64(size) + 7) &^ 7 //nolint:mnd // the kernel aligns keys and values on 8 bytes
+}
+
+// countEntries returns the number of entries of the map.
+func countEntries(m *ebpf.Map) (uint32, error) {
+	key := make([]byte, m.KeySize())
+	value := make([]byte, m.ValueSize())
+	var entries uint32
+	it := m.Iterate()
+	for it.Next(&key, &value) {
+		entries++
+	}
+	return entries, it.Err()
+}
+
+// mapBytes estimates the memory of a hash map, counting its entries only when they are not preallocated.
+func mapBytes(m *ebpf.Map) (uint64, error) {
+	info, err := m.Info()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get info of map %s: %w", m, err)
+	}
+	var entries uint32
+	if info.Flags&BPFFNoPrealloc != 0 {
+		if entries, err = countEntries(m); err != nil {
+			return 0, fmt.Errorf("failed to count entries of map %s: %w", m, err)
+		}
+	}
+	return hashMapBytes(info, entries), nil
+}
+
+// policyStringMapsBytes estimates the memory of the policy string and prefix maps
+// and of the inner maps of every policy.
+func (m *Manager) policyStringMapsBytes() (uint64, error) {
+	var total uint64
+	var errs []error
+	for _, outer := range slices.Concat(m.policyStringMaps, []*ebpf.Map{m.objs.PolPrefixMaps}) {
+		size, err := mapBytes(outer)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		total += size
+
+		var policyID uint64
+		// Next closes the previous inner map when it decodes the next one.
+		var inner *ebpf.Map
+		it := outer.Iterate()
+		for it.Next(&policyID, &inner) {
+			if size, err = mapBytes(inner); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			total += size
+		}
+		if inner != nil {
+			if err = inner.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close inner map of %s: %w", outer, err))
+			}
+		}
+		if err = it.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to iterate map %s: %w", outer, err))
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+// mapMemoryCollector reports an estimate of the memory used by the policy BPF maps.
+type mapMemoryCollector struct {
+	manager *Manager
+	desc    *prometheus.Desc
+}
+
+var _ prometheus.Collector = &mapMemoryCollector{}
+
+// MapMemoryCollector returns the collector of the runtime_enforcer_bpf_map_bytes gauge.
+// The maps are walked when the metrics are scraped, the inner maps grow with the values of the policies.
+func (m *Manager) MapMemoryCollector() prometheus.Collector {
+	return &mapMemoryCollector{
+		manager: m,
+		desc: prometheus.NewDesc(
+			"runtime_enforcer_bpf_map_bytes",
+			"Estimated memory used by the BPF maps storing the values of the policies, in bytes.",
+			nil,
+			nil,
+		),
+	}
+}
+
+func (c *mapMemoryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *mapMemoryCollector) Collect(ch chan<- prometheus.Metric) {
+	total, err := c.manager.policyStringMapsBytes()
+	if err != nil {
+		// the maps change while they are walked, the estimate is still reported.
+		c.manager.logger.Warn("failed to estimate the memory of the BPF maps", "error", err)
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(total))
+}
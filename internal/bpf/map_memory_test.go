@@ -0,0 +1,29 @@
+package bpf
+
+import (
+	"testing"
+
+	"github.com/cilium/ebpf"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashMapBytes(t *testing.T) {
+	// preallocated: 4 buckets and 3 elements with a key of 24 bytes and a value padded to 8 bytes.
+	require.Equal(t, uint64(4*16+3*(48+24+8)), hashMapBytes(&ebpf.MapInfo{
+		KeySize:    24,
+		ValueSize:  1,
+		MaxEntries: 3,
+	}, 0))
+	// not preallocated: only the entries hold elements.
+	require.Equal(t, uint64(512*16+2*(48+256+8)), hashMapBytes(&ebpf.MapInfo{
+		KeySize:    256,
+		ValueSize:  1,
+		MaxEntries: fixedMaxEntriesPre5_9,
+		Flags:      BPFFNoPrealloc,
+	}, 2))
+	require.Equal(t, uint64(16+48+8+8), hashMapBytes(&ebpf.MapInfo{
+		KeySize:    8,
+		ValueSize:  4,
+		MaxEntries: 1,
+	}, 0))
+}
@@ -0,0 +1,115 @@
+package bpf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/cilium/ebpf"
+)
+
+const (
+	// MaxPrefixLen is the maximum length of an allowed prefix, the LPM tries don't support longer keys.
+	// It must match POLICY_PREFIX_MAX_LEN in bpf/prefix_maps.h.
+	MaxPrefixLen = 256
+
+	// prefixLenSize is the size of the prefixlen field at the beginning of the LPM trie keys.
+	prefixLenSize = 4
+	bitsPerByte   = 8
+)
+
+// prefixKey returns the LPM trie key matching every path starting with the prefix.
+func prefixKey(prefix string) ([]byte, error) {
+	prefix = trimNul(prefix)
+	if len(prefix) == 0 {
+		return nil, errors.New("prefix is empty")
+	}
+	if len(prefix) > MaxPrefixLen {
+		return nil, errors.New("prefix is too long")
+	}
+	key := make([]byte, prefixLenSize+MaxPrefixLen)
+	//nolint:gosec // the prefix is at most MaxPrefixLen long
+	binary.NativeEndian.PutUint32(key, uint32(len(prefix)*bitsPerByte))
+	copy(key[prefixLenSize:], prefix)
+	return key, nil
+}
+
+func (m *Manager) generatePrefixMap(policyID uint64, prefixes []string) (*ebpf.Map, error) {
+	keys := make([][]byte, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		key, err := prefixKey(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("prefix %s invalid: %w", prefix, err)
+		}
+		keys = append(keys, key)
+	}
+
+	name := fmt.Sprintf("p_%d_prefix_map", policyID)
+	innerSpec := &ebpf.MapSpec{
+		Name:       name,
+		Type:       ebpf.LPMTrie,
+		KeySize:    uint32(prefixLenSize + MaxPrefixLen),
+		ValueSize:  uint32(1),
+		MaxEntries: uint32(len(keys)), //nolint:gosec // len(...) cannot be larger than math.MaxUint32
+		// The LPM tries cannot be preallocated.
+		Flags: uint32(BPFFNoPrealloc),
+	}
+	if m.isKernelPre5_9() {
+		innerSpec.MaxEntries = uint32(fixedMaxEntriesPre5_9)
+	}
+
+	inner, err := ebpf.NewMap(innerSpec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create inner_map: %w", err)
+	}
+	one := uint8(1)
+	for _, key := range keys {
+		if err = inner.Update(key, one, 0); err != nil {
+			_ = inner.Close()
+			return nil, fmt.Errorf("failed to insert value into %s: %w", name, err)
+		}
+	}
+	return inner, nil
+}
+
+// replacePrefixMap replaces the prefixes allowed by the policy, the policy has no prefix map
+// when it allows no prefix.
+func (m *Manager) replacePrefixMap(policyID uint64, prefixes []string) error {
+	if len(prefixes) == 0 {
+		return m.removePrefixMap(policyID)
+	}
+	inner, err := m.generatePrefixMap(policyID, prefixes)
+	if err != nil {
+		return err
+	}
+	defer inner.Close()
+
+	if err = m.objs.PolPrefixMaps.Update(policyID, inner, ebpf.UpdateAny); err != nil {
+		return fmt.Errorf("failed to update inner policy (id=%d) prefix map: %w", policyID, err)
+	}
+	m.logger.Debug("handler: replaced inner map inside policy prefixes", "policyID", policyID)
+	return nil
+}
+
+func (m *Manager) removePrefixMap(policyID uint64) error {
+	if err := m.objs.PolPrefixMaps.Delete(policyID); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+		return fmt.Errorf("failed to remove policy (id=%d) from map %s: %w",
+			policyID, m.objs.PolPrefixMaps.String(), err)
+	}
+	return nil
+}
+
+// GetPolicyUpdatePrefixesFunc exposes a function used to interact with BPF maps storing the prefixes
+// of the allowed binaries, e.g. "/opt/app/" allows every binary under /opt/app.
+func (m *Manager) GetPolicyUpdatePrefixesFunc() func(policyID uint64, prefixes []string, op PolicyValuesOperation) error {
+	return func(policyID uint64, prefixes []string, op PolicyValuesOperation) error {
+		switch op {
+		case AddValuesToPolicy, ReplaceValuesInPolicy:
+			return m.handleErrOnShutdown(m.replacePrefixMap(policyID, prefixes))
+		case RemoveValuesFromPolicy:
+			return m.handleErrOnShutdown(m.removePrefixMap(policyID))
+		default:
+			panic("unhandled operation")
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package bpf
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixKey(t *testing.T) {
+	key, err := prefixKey("/opt/app/\x00")
+	require.NoError(t, err)
+	require.Len(t, key, prefixLenSize+MaxPrefixLen)
+	require.Equal(t, uint32(len("/opt/app/")*bitsPerByte), binary.NativeEndian.Uint32(key))
+	require.Equal(t, "/opt/app/", strings.TrimRight(string(key[prefixLenSize:]), "\x00"))
+
+	_, err = prefixKey(strings.Repeat("a", MaxPrefixLen))
+	require.NoError(t, err)
+	_, err = prefixKey(strings.Repeat("a", MaxPrefixLen+1))
+	require.Error(t, err)
+	_, err = prefixKey("")
+	require.Error(t, err)
+}
+
+// TestPrefixesDontChangeStringMaps checks that the prefixes are kept out of the string maps,
+// whose buckets are only sized by the allowed paths.
+func TestPrefixesDontChangeStringMaps(t *testing.T) {
+	maps, err := convertValuesToBPFStringMaps([]string{"/usr/bin/true"})
+	require.NoError(t, err)
+	for i, subMap := range maps {
+		if i == 0 {
+			require.Len(t, subMap, 1)
+			continue
+		}
+		require.Empty(t, subMap)
+	}
+}
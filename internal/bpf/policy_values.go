@@ -3,6 +3,7 @@ package bpf
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/cilium/ebpf"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/kernels"
@@ -101,12 +102,15 @@ func stringPaddedLen(s int) int {
 	return stringMapSize10
 }
 
+// trimNul removes any trailing nul characters ("\0" or 0x00), so that a path ending with its
+// string terminator has the same key in the string maps as the clean path.
+func trimNul(v string) string {
+	return strings.TrimRight(v, "\x00")
+}
+
 func argStringSelectorValue(v string, removeNul bool, currKernelVer int) ([MaxStringMapsSize]byte, int, error) {
 	if removeNul {
-		// Remove any trailing nul characters ("\0" or 0x00)
-		for v[len(v)-1] == 0 {
-			v = v[0 : len(v)-1]
-		}
+		v = trimNul(v)
 	}
 	ret := [MaxStringMapsSize]byte{}
 	b := []byte(v)
@@ -135,7 +139,7 @@ func argStringSelectorValue(v string, removeNul bool, currKernelVer int) ([MaxSt
 }
 
 func putValueInMap(m SelectorStringMaps, v string) error {
-	value, size, err := argStringSelectorValue(v, false, kernels.GetCurrKernelVersion())
+	value, size, err := argStringSelectorValue(v, true, kernels.GetCurrKernelVersion())
 	if err != nil {
 		return fmt.Errorf("value %s invalid: %w", v, err)
 	}
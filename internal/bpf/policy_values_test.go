@@ -99,3 +99,23 @@ func TestArgStringSelectorValue(t *testing.T) {
 		})
 	}
 }
+
+func TestArgStringSelectorValueTrailingNul(t *testing.T) {
+	kernelVer := int(kernels.KernelStringToNumeric("6.1"))
+	stored, err := convertValuesToBPFStringMaps([]string{"/usr/bin/sleep"})
+	require.NoError(t, err)
+
+	// a path carrying its terminator matches the clean allow-list entry.
+	value, size, err := argStringSelectorValue("/usr/bin/sleep\x00\x00", true, kernelVer)
+	require.NoError(t, err)
+	require.Equal(t, stringMapSize0, size)
+	require.Contains(t, stored[0], value)
+
+	// and the allow-list entries are stored without it.
+	terminated, err := convertValuesToBPFStringMaps([]string{"/usr/bin/sleep\x00"})
+	require.NoError(t, err)
+	require.Equal(t, stored, terminated)
+
+	_, _, err = argStringSelectorValue("\x00", true, kernelVer)
+	require.ErrorContains(t, err, "string is empty")
+}
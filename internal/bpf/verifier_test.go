@@ -7,6 +7,7 @@ import (
 )
 
 // run it with: go test -v -run TestNoVerifierFailures ./internal/bpf -count=1 -exec "sudo -E".
+// or without privileges on the host with: make test-bpf-container.
 func TestNoVerifierFailures(t *testing.T) {
 	tests := []struct {
 		name           string
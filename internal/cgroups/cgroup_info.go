@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 
@@ -27,6 +28,9 @@ const (
 
 	// memoryControllerName is the memory controller name.
 	memoryControllerName = "memory"
+
+	// subtreeControlFile lists the cgroupv2 controllers enabled for the children of a cgroup.
+	subtreeControlFile = "cgroup.subtree_control"
 )
 
 type CgroupInfo struct {
@@ -144,6 +148,27 @@ func findMemoryController(path string) (uint32, error) {
 	return 0, fmt.Errorf("no '%s' controller among: %v", memoryControllerName, allControllersNames)
 }
 
+// checkDelegatedControllers returns an error if the memory controller is not enabled for the children
+// of the cgroupv2 root. Like in cgroupv1, k8s containers are expected to have their own memory cgroup,
+// without it the container runtime placed them in an unexpected hierarchy.
+func checkDelegatedControllers(cgroupRoot string) error {
+	path := filepath.Join(cgroupRoot, subtreeControlFile)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	// Expected format cgroupv2, the controllers are separated by spaces:
+	//
+	// cpuset cpu io memory hugetlb pids rdma misc
+	controllers := strings.Fields(string(content))
+	if !slices.Contains(controllers, memoryControllerName) {
+		return fmt.Errorf("the '%s' controller is not delegated to the container cgroups, '%s' only enables: %v",
+			memoryControllerName, path, controllers)
+	}
+	return nil
+}
+
 // getMountPointType returns error if the provided path is not a mount point. If it is a mount point, it returns the filesystem type.
 func getMountPointType(path string) (int64, error) {
 	var st, pst unix.Stat_t
@@ -181,6 +206,9 @@ func getCgroupInfo() (*CgroupInfo, error) {
 	switch fsType {
 	// for cgroupv2 the fs type is CGROUP2_SUPER_MAGIC
 	case unix.CGROUP2_SUPER_MAGIC:
+		if err = checkDelegatedControllers(defaultCgroupMountPoint); err != nil {
+			return nil, err
+		}
 		return &CgroupInfo{
 			cgroupResolutionPrefix: defaultCgroupMountPoint,
 			fsMagic:                unix.CGROUP2_SUPER_MAGIC,
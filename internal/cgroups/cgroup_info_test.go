@@ -3,6 +3,7 @@ package cgroups
 import (
 	"math"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -67,3 +68,41 @@ pids 3 3 1
 		})
 	}
 }
+
+func TestCheckDelegatedControllers(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileContent string
+		expectedErr string
+	}{
+		{
+			name:        "memory delegated",
+			fileContent: "cpuset cpu io memory hugetlb pids rdma misc\n",
+		},
+		{
+			name:        "memory not delegated",
+			fileContent: "cpu pids\n",
+			expectedErr: "the 'memory' controller is not delegated to the container cgroups",
+		},
+		{
+			name:        "no controller delegated",
+			fileContent: "",
+			expectedErr: "only enables: []",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(root, subtreeControlFile), []byte(tt.fileContent), 0o644))
+
+			err := checkDelegatedControllers(root)
+			if tt.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+
+	require.ErrorContains(t, checkDelegatedControllers(t.TempDir()), "failed to read")
+}
@@ -81,40 +81,91 @@ func SystemdExpandSlice(slice string) (string, error) {
 	return pathBuilder.String(), nil
 }
 
+// CgroupDriver is the cgroup driver used by the kubelet and the container runtime.
+type CgroupDriver string
+
+const (
+	// CgroupDriverSystemd produces "slice:prefix:name" cgroup paths, expanded into systemd units.
+	CgroupDriverSystemd CgroupDriver = "systemd"
+	// CgroupDriverCgroupfs produces cgroup paths such as "/kubepods/besteffort/pod<uid>/<containerid>".
+	CgroupDriverCgroupfs CgroupDriver = "cgroupfs"
+)
+
+// DetectCgroupDriver returns the cgroup driver that produced the given cgroup path.
+func DetectCgroupDriver(cgroupPath string) CgroupDriver {
+	if strings.Contains(cgroupPath, "/") {
+		return CgroupDriverCgroupfs
+	}
+	return CgroupDriverSystemd
+}
+
+// defaultSystemdSlice is the slice used by the OCI runtimes when the cgroup path doesn't specify one.
+const defaultSystemdSlice = "system.slice"
+
 // ParseCgroupsPath parses the cgroup path from the CRI response.
 //
 // Example input: kubelet-kubepods-besteffort-pod83b090de_9676_407c_99aa_d33dc6aa0c0d.slice:cri-containerd:18b2adc8507104e412c946bec11679590801f547eee513fa298054f14fbf4240
 //
 // Example output:
 // /kubelet.slice/kubelet-kubepods.slice/kubelet-kubepods-besteffort.slice/kubelet-kubepods-besteffort-pod83b090de_9676_407c_99aa_d33dc6aa0c0d.slice/cri-containerd-18b2adc8507104e412c946bec11679590801f547eee513fa298054f14fbf4240.scope.
+//
+// The paths of the cgroupfs driver are already relative to the cgroup root, so they are only cleaned up.
 func ParseCgroupsPath(cgroupPath string) (string, error) {
-	if strings.Contains(cgroupPath, "/") {
-		return cgroupPath, nil
+	switch DetectCgroupDriver(cgroupPath) {
+	case CgroupDriverCgroupfs:
+		// The path is anchored to the root so that it can't point outside of the cgroup hierarchy.
+		return filepath.Clean("/" + cgroupPath), nil
+	case CgroupDriverSystemd:
+		return parseSystemdCgroupsPath(cgroupPath)
+	default:
+		return "", fmt.Errorf("unknown cgroup path: %s", cgroupPath)
 	}
+}
 
+func parseSystemdCgroupsPath(cgroupPath string) (string, error) {
 	// There are some cases where CgroupsPath  is specified as "slice:prefix:name"
 	// From runc --help
 	//   --systemd-cgroup    enable systemd cgroup support, expects cgroupsPath to be of form "slice:prefix:name"
 	//                       for e.g. "system.slice:runc:434234"
 	//
 	// https://github.com/opencontainers/runc/blob/5cf9bb229feed19a767cbfdf9702f6487341e29e/libcontainer/specconv/spec_linux.go#L655-L663
+	//
+	// crun and youki accept the same format with a few variations:
+	//   - an empty slice means the default one, e.g. ":crun:434234".
+	//   - an empty prefix is omitted from the scope name, e.g. "system.slice::434234" (crun).
+	//   - a name already ending with ".scope" is used as the unit name, e.g. "system.slice::434234.scope" (crun).
 	parts := strings.Split(cgroupPath, ":")
 	const cgroupPathSlicePrefixNameParts = 3
 	if len(parts) == cgroupPathSlicePrefixNameParts {
 		var err error
 		// kubelet-kubepods-besteffort-pod83b090de_9676_407c_99aa_d33dc6aa0c0d.slice:cri-containerd:18b2adc8507104e412c946bec11679590801f547eee513fa298054f14fbf4240
 		slice, containerRuntimeName, containerID := parts[0], parts[1], parts[2]
+		if containerID == "" {
+			return "", fmt.Errorf("failed to parse cgroup path: %s (missing container name)", cgroupPath)
+		}
+		if slice == "" {
+			slice = defaultSystemdSlice
+		}
 		slice, err = SystemdExpandSlice(slice)
 		if err != nil {
 			return "", fmt.Errorf("failed to parse cgroup path: %s (%s does not seem to be a slice)", cgroupPath, slice)
 		}
-		// https://github.com/opencontainers/runc/blob/5cf9bb229feed19a767cbfdf9702f6487341e29e/libcontainer/cgroups/systemd/common.go#L95-L101
-		if !strings.HasSuffix(containerID, ".slice") {
-			// We want something like this: cri-containerd-18b2adc8507104e412c946bec11679590801f547eee513fa298054f14fbf4240.scope
-			containerID = containerRuntimeName + "-" + containerID + ".scope"
-		}
-		return filepath.Join(slice, containerID), nil
+		return filepath.Join(slice, systemdUnitName(containerRuntimeName, containerID)), nil
 	}
 
 	return "", fmt.Errorf("unknown cgroup path: %s", cgroupPath)
 }
+
+// systemdUnitName returns the name of the systemd unit of the container.
+//
+// https://github.com/opencontainers/runc/blob/5cf9bb229feed19a767cbfdf9702f6487341e29e/libcontainer/cgroups/systemd/common.go#L95-L101
+func systemdUnitName(prefix, name string) string {
+	if strings.HasSuffix(name, ".slice") || strings.HasSuffix(name, ".scope") {
+		return name
+	}
+	if prefix == "" {
+		return name + ".scope"
+	}
+	// We want something like this: cri-containerd-18b2adc8507104e412c946bec11679590801f547eee513fa298054f14fbf4240.scope
+	return prefix + "-" + name + ".scope"
+}
@@ -18,6 +18,64 @@ func TestParseCgroupsPath(t *testing.T) {
 			in:       "kubelet-kubepods-besteffort-pod83b090de_9676_407c_99aa_d33dc6aa0c0d.slice:cri-containerd:18b2adc8507104e412c946bec11679590801f547eee513fa298054f14fbf4240",
 			expected: "/kubelet.slice/kubelet-kubepods.slice/kubelet-kubepods-besteffort.slice/kubelet-kubepods-besteffort-pod83b090de_9676_407c_99aa_d33dc6aa0c0d.slice/cri-containerd-18b2adc8507104e412c946bec11679590801f547eee513fa298054f14fbf4240.scope",
 		},
+		{
+			// CRI-O with crun and the systemd cgroup manager
+			name:     "crun systemd",
+			in:       "kubepods-burstable-pod5c5a0ad2_7a8b_4b4e_8c4f_2f5e59f1a1b2.slice:crio:8e0c8f4d5ba5b1a35dcc2b1e8e1e4f9b07c5f0a1d2e3f4a5b6c7d8e9f0a1b2c3",
+			expected: "/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod5c5a0ad2_7a8b_4b4e_8c4f_2f5e59f1a1b2.slice/crio-8e0c8f4d5ba5b1a35dcc2b1e8e1e4f9b07c5f0a1d2e3f4a5b6c7d8e9f0a1b2c3.scope",
+		},
+		{
+			name:     "crun systemd without prefix",
+			in:       "system.slice::8e0c8f4d5ba5",
+			expected: "/system.slice/8e0c8f4d5ba5.scope",
+		},
+		{
+			name:     "crun systemd with scope name",
+			in:       "system.slice::crio-8e0c8f4d5ba5.scope",
+			expected: "/system.slice/crio-8e0c8f4d5ba5.scope",
+		},
+		{
+			name:     "youki systemd",
+			in:       "kubepods-besteffort-pod83b090de_9676_407c_99aa_d33dc6aa0c0d.slice:youki:18b2adc85071",
+			expected: "/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod83b090de_9676_407c_99aa_d33dc6aa0c0d.slice/youki-18b2adc85071.scope",
+		},
+		{
+			name:     "youki systemd with default slice",
+			in:       ":youki:18b2adc85071",
+			expected: "/system.slice/youki-18b2adc85071.scope",
+		},
+		{
+			name:     "systemd slice as container name",
+			in:       "kubepods.slice:crio:kubepods-besteffort.slice",
+			expected: "/kubepods.slice/kubepods-besteffort.slice",
+		},
+		{
+			// crun and youki with the cgroupfs cgroup manager
+			name:     "cgroupfs",
+			in:       "/kubepods/besteffort/pod83b090de-9676-407c-99aa-d33dc6aa0c0d/18b2adc85071",
+			expected: "/kubepods/besteffort/pod83b090de-9676-407c-99aa-d33dc6aa0c0d/18b2adc85071",
+		},
+		{
+			name:     "cgroupfs relative path",
+			in:       "kubepods/besteffort/pod83b090de-9676-407c-99aa-d33dc6aa0c0d/18b2adc85071",
+			expected: "/kubepods/besteffort/pod83b090de-9676-407c-99aa-d33dc6aa0c0d/18b2adc85071",
+		},
+		{
+			// kubelet with the cgroupfs driver on a cgroup v1 node
+			name:     "cgroupfs guaranteed pod",
+			in:       "/kubepods/pod83b090de-9676-407c-99aa-d33dc6aa0c0d/18b2adc8507104e412c946bec11679590801f547eee513fa298054f14fbf4240",
+			expected: "/kubepods/pod83b090de-9676-407c-99aa-d33dc6aa0c0d/18b2adc8507104e412c946bec11679590801f547eee513fa298054f14fbf4240",
+		},
+		{
+			name:     "cgroupfs path is not expanded as a slice",
+			in:       "/kubepods.slice/kubepods-besteffort.slice/crio-18b2adc85071.scope",
+			expected: "/kubepods.slice/kubepods-besteffort.slice/crio-18b2adc85071.scope",
+		},
+		{
+			name:     "cgroupfs path cannot escape the cgroup root",
+			in:       "/kubepods/../../../etc",
+			expected: "/etc",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -28,6 +86,46 @@ func TestParseCgroupsPath(t *testing.T) {
 	}
 }
 
+func TestParseCgroupsPathErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "empty", in: ""},
+		{name: "missing prefix and name", in: "system.slice"},
+		{name: "missing container name", in: "system.slice:crio:"},
+		{name: "invalid slice", in: "system:crio:18b2adc85071"},
+		{name: "too many parts", in: "system.slice:crio:18b2adc85071:extra"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCgroupsPath(tt.in)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestDetectCgroupDriver(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected CgroupDriver
+	}{
+		{
+			in:       "kubepods-besteffort-pod83b090de_9676_407c_99aa_d33dc6aa0c0d.slice:cri-containerd:18b2adc85071",
+			expected: CgroupDriverSystemd,
+		},
+		{
+			in:       "/kubepods/besteffort/pod83b090de-9676-407c-99aa-d33dc6aa0c0d/18b2adc85071",
+			expected: CgroupDriverCgroupfs,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			require.Equal(t, tt.expected, DetectCgroupDriver(tt.in))
+		})
+	}
+}
+
 func TestSystemdExpandSlice(t *testing.T) {
 	tests := []struct {
 		in       string
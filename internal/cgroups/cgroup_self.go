@@ -0,0 +1,74 @@
+package cgroups
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// selfCgroupPath lists the cgroups of the current process.
+	selfCgroupPath = defaultProcFSPath + "/self/cgroup"
+
+	// namespacedCgroupMountPoint is where the container runtime mounts the cgroups of the container.
+	namespacedCgroupMountPoint = "/sys/fs/cgroup"
+
+	// selfCgroupFields are the fields of a line of selfCgroupPath: hierarchy ID, controllers and path.
+	selfCgroupFields = 3
+)
+
+// GetSelfCgroupID returns the ID of the cgroup of the current process, e.g. the one of the agent container.
+// With a private cgroup namespace the process sees its cgroup as the root one, which is then resolved
+// through the cgroup mount point of the container.
+func GetSelfCgroupID() (uint64, error) {
+	cgInfo, err := GetCgroupInfo()
+	if err != nil {
+		return 0, err
+	}
+	file, err := os.Open(selfCgroupPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", selfCgroupPath, err)
+	}
+	defer file.Close()
+
+	v2 := cgInfo.CgroupFsMagic() == unix.CGROUP2_SUPER_MAGIC
+	path, err := parseSelfCgroup(file, v2)
+	if err != nil {
+		return 0, err
+	}
+	if path != "/" {
+		return GetCgroupIDFromPath(filepath.Join(cgInfo.CgroupResolutionPrefix(), path))
+	}
+	if v2 {
+		return GetCgroupIDFromPath(namespacedCgroupMountPoint)
+	}
+	return GetCgroupIDFromPath(filepath.Join(namespacedCgroupMountPoint, memoryControllerName))
+}
+
+// parseSelfCgroup returns the cgroup path of the process, from the unified hierarchy on cgroupv2
+// or from the memory controller hierarchy on cgroupv1.
+func parseSelfCgroup(r io.Reader, v2 bool) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), ":", selfCgroupFields)
+		if len(fields) != selfCgroupFields {
+			continue
+		}
+		if v2 && fields[0] == "0" && fields[1] == "" {
+			return fields[2], nil
+		}
+		if !v2 && slices.Contains(strings.Split(fields[1], ","), memoryControllerName) {
+			return fields[2], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read the cgroups of the process: %w", err)
+	}
+	return "", fmt.Errorf("cgroup of the process not found in %s", selfCgroupPath)
+}
@@ -0,0 +1,30 @@
+package cgroups
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelfCgroup(t *testing.T) {
+	const v1 = `12:pids:/kubepods/besteffort/pod83b090de/18b2adc85071
+5:memory:/kubepods/besteffort/pod83b090de/18b2adc85071
+1:name=systemd:/kubepods/besteffort/pod83b090de/18b2adc85071
+`
+	path, err := parseSelfCgroup(strings.NewReader(v1), false)
+	require.NoError(t, err)
+	require.Equal(t, "/kubepods/besteffort/pod83b090de/18b2adc85071", path)
+
+	// a private cgroup namespace.
+	path, err = parseSelfCgroup(strings.NewReader("0::/\n"), true)
+	require.NoError(t, err)
+	require.Equal(t, "/", path)
+
+	path, err = parseSelfCgroup(strings.NewReader("0::/kubepods.slice/cri-containerd-18b2adc85071.scope\n"), true)
+	require.NoError(t, err)
+	require.Equal(t, "/kubepods.slice/cri-containerd-18b2adc85071.scope", path)
+
+	_, err = parseSelfCgroup(strings.NewReader(v1), true)
+	require.Error(t, err)
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	pb "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+)
+
+// bpfLoadConfigKey is the comparable form of the configuration the eBPF programs of an agent were loaded with.
+type bpfLoadConfigKey struct {
+	cgroupFsMagic     uint64
+	cgroupV1SubsysIdx uint32
+	debugMode         bool
+	learningEnabled   bool
+}
+
+func newBpfLoadConfigKey(conf *pb.BpfLoadConfig) bpfLoadConfigKey {
+	return bpfLoadConfigKey{
+		cgroupFsMagic:     conf.GetCgroupFsMagic(),
+		cgroupV1SubsysIdx: conf.GetCgroupv1SubsysIdx(),
+		debugMode:         conf.GetDebugMode(),
+		learningEnabled:   conf.GetLearningEnabled(),
+	}
+}
+
+// bpfConfigTracker reports, for each node, whether its agent loaded the eBPF programs with a configuration
+// different from the one of most nodes, e.g. a node with another cgroup version or an agent started with
+// other flags. The values are the ones of the last status sync.
+type bpfConfigTracker struct {
+	mu         sync.Mutex
+	mismatched map[string]bool
+	desc       *prometheus.Desc
+}
+
+var _ prometheus.Collector = &bpfConfigTracker{}
+
+func newBpfConfigTracker() *bpfConfigTracker {
+	return &bpfConfigTracker{
+		mismatched: make(map[string]bool),
+		desc: prometheus.NewDesc(
+			"runtime_enforcer_agent_bpf_config_mismatch",
+			"Whether the agent of a node loaded the eBPF programs with a configuration different from most nodes.",
+			[]string{"node"},
+			nil,
+		),
+	}
+}
+
+// BpfConfigMismatchCollector returns the collector of the runtime_enforcer_agent_bpf_config_mismatch gauges.
+func (r *WorkloadPolicyStatusSync) BpfConfigMismatchCollector() prometheus.Collector {
+	return r.bpfConfigMismatch
+}
+
+// checkBpfConfigs compares the load-time eBPF configuration reported by the agents and flags the nodes
+// that differ from the most common one. The agents not reporting it are skipped.
+func (r *WorkloadPolicyStatusSync) checkBpfConfigs(infos map[string]*pb.GetAgentInfoResponse) {
+	configs := make(map[string]*pb.BpfLoadConfig, len(infos))
+	for node, info := range infos {
+		if conf := info.GetBpfLoadConfig(); conf != nil {
+			configs[node] = conf
+		}
+	}
+
+	mismatched := findBpfConfigMismatches(configs)
+	for node, mismatch := range mismatched {
+		if mismatch {
+			r.logger.Info("the eBPF programs of the agent were loaded with a configuration different from most nodes",
+				"node", node, "config", configs[node].String())
+		}
+	}
+	r.bpfConfigMismatch.set(mismatched)
+}
+
+// findBpfConfigMismatches returns, for each node, whether its configuration differs from the most common one.
+// On a tie, the configuration of the first node in name order wins so that the result is stable.
+func findBpfConfigMismatches(configs map[string]*pb.BpfLoadConfig) map[string]bool {
+	counts := make(map[bpfLoadConfigKey]int)
+	for _, conf := range configs {
+		counts[newBpfLoadConfigKey(conf)]++
+	}
+	var reference bpfLoadConfigKey
+	best := ""
+	for node, conf := range configs {
+		key := newBpfLoadConfigKey(conf)
+		if best == "" || counts[key] > counts[reference] || (counts[key] == counts[reference] && node < best) {
+			reference, best = key, node
+		}
+	}
+
+	mismatched := make(map[string]bool, len(configs))
+	for node, conf := range configs {
+		mismatched[node] = newBpfLoadConfigKey(conf) != reference
+	}
+	return mismatched
+}
+
+// set replaces the nodes reported by the tracker.
+func (t *bpfConfigTracker) set(mismatched map[string]bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.mismatched = mismatched
+}
+
+func (t *bpfConfigTracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- t.desc
+}
+
+func (t *bpfConfigTracker) Collect(ch chan<- prometheus.Metric) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for node, mismatch := range t.mismatched {
+		value := 0.0
+		if mismatch {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(t.desc, prometheus.GaugeValue, value, node)
+	}
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/grpcexporter"
+	pb "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBpfConfigMismatchMetric(t *testing.T) {
+	const cgroup2SuperMagic = 0x63677270
+	cgroupV2 := &pb.BpfLoadConfig{CgroupFsMagic: cgroup2SuperMagic}
+	agentWithConfig := func(conf *pb.BpfLoadConfig) grpcexporter.AgentClientAPI {
+		return &testAgentClient{info: &pb.GetAgentInfoResponse{BpfLoadConfig: conf}}
+	}
+	r := &WorkloadPolicyStatusSync{bpfConfigMismatch: newBpfConfigTracker()}
+
+	r.checkBpfConfigs(r.getAgentInfos(t.Context(), map[string]grpcexporter.AgentClientAPI{
+		"node1": agentWithConfig(cgroupV2),
+		"node2": agentWithConfig(cgroupV2),
+		// a node still on cgroup v1.
+		"node3": agentWithConfig(&pb.BpfLoadConfig{CgroupFsMagic: 0x01021994, Cgroupv1SubsysIdx: 4}),
+		// the agents not reporting their configuration are skipped.
+		"node4": agentWithConfig(nil),
+		"node5": nil,
+	}))
+	require.NoError(t, testutil.CollectAndCompare(r.BpfConfigMismatchCollector(), strings.NewReader(`
+# HELP runtime_enforcer_agent_bpf_config_mismatch Whether the agent of a node loaded the eBPF programs with a configuration different from most nodes.
+# TYPE runtime_enforcer_agent_bpf_config_mismatch gauge
+runtime_enforcer_agent_bpf_config_mismatch{node="node1"} 0
+runtime_enforcer_agent_bpf_config_mismatch{node="node2"} 0
+runtime_enforcer_agent_bpf_config_mismatch{node="node3"} 1
+`)))
+}
+
+func TestFindBpfConfigMismatches(t *testing.T) {
+	debug := &pb.BpfLoadConfig{DebugMode: true}
+	noDebug := &pb.BpfLoadConfig{}
+
+	require.Empty(t, findBpfConfigMismatches(nil))
+	// on a tie the configuration of the first node wins.
+	require.Equal(t, map[string]bool{"node1": false, "node2": true},
+		findBpfConfigMismatches(map[string]*pb.BpfLoadConfig{"node1": debug, "node2": noDebug}))
+	require.Equal(t, map[string]bool{"node1": true, "node2": false, "node3": false},
+		findBpfConfigMismatches(map[string]*pb.BpfLoadConfig{"node1": debug, "node2": noDebug, "node3": noDebug}))
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/grpcexporter"
+	pb "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+)
+
+// cgroupIncompatibleFailures is the number of consecutive failed cgroup resolutions after which
+// the cgroup configuration of a node is considered incompatible: its containers are not enforced.
+const cgroupIncompatibleFailures = 3
+
+// getAgentInfos returns the info reported by the agent of each node, the failed agents are skipped.
+func (r *WorkloadPolicyStatusSync) getAgentInfos(
+	ctx context.Context,
+	clients map[string]grpcexporter.AgentClientAPI,
+) map[string]*pb.GetAgentInfoResponse {
+	results := callAgents(ctx, clients, r.agentConcurrency,
+		func(ctx context.Context, client grpcexporter.AgentClientAPI) (*pb.GetAgentInfoResponse, error) {
+			return client.GetAgentInfo(ctx)
+		})
+	infos := make(map[string]*pb.GetAgentInfoResponse, len(results))
+	for _, res := range results {
+		if res.err != nil {
+			r.handleAgentCallError(res.node, res.err, "failed to get agent info")
+			continue
+		}
+		infos[res.node] = res.value
+	}
+	return infos
+}
+
+// cgroupIssue returns the NodeIssueCgroupIncompatible issue of the node whose agent keeps failing
+// to resolve the cgroups of the containers, e.g. with a cgroup driver or version it doesn't support.
+func cgroupIssue(info *pb.GetAgentInfoResponse) (v1alpha1.NodeIssue, bool) {
+	resolution := info.GetCgroupResolution()
+	if resolution.GetConsecutiveFailures() < cgroupIncompatibleFailures {
+		return v1alpha1.NodeIssue{}, false
+	}
+	return v1alpha1.NodeIssue{
+		Code: v1alpha1.NodeIssueCgroupIncompatible,
+		Message: fmt.Sprintf("the last %d cgroup resolutions failed: %s",
+			resolution.GetConsecutiveFailures(), resolution.GetLastError()),
+	}, true
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	pb "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCgroupIssue(t *testing.T) {
+	withFailures := func(failures uint64) *pb.GetAgentInfoResponse {
+		return &pb.GetAgentInfoResponse{CgroupResolution: &pb.CgroupResolution{
+			ConsecutiveFailures: failures,
+			LastError:           "cgroup path not found",
+		}}
+	}
+
+	// the agents not reporting their cgroup resolutions and the occasional failures are not issues.
+	_, ok := cgroupIssue(&pb.GetAgentInfoResponse{})
+	require.False(t, ok)
+	_, ok = cgroupIssue(withFailures(cgroupIncompatibleFailures - 1))
+	require.False(t, ok)
+
+	issue, ok := cgroupIssue(withFailures(cgroupIncompatibleFailures))
+	require.True(t, ok)
+	require.Equal(t, v1alpha1.NodeIssue{
+		Code:    v1alpha1.NodeIssueCgroupIncompatible,
+		Message: "the last 3 cgroup resolutions failed: cgroup path not found",
+	}, issue)
+
+	// the node is reported in the status of the policies with its own code.
+	policyName := "ns/policy"
+	status, err := computeWpStatus(nodesInfoMap{
+		"node1": {issue: issue, policies: map[string]*pb.PolicyStatus{
+			policyName: {State: pb.PolicyState_POLICY_STATE_READY, Mode: pb.PolicyMode_POLICY_MODE_PROTECT},
+		}},
+		"node2": {issue: v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone}, policies: map[string]*pb.PolicyStatus{
+			policyName: {State: pb.PolicyState_POLICY_STATE_READY, Mode: pb.PolicyMode_POLICY_MODE_PROTECT},
+		}},
+	}, pb.PolicyMode_POLICY_MODE_PROTECT, policyName, newMissingPolicyGrace(0))
+	require.NoError(t, err)
+	require.Equal(t, map[string]v1alpha1.NodeIssue{"node1": issue}, status.NodesWithIssues)
+	require.Equal(t, 1, status.SuccessfulNodes)
+}
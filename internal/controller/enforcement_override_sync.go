@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/grpcexporter"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	overrideAppliedReason = "EnforcementOverrideApplied"
+	overrideAction        = "SetEnforcementOverride"
+)
+
+// +kubebuilder:rbac:groups=security.rancher.io,resources=enforcementoverrides,verbs=get;list;watch
+
+// syncEnforcementOverride tells every agent whether an EnforcementOverride exists, so that they all switch
+// to monitor mode within a sync interval, and restore the mode of the policies once the last one is deleted.
+// The agents restarted in the meantime get the override again at the next sync.
+// An Event is recorded on the overrides when agents switch to monitor mode.
+func (r *WorkloadPolicyStatusSync) syncEnforcementOverride(
+	ctx context.Context,
+	clients map[string]grpcexporter.AgentClientAPI,
+) error {
+	var overrides v1alpha1.EnforcementOverrideList
+	if err := r.List(ctx, &overrides); err != nil {
+		return fmt.Errorf("failed to list EnforcementOverrides: %w", err)
+	}
+	enabled := len(overrides.Items) > 0
+
+	results := callAgents(ctx, clients, r.agentConcurrency,
+		func(ctx context.Context, client grpcexporter.AgentClientAPI) (bool, error) {
+			return client.SetEnforcementOverride(ctx, enabled)
+		})
+	var switched []string
+	for _, res := range results {
+		if res.err != nil {
+			r.handleAgentCallError(res.node, res.err, "failed to set the enforcement override")
+			continue
+		}
+		if res.value {
+			switched = append(switched, res.node)
+		}
+	}
+	if len(switched) == 0 {
+		return nil
+	}
+	r.logger.Info("the agents switched the enforcement override", "enabled", enabled, "nodes", switched)
+	if !enabled {
+		return nil
+	}
+	for i := range overrides.Items {
+		r.recorder.Eventf(&overrides.Items[i], nil, corev1.EventTypeWarning, overrideAppliedReason, overrideAction,
+			"Enforcement override applied on the nodes %s, all the policies are enforced in monitor mode",
+			strings.Join(switched, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/grpcexporter"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+)
+
+func TestSyncEnforcementOverride(t *testing.T) {
+	r := createTestWPStatusSync(t)
+	recorder, ok := r.recorder.(*events.FakeRecorder)
+	require.True(t, ok)
+	node1, node2 := &testAgentClient{}, &testAgentClient{}
+	clients := map[string]grpcexporter.AgentClientAPI{
+		"node1": node1,
+		"node2": node2,
+		// the nodes without agent client are skipped.
+		"node3": nil,
+	}
+
+	require.NoError(t, r.syncEnforcementOverride(t.Context(), clients))
+	require.False(t, node1.overrideEnabled)
+	require.Empty(t, recorder.Events)
+
+	override := &v1alpha1.EnforcementOverride{ObjectMeta: metav1.ObjectMeta{Name: "incident"}}
+	require.NoError(t, r.Create(t.Context(), override))
+	require.NoError(t, r.syncEnforcementOverride(t.Context(), clients))
+	require.True(t, node1.overrideEnabled)
+	require.True(t, node2.overrideEnabled)
+	require.Len(t, recorder.Events, 1)
+	require.Equal(t, "Warning EnforcementOverrideApplied Enforcement override applied on the nodes node1, node2, "+
+		"all the policies are enforced in monitor mode", <-recorder.Events)
+
+	// the agents already in monitor mode don't record the Event again.
+	require.NoError(t, r.syncEnforcementOverride(t.Context(), clients))
+	require.Empty(t, recorder.Events)
+
+	// deleting the override restores the enforcement.
+	require.NoError(t, r.Delete(t.Context(), override))
+	require.NoError(t, r.syncEnforcementOverride(t.Context(), clients))
+	require.False(t, node1.overrideEnabled)
+	require.False(t, node2.overrideEnabled)
+	require.Empty(t, recorder.Events)
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"time"
+)
+
+type nodePolicyKey struct {
+	policy string
+	node   string
+}
+
+// missingPolicyGrace remembers since when a policy is missing on each node, so that
+// a node is only reported as failed once the policy stayed missing for the whole grace period.
+// During a rollout the policy is briefly missing on the nodes, the WorkloadPolicy stays transitioning instead.
+type missingPolicyGrace struct {
+	period   time.Duration
+	now      func() time.Time
+	since    map[nodePolicyKey]time.Time
+	observed map[nodePolicyKey]struct{}
+}
+
+func newMissingPolicyGrace(period time.Duration) *missingPolicyGrace {
+	return &missingPolicyGrace{
+		period:   period,
+		now:      time.Now,
+		since:    make(map[nodePolicyKey]time.Time),
+		observed: make(map[nodePolicyKey]struct{}),
+	}
+}
+
+// missing must be called each time the policy is missing on the node.
+// It returns true while the policy is missing for less than the grace period.
+func (g *missingPolicyGrace) missing(policy, node string) bool {
+	if g == nil || g.period == 0 {
+		return false
+	}
+	key := nodePolicyKey{policy: policy, node: node}
+	g.observed[key] = struct{}{}
+	since, ok := g.since[key]
+	if !ok {
+		since = g.now()
+		g.since[key] = since
+	}
+	return g.now().Sub(since) < g.period
+}
+
+// present must be called when the policy is found on the node, the next time it goes missing
+// the grace period starts again.
+func (g *missingPolicyGrace) present(policy, node string) {
+	if g == nil {
+		return
+	}
+	delete(g.since, nodePolicyKey{policy: policy, node: node})
+}
+
+// prune forgets the policies and nodes that were not observed since the previous call,
+// e.g. because the policy was deleted or the node left the cluster.
+func (g *missingPolicyGrace) prune() {
+	if g == nil {
+		return
+	}
+	for key := range g.since {
+		if _, ok := g.observed[key]; !ok {
+			delete(g.since, key)
+		}
+	}
+	clear(g.observed)
+}
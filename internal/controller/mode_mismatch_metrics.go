@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// modeMismatchTracker reports, for each WorkloadPolicy, the number of nodes where the policy
+// is ready but enforced in a mode different from the one of its spec.
+// The counts are the ones of the last status sync, a slow rollout shows up as a gauge that doesn't go back to 0.
+type modeMismatchTracker struct {
+	mu     sync.Mutex
+	counts map[types.NamespacedName]int
+	desc   *prometheus.Desc
+}
+
+var _ prometheus.Collector = &modeMismatchTracker{}
+
+func newModeMismatchTracker() *modeMismatchTracker {
+	return &modeMismatchTracker{
+		counts: make(map[types.NamespacedName]int),
+		desc: prometheus.NewDesc(
+			"runtime_enforcer_policy_mode_mismatch",
+			"Number of nodes where a WorkloadPolicy is not yet enforced in the mode of its spec.",
+			[]string{"namespace", "policy"},
+			nil,
+		),
+	}
+}
+
+// ModeMismatchCollector returns the collector of the runtime_enforcer_policy_mode_mismatch gauges.
+func (r *WorkloadPolicyStatusSync) ModeMismatchCollector() prometheus.Collector {
+	return r.modeMismatch
+}
+
+// record stores the number of mode-mismatched nodes computed for the policy.
+func (t *modeMismatchTracker) record(policy types.NamespacedName, nodes int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counts[policy] = nodes
+}
+
+// retain forgets the policies that are not in the given set, e.g. because they were deleted.
+func (t *modeMismatchTracker) retain(policies map[types.NamespacedName]struct{}) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for policy := range t.counts {
+		if _, ok := policies[policy]; !ok {
+			delete(t.counts, policy)
+		}
+	}
+}
+
+func (t *modeMismatchTracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- t.desc
+}
+
+func (t *modeMismatchTracker) Collect(ch chan<- prometheus.Metric) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for policy, nodes := range t.counts {
+		ch <- prometheus.MustNewConstMetric(t.desc, prometheus.GaugeValue, float64(nodes),
+			policy.Namespace, policy.Name)
+	}
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	pb "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestModeMismatchMetric(t *testing.T) {
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"},
+		Spec:       v1alpha1.WorkloadPolicySpec{Mode: policymode.ProtectString},
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(wp).WithStatusSubresource(wp).Build()
+	r := &WorkloadPolicyStatusSync{
+		Client:       cl,
+		recorder:     events.NewFakeRecorder(10),
+		modeMismatch: newModeMismatchTracker(),
+	}
+
+	nodeInMode := func(mode pb.PolicyMode) nodeInfo {
+		return nodeInfo{
+			issue: v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone},
+			policies: map[string]*pb.PolicyStatus{
+				wp.NamespacedName(): {State: pb.PolicyState_POLICY_STATE_READY, Mode: mode},
+			},
+		}
+	}
+	require.NoError(t, r.processWorkloadPolicy(t.Context(), wp, nodesInfoMap{
+		"node1": nodeInMode(pb.PolicyMode_POLICY_MODE_MONITOR),
+		"node2": nodeInMode(pb.PolicyMode_POLICY_MODE_MONITOR),
+		"node3": nodeInMode(pb.PolicyMode_POLICY_MODE_PROTECT),
+	}, nil, nil))
+	require.NoError(t, testutil.CollectAndCompare(r.ModeMismatchCollector(), strings.NewReader(`
+# HELP runtime_enforcer_policy_mode_mismatch Number of nodes where a WorkloadPolicy is not yet enforced in the mode of its spec.
+# TYPE runtime_enforcer_policy_mode_mismatch gauge
+runtime_enforcer_policy_mode_mismatch{namespace="ns",policy="policy"} 2
+`)))
+
+	// the deleted policies are not reported anymore.
+	r.modeMismatch.retain(nil)
+	require.Zero(t, testutil.CollectAndCount(r.ModeMismatchCollector()))
+}
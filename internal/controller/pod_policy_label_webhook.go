@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/validate--v1-pod,mutating=false,failurePolicy=fail,sideEffects=None,groups="",resources=pods,verbs=update,versions=v1,name=validate-pod-policy-label.rancher.io,admissionReviewVersions=v1
+
+// PodPolicyLabelValidator enforces the immutability of the policy label on Pods.
+// It mirrors the pod-policy-validation ValidatingAdmissionPolicy shipped with the chart
+// and is meant to be enabled on clusters where ValidatingAdmissionPolicy is not available.
+type PodPolicyLabelValidator struct{}
+
+var _ admission.Validator[*corev1.Pod] = &PodPolicyLabelValidator{}
+
+func (v *PodPolicyLabelValidator) ValidateCreate(
+	_ context.Context,
+	_ *corev1.Pod,
+) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *PodPolicyLabelValidator) ValidateUpdate(
+	_ context.Context,
+	oldPod, newPod *corev1.Pod,
+) (admission.Warnings, error) {
+	oldPolicy, oldFound := oldPod.GetLabels()[v1alpha1.PolicyLabelKey]
+	newPolicy, newFound := newPod.GetLabels()[v1alpha1.PolicyLabelKey]
+
+	if oldFound == newFound && oldPolicy == newPolicy {
+		return nil, nil
+	}
+
+	return nil, apierrors.NewForbidden(
+		schema.GroupResource{
+			Group:    "",
+			Resource: "pods",
+		},
+		newPod.GetName(),
+		fmt.Errorf(
+			"the label '%s' is immutable. You cannot add, remove, or change its value",
+			v1alpha1.PolicyLabelKey,
+		),
+	)
+}
+
+func (v *PodPolicyLabelValidator) ValidateDelete(
+	_ context.Context,
+	_ *corev1.Pod,
+) (admission.Warnings, error) {
+	return nil, nil
+}
@@ -0,0 +1,72 @@
+package controller_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/controller"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Pod policy label Webhook", func() {
+	var validator *controller.PodPolicyLabelValidator
+
+	newPod := func(labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-pod",
+				Namespace: "default",
+				Labels:    labels,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		validator = &controller.PodPolicyLabelValidator{}
+	})
+
+	Context("ValidateUpdate", func() {
+		It("allows updates that keep the policy label unchanged", func() {
+			oldPod := newPod(map[string]string{v1alpha1.PolicyLabelKey: "policy", "app": "test"})
+			updatedPod := newPod(map[string]string{v1alpha1.PolicyLabelKey: "policy", "app": "changed"})
+			_, err := validator.ValidateUpdate(ctx, oldPod, updatedPod)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("allows updates on pods without the policy label", func() {
+			_, err := validator.ValidateUpdate(ctx, newPod(nil), newPod(map[string]string{"app": "test"}))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("denies adding the policy label", func() {
+			_, err := validator.ValidateUpdate(ctx,
+				newPod(nil),
+				newPod(map[string]string{v1alpha1.PolicyLabelKey: "policy"}))
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		})
+
+		It("denies removing the policy label", func() {
+			_, err := validator.ValidateUpdate(ctx,
+				newPod(map[string]string{v1alpha1.PolicyLabelKey: "policy"}),
+				newPod(nil))
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		})
+
+		It("denies changing the policy label", func() {
+			_, err := validator.ValidateUpdate(ctx,
+				newPod(map[string]string{v1alpha1.PolicyLabelKey: "policy"}),
+				newPod(map[string]string{v1alpha1.PolicyLabelKey: "other"}))
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		})
+
+		It("denies setting the policy label to an empty value", func() {
+			_, err := validator.ValidateUpdate(ctx,
+				newPod(nil),
+				newPod(map[string]string{v1alpha1.PolicyLabelKey: ""}))
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxConflictingWorkloads avoids oversized condition messages.
+const maxConflictingWorkloads = 5
+
+// labelConflicts maps the namespaced name of a policy to the workloads whose pods also reference other policies.
+// A nil map means the conflicts are unknown, e.g. the pods could not be listed.
+type labelConflicts map[string][]string
+
+// workloadController identifies the controller owning a set of pods.
+type workloadController struct {
+	namespace string
+	kind      string
+	name      string
+}
+
+// getLabelConflicts lists the pods referencing a policy and returns the conflicts between them.
+func (r *WorkloadPolicyStatusSync) getLabelConflicts(ctx context.Context) (labelConflicts, error) {
+	podList := &metav1.PartialObjectMetadataList{}
+	podList.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("PodList"))
+	if err := r.List(ctx, podList, client.HasLabels{v1alpha1.PolicyLabelKey}); err != nil {
+		return nil, fmt.Errorf("failed to list the pods referencing a policy: %w", err)
+	}
+	return findLabelConflicts(podList.Items), nil
+}
+
+// findLabelConflicts groups the pods by controller: the pods of a controller are expected to reference
+// the same policy. When they don't, every policy they reference is in conflict. Pods without controller
+// reference a single policy and cannot conflict.
+func findLabelConflicts(pods []metav1.PartialObjectMetadata) labelConflicts {
+	policiesByController := make(map[workloadController]map[string]struct{})
+	for i := range pods {
+		owner := metav1.GetControllerOf(&pods[i])
+		if owner == nil {
+			continue
+		}
+		controller := workloadController{namespace: pods[i].Namespace, kind: owner.Kind, name: owner.Name}
+		if policiesByController[controller] == nil {
+			policiesByController[controller] = make(map[string]struct{})
+		}
+		policy := types.NamespacedName{Namespace: pods[i].Namespace, Name: pods[i].Labels[v1alpha1.PolicyLabelKey]}
+		policiesByController[controller][policy.String()] = struct{}{}
+	}
+
+	conflicts := make(labelConflicts)
+	for controller, policies := range policiesByController {
+		if len(policies) < 2 { //nolint:mnd // a conflict needs two policies
+			continue
+		}
+		names := slices.Sorted(maps.Keys(policies))
+		description := fmt.Sprintf("%s %s (%s)", controller.kind, controller.name, strings.Join(names, ", "))
+		for _, name := range names {
+			conflicts[name] = append(conflicts[name], description)
+		}
+	}
+	for _, descriptions := range conflicts {
+		slices.Sort(descriptions)
+	}
+	return conflicts
+}
+
+// setLabelConflictCondition sets the PolicyLabelConflictCondition of the policy in the new status.
+// When the conflicts are unknown the previous condition is kept.
+func setLabelConflictCondition(
+	wp *v1alpha1.WorkloadPolicy,
+	status *v1alpha1.WorkloadPolicyStatus,
+	conflicts labelConflicts,
+) {
+	// the conditions are copied so that their transition time is kept across the syncs.
+	status.Conditions = slices.Clone(wp.Status.Conditions)
+	if conflicts == nil {
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               v1alpha1.PolicyLabelConflictCondition,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: wp.Generation,
+		Reason:             v1alpha1.PolicyLabelNoConflictReason,
+		Message:            "The pods of each workload controller reference the same policy",
+	}
+	if workloads := conflicts[wp.NamespacedName()]; len(workloads) > 0 {
+		message := strings.Join(workloads, "; ")
+		if len(workloads) > maxConflictingWorkloads {
+			message = fmt.Sprintf("%s (and %d more)",
+				strings.Join(workloads[:maxConflictingWorkloads], "; "), len(workloads)-maxConflictingWorkloads)
+		}
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = v1alpha1.PolicyLabelConflictReason
+		condition.Message = "The pods of these workload controllers reference different policies: " + message
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func labeledPod(namespace, name, policy, ownerKind, ownerName string) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace: namespace,
+		Name:      name,
+		Labels:    map[string]string{v1alpha1.PolicyLabelKey: policy},
+	}}
+	if ownerKind != "" {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: ownerKind, Name: ownerName, Controller: &controller},
+		}
+	}
+	return pod
+}
+
+func TestGetLabelConflicts(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		// the replicas of the statefulset are enforced by different policies.
+		labeledPod("ns", "db-0", "db-v1", "StatefulSet", "db"),
+		labeledPod("ns", "db-1", "db-v2", "StatefulSet", "db"),
+		// a workload with the same name in another namespace is not related.
+		labeledPod("other", "db-0", "db-v3", "StatefulSet", "db"),
+		labeledPod("ns", "web-7c9f-abcde", "web", "ReplicaSet", "web-7c9f"),
+		labeledPod("ns", "web-7c9f-fghij", "web", "ReplicaSet", "web-7c9f"),
+		// a rollout creates a new replicaset, its pods can reference another policy.
+		labeledPod("ns", "web-5d8b-klmno", "web-v2", "ReplicaSet", "web-5d8b"),
+		labeledPod("ns", "debug", "db-v2", "", ""),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "unlabeled"}},
+	).Build()
+	r := &WorkloadPolicyStatusSync{Client: cl}
+
+	conflicts, err := r.getLabelConflicts(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, labelConflicts{
+		"ns/db-v1": {"StatefulSet db (ns/db-v1, ns/db-v2)"},
+		"ns/db-v2": {"StatefulSet db (ns/db-v1, ns/db-v2)"},
+	}, conflicts)
+}
+
+func TestSetLabelConflictCondition(t *testing.T) {
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "db-v1", Generation: 2},
+	}
+	conflicts := labelConflicts{
+		"ns/db-v1": {"StatefulSet db (ns/db-v1, ns/db-v2)"},
+		"ns/db-v2": {"StatefulSet db (ns/db-v1, ns/db-v2)"},
+	}
+
+	var status v1alpha1.WorkloadPolicyStatus
+	setLabelConflictCondition(wp, &status, conflicts)
+	condition := meta.FindStatusCondition(status.Conditions, v1alpha1.PolicyLabelConflictCondition)
+	require.NotNil(t, condition)
+	require.Equal(t, metav1.ConditionTrue, condition.Status)
+	require.Equal(t, v1alpha1.PolicyLabelConflictReason, condition.Reason)
+	require.Equal(t, int64(2), condition.ObservedGeneration)
+	require.Contains(t, condition.Message, "StatefulSet db (ns/db-v1, ns/db-v2)")
+
+	// the conflict is still there: the transition time is not updated.
+	transition := metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	status.Conditions[0].LastTransitionTime = transition
+	wp.Status = status
+	status = v1alpha1.WorkloadPolicyStatus{}
+	setLabelConflictCondition(wp, &status, conflicts)
+	require.Equal(t, transition, status.Conditions[0].LastTransitionTime)
+
+	// the pods could not be listed: the previous condition is kept.
+	status = v1alpha1.WorkloadPolicyStatus{}
+	setLabelConflictCondition(wp, &status, nil)
+	require.Equal(t, wp.Status.Conditions, status.Conditions)
+
+	// the conflict is solved.
+	status = v1alpha1.WorkloadPolicyStatus{}
+	setLabelConflictCondition(wp, &status, labelConflicts{})
+	condition = meta.FindStatusCondition(status.Conditions, v1alpha1.PolicyLabelConflictCondition)
+	require.NotNil(t, condition)
+	require.Equal(t, metav1.ConditionFalse, condition.Status)
+	require.Equal(t, v1alpha1.PolicyLabelNoConflictReason, condition.Reason)
+	require.NotEqual(t, transition, condition.LastTransitionTime)
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"encoding/json"
+	"time"
+
+	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// approvalLabelOrDefault returns the label marking the proposals as approved,
+// ApprovalLabelKey when none is configured.
+func approvalLabelOrDefault(labelKey string) string {
+	if labelKey == "" {
+		return securityv1alpha1.ApprovalLabelKey
+	}
+	return labelKey
+}
+
+// approvalAnnotations returns the audit annotations of the policy promoted from the proposal.
+// The approval is found in the managed fields of the proposal: the entry owning the approval
+// label, labelKey, tells who set it and when. The promotion time is used when the label has no owner,
+// e.g. when the managed fields were stripped.
+func approvalAnnotations(
+	proposal *securityv1alpha1.WorkloadPolicyProposal,
+	labelKey string,
+	now time.Time,
+) map[string]string {
+	approvedAt := now
+	var approvedBy string
+	for _, entry := range proposal.GetManagedFields() {
+		if !ownsLabel(entry, labelKey) {
+			continue
+		}
+		approvedBy = entry.Manager
+		if entry.Time != nil {
+			approvedAt = entry.Time.Time
+		}
+	}
+
+	annotations := map[string]string{
+		securityv1alpha1.ApprovedAtAnnotationKey: approvedAt.UTC().Format(time.RFC3339),
+	}
+	if approvedBy != "" {
+		annotations[securityv1alpha1.ApprovedByAnnotationKey] = approvedBy
+	}
+	return annotations
+}
+
+// ownsLabel reports whether the managed fields entry owns the label of the object.
+func ownsLabel(entry metav1.ManagedFieldsEntry, label string) bool {
+	if entry.FieldsV1 == nil {
+		return false
+	}
+	var fields struct {
+		Metadata struct {
+			Labels map[string]json.RawMessage `json:"f:labels"`
+		} `json:"f:metadata"`
+	}
+	if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+		return false
+	}
+	_, ok := fields.Metadata.Labels["f:"+label]
+	return ok
+}
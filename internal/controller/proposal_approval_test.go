@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApprovalAnnotations(t *testing.T) {
+	promotedAt := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	labeledAt := metav1.NewTime(time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC))
+	proposal := &v1alpha1.WorkloadPolicyProposal{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{v1alpha1.ApprovalLabelKey: "true"},
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{
+					Manager:   "agent",
+					Operation: metav1.ManagedFieldsOperationUpdate,
+					Time:      &labeledAt,
+					FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:rulesByContainer":{}}}`)},
+				},
+				{
+					Manager:   "kubectl-label",
+					Operation: metav1.ManagedFieldsOperationUpdate,
+					Time:      &labeledAt,
+					FieldsV1: &metav1.FieldsV1{
+						Raw: []byte(`{"f:metadata":{"f:labels":{".":{},"f:security.rancher.io/policy-ready":{}}}}`),
+					},
+				},
+			},
+		},
+	}
+	require.Equal(t, map[string]string{
+		v1alpha1.ApprovedAtAnnotationKey: "2026-03-01T09:30:00Z",
+		v1alpha1.ApprovedByAnnotationKey: "kubectl-label",
+	}, approvalAnnotations(proposal, v1alpha1.ApprovalLabelKey, promotedAt))
+
+	// with a custom approval label, the owner of the default one is not the approver.
+	require.Equal(t, map[string]string{
+		v1alpha1.ApprovedAtAnnotationKey: "2026-03-02T10:00:00Z",
+	}, approvalAnnotations(proposal, "example.com/approved", promotedAt))
+
+	// without managed fields the promotion time is recorded.
+	proposal.ManagedFields = nil
+	require.Equal(t, map[string]string{
+		v1alpha1.ApprovedAtAnnotationKey: "2026-03-02T10:00:00Z",
+	}, approvalAnnotations(proposal, v1alpha1.ApprovalLabelKey, promotedAt))
+}
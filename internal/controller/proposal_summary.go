@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+)
+
+// ProposalSummaryPath is the path of the metrics server serving the ProposalSummary.
+const ProposalSummaryPath = "/proposals/summary"
+
+// ProposalSummary is the learning progress of all the WorkloadPolicyProposals of the cluster.
+type ProposalSummary struct {
+	Proposals   int `json:"proposals"`
+	Executables int `json:"executables"`
+	// Approved is the number of proposals labeled to be promoted.
+	Approved   int                                 `json:"approved"`
+	Namespaces map[string]NamespaceProposalSummary `json:"namespaces"`
+}
+
+// NamespaceProposalSummary is the learning progress of the WorkloadPolicyProposals of a namespace.
+type NamespaceProposalSummary struct {
+	Proposals   int                       `json:"proposals"`
+	Executables int                       `json:"executables"`
+	Workloads   []WorkloadProposalSummary `json:"workloads"`
+}
+
+// WorkloadProposalSummary is the learning progress of the WorkloadPolicyProposal of a workload.
+type WorkloadProposalSummary struct {
+	Proposal     string `json:"proposal"`
+	Workload     string `json:"workload,omitempty"`
+	WorkloadKind string `json:"workloadKind,omitempty"`
+	// ExecutablesByContainer is the number of executables learned for each container.
+	ExecutablesByContainer map[string]int `json:"executablesByContainer"`
+	Executables            int            `json:"executables"`
+	// Full is set when the proposal reached the maximum number of executables that can be learned.
+	Full     bool `json:"full"`
+	Approved bool `json:"approved"`
+	// ContributingNodes are the nodes whose agent learned into the proposal.
+	ContributingNodes []string `json:"contributingNodes,omitempty"`
+}
+
+// ProposalSummaryHandler serves, as JSON, the ProposalSummary of the cluster.
+type ProposalSummaryHandler struct {
+	Client client.Reader
+	// ApprovalLabelKey is the label marking the proposals as approved, ApprovalLabelKey when empty.
+	ApprovalLabelKey string
+}
+
+func (h *ProposalSummaryHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	logger := logf.FromContext(req.Context())
+
+	var proposals v1alpha1.WorkloadPolicyProposalList
+	if err := h.Client.List(req.Context(), &proposals); err != nil {
+		logger.Error(err, "failed to list WorkloadPolicyProposals")
+		http.Error(w, "failed to list WorkloadPolicyProposals", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	summary := summarizeProposals(proposals.Items, approvalLabelOrDefault(h.ApprovalLabelKey))
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		logger.Error(err, "failed to write proposal summary")
+	}
+}
+
+func summarizeProposals(proposals []v1alpha1.WorkloadPolicyProposal, approvalLabelKey string) ProposalSummary {
+	summary := ProposalSummary{Namespaces: make(map[string]NamespaceProposalSummary)}
+	for i := range proposals {
+		proposal := &proposals[i]
+		workload := WorkloadProposalSummary{
+			Proposal:               proposal.Name,
+			ExecutablesByContainer: make(map[string]int, len(proposal.Spec.RulesByContainer)),
+			Full:                   proposal.IsFull(),
+			Approved:               proposal.Labels[approvalLabelKey] == "true",
+			ContributingNodes:      proposal.Status.ContributingNodes,
+		}
+		if len(proposal.OwnerReferences) > 0 {
+			workload.Workload = proposal.OwnerReferences[0].Name
+			workload.WorkloadKind = proposal.OwnerReferences[0].Kind
+		}
+		for containerName, rules := range proposal.Spec.RulesByContainer {
+			if rules == nil {
+				continue
+			}
+			workload.ExecutablesByContainer[containerName] = len(rules.Executables.Allowed)
+			workload.Executables += len(rules.Executables.Allowed)
+		}
+
+		ns := summary.Namespaces[proposal.Namespace]
+		ns.Proposals++
+		ns.Executables += workload.Executables
+		ns.Workloads = append(ns.Workloads, workload)
+		summary.Namespaces[proposal.Namespace] = ns
+
+		summary.Proposals++
+		summary.Executables += workload.Executables
+		if workload.Approved {
+			summary.Approved++
+		}
+	}
+	for _, ns := range summary.Namespaces {
+		slices.SortFunc(ns.Workloads, func(a, b WorkloadProposalSummary) int {
+			return cmp.Compare(a.Proposal, b.Proposal)
+		})
+	}
+	return summary
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestProposalSummaryHandler(t *testing.T) {
+	newProposal := func(namespace, name, workload string, executables ...string) *v1alpha1.WorkloadPolicyProposal {
+		proposal := &v1alpha1.WorkloadPolicyProposal{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		}
+		proposal.AddPartialOwnerReferenceDetails("Deployment", workload)
+		for _, exe := range executables {
+			proposal.AddProcess("main", exe)
+		}
+		return proposal
+	}
+	approved := newProposal("team-a", "deploy-web", "web", "/bin/sh", "/usr/bin/nginx")
+	approved.Labels = map[string]string{v1alpha1.ApprovalLabelKey: "true"}
+	approved.AddContributingNode("node-2")
+	approved.AddContributingNode("node-1")
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		approved,
+		newProposal("team-a", "deploy-api", "api", "/bin/api"),
+		newProposal("team-b", "deploy-db", "db", "/bin/postgres", "/bin/sh", "/bin/pg_ctl"),
+	).Build()
+
+	handler := &ProposalSummaryHandler{Client: cl}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequestWithContext(t.Context(), http.MethodGet, ProposalSummaryPath, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var summary ProposalSummary
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &summary))
+	require.Equal(t, 3, summary.Proposals)
+	require.Equal(t, 6, summary.Executables)
+	require.Equal(t, 1, summary.Approved)
+	require.Len(t, summary.Namespaces, 2)
+
+	teamA := summary.Namespaces["team-a"]
+	require.Equal(t, 2, teamA.Proposals)
+	require.Equal(t, 3, teamA.Executables)
+	require.Equal(t, []WorkloadProposalSummary{
+		{
+			Proposal:               "deploy-api",
+			Workload:               "api",
+			WorkloadKind:           "Deployment",
+			ExecutablesByContainer: map[string]int{"main": 1},
+			Executables:            1,
+		},
+		{
+			Proposal:               "deploy-web",
+			Workload:               "web",
+			WorkloadKind:           "Deployment",
+			ExecutablesByContainer: map[string]int{"main": 2},
+			Executables:            2,
+			Approved:               true,
+			ContributingNodes:      []string{"node-1", "node-2"},
+		},
+	}, teamA.Workloads)
+	require.Equal(t, 3, summary.Namespaces["team-b"].Executables)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequestWithContext(t.Context(), http.MethodPost, ProposalSummaryPath, nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
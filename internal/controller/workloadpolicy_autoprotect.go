@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/loglevel"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+)
+
+// DefaultAutoProtectObservationInterval is the default time between two observations of the proposal
+// referenced by the autoProtect of a WorkloadPolicy.
+const DefaultAutoProtectObservationInterval = time.Minute
+
+const (
+	autoProtectedReason = "AutoProtected"
+	protectAction       = "Protect"
+)
+
+// WorkloadPolicyAutoProtectReconciler switches the WorkloadPolicies with an autoProtect from monitor
+// to protect once their proposal learned no new executable for the required number of observations.
+type WorkloadPolicyAutoProtectReconciler struct {
+	client.Client
+
+	// Recorder reports the switch to protect on the policies, it is optional.
+	Recorder events.EventRecorder
+	// ObservationInterval is the time between two observations of a proposal,
+	// DefaultAutoProtectObservationInterval when zero.
+	ObservationInterval time.Duration
+
+	// now returns the current time, time.Now when nil.
+	now func() time.Time
+}
+
+// +kubebuilder:rbac:groups=security.rancher.io,resources=workloadpolicies,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=security.rancher.io,resources=workloadpolicyproposals,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups=security.rancher.io,resources=workloadpolicyproposals/status,verbs=get;update;patch
+
+func (r *WorkloadPolicyAutoProtectReconciler) Reconcile(
+	ctx context.Context,
+	req ctrl.Request,
+) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var policy securityv1alpha1.WorkloadPolicy
+	if err := r.Get(ctx, req.NamespacedName, &policy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	autoProtect := policy.Spec.AutoProtect
+	if autoProtect == nil || policy.Spec.Mode != policymode.MonitorString || policy.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	interval := r.ObservationInterval
+	if interval == 0 {
+		interval = DefaultAutoProtectObservationInterval
+	}
+
+	var proposal securityv1alpha1.WorkloadPolicyProposal
+	err := r.Get(ctx, client.ObjectKey{Namespace: policy.Namespace, Name: autoProtect.Proposal}, &proposal)
+	if err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to get WorkloadPolicyProposal %s: %w", autoProtect.Proposal, err)
+		}
+		logger.V(loglevel.VerbosityDebug).Info("Waiting for the proposal of the autoProtect",
+			"policy", policy.NamespacedName(), "proposal", autoProtect.Proposal)
+		return ctrl.Result{RequeueAfter: interval}, nil
+	}
+
+	now := r.clock()
+	if last := proposal.Status.LastObservationTime; last != nil {
+		if elapsed := now.Sub(last.Time); elapsed < interval {
+			return ctrl.Result{RequeueAfter: interval - elapsed}, nil
+		}
+	}
+	observeProposal(&proposal, now)
+	if err = r.Status().Update(ctx, &proposal); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update WorkloadPolicyProposal status: %w", err)
+	}
+	if proposal.Status.StableObservations < autoProtect.StableObservations {
+		return ctrl.Result{RequeueAfter: interval}, nil
+	}
+
+	if err = r.protect(ctx, &policy, &proposal); err != nil {
+		return ctrl.Result{}, err
+	}
+	logger.Info("Switched WorkloadPolicy to protect, its proposal is stable",
+		"policy", policy.NamespacedName(), "proposal", proposal.Name)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// The status updates of the policies are ignored, the proposals are observed on a timer.
+func (r *WorkloadPolicyAutoProtectReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&securityv1alpha1.WorkloadPolicy{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Named("workloadpolicy-autoprotect").
+		Complete(r)
+}
+
+func (r *WorkloadPolicyAutoProtectReconciler) clock() time.Time {
+	if r.now == nil {
+		return time.Now()
+	}
+	return r.now()
+}
+
+// protect adds the executables of the proposal to the policy, sets it in protect mode and deletes the proposal.
+func (r *WorkloadPolicyAutoProtectReconciler) protect(
+	ctx context.Context,
+	policy *securityv1alpha1.WorkloadPolicy,
+	proposal *securityv1alpha1.WorkloadPolicyProposal,
+) error {
+	patch := client.MergeFrom(policy.DeepCopy())
+	addProposalRules(policy, proposal)
+	policy.Spec.Mode = policymode.ProtectString
+	if policy.Labels == nil {
+		policy.Labels = make(map[string]string)
+	}
+	// the learning controller doesn't recreate the proposal of a workload protected by a promoted policy.
+	policy.Labels[securityv1alpha1.PromotedFromLabelKey] = proposal.Name
+	if err := r.Patch(ctx, policy, patch); err != nil {
+		return fmt.Errorf("failed to switch WorkloadPolicy to protect: %w", err)
+	}
+	if r.Recorder != nil {
+		r.Recorder.Eventf(policy, proposal, corev1.EventTypeNormal, autoProtectedReason, protectAction,
+			"Switched to protect, proposal %s learned no new executable for %d observations",
+			proposal.Name, proposal.Status.StableObservations)
+	}
+
+	if err := r.Delete(ctx, proposal); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	return nil
+}
+
+// observeProposal records an observation of the proposal made at now. The observations are stable
+// as long as the generation of the proposal, bumped by every new learned executable, doesn't change.
+func observeProposal(proposal *securityv1alpha1.WorkloadPolicyProposal, now time.Time) {
+	status := &proposal.Status
+	if status.LastObservationTime != nil && status.ObservedGeneration == proposal.Generation {
+		status.StableObservations++
+	} else {
+		status.StableObservations = 0
+	}
+	status.ObservedGeneration = proposal.Generation
+	status.LastObservationTime = &metav1.Time{Time: now}
+}
+
+// addProposalRules adds the executables learned by the proposal to the allowed executables of the policy,
+// the rules already in the policy are kept.
+func addProposalRules(policy *securityv1alpha1.WorkloadPolicy, proposal *securityv1alpha1.WorkloadPolicyProposal) {
+	for containerName, learned := range proposal.Spec.RulesByContainer {
+		if learned == nil {
+			continue
+		}
+		if policy.Spec.RulesByContainer == nil {
+			policy.Spec.RulesByContainer = make(map[string]*securityv1alpha1.WorkloadPolicyRules)
+		}
+		rules := policy.Spec.RulesByContainer[containerName]
+		if rules == nil {
+			rules = &securityv1alpha1.WorkloadPolicyRules{}
+			policy.Spec.RulesByContainer[containerName] = rules
+		}
+		for _, executable := range learned.Executables.Allowed {
+			if !slices.Contains(rules.Executables.Allowed, executable) {
+				rules.Executables.Allowed = append(rules.Executables.Allowed, executable)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWorkloadPolicyAutoProtect(t *testing.T) {
+	policy := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: policymode.MonitorString,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"app": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh"}}},
+			},
+			AutoProtect: &v1alpha1.WorkloadPolicyAutoProtect{Proposal: "deploy-app", StableObservations: 2},
+		},
+	}
+	proposal := &v1alpha1.WorkloadPolicyProposal{
+		ObjectMeta: metav1.ObjectMeta{Name: "deploy-app", Namespace: "ns", Generation: 1},
+		Spec: v1alpha1.WorkloadPolicyProposalSpec{
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"app": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh", "/bin/app"}}},
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(policy, proposal).
+		WithStatusSubresource(&v1alpha1.WorkloadPolicyProposal{}).
+		Build()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	r := &WorkloadPolicyAutoProtectReconciler{
+		Client:              cl,
+		Recorder:            events.NewFakeRecorder(10),
+		ObservationInterval: time.Minute,
+		now:                 func() time.Time { return now },
+	}
+	observe := func(at time.Duration) ctrl.Result {
+		now = start.Add(at)
+		res, err := r.Reconcile(t.Context(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(policy)})
+		require.NoError(t, err)
+		return res
+	}
+	stableObservations := func() int32 {
+		var learned v1alpha1.WorkloadPolicyProposal
+		require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(proposal), &learned))
+		return learned.Status.StableObservations
+	}
+
+	require.Equal(t, ctrl.Result{RequeueAfter: time.Minute}, observe(0))
+	require.Zero(t, stableObservations())
+	// the reconciles between two observations are not counted.
+	require.Equal(t, ctrl.Result{RequeueAfter: 50 * time.Second}, observe(10*time.Second))
+	require.Zero(t, stableObservations())
+	observe(time.Minute)
+	require.Equal(t, int32(1), stableObservations())
+
+	// a new learned executable restarts the count.
+	var learned v1alpha1.WorkloadPolicyProposal
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(proposal), &learned))
+	learned.Spec.RulesByContainer["app"].Executables.Allowed = append(
+		learned.Spec.RulesByContainer["app"].Executables.Allowed, "/bin/ls")
+	learned.Generation++
+	require.NoError(t, cl.Update(t.Context(), &learned))
+	observe(2 * time.Minute)
+	require.Zero(t, stableObservations())
+	observe(3 * time.Minute)
+	require.Equal(t, int32(1), stableObservations())
+
+	require.Equal(t, ctrl.Result{}, observe(4*time.Minute))
+	var protected v1alpha1.WorkloadPolicy
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(policy), &protected))
+	require.Equal(t, policymode.ProtectString, protected.Spec.Mode)
+	require.Equal(t, []string{"/bin/sh", "/bin/app", "/bin/ls"},
+		protected.Spec.RulesByContainer["app"].Executables.Allowed)
+	require.Equal(t, "deploy-app", protected.Labels[v1alpha1.PromotedFromLabelKey])
+	err := cl.Get(t.Context(), client.ObjectKeyFromObject(proposal), &learned)
+	require.True(t, apierrors.IsNotFound(err))
+
+	// the protected policy is not observed anymore.
+	require.Equal(t, ctrl.Result{}, observe(5*time.Minute))
+}
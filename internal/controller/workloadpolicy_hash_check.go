@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// hashRuleError rejects the policy when it has allowedHashes rules and the agents don't enforce them:
+// without hash matching they never allow the executables of these rules.
+func hashRuleError(policy *v1alpha1.WorkloadPolicy, hashMatching bool) error {
+	if hashMatching {
+		return nil
+	}
+	var containers []string
+	for _, container := range slices.Sorted(maps.Keys(policy.Spec.RulesByContainer)) {
+		rules := policy.Spec.RulesByContainer[container]
+		if rules != nil && len(rules.Executables.AllowedHashes) > 0 {
+			containers = append(containers, container)
+		}
+	}
+	if len(containers) == 0 {
+		return nil
+	}
+	return apierrors.NewForbidden(
+		schema.GroupResource{
+			Group:    "security.rancher.io",
+			Resource: "workloadpolicies",
+		},
+		policy.Name,
+		fmt.Errorf("the containers %s have allowedHashes rules but hash matching is not enabled, "+
+			"their executables would never be allowed", strings.Join(containers, ", ")),
+	)
+}
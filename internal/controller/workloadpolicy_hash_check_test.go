@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHashRuleError(t *testing.T) {
+	policy := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: policymode.ProtectString,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"main": {Executables: v1alpha1.WorkloadPolicyExecutables{
+					AllowedHashes: []v1alpha1.ExecutableHash{{Path: "/bin/app", SHA256: "abc"}},
+				}},
+				"sidecar": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/usr/bin/sleep"}}},
+			},
+		},
+	}
+
+	require.NoError(t, hashRuleError(policy, true))
+
+	err := hashRuleError(policy, false)
+	require.True(t, apierrors.IsForbidden(err))
+	require.ErrorContains(t, err, "the containers main have allowedHashes rules but hash matching is not enabled")
+
+	// a policy without allowedHashes rules is not affected.
+	policy.Spec.RulesByContainer["main"].Executables.AllowedHashes = nil
+	require.NoError(t, hashRuleError(policy, false))
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/kernels"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// DefaultMinKernelVersion is the oldest kernel supported by the agent on x86_64, see docs/compatibility.adoc.
+const DefaultMinKernelVersion = "5.8"
+
+// The kernel limits of the eBPF maps holding the allowed executables, see internal/bpf/policy_values.go.
+const (
+	// Before 5.9 the inner maps have a fixed number of entries.
+	kernelFixedMaxEntries    = "5.9"
+	fixedMaxEntriesPerPolicy = 500
+	// Before 5.11 the hash keys, and so the paths, cannot be longer than 512 bytes.
+	kernelLongPaths      = "5.11"
+	maxPathLengthPre5_11 = 512
+)
+
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+
+// kernelWarnings lists the nodes and warns when some of them run a kernel that cannot enforce the policy.
+// The policy is admitted anyway: the nodes may be drained or upgraded before its pods are scheduled there.
+func (v *PolicyCustomValidator) kernelWarnings(ctx context.Context, policy *v1alpha1.WorkloadPolicy) admission.Warnings {
+	nodes := &corev1.NodeList{}
+	if err := v.Client.List(ctx, nodes); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list the nodes to check their kernel version",
+			"name", policy.GetName())
+		return nil
+	}
+	return policyKernelWarnings(policy, nodes.Items, v.MinKernelVersion)
+}
+
+// policyKernelWarnings returns a warning for each kernel limit the policy exceeds on some nodes.
+// The nodes not reporting their kernel version are ignored.
+func policyKernelWarnings(policy *v1alpha1.WorkloadPolicy, nodes []corev1.Node, minKernelVersion string) []string {
+	var belowMin, fixedEntries, shortPaths []string
+	for _, node := range nodes {
+		release := node.Status.NodeInfo.KernelVersion
+		if release == "" {
+			continue
+		}
+		version := int(kernels.KernelStringToNumeric(release))
+		if minKernelVersion != "" && kernels.VersionIsLowerThan(version, minKernelVersion) {
+			belowMin = append(belowMin, node.Name)
+			continue
+		}
+		if kernels.VersionIsLowerThan(version, kernelFixedMaxEntries) {
+			fixedEntries = append(fixedEntries, node.Name)
+		}
+		if kernels.VersionIsLowerThan(version, kernelLongPaths) {
+			shortPaths = append(shortPaths, node.Name)
+		}
+	}
+
+	var warnings []string
+	if len(belowMin) > 0 {
+		slices.Sort(belowMin)
+		warnings = append(warnings, fmt.Sprintf(
+			"WorkloadPolicy %q cannot be enforced on %d node(s) running a kernel older than %s: %s",
+			policy.Name, len(belowMin), minKernelVersion, listNames(belowMin)))
+	}
+
+	var tooMany, tooLong []string
+	for _, container := range slices.Sorted(maps.Keys(policy.Spec.RulesByContainer)) {
+		rules := policy.Spec.RulesByContainer[container]
+		if rules == nil {
+			continue
+		}
+		if len(rules.Executables.Allowed) > fixedMaxEntriesPerPolicy ||
+			len(rules.Executables.AllowedPrefixes) > fixedMaxEntriesPerPolicy {
+			tooMany = append(tooMany, container)
+		}
+		if slices.ContainsFunc(rules.Executables.Allowed, func(path string) bool {
+			return len(path) > maxPathLengthPre5_11
+		}) {
+			tooLong = append(tooLong, container)
+		}
+	}
+	if len(fixedEntries) > 0 && len(tooMany) > 0 {
+		slices.Sort(fixedEntries)
+		warnings = append(warnings, fmt.Sprintf(
+			"the containers %s allow more than %d executables or prefixes, which may not be enforced on %d node(s) "+
+				"running a kernel older than %s: %s",
+			listNames(tooMany), fixedMaxEntriesPerPolicy, len(fixedEntries), kernelFixedMaxEntries,
+			listNames(fixedEntries)))
+	}
+	if len(shortPaths) > 0 && len(tooLong) > 0 {
+		slices.Sort(shortPaths)
+		warnings = append(warnings, fmt.Sprintf(
+			"the containers %s allow executables whose path is longer than %d bytes, which cannot be enforced "+
+				"on %d node(s) running a kernel older than %s: %s",
+			listNames(tooLong), maxPathLengthPre5_11, len(shortPaths), kernelLongPaths, listNames(shortPaths)))
+	}
+	return warnings
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPolicyKernelWarnings(t *testing.T) {
+	node := func(name, kernel string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KernelVersion: kernel}},
+		}
+	}
+	policyWith := func(allowed ...string) *v1alpha1.WorkloadPolicy {
+		return &v1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-policy"},
+			Spec: v1alpha1.WorkloadPolicySpec{
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					"main": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: allowed}},
+				},
+			},
+		}
+	}
+
+	nodes := []corev1.Node{
+		node("node-a", "6.8.0-45-generic"),
+		node("node-c", "5.10.0-32-amd64"),
+		node("node-b", "5.4.0-150-generic"),
+		node("node-d", ""),
+	}
+
+	// a small policy can only be a problem for the nodes below the minimum kernel.
+	small := policyWith("/usr/bin/sleep")
+	require.Empty(t, policyKernelWarnings(small, nodes, ""))
+	require.Empty(t, policyKernelWarnings(small, nodes, "5.4"))
+	require.Equal(t, []string{
+		`WorkloadPolicy "test-policy" cannot be enforced on 2 node(s) running a kernel older than 5.11: node-b, node-c`,
+	}, policyKernelWarnings(small, nodes, "5.11"))
+
+	// the long paths cannot be enforced before 5.11.
+	long := policyWith("/usr/bin/sleep", "/"+strings.Repeat("a", maxPathLengthPre5_11))
+	require.Equal(t, []string{
+		"the containers main allow executables whose path is longer than 512 bytes, which cannot be enforced " +
+			"on 2 node(s) running a kernel older than 5.11: node-b, node-c",
+	}, policyKernelWarnings(long, nodes, "5.4"))
+
+	// the nodes below the minimum kernel are only reported once.
+	require.Equal(t, []string{
+		`WorkloadPolicy "test-policy" cannot be enforced on 1 node(s) running a kernel older than 5.8: node-b`,
+		"the containers main allow executables whose path is longer than 512 bytes, which cannot be enforced " +
+			"on 1 node(s) running a kernel older than 5.11: node-c",
+	}, policyKernelWarnings(long, nodes, DefaultMinKernelVersion))
+
+	// the inner maps have a fixed number of entries before 5.9.
+	allowed := make([]string, 0, fixedMaxEntriesPerPolicy+1)
+	for i := range fixedMaxEntriesPerPolicy + 1 {
+		allowed = append(allowed, fmt.Sprintf("/usr/bin/exe-%d", i))
+	}
+	require.Equal(t, []string{
+		"the containers main allow more than 500 executables or prefixes, which may not be enforced on 1 node(s) " +
+			"running a kernel older than 5.9: node-b",
+	}, policyKernelWarnings(policyWith(allowed...), nodes, "5.4"))
+	require.Empty(t, policyKernelWarnings(policyWith(allowed[:fixedMaxEntriesPerPolicy]...), nodes, "5.4"))
+}
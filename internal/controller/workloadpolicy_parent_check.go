@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// protectMode returns why the policy is, or will be, in "protect" mode, empty when it is not.
+func protectMode(policy *v1alpha1.WorkloadPolicy) string {
+	switch {
+	case policy.Spec.Mode == policymode.ProtectString:
+		return `is in "protect" mode`
+	case policy.Spec.AutoProtect != nil:
+		return `will be switched to "protect" mode by autoProtect`
+	default:
+		return ""
+	}
+}
+
+// effectiveRules returns the rules enforced for the policy once the ones of its base policy are merged, as the
+// agents do. A missing base policy is not inherited yet.
+func effectiveRules(
+	policy, base *v1alpha1.WorkloadPolicy,
+) map[string]*v1alpha1.WorkloadPolicyRules {
+	if base == nil {
+		return policy.Spec.RulesByContainer
+	}
+	lower, higher := base.Spec.RulesByContainer, policy.Spec.RulesByContainer
+	if base.Spec.Priority > policy.Spec.Priority {
+		lower, higher = higher, lower
+	}
+	merged := make(map[string]*v1alpha1.WorkloadPolicyRules, len(lower)+len(higher))
+	maps.Copy(merged, lower)
+	maps.Copy(merged, higher)
+	return merged
+}
+
+// parentRuleContainers returns the containers with allowedWithParent rules.
+func parentRuleContainers(rulesByContainer map[string]*v1alpha1.WorkloadPolicyRules) []string {
+	var containers []string
+	for _, container := range slices.Sorted(maps.Keys(rulesByContainer)) {
+		rules := rulesByContainer[container]
+		if rules != nil && len(rules.Executables.AllowedWithParent) > 0 {
+			containers = append(containers, container)
+		}
+	}
+	return containers
+}
+
+// basePolicy returns the base policy of the given one, nil when it has none or it doesn't exist.
+func (v *PolicyCustomValidator) basePolicy(
+	ctx context.Context,
+	policy *v1alpha1.WorkloadPolicy,
+) (*v1alpha1.WorkloadPolicy, error) {
+	if policy.Spec.BasePolicy == "" || policy.Spec.BasePolicy == policy.Name {
+		return nil, nil
+	}
+	base := &v1alpha1.WorkloadPolicy{}
+	err := v.Client.Get(ctx, client.ObjectKey{Namespace: policy.Namespace, Name: policy.Spec.BasePolicy}, base)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get the base WorkloadPolicy %q: %w", policy.Spec.BasePolicy, err)
+	}
+	return base, nil
+}
+
+// parentRuleError rejects the allowedWithParent rules of the policies that are, or will be, in "protect" mode,
+// their own ones and the inherited ones: the parent condition is only evaluated on the violations reported in
+// "monitor" mode, the kernel blocks these executables in "protect" mode whatever their parent.
+// The policy is rejected as well when it is the base policy of such a policy.
+func (v *PolicyCustomValidator) parentRuleError(ctx context.Context, policy *v1alpha1.WorkloadPolicy) error {
+	if when := protectMode(policy); when != "" {
+		base, err := v.basePolicy(ctx, policy)
+		if err != nil {
+			return err
+		}
+		if containers := parentRuleContainers(effectiveRules(policy, base)); len(containers) > 0 {
+			return parentRuleForbidden(policy, fmt.Errorf(
+				"WorkloadPolicy %q %s, the containers %s cannot have allowedWithParent rules, "+
+					"their executables would be blocked whatever their parent",
+				policy.Name, when, strings.Join(containers, ", ")))
+		}
+	}
+
+	if len(parentRuleContainers(policy.Spec.RulesByContainer)) == 0 {
+		return nil
+	}
+	children := &v1alpha1.WorkloadPolicyList{}
+	if err := v.Client.List(ctx, children, client.InNamespace(policy.Namespace)); err != nil {
+		return fmt.Errorf("list WorkloadPolicies in namespace %q: %w", policy.Namespace, err)
+	}
+	for i := range children.Items {
+		child := &children.Items[i]
+		if child.Name == policy.Name || child.Spec.BasePolicy != policy.Name {
+			continue
+		}
+		when := protectMode(child)
+		if when == "" {
+			continue
+		}
+		if containers := parentRuleContainers(effectiveRules(child, policy)); len(containers) > 0 {
+			return parentRuleForbidden(policy, fmt.Errorf(
+				"WorkloadPolicy %q inheriting from it %s, the containers %s cannot have allowedWithParent rules, "+
+					"their executables would be blocked whatever their parent",
+				child.Name, when, strings.Join(containers, ", ")))
+		}
+	}
+	return nil
+}
+
+func parentRuleForbidden(policy *v1alpha1.WorkloadPolicy, err error) error {
+	return apierrors.NewForbidden(
+		schema.GroupResource{
+			Group:    "security.rancher.io",
+			Resource: "workloadpolicies",
+		},
+		policy.Name,
+		err,
+	)
+}
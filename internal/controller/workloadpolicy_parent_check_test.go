@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func parentRulePolicy(
+	name, mode string,
+	rulesByContainer map[string]*v1alpha1.WorkloadPolicyRules,
+) *v1alpha1.WorkloadPolicy {
+	return &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       v1alpha1.WorkloadPolicySpec{Mode: mode, RulesByContainer: rulesByContainer},
+	}
+}
+
+func parentRules() *v1alpha1.WorkloadPolicyRules {
+	return &v1alpha1.WorkloadPolicyRules{Executables: v1alpha1.WorkloadPolicyExecutables{
+		AllowedWithParent: []v1alpha1.ExecutableWithParent{
+			{Path: "/bin/sh", Parents: []string{"/entrypoint.sh"}},
+		},
+	}}
+}
+
+func TestParentRuleError(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	sidecar := &v1alpha1.WorkloadPolicyRules{
+		Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/usr/bin/sleep"}},
+	}
+	base := parentRulePolicy("base", policymode.MonitorString, map[string]*v1alpha1.WorkloadPolicyRules{
+		"main": parentRules(),
+	})
+	child := parentRulePolicy("child", policymode.ProtectString, map[string]*v1alpha1.WorkloadPolicyRules{
+		"sidecar": sidecar,
+	})
+	child.Spec.BasePolicy = "base"
+	v := &PolicyCustomValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(base).Build()}
+
+	policy := parentRulePolicy("test-policy", policymode.MonitorString, map[string]*v1alpha1.WorkloadPolicyRules{
+		"main":    parentRules(),
+		"sidecar": sidecar,
+	})
+	// the parent condition applies in "monitor" mode.
+	require.NoError(t, v.parentRuleError(t.Context(), policy))
+
+	policy.Spec.AutoProtect = &v1alpha1.WorkloadPolicyAutoProtect{Proposal: "test-proposal", StableObservations: 3}
+	err := v.parentRuleError(t.Context(), policy)
+	require.True(t, apierrors.IsForbidden(err))
+	require.ErrorContains(t, err, `WorkloadPolicy "test-policy" will be switched to "protect" mode by autoProtect, `+
+		"the containers main cannot have allowedWithParent rules")
+
+	policy.Spec.Mode = policymode.ProtectString
+	err = v.parentRuleError(t.Context(), policy)
+	require.True(t, apierrors.IsForbidden(err))
+	require.ErrorContains(t, err, `WorkloadPolicy "test-policy" is in "protect" mode, `+
+		"the containers main cannot have allowedWithParent rules")
+
+	// a policy without allowedWithParent executables is not affected.
+	policy.Spec.RulesByContainer["main"].Executables.AllowedWithParent = nil
+	require.NoError(t, v.parentRuleError(t.Context(), policy))
+
+	// the rules inherited from the base policy are checked as well.
+	err = v.parentRuleError(t.Context(), child)
+	require.True(t, apierrors.IsForbidden(err))
+	require.ErrorContains(t, err, `WorkloadPolicy "child" is in "protect" mode, `+
+		"the containers main cannot have allowedWithParent rules")
+
+	// unless the container is overridden by the child policy.
+	child.Spec.RulesByContainer["main"] = sidecar
+	require.NoError(t, v.parentRuleError(t.Context(), child))
+	delete(child.Spec.RulesByContainer, "main")
+
+	// the base policy cannot get allowedWithParent rules inherited by a "protect" policy.
+	v.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(child).Build()
+	err = v.parentRuleError(t.Context(), base)
+	require.True(t, apierrors.IsForbidden(err))
+	require.ErrorContains(t, err, `WorkloadPolicy "child" inheriting from it is in "protect" mode, `+
+		"the containers main cannot have allowedWithParent rules")
+}
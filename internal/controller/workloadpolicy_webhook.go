@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
@@ -14,13 +15,21 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
-// maxPodNames avoids oversized response.
-const maxPodNames = 10
+// maxNames avoids oversized response.
+const maxNames = 10
 
-// +kubebuilder:webhook:path=/validate-security-rancher-io-v1alpha1-workloadpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=security.rancher.io,resources=workloadpolicies,verbs=delete,versions=v1alpha1,name=validate-workloadpolicies.rancher.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-security-rancher-io-v1alpha1-workloadpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=security.rancher.io,resources=workloadpolicies,verbs=create;update;delete,versions=v1alpha1,name=validate-workloadpolicies.rancher.io,admissionReviewVersions=v1
 
 type PolicyCustomValidator struct {
 	Client client.Client
+	// RestrictedNamespaces are the namespaces where WorkloadPolicies cannot be created, e.g. kube-system.
+	RestrictedNamespaces []string
+	// MinKernelVersion is the kernel version below which a node cannot enforce any WorkloadPolicy, e.g. "5.8".
+	// An empty version disables the check, the kernel limits of the eBPF maps are still reported.
+	MinKernelVersion string
+	// HashMatching is set when the agents enforce the allowedHashes rules, the policies with such rules are
+	// rejected otherwise.
+	HashMatching bool
 }
 
 var _ admission.Validator[*v1alpha1.WorkloadPolicy] = &PolicyCustomValidator{}
@@ -31,7 +40,23 @@ func (v *PolicyCustomValidator) ValidateCreate(
 ) (admission.Warnings, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("Validation for WorkloadPolicy upon creation", "name", policy.GetName())
-	return nil, nil
+	if slices.Contains(v.RestrictedNamespaces, policy.Namespace) {
+		return nil, apierrors.NewForbidden(
+			schema.GroupResource{
+				Group:    "security.rancher.io",
+				Resource: "workloadpolicies",
+			},
+			policy.Name,
+			fmt.Errorf("WorkloadPolicies cannot be created in the restricted namespace %q", policy.Namespace),
+		)
+	}
+	if err := hashRuleError(policy, v.HashMatching); err != nil {
+		return nil, err
+	}
+	if err := v.parentRuleError(ctx, policy); err != nil {
+		return nil, err
+	}
+	return v.kernelWarnings(ctx, policy), nil
 }
 
 func (v *PolicyCustomValidator) ValidateUpdate(
@@ -40,7 +65,13 @@ func (v *PolicyCustomValidator) ValidateUpdate(
 ) (admission.Warnings, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("Validation for WorkloadPolicy upon update", "name", newPolicy.GetName())
-	return nil, nil
+	if err := hashRuleError(newPolicy, v.HashMatching); err != nil {
+		return nil, err
+	}
+	if err := v.parentRuleError(ctx, newPolicy); err != nil {
+		return nil, err
+	}
+	return v.kernelWarnings(ctx, newPolicy), nil
 }
 
 func (v *PolicyCustomValidator) ValidateDelete(
@@ -89,18 +120,18 @@ func (v *PolicyCustomValidator) ValidateDelete(
 			policy.Name,
 			len(podNames),
 			policy.Namespace,
-			listPodNames(podNames),
+			listNames(podNames),
 		),
 	)
 }
 
-func listPodNames(names []string) string {
+func listNames(names []string) string {
 	if len(names) == 0 {
 		return ""
 	}
-	if len(names) <= maxPodNames {
+	if len(names) <= maxNames {
 		return strings.Join(names, ", ")
 	}
-	podNames := strings.Join(names[:maxPodNames], ", ")
-	return fmt.Sprintf("%s (and %d more)", podNames, len(names)-maxPodNames)
+	listed := strings.Join(names[:maxNames], ", ")
+	return fmt.Sprintf("%s (and %d more)", listed, len(names)-maxNames)
 }
@@ -56,6 +56,23 @@ var _ = Describe("WorkloadPolicy Webhook", func() {
 		}))).To(Succeed())
 	})
 
+	Context("ValidateCreate", func() {
+		It("allows creation outside of the restricted namespaces", func() {
+			validator.RestrictedNamespaces = []string{"kube-system"}
+			warns, err := validator.ValidateCreate(ctx, policy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warns).To(BeEmpty())
+		})
+
+		It("denies creation in a restricted namespace", func() {
+			validator.RestrictedNamespaces = []string{"kube-system", testNS}
+			_, err := validator.ValidateCreate(ctx, policy)
+			Expect(err).To(HaveOccurred())
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("restricted namespace \"default\""))
+		})
+	})
+
 	Context("ValidateDelete", func() {
 		It("allows deletion when no pods reference the policy", func() {
 			warns, err := validator.ValidateDelete(ctx, policy)
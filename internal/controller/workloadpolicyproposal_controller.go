@@ -3,9 +3,13 @@ package controller
 import (
 	"context"
 	"fmt"
+	"slices"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -20,8 +24,17 @@ type WorkloadPolicyProposalReconciler struct {
 	client.Client
 
 	Scheme *runtime.Scheme
+	// Recorder reports on the promoted policies the issues found during the promotion, it is optional.
+	Recorder events.EventRecorder
+	// ApprovalLabelKey is the label marking the proposals to promote, ApprovalLabelKey when empty.
+	ApprovalLabelKey string
 }
 
+const (
+	missingContainersReason = "MissingContainers"
+	promoteAction           = "Promote"
+)
+
 // +kubebuilder:rbac:groups=security.rancher.io,resources=workloadpolicyproposals,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=security.rancher.io,resources=workloadpolicyproposals/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=security.rancher.io,resources=workloadpolicies,verbs=get;list;watch;create;patch
@@ -67,8 +80,9 @@ func (r *WorkloadPolicyProposalReconciler) Reconcile(
 		return ctrl.Result{}, nil
 	}
 
+	approvalLabelKey := approvalLabelOrDefault(r.ApprovalLabelKey)
 	labels := policyProposal.GetLabels()
-	approved := labels[securityv1alpha1.ApprovalLabelKey] == "true"
+	approved := labels[approvalLabelKey] == "true"
 
 	if !approved {
 		return ctrl.Result{}, nil
@@ -81,6 +95,7 @@ func (r *WorkloadPolicyProposalReconciler) Reconcile(
 			Labels: map[string]string{
 				securityv1alpha1.PromotedFromLabelKey: policyProposal.Name,
 			},
+			Annotations: approvalAnnotations(&policyProposal, approvalLabelKey, time.Now()),
 		},
 		Spec: policyProposal.Spec.IntoWorkloadPolicySpec(),
 	}
@@ -92,6 +107,7 @@ func (r *WorkloadPolicyProposalReconciler) Reconcile(
 		}
 		return ctrl.Result{}, fmt.Errorf("failed to create WorkloadPolicy: %w", err)
 	}
+	r.warnMissingContainers(&policyProposal, &policy)
 
 	// Once we successfully promote the proposal into a policy, we no longer
 	// need the proposal to remain in the cluster.
@@ -102,6 +118,47 @@ func (r *WorkloadPolicyProposalReconciler) Reconcile(
 	return ctrl.Result{}, nil
 }
 
+// warnMissingContainers emits a warning Event on the promoted policy when it has no rules
+// for some of the containers observed during the learning, these containers are not enforced.
+func (r *WorkloadPolicyProposalReconciler) warnMissingContainers(
+	proposal *securityv1alpha1.WorkloadPolicyProposal,
+	policy *securityv1alpha1.WorkloadPolicy,
+) {
+	if r.Recorder == nil {
+		return
+	}
+	missing := uncoveredContainers(proposal, policy)
+	if len(missing) == 0 {
+		return
+	}
+	r.Recorder.Eventf(policy, proposal, corev1.EventTypeWarning, missingContainersReason, promoteAction,
+		"Promoted from proposal %s without rules for the containers %v, they are not enforced",
+		proposal.Name, missing)
+}
+
+// uncoveredContainers returns the sorted containers observed by the proposal that have no rules in the policy.
+func uncoveredContainers(
+	proposal *securityv1alpha1.WorkloadPolicyProposal,
+	policy *securityv1alpha1.WorkloadPolicy,
+) []string {
+	observed := make(map[string]struct{}, len(proposal.Spec.RulesByContainer))
+	for containerName := range proposal.Spec.RulesByContainer {
+		observed[containerName] = struct{}{}
+	}
+	for containerName := range proposal.Status.ProcessCountByContainer {
+		observed[containerName] = struct{}{}
+	}
+
+	var missing []string
+	for containerName := range observed {
+		if policy.Spec.RulesByContainer[containerName] == nil {
+			missing = append(missing, containerName)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *WorkloadPolicyProposalReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
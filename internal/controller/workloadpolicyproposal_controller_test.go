@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestPromotionWarnsAboutMissingContainers(t *testing.T) {
+	rules := func(executables ...string) *v1alpha1.WorkloadPolicyRules {
+		return &v1alpha1.WorkloadPolicyRules{Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: executables}}
+	}
+	newProposal := func(name string, rulesByContainer map[string]*v1alpha1.WorkloadPolicyRules) *v1alpha1.WorkloadPolicyProposal {
+		return &v1alpha1.WorkloadPolicyProposal{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{v1alpha1.ApprovalLabelKey: "true"},
+			},
+			Spec: v1alpha1.WorkloadPolicyProposalSpec{RulesByContainer: rulesByContainer},
+			// the sidecar was observed but its rules were removed from the proposal before the approval.
+			Status: v1alpha1.WorkloadPolicyProposalStatus{
+				ProcessCountByContainer: map[string]int{"main": 1, "sidecar": 2},
+			},
+		}
+	}
+	partial := newProposal("partial", map[string]*v1alpha1.WorkloadPolicyRules{"main": rules("/bin/sleep")})
+	complete := newProposal("complete", map[string]*v1alpha1.WorkloadPolicyRules{
+		"main":    rules("/bin/sleep"),
+		"sidecar": rules("/bin/sh", "/bin/cat"),
+	})
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(partial, complete).Build()
+	recorder := events.NewFakeRecorder(10)
+	r := &WorkloadPolicyProposalReconciler{Client: cl, Scheme: scheme, Recorder: recorder}
+
+	promote := func(proposal *v1alpha1.WorkloadPolicyProposal) {
+		t.Helper()
+		_, err := r.Reconcile(t.Context(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(proposal)})
+		require.NoError(t, err)
+		var policy v1alpha1.WorkloadPolicy
+		require.NoError(t, cl.Get(t.Context(), types.NamespacedName{Namespace: "default", Name: proposal.Name}, &policy))
+	}
+
+	promote(partial)
+	require.Equal(t,
+		"Warning MissingContainers Promoted from proposal partial without rules for the containers [sidecar], "+
+			"they are not enforced",
+		<-recorder.Events)
+
+	promote(complete)
+	require.Empty(t, recorder.Events)
+}
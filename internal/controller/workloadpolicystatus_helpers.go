@@ -9,6 +9,17 @@ import (
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/loglevel"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
 	pb "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	phaseChangedReason = "PhaseChanged"
+	phaseChangedAction = "SyncStatus"
+
+	percent = 100
 )
 
 func convertToPolicyMode(mode string) pb.PolicyMode {
@@ -26,15 +37,24 @@ func computeWpStatus(
 	nodesInfo nodesInfoMap,
 	expectedMode pb.PolicyMode,
 	wpNamespacedName string,
+	grace *missingPolicyGrace,
 ) (v1alpha1.WorkloadPolicyStatus, error) {
 	status := v1alpha1.WorkloadPolicyStatus{
 		TotalNodes: len(nodesInfo),
 	}
+	addNodeIssue := func(nodeName string, issue v1alpha1.NodeIssue) {
+		if issue.Code == v1alpha1.NodeIssueMissingPolicy && grace.missing(wpNamespacedName, nodeName) {
+			// the node loads a new policy, it is not transitioning mode.
+			status.PendingNodes++
+			return
+		}
+		status.AddNodeIssue(nodeName, issue)
+	}
 
 	for nodeName, nodeInfo := range nodesInfo {
 		// If we previously detected that the policy is not deployed on this node, we can skip it.
 		if nodeInfo.issue.Code != v1alpha1.NodeIssueNone {
-			status.AddNodeIssue(nodeName, nodeInfo.issue)
+			addNodeIssue(nodeName, nodeInfo.issue)
 			continue
 		}
 
@@ -46,12 +66,15 @@ func computeWpStatus(
 
 		policyStatus, ok := policies[wpNamespacedName]
 		if !ok || policyStatus == nil {
-			status.AddNodeIssue(nodeName, v1alpha1.NodeIssue{
+			addNodeIssue(nodeName, v1alpha1.NodeIssue{
 				Code:    v1alpha1.NodeIssueMissingPolicy,
 				Message: "policy not present on the node",
 			})
 			continue
 		}
+		grace.present(wpNamespacedName, nodeName)
+		status.MatchedContainers += int(policyStatus.GetMatchedContainers())
+		status.EnforcedContainers += int(policyStatus.GetEnforcedContainers())
 
 		switch policyStatus.GetState() {
 		case pb.PolicyState_POLICY_STATE_READY:
@@ -65,7 +88,7 @@ func computeWpStatus(
 			if msg == "" {
 				msg = "policy is in error state"
 			}
-			status.AddNodeIssue(nodeName, v1alpha1.NodeIssue{
+			addNodeIssue(nodeName, v1alpha1.NodeIssue{
 				Code:    v1alpha1.NodeIssuePolicyFailed,
 				Message: msg,
 			})
@@ -76,31 +99,43 @@ func computeWpStatus(
 		}
 	}
 
-	if status.TotalNodes != status.FailedNodes+status.TransitioningNodes+status.SuccessfulNodes {
+	if status.TotalNodes != status.FailedNodes+status.TransitioningNodes+status.PendingNodes+status.SuccessfulNodes {
 		return v1alpha1.WorkloadPolicyStatus{},
-			fmt.Errorf("inconsistent node stats, total: %d != successful(%d)+transitioning(%d)+failed(%d)",
-				status.TotalNodes, status.SuccessfulNodes, status.TransitioningNodes, status.FailedNodes)
+			fmt.Errorf(
+				"inconsistent node stats, total: %d != successful(%d)+transitioning(%d)+pending(%d)+failed(%d)",
+				status.TotalNodes, status.SuccessfulNodes, status.TransitioningNodes, status.PendingNodes,
+				status.FailedNodes)
 	}
 
 	status.SortTransitioningNodes()
+	status.Enforcement = enforcementSummary(status.EnforcedContainers, status.MatchedContainers)
 
 	switch {
 	case status.SuccessfulNodes == status.TotalNodes:
 		status.Phase = v1alpha1.Ready
 	case status.FailedNodes > 0:
 		status.Phase = v1alpha1.Failed
-	case status.TransitioningNodes > 0:
+	case status.TransitioningNodes > 0, status.PendingNodes > 0:
 		status.Phase = v1alpha1.Transitioning
 	}
 	return status, nil
 }
 
+// enforcementSummary returns the enforced containers out of the matched ones, e.g. "48/50 (96%)".
+func enforcementSummary(enforced, matched int) string {
+	if matched == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d (%d%%)", enforced, matched, enforced*percent/matched)
+}
+
 func buildPolicyStatus(
 	wp *v1alpha1.WorkloadPolicy,
 	nodesInfo nodesInfoMap,
 	scrapedViolations []v1alpha1.ViolationRecord,
+	grace *missingPolicyGrace,
 ) (v1alpha1.WorkloadPolicyStatus, error) {
-	newStatus, err := computeWpStatus(nodesInfo, convertToPolicyMode(wp.Spec.Mode), wp.NamespacedName())
+	newStatus, err := computeWpStatus(nodesInfo, convertToPolicyMode(wp.Spec.Mode), wp.NamespacedName(), grace)
 	if err != nil {
 		return v1alpha1.WorkloadPolicyStatus{}, fmt.Errorf(
 			"failed to compute status for policy %s: %w",
@@ -122,18 +157,71 @@ func (r *WorkloadPolicyStatusSync) processWorkloadPolicy(
 	wp *v1alpha1.WorkloadPolicy,
 	nodesInfo nodesInfoMap,
 	scrapedViolations []v1alpha1.ViolationRecord,
+	conflicts labelConflicts,
 ) error {
-	status, err := buildPolicyStatus(wp, nodesInfo, scrapedViolations)
+	status, err := buildPolicyStatus(wp, nodesInfo, scrapedViolations, r.missingPolicyGrace)
+	if err != nil {
+		return err
+	}
+	base, err := r.basePolicyOf(ctx, wp)
 	if err != nil {
 		return err
 	}
+	status.AllowedExecutables = wp.AllowedExecutablesCount(base)
+	setLabelConflictCondition(wp, &status, conflicts)
+	r.modeMismatch.record(client.ObjectKeyFromObject(wp), status.TransitioningNodes)
 	newPolicy := wp.DeepCopy()
 	newPolicy.Status = status
 
 	r.logger.V(loglevel.VerbosityDebug).Info("updating",
 		"policy", newPolicy.NamespacedName(),
 		"status", newPolicy.Status)
-	return r.Status().Update(ctx, newPolicy)
+	if err = r.Status().Update(ctx, newPolicy); err != nil {
+		return err
+	}
+	r.recordPhaseTransition(newPolicy, wp.Status.Phase)
+	return nil
+}
+
+// basePolicyOf returns the base policy the rules of the policy are inherited from, nil when it has none
+// or it doesn't exist: the agents don't inherit it either.
+func (r *WorkloadPolicyStatusSync) basePolicyOf(
+	ctx context.Context,
+	wp *v1alpha1.WorkloadPolicy,
+) (*v1alpha1.WorkloadPolicy, error) {
+	if wp.Spec.BasePolicy == "" || wp.Spec.BasePolicy == wp.Name {
+		return nil, nil
+	}
+	base := &v1alpha1.WorkloadPolicy{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: wp.Namespace, Name: wp.Spec.BasePolicy}, base)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the base policy of %s: %w", wp.NamespacedName(), err)
+	}
+	return base, nil
+}
+
+// recordPhaseTransition emits an Event on the policy when its phase changed,
+// so that `kubectl describe` shows the timeline of the phases.
+func (r *WorkloadPolicyStatusSync) recordPhaseTransition(wp *v1alpha1.WorkloadPolicy, oldPhase v1alpha1.Phase) {
+	newPhase := wp.Status.Phase
+	if r.recorder == nil || newPhase == oldPhase {
+		return
+	}
+
+	eventType := corev1.EventTypeNormal
+	if newPhase == v1alpha1.Failed {
+		eventType = corev1.EventTypeWarning
+	}
+	if oldPhase == "" {
+		r.recorder.Eventf(wp, nil, eventType, phaseChangedReason, phaseChangedAction,
+			"Phase set to %s", newPhase)
+		return
+	}
+	r.recorder.Eventf(wp, nil, eventType, phaseChangedReason, phaseChangedAction,
+		"Phase changed from %s to %s", oldPhase, newPhase)
 }
 
 // mergeViolations prepends scraped violations to the existing list,
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -11,8 +13,11 @@ import (
 	"github.com/rancher-sandbox/runtime-enforcer/internal/grpcexporter"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/loglevel"
 	pb "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+	"golang.org/x/sync/errgroup"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -26,31 +31,62 @@ type nodeInfo struct {
 // Structure: NodeName -> Info.
 type nodesInfoMap map[string]nodeInfo
 
+// DefaultAgentConcurrency is the default number of agents queried in parallel during a sync.
+const DefaultAgentConcurrency = 10
+
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 // +kubebuilder:rbac:groups=security.rancher.io,resources=workloadpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=security.rancher.io,resources=workloadpolicies/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 // WorkloadPolicyStatusSync reconciles a WorkloadPolicy status.
 type WorkloadPolicyStatusSync struct {
 	client.Client
 
 	agentClientPool *grpcexporter.AgentClientPool
+	recorder        events.EventRecorder
 	updateInterval  time.Duration
-	logger          logr.Logger
+	// agentConcurrency bounds the number of agents queried in parallel.
+	agentConcurrency int
+	logger           logr.Logger
+	modeMismatch     *modeMismatchTracker
+	// bpfConfigMismatch flags the nodes whose eBPF programs were loaded with another configuration.
+	bpfConfigMismatch *bpfConfigTracker
+	// missingPolicyGrace is only used by the single-threaded sync.
+	missingPolicyGrace *missingPolicyGrace
 }
 
 // WorkloadPolicyStatusSyncConfig holds the configuration for the WorkloadPolicyStatusSync.
 type WorkloadPolicyStatusSyncConfig struct {
 	AgentPoolConf  grpcexporter.AgentClientPoolConfig
 	UpdateInterval time.Duration
+	// MissingPolicyGracePeriod is how long a policy can be missing on a node before the node is reported as failed.
+	MissingPolicyGracePeriod time.Duration
+	// AgentConcurrency is the number of agents queried in parallel, 0 means DefaultAgentConcurrency.
+	AgentConcurrency int
+}
+
+// Validate checks the configuration, it is called at startup to report malformed flags early.
+func (c *WorkloadPolicyStatusSyncConfig) Validate() error {
+	if c.UpdateInterval <= 0 {
+		return fmt.Errorf("invalid update interval: %v", c.UpdateInterval)
+	}
+	if c.MissingPolicyGracePeriod < 0 {
+		return fmt.Errorf("invalid missing policy grace period: %v", c.MissingPolicyGracePeriod)
+	}
+	if c.AgentConcurrency < 0 {
+		return fmt.Errorf("invalid agent concurrency: %d", c.AgentConcurrency)
+	}
+	return c.AgentPoolConf.Validate()
 }
 
 func NewWorkloadPolicyStatusSync(
 	c client.Client,
+	recorder events.EventRecorder,
 	config *WorkloadPolicyStatusSyncConfig,
 ) (*WorkloadPolicyStatusSync, error) {
-	if config.UpdateInterval <= 0 {
-		return nil, fmt.Errorf("invalid update interval: %v", config.UpdateInterval)
+	if err := config.Validate(); err != nil {
+		return nil, err
 	}
 
 	agentClientPool, err := grpcexporter.NewAgentClientPool(config.AgentPoolConf)
@@ -58,10 +94,21 @@ func NewWorkloadPolicyStatusSync(
 		return nil, fmt.Errorf("failed to create agent client pool: %w", err)
 	}
 
+	agentConcurrency := config.AgentConcurrency
+	if agentConcurrency == 0 {
+		agentConcurrency = DefaultAgentConcurrency
+	}
+
 	return &WorkloadPolicyStatusSync{
-		Client:          c,
-		agentClientPool: agentClientPool,
-		updateInterval:  config.UpdateInterval,
+		Client:           c,
+		agentClientPool:  agentClientPool,
+		recorder:         recorder,
+		updateInterval:   config.UpdateInterval,
+		agentConcurrency: agentConcurrency,
+		modeMismatch:     newModeMismatchTracker(),
+
+		bpfConfigMismatch:  newBpfConfigTracker(),
+		missingPolicyGrace: newMissingPolicyGrace(config.MissingPolicyGracePeriod),
 	}, nil
 }
 
@@ -85,21 +132,28 @@ func (r *WorkloadPolicyStatusSync) Start(ctx context.Context) error {
 func (r *WorkloadPolicyStatusSync) sync(
 	ctx context.Context,
 ) error {
-	// As first step, we list all WorkloadPolicies, if there are none, we can reschedule and exit early
+	clients, err := r.agentClientPool.UpdatePool(ctx, r.Client)
+	if err != nil {
+		return err
+	}
+	// the override is pushed even without policies, so that the ones created during the override are monitored.
+	if err = r.syncEnforcementOverride(ctx, clients); err != nil {
+		r.logger.Error(err, "failed to sync the enforcement override")
+	}
+
+	// Then we list all WorkloadPolicies, if there are none, we can reschedule and exit early
 	var wpList v1alpha1.WorkloadPolicyList
-	if err := r.List(ctx, &wpList); err != nil {
+	if err = r.List(ctx, &wpList); err != nil {
 		return err
 	}
 
 	if len(wpList.Items) == 0 {
 		r.logger.V(loglevel.VerbosityDebug).Info("No WorkloadPolicies found, retrying later")
+		r.modeMismatch.retain(nil)
+		r.missingPolicyGrace.prune()
 		return nil
 	}
 
-	clients, err := r.agentClientPool.UpdatePool(ctx, r.Client)
-	if err != nil {
-		return err
-	}
 	nodesInfo := make(nodesInfoMap, len(clients))
 
 	for nodeName, client := range clients {
@@ -112,44 +166,60 @@ func (r *WorkloadPolicyStatusSync) sync(
 					Message: "No agent client available",
 				},
 			}
-			continue
 		}
+	}
 
+	results := callAgents(ctx, clients, r.agentConcurrency,
+		func(ctx context.Context, client grpcexporter.AgentClientAPI) (map[string]*pb.PolicyStatus, error) {
+			return client.ListPoliciesStatus(ctx)
+		})
+	for _, res := range results {
 		// by default success state
 		nodeIssue := v1alpha1.NodeIssue{
 			Code:    v1alpha1.NodeIssueNone,
 			Message: "",
 		}
-		var policies map[string]*pb.PolicyStatus
-		policies, err = client.ListPoliciesStatus(ctx)
-		if err != nil {
-			// in case of error we close the connection and we will open a new one at the next sync
-			r.agentClientPool.MarkStaleAgentClient(nodeName)
-			r.logger.Error(err, "failed to get policies status", "node", nodeName)
+		if res.err != nil {
+			r.handleAgentCallError(res.node, res.err, "failed to get policies status")
 			nodeIssue = v1alpha1.NodeIssue{
 				Code:    v1alpha1.NodeIssueMissingPolicy,
-				Message: fmt.Sprintf("cannot list node policies: %v", err),
+				Message: fmt.Sprintf("cannot list node policies: %v", res.err),
 			}
-		} else if len(policies) == 0 {
+		} else if len(res.value) == 0 {
 			// if there are no policies for this pod we have an error because in previous steps
 			// we checked that we have policies deployed in the cluster.
-			r.logger.Error(errors.New("empty policy list"), "No policies found", "node", nodeName)
+			r.logger.Error(errors.New("empty policy list"), "No policies found", "node", res.node)
 			nodeIssue = v1alpha1.NodeIssue{
 				Code:    v1alpha1.NodeIssueMissingPolicy,
 				Message: "empty policy list",
 			}
 		}
-		nodesInfo[nodeName] = nodeInfo{
-			policies: policies,
+		nodesInfo[res.node] = nodeInfo{
+			policies: res.value,
 			issue:    nodeIssue,
 		}
 	}
 
+	agentInfos := r.getAgentInfos(ctx, clients)
+	r.checkBpfConfigs(agentInfos)
+	for nodeName, info := range agentInfos {
+		if issue, ok := cgroupIssue(info); ok && nodesInfo[nodeName].issue.Code == v1alpha1.NodeIssueNone {
+			r.logger.Info("the agent keeps failing to resolve the cgroups", "node", nodeName, "error", issue.Message)
+			nodesInfo[nodeName] = nodeInfo{policies: nodesInfo[nodeName].policies, issue: issue}
+		}
+	}
 	violationsByPolicy := r.getViolationsByPolicy(ctx, clients)
+	conflicts, err := r.getLabelConflicts(ctx)
+	if err != nil {
+		r.logger.Error(err, "failed to detect the policy label conflicts")
+	}
 
 	// Now we iterate over all WSPs and update their status based on the collected policies status from the agents
+	policies := make(map[types.NamespacedName]struct{}, len(wpList.Items))
 	for _, wp := range wpList.Items {
-		if err = r.processWorkloadPolicy(ctx, &wp, nodesInfo, violationsByPolicy[wp.NamespacedName()]); err != nil {
+		policies[client.ObjectKeyFromObject(&wp)] = struct{}{}
+		err = r.processWorkloadPolicy(ctx, &wp, nodesInfo, violationsByPolicy[wp.NamespacedName()], conflicts)
+		if err != nil {
 			r.logger.Error(
 				err,
 				"failed to process workload policy",
@@ -157,10 +227,24 @@ func (r *WorkloadPolicyStatusSync) sync(
 			)
 		}
 	}
+	r.modeMismatch.retain(policies)
+	r.missingPolicyGrace.prune()
 
 	return nil
 }
 
+// handleAgentCallError logs the failed call to the agent of the node. The connection is closed
+// and opened again at the next sync, unless the response was too large: reconnecting would fail the same way.
+func (r *WorkloadPolicyStatusSync) handleAgentCallError(nodeName string, err error, msg string) {
+	if grpcexporter.IsResponseTooLarge(err) {
+		r.logger.Error(err, msg+": the response of the agent exceeds the maximum message size, "+
+			"raise --wp-status-reconciler-agent-grpc-max-recv-msg-size", "node", nodeName)
+		return
+	}
+	r.agentClientPool.MarkStaleAgentClient(nodeName)
+	r.logger.Error(err, msg, "node", nodeName)
+}
+
 // getViolationsByPolicy gets all the violations for a single policy.
 func (r *WorkloadPolicyStatusSync) getViolationsByPolicy(
 	ctx context.Context,
@@ -170,15 +254,19 @@ func (r *WorkloadPolicyStatusSync) getViolationsByPolicy(
 	for nodeName, client := range clients {
 		if client == nil {
 			r.logger.Info("cannot get a agent client for the node", "node", nodeName)
-			continue
 		}
-		pbViolations, err := client.ScrapeViolations(ctx)
-		if err != nil {
-			r.agentClientPool.MarkStaleAgentClient(nodeName)
-			r.logger.Error(err, "failed to scrape violations", "node", nodeName)
+	}
+
+	results := callAgents(ctx, clients, r.agentConcurrency,
+		func(ctx context.Context, client grpcexporter.AgentClientAPI) ([]*pb.ViolationRecord, error) {
+			return client.ScrapeViolations(ctx)
+		})
+	for _, res := range results {
+		if res.err != nil {
+			r.handleAgentCallError(res.node, res.err, "failed to scrape violations")
 			continue
 		}
-		for _, v := range pbViolations {
+		for _, v := range res.value {
 			namespacedName := v.GetPolicyName()
 			rec := v1alpha1.ViolationRecord{
 				Timestamp:      metav1.NewTime(v.GetTimestamp().AsTime()),
@@ -194,3 +282,38 @@ func (r *WorkloadPolicyStatusSync) getViolationsByPolicy(
 
 	return violationsByPolicy
 }
+
+// agentResult is the answer of the agent of a node.
+type agentResult[T any] struct {
+	node  string
+	value T
+	err   error
+}
+
+// callAgents calls fn on the agents of the nodes, at most limit at a time, and skips the nodes without client.
+// The results are sorted by node name so that what is built from them does not depend on the order
+// the agents answered in. The pool is not safe for concurrent use: the failures are handled by the caller.
+func callAgents[T any](
+	ctx context.Context,
+	clients map[string]grpcexporter.AgentClientAPI,
+	limit int,
+	fn func(context.Context, grpcexporter.AgentClientAPI) (T, error),
+) []agentResult[T] {
+	nodes := slices.Sorted(maps.Keys(clients))
+	nodes = slices.DeleteFunc(nodes, func(node string) bool { return clients[node] == nil })
+	results := make([]agentResult[T], len(nodes))
+
+	var g errgroup.Group
+	g.SetLimit(max(limit, 1))
+	for i, node := range nodes {
+		client := clients[node]
+		g.Go(func() error {
+			value, err := fn(ctx, client)
+			results[i] = agentResult[T]{node: node, value: value, err: err}
+			return nil
+		})
+	}
+	// the calls never fail the group, their errors are in the results.
+	_ = g.Wait()
+	return results
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -17,6 +18,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -39,7 +42,7 @@ func createTestWPStatusSync(t *testing.T) *WorkloadPolicyStatusSync {
 		UpdateInterval: 1 * time.Second,
 	}
 
-	r, err := NewWorkloadPolicyStatusSync(cl, config)
+	r, err := NewWorkloadPolicyStatusSync(cl, events.NewFakeRecorder(10), config)
 	require.NoError(t, err)
 	return r
 }
@@ -48,6 +51,9 @@ type testAgentClient struct {
 	policies   map[string]*pb.PolicyStatus
 	violations []*pb.ViolationRecord
 	scrapeErr  error
+	info       *pb.GetAgentInfoResponse
+	// overrideEnabled is the last enforcement override pushed to the agent.
+	overrideEnabled bool
 }
 
 func (c *testAgentClient) ListPoliciesStatus(_ context.Context) (map[string]*pb.PolicyStatus, error) {
@@ -62,6 +68,16 @@ func (c *testAgentClient) ScrapeViolations(_ context.Context) ([]*pb.ViolationRe
 	return c.violations, c.scrapeErr
 }
 
+func (c *testAgentClient) GetAgentInfo(_ context.Context) (*pb.GetAgentInfoResponse, error) {
+	return c.info, nil
+}
+
+func (c *testAgentClient) SetEnforcementOverride(_ context.Context, enabled bool) (bool, error) {
+	changed := c.overrideEnabled != enabled
+	c.overrideEnabled = enabled
+	return changed, nil
+}
+
 func (c *testAgentClient) Close() error {
 	return nil
 }
@@ -203,11 +219,58 @@ func TestComputeWpStatus(t *testing.T) {
 				Phase:              v1alpha1.Ready,
 			},
 		},
+		{
+			// - node1 enforces all of its containers.
+			// - node2 has 2 containers without a resolved cgroup.
+			// - node3 has no container of the policy.
+			name: "enforced containers are summed over the nodes",
+			nodes: nodesInfoMap{
+				node1: nodeInfo{
+					issue: v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone},
+					policies: map[string]*pb.PolicyStatus{
+						policyName: {
+							State:              pb.PolicyState_POLICY_STATE_READY,
+							Mode:               expectedMode,
+							MatchedContainers:  30,
+							EnforcedContainers: 30,
+						},
+					},
+				},
+				node2: nodeInfo{
+					issue: v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone},
+					policies: map[string]*pb.PolicyStatus{
+						policyName: {
+							State:              pb.PolicyState_POLICY_STATE_READY,
+							Mode:               expectedMode,
+							MatchedContainers:  20,
+							EnforcedContainers: 18,
+						},
+					},
+				},
+				node3: nodeInfo{
+					issue: v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone},
+					policies: map[string]*pb.PolicyStatus{
+						policyName: {
+							State: pb.PolicyState_POLICY_STATE_READY,
+							Mode:  expectedMode,
+						},
+					},
+				},
+			},
+			expected: v1alpha1.WorkloadPolicyStatus{
+				TotalNodes:         3,
+				SuccessfulNodes:    3,
+				Phase:              v1alpha1.Ready,
+				MatchedContainers:  50,
+				EnforcedContainers: 48,
+				Enforcement:        "48/50 (96%)",
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := computeWpStatus(tt.nodes, expectedMode, policyName)
+			got, err := computeWpStatus(tt.nodes, expectedMode, policyName, nil)
 			require.NoError(t, err)
 			require.Equal(t, tt.expected, got)
 		})
@@ -302,7 +365,7 @@ func TestWorkloadPolicyViolationCount(t *testing.T) {
 		scraped[i] = makeRecord(i + 2)
 	}
 
-	status, err := buildPolicyStatus(wp, nil, scraped)
+	status, err := buildPolicyStatus(wp, nil, scraped, nil)
 	require.NoError(t, err)
 
 	require.Equal(t, int64(101), status.ViolationCount)
@@ -365,6 +428,23 @@ func TestGetViolationsByPolicy(t *testing.T) {
 		require.Equal(t, []v1alpha1.ViolationRecord{apiRec("pod-3", "node2")}, got[nnB])
 	})
 
+	t.Run("orders violations by node", func(t *testing.T) {
+		r := createTestWPStatusSync(t)
+
+		clients := make(map[string]grpcexporter.AgentClientAPI)
+		var expected []v1alpha1.ViolationRecord
+		for i := range 20 {
+			node := fmt.Sprintf("node%02d", i)
+			clients[node] = &testAgentClient{
+				violations: []*pb.ViolationRecord{pbRec("default/policy-a", "pod", node)},
+			}
+			expected = append(expected, apiRec("pod", node))
+		}
+
+		got := r.getViolationsByPolicy(context.Background(), clients)
+		require.Equal(t, expected, got["default/policy-a"])
+	})
+
 	t.Run("skips nodes without connection", func(t *testing.T) {
 		r := createTestWPStatusSync(t)
 		// No connections set up.
@@ -394,3 +474,299 @@ func TestGetViolationsByPolicy(t *testing.T) {
 		require.Empty(t, got)
 	})
 }
+
+// slowAgentClient records how many calls to the agents are in flight at the same time.
+type slowAgentClient struct {
+	testAgentClient
+
+	inFlight    *atomic.Int32
+	maxInFlight *atomic.Int32
+}
+
+func (c *slowAgentClient) ListPoliciesStatus(ctx context.Context) (map[string]*pb.PolicyStatus, error) {
+	n := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for {
+		current := c.maxInFlight.Load()
+		if n <= current || c.maxInFlight.CompareAndSwap(current, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return c.testAgentClient.ListPoliciesStatus(ctx)
+}
+
+func TestCallAgents(t *testing.T) {
+	const limit = 3
+
+	var inFlight, maxInFlight atomic.Int32
+	clients := map[string]grpcexporter.AgentClientAPI{"node-missing": nil}
+	for i := range 12 {
+		node := fmt.Sprintf("node%02d", i)
+		clients[node] = &slowAgentClient{
+			testAgentClient: testAgentClient{policies: map[string]*pb.PolicyStatus{node: {}}},
+			inFlight:        &inFlight,
+			maxInFlight:     &maxInFlight,
+		}
+	}
+
+	results := callAgents(context.Background(), clients, limit,
+		func(ctx context.Context, client grpcexporter.AgentClientAPI) (map[string]*pb.PolicyStatus, error) {
+			return client.ListPoliciesStatus(ctx)
+		})
+
+	require.Len(t, results, 12)
+	for i, res := range results {
+		node := fmt.Sprintf("node%02d", i)
+		require.Equal(t, node, res.node)
+		require.NoError(t, res.err)
+		require.Contains(t, res.value, node)
+	}
+	require.LessOrEqual(t, maxInFlight.Load(), int32(limit))
+	require.Greater(t, maxInFlight.Load(), int32(1))
+}
+
+func TestWorkloadPolicyStatusSyncConfigValidate(t *testing.T) {
+	validConfig := func() *WorkloadPolicyStatusSyncConfig {
+		return &WorkloadPolicyStatusSyncConfig{
+			AgentPoolConf: grpcexporter.AgentClientPoolConfig{
+				AgentFactoryConfig: grpcexporter.AgentFactoryConfig{
+					Port:        grpcexporter.DefaultAgentPort,
+					IdleTimeout: grpcexporter.DefaultAgentIdleTimeout,
+				},
+				LabelSelectorString: "app.kubernetes.io/component=agent,app.kubernetes.io/name=runtime-enforcer",
+				Namespace:           "runtime-enforcer",
+			},
+			UpdateInterval: time.Second,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		mutate      func(*WorkloadPolicyStatusSyncConfig)
+		expectedErr string
+	}{
+		{
+			name:   "valid configuration",
+			mutate: func(*WorkloadPolicyStatusSyncConfig) {},
+		},
+		{
+			name:   "empty namespace falls back to the controller one",
+			mutate: func(c *WorkloadPolicyStatusSyncConfig) { c.AgentPoolConf.Namespace = "" },
+		},
+		{
+			name:        "missing update interval",
+			mutate:      func(c *WorkloadPolicyStatusSyncConfig) { c.UpdateInterval = 0 },
+			expectedErr: "invalid update interval",
+		},
+		{
+			name:        "negative missing policy grace period",
+			mutate:      func(c *WorkloadPolicyStatusSyncConfig) { c.MissingPolicyGracePeriod = -time.Second },
+			expectedErr: "invalid missing policy grace period",
+		},
+		{
+			name:        "negative agent concurrency",
+			mutate:      func(c *WorkloadPolicyStatusSyncConfig) { c.AgentConcurrency = -1 },
+			expectedErr: "invalid agent concurrency",
+		},
+		{
+			name:        "selector without value",
+			mutate:      func(c *WorkloadPolicyStatusSyncConfig) { c.AgentPoolConf.LabelSelectorString = "app" },
+			expectedErr: "label should be in the format 'key=value'",
+		},
+		{
+			name:        "selector with an empty key",
+			mutate:      func(c *WorkloadPolicyStatusSyncConfig) { c.AgentPoolConf.LabelSelectorString = "app=agent,=x" },
+			expectedErr: "invalid label key",
+		},
+		{
+			name:        "selector with an invalid value",
+			mutate:      func(c *WorkloadPolicyStatusSyncConfig) { c.AgentPoolConf.LabelSelectorString = "app=not valid" },
+			expectedErr: "invalid label value",
+		},
+		{
+			name:        "invalid namespace",
+			mutate:      func(c *WorkloadPolicyStatusSyncConfig) { c.AgentPoolConf.Namespace = "Not_A_Namespace" },
+			expectedErr: "invalid agent namespace",
+		},
+		{
+			name:        "invalid port",
+			mutate:      func(c *WorkloadPolicyStatusSyncConfig) { c.AgentPoolConf.Port = 0 },
+			expectedErr: "invalid agent port",
+		},
+		{
+			name:   "agent connections never idle",
+			mutate: func(c *WorkloadPolicyStatusSyncConfig) { c.AgentPoolConf.IdleTimeout = 0 },
+		},
+		{
+			name:        "negative idle timeout",
+			mutate:      func(c *WorkloadPolicyStatusSyncConfig) { c.AgentPoolConf.IdleTimeout = -time.Minute },
+			expectedErr: "invalid agent idle timeout",
+		},
+		{
+			name:        "negative max receive message size",
+			mutate:      func(c *WorkloadPolicyStatusSyncConfig) { c.AgentPoolConf.MaxRecvMsgSize = -1 },
+			expectedErr: "invalid agent max receive message size",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := validConfig()
+			tt.mutate(config)
+			err := config.Validate()
+			if tt.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestProcessWorkloadPolicyRecordsPhaseTransitions(t *testing.T) {
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "policy",
+			Namespace: "ns",
+		},
+		Spec: v1alpha1.WorkloadPolicySpec{Mode: policymode.ProtectString},
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(wp).
+		WithStatusSubresource(wp).
+		Build()
+	recorder := events.NewFakeRecorder(10)
+	r := &WorkloadPolicyStatusSync{Client: cl, recorder: recorder}
+
+	nodeWithState := func(state pb.PolicyState) nodesInfoMap {
+		return nodesInfoMap{
+			"node1": nodeInfo{
+				issue: v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone},
+				policies: map[string]*pb.PolicyStatus{
+					wp.NamespacedName(): {State: state, Mode: pb.PolicyMode_POLICY_MODE_PROTECT},
+				},
+			},
+		}
+	}
+	sync := func(nodes nodesInfoMap) {
+		var current v1alpha1.WorkloadPolicy
+		require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(wp), &current))
+		require.NoError(t, r.processWorkloadPolicy(t.Context(), &current, nodes, nil, nil))
+	}
+
+	sync(nodeWithState(pb.PolicyState_POLICY_STATE_READY))
+	require.Equal(t, "Normal PhaseChanged Phase set to Ready", <-recorder.Events)
+
+	// the phase doesn't change, no event is emitted.
+	sync(nodeWithState(pb.PolicyState_POLICY_STATE_READY))
+	require.Empty(t, recorder.Events)
+
+	sync(nodeWithState(pb.PolicyState_POLICY_STATE_ERROR))
+	require.Equal(t, "Warning PhaseChanged Phase changed from Ready to Failed", <-recorder.Events)
+}
+
+func TestProcessWorkloadPolicyAllowedExecutables(t *testing.T) {
+	base := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "base", Namespace: "ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: policymode.MonitorString,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"main":    {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sh"}}},
+				"sidecar": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+			},
+		},
+	}
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:       policymode.MonitorString,
+			BasePolicy: "base",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"main": {Executables: v1alpha1.WorkloadPolicyExecutables{
+					Allowed:         []string{"/bin/sh", "/bin/ls"},
+					AllowedPrefixes: []string{"/usr/local/bin/"},
+				}},
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(base, wp).
+		WithStatusSubresource(base, wp).
+		Build()
+	r := &WorkloadPolicyStatusSync{Client: cl}
+
+	allowedExecutables := func(policy *v1alpha1.WorkloadPolicy) int {
+		var current v1alpha1.WorkloadPolicy
+		require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(policy), &current))
+		require.NoError(t, r.processWorkloadPolicy(t.Context(), &current, nil, nil, nil))
+		require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(policy), &current))
+		return current.Status.AllowedExecutables
+	}
+
+	// the prefixes and the rules of the containers inherited from the base policy are counted.
+	require.Equal(t, 4, allowedExecutables(wp))
+	require.Equal(t, 2, allowedExecutables(base))
+
+	// a missing base policy is not inherited.
+	require.NoError(t, cl.Delete(t.Context(), base))
+	require.Equal(t, 3, allowedExecutables(wp))
+}
+
+func TestComputeWpStatusMissingPolicyGracePeriod(t *testing.T) {
+	policyName := "example"
+	now := time.Now()
+	grace := newMissingPolicyGrace(time.Minute)
+	grace.now = func() time.Time { return now }
+
+	ready := nodeInfo{
+		issue: v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone},
+		policies: map[string]*pb.PolicyStatus{
+			policyName: {State: pb.PolicyState_POLICY_STATE_READY, Mode: pb.PolicyMode_POLICY_MODE_PROTECT},
+		},
+	}
+	missing := nodeInfo{
+		issue:    v1alpha1.NodeIssue{Code: v1alpha1.NodeIssueNone},
+		policies: map[string]*pb.PolicyStatus{"other": {State: pb.PolicyState_POLICY_STATE_READY}},
+	}
+	requirePhase := func(node nodeInfo, expected v1alpha1.Phase) {
+		t.Helper()
+		status, err := computeWpStatus(nodesInfoMap{"node1": ready, "node2": node},
+			pb.PolicyMode_POLICY_MODE_PROTECT, policyName, grace)
+		require.NoError(t, err)
+		require.Equal(t, expected, status.Phase)
+		// a node loading the policy is not transitioning mode, it doesn't count as a mode mismatch.
+		require.Zero(t, status.TransitioningNodes)
+	}
+
+	// 1. a briefly missing policy keeps the node pending.
+	status, err := computeWpStatus(nodesInfoMap{"node1": ready, "node2": missing},
+		pb.PolicyMode_POLICY_MODE_PROTECT, policyName, grace)
+	require.NoError(t, err)
+	require.Equal(t, 1, status.PendingNodes)
+	require.Empty(t, status.NodesTransitioning)
+	requirePhase(missing, v1alpha1.Transitioning)
+	now = now.Add(30 * time.Second)
+	requirePhase(missing, v1alpha1.Transitioning)
+
+	// 2. the policy shows up again, it can go missing again for a whole grace period.
+	requirePhase(ready, v1alpha1.Ready)
+	now = now.Add(45 * time.Second)
+	requirePhase(missing, v1alpha1.Transitioning)
+
+	// 3. the node fails once the policy stays missing for longer than the grace period.
+	now = now.Add(time.Minute)
+	requirePhase(missing, v1alpha1.Failed)
+
+	// 4. the nodes that are not observed anymore are forgotten.
+	grace.prune()
+	require.Len(t, grace.since, 1)
+	grace.prune()
+	require.Empty(t, grace.since)
+}
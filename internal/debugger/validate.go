@@ -240,6 +240,10 @@ func ValidatePodCacheIntegrity(ctx context.Context,
 		var podCacheList []*agentv1.PodView
 		podCacheList, err = client.ListPodCache(ctx)
 		if err != nil {
+			if grpcexporter.IsResponseTooLarge(err) {
+				logger.ErrorContext(ctx, "Pod cache of the agent exceeds the maximum message size", "node", nodeName, "error", err)
+				continue
+			}
 			pool.MarkStaleAgentClient(nodeName)
 			logger.ErrorContext(ctx, "Failed to list pod cache", "node", nodeName, "error", err)
 			continue
@@ -0,0 +1,117 @@
+package enforcementgap
+
+import (
+	"context"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+)
+
+const (
+	// DefaultInterval is how often the unresolved containers are looked up.
+	DefaultInterval = 30 * time.Second
+	// DefaultThreshold is how long a container can be created without a resolved cgroup before it is reported,
+	// it leaves time for the slow image pulls and init containers.
+	DefaultThreshold = 2 * time.Minute
+
+	unresolvedReason = "ContainerNotEnforced"
+	unresolvedAction = "ResolveCgroup"
+)
+
+// Reporter emits a warning Event on the pods whose containers never got a cgroup, e.g. because they
+// crash loop before they start: the agent cannot enforce them.
+// Each container is reported once until it is resolved or its pod is removed.
+type Reporter struct {
+	recorder  events.EventRecorder
+	source    func(minAge time.Duration) []resolver.UnresolvedContainer
+	interval  time.Duration
+	threshold time.Duration
+	// reported are the containers already reported.
+	reported map[reportedKey]struct{}
+}
+
+type reportedKey struct {
+	podID resolver.PodID
+	name  resolver.ContainerName
+}
+
+type Option func(*Reporter)
+
+// WithThreshold sets how long a container can be unresolved before it is reported.
+func WithThreshold(threshold time.Duration) Option {
+	return func(r *Reporter) {
+		r.threshold = threshold
+	}
+}
+
+// WithInterval sets how often the unresolved containers are looked up.
+func WithInterval(interval time.Duration) Option {
+	return func(r *Reporter) {
+		r.interval = interval
+	}
+}
+
+// NewReporter creates a Reporter, source returns the containers unresolved for at least the given age,
+// e.g. resolver.UnresolvedContainers.
+func NewReporter(
+	recorder events.EventRecorder,
+	source func(minAge time.Duration) []resolver.UnresolvedContainer,
+	opts ...Option,
+) *Reporter {
+	r := &Reporter{
+		recorder:  recorder,
+		source:    source,
+		interval:  DefaultInterval,
+		threshold: DefaultThreshold,
+		reported:  make(map[reportedKey]struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Start reports the unresolved containers until the context is done.
+func (r *Reporter) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.report()
+		}
+	}
+}
+
+// report emits an Event for the containers not reported yet.
+func (r *Reporter) report() {
+	unresolved := make(map[reportedKey]struct{})
+	for _, container := range r.source(r.threshold) {
+		key := reportedKey{podID: container.PodID, name: container.Name}
+		unresolved[key] = struct{}{}
+		if _, ok := r.reported[key]; ok {
+			continue
+		}
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: container.Namespace,
+			Name:      container.PodName,
+			UID:       types.UID(container.PodID),
+		}}
+		r.recorder.Eventf(pod, nil, corev1.EventTypeWarning, unresolvedReason, unresolvedAction,
+			"Container %s was created at %s but its cgroup was never resolved, it is not enforced",
+			container.Name, container.Since.UTC().Format(time.RFC3339))
+		r.reported[key] = struct{}{}
+	}
+	// a container resolved and unresolved again is reported again.
+	for key := range r.reported {
+		if _, ok := unresolved[key]; !ok {
+			delete(r.reported, key)
+		}
+	}
+}
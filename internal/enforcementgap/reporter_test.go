@@ -0,0 +1,44 @@
+package enforcementgap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/events"
+)
+
+func TestReporterReport(t *testing.T) {
+	recorder := events.NewFakeRecorder(10)
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	unresolved := []resolver.UnresolvedContainer{
+		{PodID: "pod-uid", Namespace: "default", PodName: "web", Name: "app", Since: since},
+	}
+	var minAges []time.Duration
+	r := NewReporter(recorder, func(minAge time.Duration) []resolver.UnresolvedContainer {
+		minAges = append(minAges, minAge)
+		return unresolved
+	}, WithThreshold(time.Minute))
+
+	r.report()
+	require.Equal(t, []time.Duration{time.Minute}, minAges)
+	require.Len(t, recorder.Events, 1)
+	require.Equal(t,
+		"Warning ContainerNotEnforced Container app was created at 2026-01-01T00:00:00Z but its cgroup "+
+			"was never resolved, it is not enforced",
+		<-recorder.Events)
+
+	// the container is reported once.
+	r.report()
+	require.Empty(t, recorder.Events)
+
+	// the container is reported again once it was resolved then unresolved again.
+	unresolved = nil
+	r.report()
+	unresolved = []resolver.UnresolvedContainer{
+		{PodID: "pod-uid", Namespace: "default", PodName: "web", Name: "app", Since: since},
+	}
+	r.report()
+	require.Len(t, recorder.Events, 1)
+}
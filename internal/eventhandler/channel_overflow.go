@@ -0,0 +1,42 @@
+package eventhandler
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ChannelOverflowPolicy defines what happens to a learning event when the event channel is full.
+type ChannelOverflowPolicy string
+
+const (
+	// ChannelOverflowDrop drops the event and counts it, the event scraper is never slowed down.
+	ChannelOverflowDrop ChannelOverflowPolicy = "drop"
+	// ChannelOverflowBlock waits until the reconciler makes room in the channel, no event is lost.
+	ChannelOverflowBlock ChannelOverflowPolicy = "block"
+)
+
+// ParseChannelOverflowPolicy parses the value of the --channel-overflow flag.
+func ParseChannelOverflowPolicy(s string) (ChannelOverflowPolicy, error) {
+	switch policy := ChannelOverflowPolicy(s); policy {
+	case ChannelOverflowDrop, ChannelOverflowBlock:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown channel overflow policy %q, must be one of: %s, %s",
+			s, ChannelOverflowDrop, ChannelOverflowBlock)
+	}
+}
+
+// WithChannelOverflowPolicy sets the behavior of EnqueueEvent when the event channel is full.
+func WithChannelOverflowPolicy(policy ChannelOverflowPolicy) Option {
+	return func(r *LearningReconciler) {
+		r.overflowPolicy = policy
+	}
+}
+
+func newDroppedEventsCounter() prometheus.Counter {
+	return prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "runtime_enforcer_learning_events_dropped_total",
+		Help: "Number of learning events dropped because the learning event channel was full.",
+	})
+}
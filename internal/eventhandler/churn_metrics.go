@@ -0,0 +1,133 @@
+package eventhandler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultProposalStabilizationWindow is how long a proposal can keep learning new
+	// executables without interruption before it is reported as never stabilized.
+	DefaultProposalStabilizationWindow = time.Hour
+	// proposalQuietPeriod is the gap without updates after which a proposal is considered stable
+	// and its update streak is reset.
+	proposalQuietPeriod = 10 * time.Minute
+)
+
+type proposalStreak struct {
+	start      time.Time
+	lastUpdate time.Time
+}
+
+// proposalChurnTracker counts the updates done on each WorkloadPolicyProposal and reports
+// the proposals that keep changing for longer than the stabilization window.
+// Noisy or compromised workloads never stop learning new executables, so they show up here.
+type proposalChurnTracker struct {
+	mu      sync.Mutex
+	window  time.Duration
+	now     func() time.Time
+	streaks map[types.NamespacedName]*proposalStreak
+
+	updates      *prometheus.CounterVec
+	unstableDesc *prometheus.Desc
+}
+
+var _ prometheus.Collector = &proposalChurnTracker{}
+
+func newProposalChurnTracker(window time.Duration) *proposalChurnTracker {
+	return &proposalChurnTracker{
+		window:  window,
+		now:     time.Now,
+		streaks: make(map[types.NamespacedName]*proposalStreak),
+		updates: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "runtime_enforcer_learning_proposal_updates_total",
+			Help: "Number of times the agent created or updated a WorkloadPolicyProposal with a new executable.",
+		}, []string{"namespace", "proposal"}),
+		unstableDesc: prometheus.NewDesc(
+			"runtime_enforcer_learning_proposal_unstable",
+			"Set to 1 when a WorkloadPolicyProposal kept learning new executables for longer than the stabilization window.",
+			[]string{"namespace", "proposal"},
+			nil,
+		),
+	}
+}
+
+// recordUpdate must be called each time a proposal is created or updated.
+func (t *proposalChurnTracker) recordUpdate(proposal types.NamespacedName) {
+	t.updates.WithLabelValues(proposal.Namespace, proposal.Name).Inc()
+
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	streak, ok := t.streaks[proposal]
+	if !ok || now.Sub(streak.lastUpdate) > proposalQuietPeriod {
+		streak = &proposalStreak{start: now}
+		t.streaks[proposal] = streak
+	}
+	streak.lastUpdate = now
+}
+
+// forget drops the metrics of a deleted proposal, so that the series of the proposals learned by the agent
+// don't accumulate.
+func (t *proposalChurnTracker) forget(proposal types.NamespacedName) {
+	t.updates.DeleteLabelValues(proposal.Namespace, proposal.Name)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.streaks, proposal)
+}
+
+// proposalDeleted is the delete handler of the WorkloadPolicyProposal informer.
+func (t *proposalChurnTracker) proposalDeleted(obj any) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	proposal, ok := obj.(client.Object)
+	if !ok {
+		return
+	}
+	t.forget(client.ObjectKeyFromObject(proposal))
+}
+
+func (t *proposalChurnTracker) isUnstable(streak *proposalStreak, now time.Time) bool {
+	return now.Sub(streak.lastUpdate) <= proposalQuietPeriod &&
+		streak.lastUpdate.Sub(streak.start) >= t.window
+}
+
+func (t *proposalChurnTracker) Describe(ch chan<- *prometheus.Desc) {
+	t.updates.Describe(ch)
+	ch <- t.unstableDesc
+}
+
+func (t *proposalChurnTracker) Collect(ch chan<- prometheus.Metric) {
+	t.updates.Collect(ch)
+
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for proposal, streak := range t.streaks {
+		// a proposal that stopped changing is stable, we don't need to track it anymore.
+		if now.Sub(streak.lastUpdate) > proposalQuietPeriod {
+			delete(t.streaks, proposal)
+			continue
+		}
+		var value float64
+		if t.isUnstable(streak, now) {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(
+			t.unstableDesc,
+			prometheus.GaugeValue,
+			value,
+			proposal.Namespace,
+			proposal.Name,
+		)
+	}
+}
@@ -0,0 +1,78 @@
+package eventhandler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+func TestProposalChurnTracker(t *testing.T) {
+	proposal := types.NamespacedName{Namespace: "default", Name: "deploy-ubuntu"}
+	now := time.Now()
+	tracker := newProposalChurnTracker(time.Hour)
+	tracker.now = func() time.Time { return now }
+
+	unstableMetric := func(value string) string {
+		return `
+# HELP runtime_enforcer_learning_proposal_unstable Set to 1 when a WorkloadPolicyProposal kept learning new executables for longer than the stabilization window.
+# TYPE runtime_enforcer_learning_proposal_unstable gauge
+runtime_enforcer_learning_proposal_unstable{namespace="default",proposal="deploy-ubuntu"} ` + value + "\n"
+	}
+
+	t.Run("a new proposal is not unstable", func(t *testing.T) {
+		tracker.recordUpdate(proposal)
+		require.InDelta(t, 1, testutil.ToFloat64(tracker.updates.WithLabelValues("default", "deploy-ubuntu")), 0)
+		require.NoError(t, testutil.CollectAndCompare(tracker, strings.NewReader(unstableMetric("0")),
+			"runtime_enforcer_learning_proposal_unstable"))
+	})
+
+	t.Run("updates persisting beyond the window mark the proposal unstable", func(t *testing.T) {
+		// keep learning within the quiet period until we exceed the window.
+		for range 7 {
+			now = now.Add(proposalQuietPeriod - time.Second)
+			tracker.recordUpdate(proposal)
+		}
+		require.InDelta(t, 8, testutil.ToFloat64(tracker.updates.WithLabelValues("default", "deploy-ubuntu")), 0)
+		require.NoError(t, testutil.CollectAndCompare(tracker, strings.NewReader(unstableMetric("1")),
+			"runtime_enforcer_learning_proposal_unstable"))
+	})
+
+	t.Run("a quiet proposal is considered stable again", func(t *testing.T) {
+		now = now.Add(proposalQuietPeriod + time.Second)
+		require.Equal(t, 0, testutil.CollectAndCount(tracker, "runtime_enforcer_learning_proposal_unstable"))
+		require.Empty(t, tracker.streaks)
+
+		// a new update starts a new streak.
+		tracker.recordUpdate(proposal)
+		require.NoError(t, testutil.CollectAndCompare(tracker, strings.NewReader(unstableMetric("0")),
+			"runtime_enforcer_learning_proposal_unstable"))
+	})
+	t.Run("a deleted proposal is forgotten", func(t *testing.T) {
+		other := types.NamespacedName{Namespace: "default", Name: "deploy-other"}
+		tracker.recordUpdate(other)
+		require.Equal(t, 2, testutil.CollectAndCount(tracker, "runtime_enforcer_learning_proposal_updates_total"))
+
+		tracker.proposalDeleted(&securityv1alpha1.WorkloadPolicyProposal{
+			ObjectMeta: metav1.ObjectMeta{Namespace: proposal.Namespace, Name: proposal.Name},
+		})
+		require.Equal(t, 1, testutil.CollectAndCount(tracker, "runtime_enforcer_learning_proposal_updates_total"))
+		require.Equal(t, 1, testutil.CollectAndCount(tracker, "runtime_enforcer_learning_proposal_unstable"))
+		require.NotContains(t, tracker.streaks, proposal)
+
+		// the deletions missed by the informer are received as tombstones.
+		tracker.proposalDeleted(toolscache.DeletedFinalStateUnknown{
+			Key: other.String(),
+			Obj: &securityv1alpha1.WorkloadPolicyProposal{
+				ObjectMeta: metav1.ObjectMeta{Namespace: other.Namespace, Name: other.Name},
+			},
+		})
+		require.Equal(t, 0, testutil.CollectAndCount(tracker))
+	})
+}
@@ -5,26 +5,34 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
 	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/eventhandler/proposalutils"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/eventscraper"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/loglevel"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -40,6 +48,13 @@ const (
 	// The default ratelimiter setting from controller-runtime.
 	baseDelay = 5 * time.Millisecond
 	maxDelay  = 1000 * time.Second
+
+	// A proposal write conflicting with another agent is retried up to conflictRetrySteps times,
+	// 5ms * (2^0 + 2^1 + ... + 2^7) ~= 1.3s, before the reconcile is requeued.
+	conflictRetrySteps  = 8
+	conflictRetryFactor = 2
+	// conflictRetryJitter spreads the agents retrying the same proposal.
+	conflictRetryJitter = 0.5
 )
 
 type LearningReconciler struct {
@@ -51,13 +66,56 @@ type LearningReconciler struct {
 	// OwnerRefEnricher can be overridden during testing
 	OwnerRefEnricher func(wp *securityv1alpha1.WorkloadPolicyProposal, workloadKind string, workload string)
 	ratelimiter      workqueue.TypedRateLimiter[eventscraper.KubeProcessInfo]
+	conflictBackoff  wait.Backoff
+	churn            *proposalChurnTracker
+	overflowPolicy   ChannelOverflowPolicy
+	droppedEvents    prometheus.Counter
+	redactedPaths    *regexp.Regexp
+	redactedEvents   prometheus.Counter
+	approvalLabelKey string
+	// nodeName is added to the contributing nodes of the proposals learned by the reconciler.
+	nodeName string
+}
+
+type Option func(*LearningReconciler)
+
+// WithProposalStabilizationWindow sets how long a proposal can keep changing
+// before it is reported as never stabilized.
+func WithProposalStabilizationWindow(window time.Duration) Option {
+	return func(r *LearningReconciler) {
+		r.churn.window = window
+	}
+}
+
+// WithApprovalLabelKey sets the label marking the proposals as approved, learning stops on them.
+// It must match the label the controller promotes the proposals on.
+func WithApprovalLabelKey(key string) Option {
+	return func(r *LearningReconciler) {
+		r.approvalLabelKey = key
+	}
+}
+
+// WithConflictBackoff sets how the proposal writes conflicting with other agents are retried
+// before the reconcile is requeued.
+func WithConflictBackoff(backoff wait.Backoff) Option {
+	return func(r *LearningReconciler) {
+		r.conflictBackoff = backoff
+	}
+}
+
+// WithNodeName sets the node recorded in the contributing nodes of the proposals the agent learns into.
+func WithNodeName(nodeName string) Option {
+	return func(r *LearningReconciler) {
+		r.nodeName = nodeName
+	}
 }
 
 func NewLearningReconciler(
 	client client.Client,
 	selector labels.Selector,
+	opts ...Option,
 ) *LearningReconciler {
-	return &LearningReconciler{
+	r := &LearningReconciler{
 		Client: client,
 		eventChan: make(
 			chan event.TypedGenericEvent[eventscraper.KubeProcessInfo],
@@ -76,7 +134,22 @@ func NewLearningReconciler(
 			baseDelay,
 			maxDelay,
 		),
+		conflictBackoff: wait.Backoff{
+			Steps:    conflictRetrySteps,
+			Duration: baseDelay,
+			Factor:   conflictRetryFactor,
+			Jitter:   conflictRetryJitter,
+		},
+		churn:            newProposalChurnTracker(DefaultProposalStabilizationWindow),
+		overflowPolicy:   ChannelOverflowDrop,
+		droppedEvents:    newDroppedEventsCounter(),
+		redactedEvents:   newRedactedEventsCounter(),
+		approvalLabelKey: securityv1alpha1.ApprovalLabelKey,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
 // handleAdmissionError deals with the errors returned by our mutating webhook.
@@ -126,12 +199,48 @@ func (r *LearningReconciler) handleAdmissionError(logger logr.Logger, err error)
 // kubebuilder annotations for accessing policy proposals and namespaces.
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 // +kubebuilder:rbac:groups=security.rancher.io,resources=workloadpolicyproposals,verbs=create;get;list;watch;update;patch
-// +kubebuilder:rbac:groups=security.rancher.io,resources=workloadpolicies,verbs=list;watch
+// +kubebuilder:rbac:groups=security.rancher.io,resources=workloadpolicyproposals/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=security.rancher.io,resources=workloadpolicies,verbs=get;list;watch
+
+// proposalName returns the name of the proposal of the workload.
+// When the name is already taken by the proposal of another workload, a name suffixed with a hash
+// of the workload is used instead, so that the proposals don't overwrite each other.
+func (r *LearningReconciler) proposalName(ctx context.Context, req eventscraper.KubeProcessInfo) (string, error) {
+	proposalName, err := proposalutils.GetWorkloadPolicyProposalName(req.WorkloadKind, req.Workload)
+	if err != nil {
+		return "", reconcile.TerminalError(fmt.Errorf("failed to get proposal name: %w", err))
+	}
+
+	var existing securityv1alpha1.WorkloadPolicyProposal
+	err = r.Client.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: proposalName}, &existing)
+	if apierrors.IsNotFound(err) {
+		return proposalName, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get WorkloadPolicyProposal %s: %w", proposalName, err)
+	}
+	if proposalutils.IsProposalOfWorkload(&existing, req.WorkloadKind, req.Workload) {
+		return proposalName, nil
+	}
+
+	fallbackName, err := proposalutils.GetFallbackWorkloadPolicyProposalName(req.WorkloadKind, req.Workload)
+	if err != nil {
+		return "", reconcile.TerminalError(fmt.Errorf("failed to get proposal name: %w", err))
+	}
+	log.FromContext(ctx).V(loglevel.VerbosityDebug).Info(
+		"Proposal name already used by another workload",
+		"proposal", proposalName,
+		"owner", existing.OwnerReferences[0].Name,
+		"fallback", fallbackName,
+	)
+	return fallbackName, nil
+}
 
 // skipOrLearn decides whether to skip learning.
 //
 // Skip (true, nil) when:
-//   - req.PolicyName is set (pod already has security.rancher.io/policy).
+//   - req.PolicyName is set (pod already has security.rancher.io/policy), unless the policy is in monitor mode
+//     with an autoProtect referencing the proposal.
 //   - the proposal does not exist but a WorkloadPolicy with workloadpolicy.security.rancher.io/promoted-from=<proposalName> exists.
 //
 // Learn (false, nil) when:
@@ -145,7 +254,11 @@ func (r *LearningReconciler) skipOrLearn(
 ) (bool, error) {
 	logger := log.FromContext(ctx)
 
-	if req.PolicyName != "" {
+	autoProtected, err := r.isLearnedForAutoProtect(ctx, req, proposalName)
+	if err != nil {
+		return false, err
+	}
+	if req.PolicyName != "" && !autoProtected {
 		logger.V(3).Info( //nolint:mnd // 3 is the verbosity level for detailed debug info
 			"Ignoring learning event because pod is already bound to a WorkloadPolicy",
 			"workload", req.Workload,
@@ -155,7 +268,7 @@ func (r *LearningReconciler) skipOrLearn(
 		return true, nil
 	}
 
-	err := r.Client.Get(ctx, types.NamespacedName{
+	err = r.Client.Get(ctx, types.NamespacedName{
 		Namespace: req.Namespace,
 		Name:      proposalName,
 	}, policyProposal)
@@ -186,6 +299,29 @@ func (r *LearningReconciler) skipOrLearn(
 	return false, nil
 }
 
+// isLearnedForAutoProtect reports whether the pod of the event is bound to a WorkloadPolicy in monitor mode
+// switched to protect once the proposal stops changing, the executables of these pods are still learned.
+func (r *LearningReconciler) isLearnedForAutoProtect(
+	ctx context.Context,
+	req eventscraper.KubeProcessInfo,
+	proposalName string,
+) (bool, error) {
+	if req.PolicyName == "" {
+		return false, nil
+	}
+	var policy securityv1alpha1.WorkloadPolicy
+	err := r.Client.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: req.PolicyName}, &policy)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get WorkloadPolicy %s: %w", req.PolicyName, err)
+	}
+	autoProtect := policy.Spec.AutoProtect
+	return autoProtect != nil && autoProtect.Proposal == proposalName &&
+		policy.Spec.Mode == policymode.MonitorString, nil
+}
+
 // Reconcile maintains a retry mechanism with exponential backoff when processing learning events.
 func (r *LearningReconciler) Reconcile(
 	ctx context.Context,
@@ -195,7 +331,8 @@ func (r *LearningReconciler) Reconcile(
 
 	ret, err := r.reconcile(ctx, req)
 	if err != nil {
-		// We use our own ratelimiter to deal with by-design conflict errors.
+		// The conflicts are already retried a few times while writing, when they persist
+		// we use our own ratelimiter to deal with by-design conflict errors.
 		// We're totally fine with controller-runtime's ratelimiter by returning `Requeue: true`,
 		// but this field is deprecated, and we'd like to make sure that it won't retry forever.
 		// See also: https://github.com/kubernetes-sigs/controller-runtime/pull/3107
@@ -262,9 +399,9 @@ func (r *LearningReconciler) reconcile(
 		return ctrl.Result{}, nil
 	}
 
-	proposalName, err = proposalutils.GetWorkloadPolicyProposalName(req.WorkloadKind, req.Workload)
+	proposalName, err = r.proposalName(ctx, req)
 	if err != nil {
-		return ctrl.Result{}, reconcile.TerminalError(fmt.Errorf("failed to get proposal name: %w", err))
+		return ctrl.Result{}, err
 	}
 
 	policyProposal := &securityv1alpha1.WorkloadPolicyProposal{
@@ -279,13 +416,42 @@ func (r *LearningReconciler) reconcile(
 		return ctrl.Result{}, err
 	}
 
-	if _, err = controllerutil.CreateOrUpdate(ctx, r.Client, policyProposal, func() error {
+	// Several agents learn the same workload, so their writes conflict by design. They are retried here
+	// with a fresh copy of the proposal, the reconcile is only requeued if they keep conflicting.
+	err = retry.OnError(r.conflictBackoff, isWriteConflict, func() error {
+		return r.learnProcess(ctx, logger, req, client.ObjectKeyFromObject(policyProposal))
+	})
+	return ctrl.Result{}, err
+}
+
+// isWriteConflict reports whether the proposal was written concurrently by another agent.
+// Two agents can also race to create it, the loser then gets an already exists error.
+func isWriteConflict(err error) bool {
+	return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+}
+
+// learnProcess adds the executable of the event to the proposal, creating the proposal if needed.
+func (r *LearningReconciler) learnProcess(
+	ctx context.Context,
+	logger logr.Logger,
+	req eventscraper.KubeProcessInfo,
+	key client.ObjectKey,
+) error {
+	policyProposal := &securityv1alpha1.WorkloadPolicyProposal{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+		},
+	}
+	learning := false
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, policyProposal, func() error {
 		// We don't learn any new process if the policy proposal was promoted
 		// to an actual policy
 		labels := policyProposal.GetLabels()
-		if labels[securityv1alpha1.ApprovalLabelKey] == "true" {
+		if labels[r.approvalLabelKey] == "true" {
 			return nil
 		}
+		learning = true
 
 		if policyProposal.IsFull() {
 			logger.Info("proposal is full, cannot add new executables",
@@ -305,14 +471,45 @@ func (r *LearningReconciler) reconcile(
 		// if we don't populate a partial owner reference here the webhook won't be able to populate the owner reference because it doesn't know who is the owner.
 		r.OwnerRefEnricher(policyProposal, req.WorkloadKind, req.Workload)
 		return nil
-	}); err != nil {
-		return ctrl.Result{}, r.handleAdmissionError(logger, err)
+	})
+	if err != nil {
+		return r.handleAdmissionError(logger, err)
 	}
-	return ctrl.Result{}, nil
+	if op != controllerutil.OperationResultNone {
+		r.churn.recordUpdate(client.ObjectKeyFromObject(policyProposal))
+	}
+
+	// The status is a subresource, so it cannot be updated together with the spec.
+	countsChanged := policyProposal.UpdateProcessCounts()
+	commonChanged := policyProposal.UpdateCommonExecutables()
+	nodeAdded := learning && policyProposal.AddContributingNode(r.nodeName)
+	if countsChanged || commonChanged || nodeAdded {
+		if err = r.Client.Status().Update(ctx, policyProposal); err != nil {
+			return fmt.Errorf("failed to update WorkloadPolicyProposal status: %w", err)
+		}
+	}
+	return nil
 }
 
+// EnqueueEvent sends the event to the reconciler.
+// Events with a redacted executable path are dropped here, before they reach any log or proposal.
+// When the event channel is full, the event is dropped or the call blocks depending on the overflow policy.
 func (r *LearningReconciler) EnqueueEvent(evt eventscraper.KubeProcessInfo) {
-	r.eventChan <- event.TypedGenericEvent[eventscraper.KubeProcessInfo]{Object: evt}
+	if r.isRedacted(evt.ExecutablePath) {
+		r.redactedEvents.Inc()
+		return
+	}
+	genericEvt := event.TypedGenericEvent[eventscraper.KubeProcessInfo]{Object: evt}
+	switch r.overflowPolicy {
+	case ChannelOverflowBlock:
+		r.eventChan <- genericEvt
+	case ChannelOverflowDrop:
+		select {
+		case r.eventChan <- genericEvt:
+		default:
+			r.droppedEvents.Inc()
+		}
+	}
 }
 
 // ProcessEventHandler implements handler.TypedEventHandler[eventscraper.KubeProcessInfo, eventscraper.KubeProcessInfo].
@@ -353,6 +550,25 @@ func (e ProcessEventHandler) Generic(
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *LearningReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := metrics.Registry.Register(r.churn); err != nil {
+		return fmt.Errorf("failed to register learning metrics: %w", err)
+	}
+	if err := metrics.Registry.Register(r.droppedEvents); err != nil {
+		return fmt.Errorf("failed to register learning metrics: %w", err)
+	}
+	if err := metrics.Registry.Register(r.redactedEvents); err != nil {
+		return fmt.Errorf("failed to register learning metrics: %w", err)
+	}
+	proposals, err := mgr.GetCache().GetInformer(context.Background(), &securityv1alpha1.WorkloadPolicyProposal{},
+		cache.BlockUntilSynced(false))
+	if err != nil {
+		return fmt.Errorf("failed to get the WorkloadPolicyProposal informer: %w", err)
+	}
+	if _, err = proposals.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		DeleteFunc: r.churn.proposalDeleted,
+	}); err != nil {
+		return fmt.Errorf("failed to watch the WorkloadPolicyProposal deletions: %w", err)
+	}
 	return builder.TypedControllerManagedBy[eventscraper.KubeProcessInfo](mgr).
 		Named("learningEvent").
 		WatchesRawSource(
@@ -3,6 +3,7 @@ package eventhandler_test
 import (
 	"context"
 	"fmt"
+	"time"
 
 	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/eventhandler"
@@ -18,13 +19,18 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-func newTestLearningReconciler(client client.Client, selector labels.Selector) *eventhandler.LearningReconciler {
-	reconciler := eventhandler.NewLearningReconciler(client, selector)
+func newTestLearningReconciler(
+	client client.Client,
+	selector labels.Selector,
+	opts ...eventhandler.Option,
+) *eventhandler.LearningReconciler {
+	reconciler := eventhandler.NewLearningReconciler(client, selector, opts...)
 	// we don't want owner references to be added in tests because the webhook won't complete it and the api server will reject the resource creation with a partial ownerReference.
 	reconciler.OwnerRefEnricher = func(_ *securityv1alpha1.WorkloadPolicyProposal, _ string, _ string) {}
 	return reconciler
@@ -106,6 +112,13 @@ var _ = Describe("Learning", func() {
 			// The test case here is pretty lenient to prevent tests from broken randomly.
 			const workerNum = 10
 			const eventsToProcessNum = 10
+			// every worker can lose the race for each write against all the others, the conflicts are retried
+			// as many times as needed for each event to be learned without any requeue.
+			conflictBackoff := wait.Backoff{
+				Steps:    workerNum * eventsToProcessNum,
+				Duration: time.Millisecond,
+				Jitter:   1,
+			}
 
 			eventsToProcess := []eventscraper.KubeProcessInfo{}
 			expectedAllowList := []string{}
@@ -149,18 +162,16 @@ var _ = Describe("Learning", func() {
 						return fmt.Errorf("failed to create client: %w", err)
 					}
 
-					reconciler := newTestLearningReconciler(perWorkerClient, defaultNamespaceSelector)
+					reconciler := newTestLearningReconciler(perWorkerClient, defaultNamespaceSelector,
+						eventhandler.WithConflictBackoff(conflictBackoff))
 					for _, learningEvent := range eventsToProcess {
-						for {
-							// with the internal ratelimiter, the learning controller would return RequeueAfter instead of a conflict error.
-							ret, err = reconciler.Reconcile(groupCtx, learningEvent)
-							if err == nil && ret.RequeueAfter == 0 {
-								// This means the item is correctly written.
-								break
-							}
-							if err != nil {
-								return err
-							}
+						// the conflicts with the other workers are retried by the reconciler itself.
+						ret, err = reconciler.Reconcile(groupCtx, learningEvent)
+						if err != nil {
+							return err
+						}
+						if ret.RequeueAfter != 0 {
+							return fmt.Errorf("%s: %s was requeued", name, learningEvent.ExecutablePath)
 						}
 					}
 					logf.Log.Info("worker finished", "name", name)
@@ -189,6 +200,7 @@ var _ = Describe("Learning", func() {
 
 			Expect(rules.Executables.Allowed).To(HaveLen(eventsToProcessNum))
 			Expect(rules.Executables.Allowed).To(ContainElements(expectedAllowList))
+			Expect(proposalResult.Status.ProcessCountByContainer).To(HaveKeyWithValue("ubuntu", eventsToProcessNum))
 		})
 
 		It("should correctly learn process behavior", func() {
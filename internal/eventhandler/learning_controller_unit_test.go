@@ -1,13 +1,34 @@
 package eventhandler
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/eventhandler/proposalutils"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/eventscraper"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/podworkload"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 func TestHandleAdmissionError(t *testing.T) {
@@ -61,3 +82,416 @@ func TestHandleAdmissionError(t *testing.T) {
 		assert.ErrorIs(t, err, plainErr, "expected returned error to wrap original plain error")
 	})
 }
+
+func TestLearningReconcilerProposalName(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, securityv1alpha1.AddToScheme(scheme))
+	existing := &securityv1alpha1.WorkloadPolicyProposal{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "deploy-foo",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "foo"}},
+		},
+	}
+	r := &LearningReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build(),
+	}
+
+	t.Run("uses the regular name when the proposal belongs to the workload", func(t *testing.T) {
+		name, err := r.proposalName(t.Context(), eventscraper.KubeProcessInfo{
+			Namespace: "default", Workload: "foo", WorkloadKind: "Deployment",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "deploy-foo", name)
+	})
+
+	t.Run("uses the regular name when there is no proposal", func(t *testing.T) {
+		name, err := r.proposalName(t.Context(), eventscraper.KubeProcessInfo{
+			Namespace: "default", Workload: "bar", WorkloadKind: "Deployment",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "deploy-bar", name)
+	})
+
+	t.Run("uses the fallback name when the proposal belongs to another workload", func(t *testing.T) {
+		// another Deployment already owns the proposal named after "foo".
+		existing.OwnerReferences[0].Name = "foo-with-a-longer-name"
+		require.NoError(t, r.Client.Update(t.Context(), existing))
+
+		name, err := r.proposalName(t.Context(), eventscraper.KubeProcessInfo{
+			Namespace: "default", Workload: "foo", WorkloadKind: "Deployment",
+		})
+		require.NoError(t, err)
+		fallback, err := proposalutils.GetFallbackWorkloadPolicyProposalName("Deployment", "foo")
+		require.NoError(t, err)
+		assert.Equal(t, fallback, name)
+	})
+}
+
+func TestEnqueueEventOverflow(t *testing.T) {
+	newReconciler := func(policy ChannelOverflowPolicy) *LearningReconciler {
+		r := NewLearningReconciler(nil, labels.Everything(), WithChannelOverflowPolicy(policy))
+		r.eventChan = make(chan event.TypedGenericEvent[eventscraper.KubeProcessInfo], 1)
+		return r
+	}
+	evt := func(exe string) eventscraper.KubeProcessInfo {
+		return eventscraper.KubeProcessInfo{Namespace: "ns", Workload: "wl", ExecutablePath: exe}
+	}
+
+	t.Run("drop", func(t *testing.T) {
+		r := newReconciler(ChannelOverflowDrop)
+		r.EnqueueEvent(evt("/bin/first"))
+		// the channel is full, the event is dropped without blocking.
+		r.EnqueueEvent(evt("/bin/second"))
+
+		require.InDelta(t, 1, promtestutil.ToFloat64(r.droppedEvents), 0)
+		require.Len(t, r.eventChan, 1)
+		require.Equal(t, "/bin/first", (<-r.eventChan).Object.ExecutablePath)
+	})
+
+	t.Run("block", func(t *testing.T) {
+		r := newReconciler(ChannelOverflowBlock)
+		r.EnqueueEvent(evt("/bin/first"))
+
+		done := make(chan struct{})
+		go func() {
+			r.EnqueueEvent(evt("/bin/second"))
+			close(done)
+		}()
+		select {
+		case <-done:
+			t.Fatal("EnqueueEvent returned while the channel was full")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		// no event is lost once the reconciler makes room in the channel.
+		require.Equal(t, "/bin/first", (<-r.eventChan).Object.ExecutablePath)
+		<-done
+		require.Equal(t, "/bin/second", (<-r.eventChan).Object.ExecutablePath)
+		require.InDelta(t, 0, promtestutil.ToFloat64(r.droppedEvents), 0)
+	})
+}
+
+func TestParseChannelOverflowPolicy(t *testing.T) {
+	policy, err := ParseChannelOverflowPolicy("block")
+	require.NoError(t, err)
+	require.Equal(t, ChannelOverflowBlock, policy)
+
+	policy, err = ParseChannelOverflowPolicy("drop")
+	require.NoError(t, err)
+	require.Equal(t, ChannelOverflowDrop, policy)
+
+	_, err = ParseChannelOverflowPolicy("discard")
+	require.Error(t, err)
+}
+
+func TestEnqueueEventRedactedPaths(t *testing.T) {
+	r := NewLearningReconciler(nil, labels.Everything(), WithRedactedPaths(regexp.MustCompile(`^/tmp/.*token`)))
+	r.EnqueueEvent(eventscraper.KubeProcessInfo{Namespace: "ns", Workload: "wl", ExecutablePath: "/tmp/run-token-abc"})
+	r.EnqueueEvent(eventscraper.KubeProcessInfo{Namespace: "ns", Workload: "wl", ExecutablePath: "/usr/bin/ls"})
+
+	require.InDelta(t, 1, promtestutil.ToFloat64(r.redactedEvents), 0)
+	require.Len(t, r.eventChan, 1)
+	require.Equal(t, "/usr/bin/ls", (<-r.eventChan).Object.ExecutablePath)
+}
+
+func TestLearningReconcilerTruncatedWorkloadContainerNames(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, securityv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}).
+		WithStatusSubresource(&securityv1alpha1.WorkloadPolicyProposal{}).
+		Build()
+	r := NewLearningReconciler(cl, labels.Everything())
+
+	// the deployment name is too long to be recovered from the pod name.
+	podName := "ubuntu-deploymenttttttttttttttttttttttttttttttttttttttttttq8fcg"
+	workload, kind, truncated := podworkload.GetTruncatedWorkloadInfo(podName,
+		map[string]string{"pod-template-hash": "674bcc58f4"})
+	require.True(t, truncated)
+
+	for _, evt := range []struct{ container, exe string }{
+		{"nginx", "/usr/sbin/nginx"},
+		{"log-shipper", "/usr/bin/fluent-bit"},
+		{"nginx", "/bin/sh"},
+	} {
+		_, err := r.Reconcile(t.Context(), eventscraper.KubeProcessInfo{
+			Namespace:      "default",
+			Workload:       workload,
+			WorkloadKind:   string(kind),
+			ContainerName:  evt.container,
+			ExecutablePath: evt.exe,
+			PodName:        podName,
+		})
+		require.NoError(t, err)
+	}
+
+	proposalName, err := proposalutils.GetWorkloadPolicyProposalName(string(kind), workload)
+	require.NoError(t, err)
+	var proposal securityv1alpha1.WorkloadPolicyProposal
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKey{Namespace: "default", Name: proposalName}, &proposal))
+
+	// the rules are keyed by the real container names, so that the promoted policy applies to them.
+	spec := proposal.Spec.IntoWorkloadPolicySpec()
+	require.ElementsMatch(t, []string{"nginx", "log-shipper"}, slices.Collect(maps.Keys(spec.RulesByContainer)))
+	require.Equal(t, []string{"/usr/sbin/nginx", "/bin/sh"}, spec.RulesByContainer["nginx"].Executables.Allowed)
+	require.Equal(t, []string{"/usr/bin/fluent-bit"}, spec.RulesByContainer["log-shipper"].Executables.Allowed)
+}
+
+func TestLearningReconcilerCustomApprovalLabelKey(t *testing.T) {
+	const approvalLabelKey = "example.com/approved"
+	scheme := runtime.NewScheme()
+	require.NoError(t, securityv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	proposal := &securityv1alpha1.WorkloadPolicyProposal{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "deploy-ubuntu",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "ubuntu"}},
+			// the default approval label is ignored once another one is configured.
+			Labels: map[string]string{securityv1alpha1.ApprovalLabelKey: "true"},
+		},
+	}
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}, proposal).
+		WithStatusSubresource(&securityv1alpha1.WorkloadPolicyProposal{}).
+		Build()
+	r := NewLearningReconciler(cl, labels.Everything(), WithApprovalLabelKey(approvalLabelKey))
+
+	learn := func(exe string) {
+		_, err := r.Reconcile(t.Context(), eventscraper.KubeProcessInfo{
+			Namespace:      "default",
+			Workload:       "ubuntu",
+			WorkloadKind:   "Deployment",
+			ContainerName:  "ubuntu",
+			ExecutablePath: exe,
+		})
+		require.NoError(t, err)
+	}
+	learn("/usr/bin/sleep")
+
+	var learned securityv1alpha1.WorkloadPolicyProposal
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(proposal), &learned))
+	require.Equal(t, []string{"/usr/bin/sleep"}, learned.Spec.RulesByContainer["ubuntu"].Executables.Allowed)
+
+	// once approved with the configured label, learning stops.
+	learned.Labels[approvalLabelKey] = "true"
+	require.NoError(t, cl.Update(t.Context(), &learned))
+	learn("/usr/bin/bash")
+
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(proposal), &learned))
+	require.Equal(t, []string{"/usr/bin/sleep"}, learned.Spec.RulesByContainer["ubuntu"].Executables.Allowed)
+}
+
+func TestLearningReconcilerRetriesConflicts(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, securityv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	proposal := &securityv1alpha1.WorkloadPolicyProposal{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "deploy-ubuntu",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "ubuntu"}},
+		},
+	}
+
+	// the first update conflicts with another agent learning a different executable.
+	updates := 0
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}, proposal).
+		WithStatusSubresource(&securityv1alpha1.WorkloadPolicyProposal{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				updates++
+				if updates > 1 {
+					return c.Update(ctx, obj, opts...)
+				}
+				var concurrent securityv1alpha1.WorkloadPolicyProposal
+				require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(obj), &concurrent))
+				concurrent.AddProcess("ubuntu", "/usr/bin/other")
+				require.NoError(t, c.Update(ctx, &concurrent))
+				return apierrors.NewConflict(
+					securityv1alpha1.GroupVersion.WithResource("workloadpolicyproposals").GroupResource(),
+					obj.GetName(),
+					errors.New("the object has been modified"),
+				)
+			},
+		}).
+		Build()
+	r := NewLearningReconciler(cl, labels.Everything())
+
+	ret, err := r.Reconcile(t.Context(), eventscraper.KubeProcessInfo{
+		Namespace:      "default",
+		Workload:       "ubuntu",
+		WorkloadKind:   "Deployment",
+		ContainerName:  "ubuntu",
+		ExecutablePath: "/usr/bin/sleep",
+	})
+	require.NoError(t, err)
+	require.Zero(t, ret.RequeueAfter)
+	require.Equal(t, 2, updates)
+
+	var learned securityv1alpha1.WorkloadPolicyProposal
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(proposal), &learned))
+	require.Equal(t, []string{"/usr/bin/other", "/usr/bin/sleep"}, learned.Spec.RulesByContainer["ubuntu"].Executables.Allowed)
+	require.Equal(t, map[string]int{"ubuntu": 2}, learned.Status.ProcessCountByContainer)
+}
+
+func TestLearningReconcilerConcurrentReplicasWithDifferentContainers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, securityv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}).
+		WithStatusSubresource(&securityv1alpha1.WorkloadPolicyProposal{}).
+		Build()
+
+	// during a rolling update the old replicas run the sidecar-v1 container and the new ones sidecar-v2.
+	replicas := [][]string{
+		{"app", "sidecar-v1"},
+		{"app", "sidecar-v1"},
+		{"app", "sidecar-v2"},
+		{"app", "sidecar-v2"},
+	}
+	const executablesNum = 5
+	expected := map[string][]string{}
+	for _, containers := range replicas {
+		for _, container := range containers {
+			if _, ok := expected[container]; ok {
+				continue
+			}
+			for i := range executablesNum {
+				expected[container] = append(expected[container], fmt.Sprintf("/usr/bin/%s-%d", container, i))
+			}
+		}
+	}
+
+	g, ctx := errgroup.WithContext(t.Context())
+	for _, containers := range replicas {
+		r := NewLearningReconciler(cl, labels.Everything())
+		g.Go(func() error {
+			for i := range executablesNum {
+				for _, container := range containers {
+					evt := eventscraper.KubeProcessInfo{
+						Namespace:      "default",
+						Workload:       "ubuntu",
+						WorkloadKind:   "Deployment",
+						ContainerName:  container,
+						ExecutablePath: fmt.Sprintf("/usr/bin/%s-%d", container, i),
+					}
+					// the event is requeued when the conflicts persist, like controller-runtime would do.
+					for {
+						ret, err := r.Reconcile(ctx, evt)
+						if err != nil {
+							return err
+						}
+						if ret.RequeueAfter == 0 {
+							break
+						}
+					}
+				}
+			}
+			return nil
+		})
+	}
+	require.NoError(t, g.Wait())
+
+	var proposal securityv1alpha1.WorkloadPolicyProposal
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKey{Namespace: "default", Name: "deploy-ubuntu"}, &proposal))
+	require.Len(t, proposal.Spec.RulesByContainer, len(expected))
+	for container, executables := range expected {
+		require.ElementsMatch(t, executables, proposal.Spec.RulesByContainer[container].Executables.Allowed, container)
+		require.Equal(t, executablesNum, proposal.Status.ProcessCountByContainer[container], container)
+	}
+}
+
+func TestLearningReconcilerContributingNodes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, securityv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	proposal := &securityv1alpha1.WorkloadPolicyProposal{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "deploy-ubuntu",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "ubuntu"}},
+		},
+	}
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}, proposal).
+		WithStatusSubresource(&securityv1alpha1.WorkloadPolicyProposal{}).
+		Build()
+
+	learn := func(nodeName, exe string) securityv1alpha1.WorkloadPolicyProposal {
+		r := NewLearningReconciler(cl, labels.Everything(), WithNodeName(nodeName))
+		_, err := r.Reconcile(t.Context(), eventscraper.KubeProcessInfo{
+			Namespace:      "default",
+			Workload:       "ubuntu",
+			WorkloadKind:   "Deployment",
+			ContainerName:  "ubuntu",
+			ExecutablePath: exe,
+		})
+		require.NoError(t, err)
+		var learned securityv1alpha1.WorkloadPolicyProposal
+		require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(proposal), &learned))
+		return learned
+	}
+
+	require.Equal(t, []string{"node-b"}, learn("node-b", "/usr/bin/sleep").Status.ContributingNodes)
+	// a node reporting an executable already learned contributes too.
+	require.Equal(t, []string{"node-a", "node-b"}, learn("node-a", "/usr/bin/sleep").Status.ContributingNodes)
+	require.Equal(t, []string{"node-a", "node-b"}, learn("node-b", "/usr/bin/bash").Status.ContributingNodes)
+
+	// the nodes reporting after the approval are not recorded.
+	learned := learn("node-b", "/usr/bin/ls")
+	learned.Labels = map[string]string{securityv1alpha1.ApprovalLabelKey: "true"}
+	require.NoError(t, cl.Update(t.Context(), &learned))
+	require.Equal(t, []string{"node-a", "node-b"}, learn("node-c", "/usr/bin/cat").Status.ContributingNodes)
+}
+
+func TestLearningReconcilerAutoProtectPolicy(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, securityv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	policy := &securityv1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "ubuntu", Namespace: "default"},
+		Spec: securityv1alpha1.WorkloadPolicySpec{
+			Mode:        "monitor",
+			AutoProtect: &securityv1alpha1.WorkloadPolicyAutoProtect{Proposal: "deploy-ubuntu", StableObservations: 1},
+		},
+	}
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}, policy).
+		WithStatusSubresource(&securityv1alpha1.WorkloadPolicyProposal{}).
+		Build()
+	r := NewLearningReconciler(cl, labels.Everything())
+
+	learn := func(exe string) []string {
+		_, err := r.Reconcile(t.Context(), eventscraper.KubeProcessInfo{
+			Namespace:      "default",
+			Workload:       "ubuntu",
+			WorkloadKind:   "Deployment",
+			ContainerName:  "ubuntu",
+			ExecutablePath: exe,
+			PolicyName:     policy.Name,
+		})
+		require.NoError(t, err)
+		var learned securityv1alpha1.WorkloadPolicyProposal
+		require.NoError(t, cl.Get(t.Context(), client.ObjectKey{Namespace: "default", Name: "deploy-ubuntu"}, &learned))
+		return learned.Spec.RulesByContainer["ubuntu"].Executables.Allowed
+	}
+
+	// the pods bound to a policy waiting to be switched to protect are still learned.
+	require.Equal(t, []string{"/usr/bin/sleep"}, learn("/usr/bin/sleep"))
+
+	// once protected, they are not learned anymore.
+	policy.Spec.Mode = "protect"
+	require.NoError(t, cl.Update(t.Context(), policy))
+	require.Equal(t, []string{"/usr/bin/sleep"}, learn("/usr/bin/bash"))
+}
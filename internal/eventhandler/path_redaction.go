@@ -0,0 +1,27 @@
+package eventhandler
+
+import (
+	"regexp"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// WithRedactedPaths drops the learning events whose executable path matches the pattern,
+// so that paths carrying sensitive data are never persisted into a WorkloadPolicyProposal.
+func WithRedactedPaths(pattern *regexp.Regexp) Option {
+	return func(r *LearningReconciler) {
+		r.redactedPaths = pattern
+	}
+}
+
+// isRedacted reports whether the executable path must not be learned.
+func (r *LearningReconciler) isRedacted(path string) bool {
+	return r.redactedPaths != nil && r.redactedPaths.MatchString(path)
+}
+
+func newRedactedEventsCounter() prometheus.Counter {
+	return prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "runtime_enforcer_learning_events_redacted_total",
+		Help: "Number of learning events dropped because their executable path matches the redaction pattern.",
+	})
+}
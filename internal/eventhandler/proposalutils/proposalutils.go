@@ -2,7 +2,10 @@ package proposalutils
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strings"
 
 	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/workloadkind"
@@ -35,8 +38,29 @@ func getKindShortName(kind string) (string, error) {
 	return shortname, nil
 }
 
+// proposalNameHashLen is the length of the hash appended to the proposal names that need to be disambiguated.
+const proposalNameHashLen = 8
+
+// workloadHash returns a short hash identifying the workload.
+func workloadHash(kind string, resourceName string) string {
+	sum := sha256.Sum256([]byte(kind + "/" + resourceName))
+	return hex.EncodeToString(sum[:])[:proposalNameHashLen]
+}
+
+// withHashSuffix appends the hash to the name, truncating the name so that the result fits in the k8s max name length.
+func withHashSuffix(name string, hash string) string {
+	maxLen := validation.DNS1123SubdomainMaxLength - len(hash) - 1
+	if len(name) > maxLen {
+		// a DNS subdomain label can't end with a '-' or a '.'
+		name = strings.TrimRight(name[:maxLen], "-.")
+	}
+	return name + "-" + hash
+}
+
 // GetWorkloadPolicyProposalName returns the name of WorkloadPolicyProposal
 // based on a high level resource and its name.
+// Names exceeding the maximum length are truncated and suffixed with a hash of the workload,
+// so that distinct workloads sharing the same prefix get distinct proposals.
 func GetWorkloadPolicyProposalName(kind string, resourceName string) (string, error) {
 	var shortname string
 	var err error
@@ -47,10 +71,34 @@ func GetWorkloadPolicyProposalName(kind string, resourceName string) (string, er
 
 	// The max name length in k8s
 	if len(ret) > validation.DNS1123SubdomainMaxLength {
-		return "", fmt.Errorf("the name %s exceeds the maximum name length", ret)
+		return withHashSuffix(ret, workloadHash(kind, resourceName)), nil
 	}
 
-	return shortname + "-" + resourceName, nil
+	return ret, nil
+}
+
+// GetFallbackWorkloadPolicyProposalName returns the name of WorkloadPolicyProposal to use when the name
+// returned by GetWorkloadPolicyProposalName is already taken by the proposal of another workload.
+func GetFallbackWorkloadPolicyProposalName(kind string, resourceName string) (string, error) {
+	shortname, err := getKindShortName(kind)
+	if err != nil {
+		return "", err
+	}
+	return withHashSuffix(shortname+"-"+resourceName, workloadHash(kind, resourceName)), nil
+}
+
+// IsProposalOfWorkload reports whether the proposal was generated for the given workload.
+// A proposal without owner reference is considered to belong to the workload.
+func IsProposalOfWorkload(
+	proposal *securityv1alpha1.WorkloadPolicyProposal,
+	kind string,
+	resourceName string,
+) bool {
+	if len(proposal.OwnerReferences) == 0 {
+		return true
+	}
+	owner := proposal.OwnerReferences[0]
+	return owner.Kind == kind && owner.Name == resourceName
 }
 
 func HasProposalBeenPromoted(
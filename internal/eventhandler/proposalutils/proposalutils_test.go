@@ -2,6 +2,7 @@ package proposalutils_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
@@ -10,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -71,6 +73,60 @@ func TestGetWorkloadPolicyProposalName(t *testing.T) {
 	}
 }
 
+func TestGetWorkloadPolicyProposalNameTruncation(t *testing.T) {
+	// both names share the same first 253 characters, so a plain truncation would collide.
+	prefix := strings.Repeat("a", validation.DNS1123SubdomainMaxLength)
+	first, err := proposalutils.GetWorkloadPolicyProposalName("Deployment", prefix+"-first")
+	require.NoError(t, err)
+	second, err := proposalutils.GetWorkloadPolicyProposalName("Deployment", prefix+"-second")
+	require.NoError(t, err)
+	otherKind, err := proposalutils.GetWorkloadPolicyProposalName("DaemonSet", prefix+"-first")
+	require.NoError(t, err)
+
+	for _, name := range []string{first, second, otherKind} {
+		assert.Empty(t, validation.IsDNS1123Subdomain(name))
+	}
+	assert.NotEqual(t, first, second)
+	assert.NotEqual(t, first, otherKind)
+
+	// the name is stable across calls.
+	again, err := proposalutils.GetWorkloadPolicyProposalName("Deployment", prefix+"-first")
+	require.NoError(t, err)
+	assert.Equal(t, first, again)
+
+	// a truncation ending with a separator still gives a valid name.
+	dotted, err := proposalutils.GetWorkloadPolicyProposalName("Deployment", strings.Repeat("a.", 200))
+	require.NoError(t, err)
+	assert.Empty(t, validation.IsDNS1123Subdomain(dotted))
+}
+
+func TestGetFallbackWorkloadPolicyProposalName(t *testing.T) {
+	name, err := proposalutils.GetWorkloadPolicyProposalName("Deployment", "foo")
+	require.NoError(t, err)
+	fallback, err := proposalutils.GetFallbackWorkloadPolicyProposalName("Deployment", "foo")
+	require.NoError(t, err)
+	assert.NotEqual(t, name, fallback)
+	assert.True(t, strings.HasPrefix(fallback, name+"-"))
+
+	_, err = proposalutils.GetFallbackWorkloadPolicyProposalName("UnknownKind", "foo")
+	assert.Error(t, err)
+}
+
+func TestIsProposalOfWorkload(t *testing.T) {
+	proposal := &securityv1alpha1.WorkloadPolicyProposal{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "deploy-foo",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "foo"}},
+		},
+	}
+	assert.True(t, proposalutils.IsProposalOfWorkload(proposal, "Deployment", "foo"))
+	assert.False(t, proposalutils.IsProposalOfWorkload(proposal, "Deployment", "bar"))
+	assert.False(t, proposalutils.IsProposalOfWorkload(proposal, "DaemonSet", "foo"))
+
+	proposal.OwnerReferences = nil
+	assert.True(t, proposalutils.IsProposalOfWorkload(proposal, "Deployment", "bar"))
+}
+
 func TestHasProposalBeenPromoted(t *testing.T) {
 	const (
 		defaultNamespace = "default"
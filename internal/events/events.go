@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/rancher-sandbox/runtime-enforcer/internal/tlsutil"
@@ -33,6 +34,28 @@ func stringToProtocol(s string) (protocol, error) {
 	}
 }
 
+// ParseHeaders parses a list of OTLP headers in the "key1=value1,key2=value2" format
+// used by the OTEL_EXPORTER_OTLP_HEADERS env var. Values can be URL encoded.
+func ParseHeaders(s string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for entry := range strings.SplitSeq(s, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		key, value, found := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid header %q: expected key=value", entry)
+		}
+		decoded, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for header %q: %w", key, err)
+		}
+		headers[key] = decoded
+	}
+	return headers, nil
+}
+
 func buildTLSConfig(caCertPath, clientCertPath, clientKeyPath string) (*tls.Config, error) {
 	// Validate that the CA certificate is readable at startup.
 	if _, err := tlsutil.LoadCACertPool(caCertPath); err != nil {
@@ -72,6 +95,7 @@ func buildTLSConfig(caCertPath, clientCertPath, clientKeyPath string) (*tls.Conf
 
 func createGRPCExporter(ctx context.Context,
 	endpoint, caCertPath, clientCertPath, clientKeyPath string,
+	headers map[string]string,
 ) (sdklog.Exporter, error) {
 	// if the user specified the correct path we shouldn't receive the http prefix here, but just to be sure.
 	gRPCEndpoint := strings.TrimPrefix(strings.TrimPrefix(endpoint, "https://"), "http://")
@@ -79,6 +103,9 @@ func createGRPCExporter(ctx context.Context,
 	opts := []otlploggrpc.Option{
 		otlploggrpc.WithEndpoint(gRPCEndpoint),
 	}
+	if len(headers) > 0 {
+		opts = append(opts, otlploggrpc.WithHeaders(headers))
+	}
 	if insecure {
 		opts = append(opts, otlploggrpc.WithInsecure())
 	} else {
@@ -93,6 +120,7 @@ func createGRPCExporter(ctx context.Context,
 
 func createHTTPExporter(ctx context.Context,
 	endpoint, caCertPath, clientCertPath, clientKeyPath string,
+	headers map[string]string,
 ) (sdklog.Exporter, error) {
 	// first we check if we are in insecure mode
 	insecure := strings.HasPrefix(endpoint, "http://")
@@ -102,6 +130,9 @@ func createHTTPExporter(ctx context.Context,
 	opts := []otlploghttp.Option{
 		otlploghttp.WithEndpoint(httpEndpoint),
 	}
+	if len(headers) > 0 {
+		opts = append(opts, otlploghttp.WithHeaders(headers))
+	}
 
 	if insecure {
 		opts = append(opts, otlploghttp.WithInsecure())
@@ -121,9 +152,11 @@ func createHTTPExporter(ctx context.Context,
 // certificate against the provided CA; otherwise insecure mode is used.
 // When clientCertPath and clientKeyPath are both non-empty, the client
 // presents a TLS certificate for mTLS authentication.
+// The headers, e.g. authentication tokens, are sent with every export request.
 func Init(
 	ctx context.Context,
 	endpoint, caCertPath, clientCertPath, clientKeyPath, protocol string,
+	headers map[string]string,
 ) (otellog.Logger, func(context.Context) error, error) {
 	var exporter sdklog.Exporter
 	proto, err := stringToProtocol(protocol)
@@ -132,9 +165,9 @@ func Init(
 	}
 	switch proto {
 	case protocolGRPC:
-		exporter, err = createGRPCExporter(ctx, endpoint, caCertPath, clientCertPath, clientKeyPath)
+		exporter, err = createGRPCExporter(ctx, endpoint, caCertPath, clientCertPath, clientKeyPath, headers)
 	case protocolHTTPProtobuf:
-		exporter, err = createHTTPExporter(ctx, endpoint, caCertPath, clientCertPath, clientKeyPath)
+		exporter, err = createHTTPExporter(ctx, endpoint, caCertPath, clientCertPath, clientKeyPath, headers)
 	}
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create OTLP log exporter: %w", err)
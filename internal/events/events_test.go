@@ -0,0 +1,140 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	otellog "go.opentelemetry.io/otel/log"
+)
+
+func TestParseHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "empty string",
+			input:    "",
+			expected: map[string]string{},
+		},
+		{
+			name:     "single header",
+			input:    "authorization=Bearer%20token",
+			expected: map[string]string{"authorization": "Bearer token"},
+		},
+		{
+			name:  "multiple headers with spaces",
+			input: "api-key = secret , tenant=team-a,",
+			expected: map[string]string{
+				"api-key": "secret",
+				"tenant":  "team-a",
+			},
+		},
+		{
+			name:     "value containing equal sign",
+			input:    "token=abc==",
+			expected: map[string]string{"token": "abc=="},
+		},
+		{
+			name:    "missing value separator",
+			input:   "authorization",
+			wantErr: true,
+		},
+		{
+			name:    "missing key",
+			input:   "=value",
+			wantErr: true,
+		},
+		{
+			name:    "invalid escape sequence",
+			input:   "key=%zz",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers, err := ParseHeaders(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, headers)
+		})
+	}
+}
+
+func TestInitStdout(t *testing.T) {
+	var out bytes.Buffer
+	logger, shutdown := InitStdout(&out)
+
+	for _, exe := range []string{"/usr/bin/ls", "/usr/bin/cat"} {
+		var rec otellog.Record
+		rec.SetEventName("policy_violation")
+		rec.SetSeverity(otellog.SeverityWarn)
+		rec.SetTimestamp(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+		rec.AddAttributes(
+			otellog.String("k8s.pod.name", "test-pod"),
+			otellog.String("proc.exepath", exe),
+			otellog.String("action", "protect"),
+		)
+		logger.Emit(t.Context(), rec)
+	}
+	require.NoError(t, shutdown(t.Context()))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &line))
+	require.Equal(t, map[string]any{
+		"time":         "2026-01-02T03:04:05Z",
+		"event":        "policy_violation",
+		"severity":     "WARN",
+		"k8s.pod.name": "test-pod",
+		"proc.exepath": "/usr/bin/cat",
+		"action":       "protect",
+	}, line)
+}
+
+func TestInitUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.sock")
+	var lc net.ListenConfig
+	listener, err := lc.Listen(t.Context(), "unix", path)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	logger, shutdown := InitUnixSocket(path)
+	for _, exe := range []string{"/usr/bin/ls", "/usr/bin/cat"} {
+		var rec otellog.Record
+		rec.SetEventName("policy_violation")
+		rec.SetTimestamp(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+		rec.AddAttributes(otellog.String("proc.exepath", exe))
+		logger.Emit(t.Context(), rec)
+	}
+	// the pending events are exported on shutdown.
+	require.NoError(t, shutdown(t.Context()))
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	received, err := io.ReadAll(conn)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(received), "\n"), "\n")
+	require.Len(t, lines, 2)
+	var line map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+	require.Equal(t, "policy_violation", line["event"])
+	require.Equal(t, "/usr/bin/ls", line["proc.exepath"])
+}
@@ -0,0 +1,96 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// jsonLinesExporter writes each log record as a single JSON object followed by a newline.
+type jsonLinesExporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+var _ sdklog.Exporter = &jsonLinesExporter{}
+
+func newJSONLinesExporter(w io.Writer) *jsonLinesExporter {
+	return &jsonLinesExporter{enc: json.NewEncoder(w)}
+}
+
+func recordToMap(rec *sdklog.Record) map[string]any {
+	// Attributes are flattened at the top level, so that the lines can be easily filtered
+	// with tools like jq. The reserved keys below are written last and always win.
+	line := make(map[string]any, rec.AttributesLen()+3)
+	rec.WalkAttributes(func(kv otellog.KeyValue) bool {
+		line[kv.Key] = valueToAny(kv.Value)
+		return true
+	})
+	line["time"] = rec.Timestamp().UTC().Format(time.RFC3339Nano)
+	line["event"] = rec.EventName()
+	line["severity"] = rec.Severity().String()
+	return line
+}
+
+func valueToAny(v otellog.Value) any {
+	switch v.Kind() {
+	case otellog.KindBool:
+		return v.AsBool()
+	case otellog.KindFloat64:
+		return v.AsFloat64()
+	case otellog.KindInt64:
+		return v.AsInt64()
+	case otellog.KindString:
+		return v.AsString()
+	case otellog.KindBytes:
+		return v.AsBytes()
+	case otellog.KindSlice:
+		values := v.AsSlice()
+		out := make([]any, 0, len(values))
+		for _, value := range values {
+			out = append(out, valueToAny(value))
+		}
+		return out
+	case otellog.KindMap:
+		kvs := v.AsMap()
+		out := make(map[string]any, len(kvs))
+		for _, kv := range kvs {
+			out[kv.Key] = valueToAny(kv.Value)
+		}
+		return out
+	case otellog.KindEmpty:
+		return nil
+	default:
+		return v.String()
+	}
+}
+
+func (e *jsonLinesExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := range records {
+		if err := e.enc.Encode(recordToMap(&records[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *jsonLinesExporter) Shutdown(context.Context) error { return nil }
+
+func (e *jsonLinesExporter) ForceFlush(context.Context) error { return nil }
+
+// InitStdout creates an OTEL log provider that writes violation events to w as JSON lines,
+// one object per event. It is meant for clusters where no OTLP collector is available.
+func InitStdout(w io.Writer) (otellog.Logger, func(context.Context) error) {
+	// Records are written synchronously: there is no network round trip to amortize.
+	provider := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewSimpleProcessor(newJSONLinesExporter(w))),
+	)
+	return provider.Logger("violation-reporter"), provider.Shutdown
+}
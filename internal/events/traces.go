@@ -0,0 +1,54 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+// InitTraces registers a global OTEL tracer provider that exports the spans, e.g. the ones of the pod
+// enforcement setup in the resolver, to the given endpoint. Only the "grpc" protocol is supported.
+// The TLS settings and the headers are the same as the ones of Init.
+func InitTraces(
+	ctx context.Context,
+	endpoint, caCertPath, clientCertPath, clientKeyPath, protocol string,
+	headers map[string]string,
+) (func(context.Context) error, error) {
+	proto, err := stringToProtocol(protocol)
+	if err != nil {
+		return nil, err
+	}
+	if proto != protocolGRPC {
+		return nil, errors.New("traces can only be exported with the grpc protocol")
+	}
+
+	opts := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(strings.TrimPrefix(strings.TrimPrefix(endpoint, "https://"), "http://")),
+	}
+	if len(headers) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(headers))
+	}
+	if caCertPath == "" {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	} else {
+		tlsConfig, tlsErr := buildTLSConfig(caCertPath, clientCertPath, clientKeyPath)
+		if tlsErr != nil {
+			return nil, tlsErr
+		}
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,100 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// socketWriteTimeout bounds a write to the socket, a collector that stopped reading
+// must not hold the export of the following events.
+const socketWriteTimeout = 2 * time.Second
+
+// socketWriter writes to a Unix domain socket. The socket is dialed again after a failure,
+// so that the collector can be restarted without restarting the agent.
+type socketWriter struct {
+	mu     sync.Mutex
+	path   string
+	conn   net.Conn
+	closed bool
+}
+
+func (w *socketWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, net.ErrClosed
+	}
+	if w.conn == nil {
+		var dialer net.Dialer
+		ctx, cancel := context.WithTimeout(context.Background(), socketWriteTimeout)
+		conn, err := dialer.DialContext(ctx, "unix", w.path)
+		cancel()
+		if err != nil {
+			return 0, fmt.Errorf("failed to connect to the event socket: %w", err)
+		}
+		w.conn = conn
+	}
+	if err := w.conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout)); err != nil {
+		return 0, w.reset(err)
+	}
+	n, err := w.conn.Write(p)
+	if err != nil {
+		return n, w.reset(err)
+	}
+	return n, nil
+}
+
+func (w *socketWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// reset drops the connection after a failure, the next write dials the socket again.
+func (w *socketWriter) reset(err error) error {
+	closeErr := w.conn.Close()
+	w.conn = nil
+	return errors.Join(fmt.Errorf("failed to write to the event socket: %w", err), closeErr)
+}
+
+// socketExporter writes the records as JSON lines to a Unix domain socket.
+type socketExporter struct {
+	*jsonLinesExporter
+
+	socket *socketWriter
+}
+
+func (e *socketExporter) Shutdown(context.Context) error {
+	return e.socket.Close()
+}
+
+// InitUnixSocket creates an OTEL log provider that writes violation events as JSON lines,
+// one object per event, to the Unix domain socket at path. The socket is served by a node-local
+// collector, the events exported while it is not listening are dropped.
+func InitUnixSocket(path string) (otellog.Logger, func(context.Context) error) {
+	socket := &socketWriter{path: path}
+	exporter := &socketExporter{
+		jsonLinesExporter: newJSONLinesExporter(socket),
+		socket:            socket,
+	}
+	// Unlike stdout, the collector can be slow or unavailable: the records are exported in the background.
+	provider := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+	)
+	return provider.Logger("violation-reporter"), provider.Shutdown
+}
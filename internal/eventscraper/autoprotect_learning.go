@@ -0,0 +1,26 @@
+package eventscraper
+
+import "github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+
+// WithAutoProtectLearning learns the violations of the policies in monitor mode with an autoProtect.
+// The BPF programs only report the executions of the containers bound to a policy as monitoring events,
+// without it the proposal of an autoProtect would never change and the policy would be switched to
+// protect with only the executables it already allowed. It requires the learning to be enabled.
+func WithAutoProtectLearning(enabled bool) Option {
+	return func(es *EventScraper) {
+		es.learnAutoProtect = enabled
+	}
+}
+
+// learnAutoProtectViolation forwards the violation to the learning when the policy of the container
+// is waiting for its proposal to be stable. The execution went through, so it is learned as it is.
+func (es *EventScraper) learnAutoProtectViolation(queued *monitoringEvent, info *KubeProcessInfo, action string) {
+	if !es.learnAutoProtect || !queued.containerView.PolicyAutoProtect || action != policymode.MonitorString {
+		return
+	}
+	for _, exePath := range es.learnedPaths(queued.containerView.Meta.RootPath, info.ExecutablePath) {
+		learned := *info
+		learned.ExecutablePath = exePath
+		es.learningEnqueueFunc(learned)
+	}
+}
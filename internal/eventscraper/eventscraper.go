@@ -3,11 +3,12 @@ package eventscraper
 import (
 	"context"
 	"log/slog"
+	"slices"
 	"time"
 
-	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/severity"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/violationbuf"
 	otellog "go.opentelemetry.io/otel/log"
 	"golang.org/x/time/rate"
@@ -53,6 +54,17 @@ type EventScraper struct {
 	violationBuffer     *violationbuf.Buffer
 	nodeName            string
 	bufferFullLimiter   *logRateLimiter
+	monitoringQueue     *monitoringQueue
+	queueFullLimiter    *logRateLimiter
+	podLabelKeys        []string
+	podAnnotationKeys   []string
+	minExportSeverity   severity.Level
+	scriptLearning      ScriptLearning
+	learnInvokedPaths   bool
+	dedup               *violationDedup
+	learnAutoProtect    bool
+	ancestryDepth       int
+	procFSPath          string
 }
 
 type KubeProcessInfo struct {
@@ -64,6 +76,8 @@ type KubeProcessInfo struct {
 	PodName        string `json:"podName"`
 	ContainerID    string `json:"containerID"`
 	PolicyName     string `json:"policyName,omitempty"`
+	// ParentExecutablePath is only known for monitoring events.
+	ParentExecutablePath string `json:"parentExecutablePath,omitempty"`
 }
 
 type Option func(*EventScraper)
@@ -85,6 +99,16 @@ func WithViolationBuffer(buf *violationbuf.Buffer, nodeName string) Option {
 	}
 }
 
+// WithPodAttributes adds the given pod labels and annotations to the violation event records,
+// e.g. to correlate them with the team owning the workload.
+// The annotations must be cached by the resolver to be found.
+func WithPodAttributes(labelKeys, annotationKeys []string) Option {
+	return func(es *EventScraper) {
+		es.podLabelKeys = labelKeys
+		es.podAnnotationKeys = annotationKeys
+	}
+}
+
 func NewEventScraper(
 	learningChannel <-chan bpf.ProcessEvent,
 	monitoringChannel <-chan bpf.ProcessEvent,
@@ -99,9 +123,16 @@ func NewEventScraper(
 		logger:              logger,
 		resolver:            resolver,
 		learningEnqueueFunc: learningEnqueueFunc,
+		minExportSeverity:   severity.Low,
+		scriptLearning:      ScriptLearningScript,
+		procFSPath:          defaultProcFSPath,
 		bufferFullLimiter: &logRateLimiter{
 			limiter: rate.NewLimiter(rate.Every(1*time.Second), 1),
 		},
+		monitoringQueue: newMonitoringQueue(monitoringQueueSize),
+		queueFullLimiter: &logRateLimiter{
+			limiter: rate.NewLimiter(rate.Every(1*time.Second), 1),
+		},
 	}
 	for _, option := range opts {
 		option(es)
@@ -109,7 +140,7 @@ func NewEventScraper(
 	return es
 }
 
-func (es *EventScraper) getKubeProcessInfo(event *bpf.ProcessEvent) *KubeProcessInfo {
+func (es *EventScraper) getContainerView(event *bpf.ProcessEvent) *resolver.ContainerView {
 	// trackerID is the ID of the container cgroup where the process is running.
 	// NRI will populate cgroup tracker map before we will start to generate learning/monitor events from ebpf.
 	containerView, err := es.resolver.GetContainerView(event.CgTrackerID)
@@ -120,13 +151,12 @@ func (es *EventScraper) getKubeProcessInfo(event *bpf.ProcessEvent) *KubeProcess
 			"error", err)
 		return nil
 	}
+	return containerView
+}
 
+func newKubeProcessInfo(containerView *resolver.ContainerView, event *bpf.ProcessEvent) *KubeProcessInfo {
 	podMeta := containerView.PodMeta
 	containerMeta := containerView.Meta
-	policyName := ""
-	if podMeta.Labels != nil {
-		policyName = podMeta.Labels[v1alpha1.PolicyLabelKey]
-	}
 
 	return &KubeProcessInfo{
 		Namespace:      podMeta.Namespace,
@@ -136,7 +166,9 @@ func (es *EventScraper) getKubeProcessInfo(event *bpf.ProcessEvent) *KubeProcess
 		ExecutablePath: event.ExePath,
 		PodName:        podMeta.Name,
 		ContainerID:    containerMeta.ID,
-		PolicyName:     policyName,
+		PolicyName:     containerView.PolicyName,
+
+		ParentExecutablePath: event.ParentExePath,
 	}
 }
 
@@ -145,25 +177,45 @@ func (es *EventScraper) Start(ctx context.Context) error {
 	defer func() {
 		es.logger.InfoContext(ctx, "event scraper has stopped")
 	}()
+	go es.dispatchMonitoringEvents(ctx)
+
+	var dedupFlush <-chan time.Time
+	if es.dedup != nil {
+		ticker := time.NewTicker(es.dedup.flushInterval())
+		defer ticker.Stop()
+		dedupFlush = ticker.C
+		// the pending violations are still exported on shutdown.
+		defer es.flushDedup(context.WithoutCancel(ctx), true)
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			// Handle context cancellation
 			return nil
+		case <-dedupFlush:
+			es.flushDedup(ctx, false)
 		case event := <-es.learningChannel:
-			kubeInfo := es.getKubeProcessInfo(&event)
-			if kubeInfo == nil {
+			containerView := es.getContainerView(&event)
+			if containerView == nil {
 				continue
 			}
-			es.learningEnqueueFunc(*kubeInfo)
-		case event := <-es.monitoringChannel:
-			kubeInfo := es.getKubeProcessInfo(&event)
-			if kubeInfo == nil {
+			learnedPaths := es.withInvokedPath(
+				es.learnedPaths(containerView.Meta.RootPath, event.ExePath), event.ExePath, event.InvokedPath)
+			for _, exePath := range learnedPaths {
+				learned := event
+				learned.ExePath = exePath
+				es.learningEnqueueFunc(*newKubeProcessInfo(containerView, &learned))
+			}
+		case <-es.monitoringQueue.ready:
+			queued, ok := es.monitoringQueue.pop()
+			if !ok {
 				continue
 			}
+			containerView := queued.containerView
+			kubeInfo := newKubeProcessInfo(containerView, &queued.event)
 
-			action := event.Mode
+			action := queued.event.Mode
 
 			policyName := kubeInfo.PolicyName
 			if policyName == "" {
@@ -172,13 +224,47 @@ func (es *EventScraper) Start(ctx context.Context) error {
 					"namespace", kubeInfo.Namespace)
 			}
 
-			es.emitViolationEvent(ctx, kubeInfo, action)
+			if es.isSevereEnough(containerView.PolicySeverity) {
+				attrs := slices.Concat(
+					ancestryAttributes(queued.ancestorExePaths), es.podAttributes(&containerView.PodMeta))
+				es.exportViolation(ctx, kubeInfo, attrs, action)
+			}
 			es.reportViolation(kubeInfo, action)
+			es.learnAutoProtectViolation(&queued, kubeInfo, action)
+		}
+	}
+}
+
+// podAttributes returns the selected labels and annotations of the pod as record attributes.
+// Following the OpenTelemetry conventions, they are named k8s.pod.label.<key> and k8s.pod.annotation.<key>.
+func (es *EventScraper) podAttributes(podMeta *resolver.PodMeta) []otellog.KeyValue {
+	var attrs []otellog.KeyValue
+	for _, key := range es.podLabelKeys {
+		if value, ok := podMeta.Labels[key]; ok {
+			attrs = append(attrs, otellog.String("k8s.pod.label."+key, value))
 		}
 	}
+	for _, key := range es.podAnnotationKeys {
+		if value, ok := podMeta.Annotations[key]; ok {
+			attrs = append(attrs, otellog.String("k8s.pod.annotation."+key, value))
+		}
+	}
+	return attrs
 }
 
-func (es *EventScraper) emitViolationEvent(ctx context.Context, info *KubeProcessInfo, action string) {
+// emitViolationEvent exports the violation. Its action is policymode.ProtectString when the execution
+// was blocked, policymode.MonitorString when a policy in monitor mode let it run although it would have
+// blocked it. The executions allowed by the policy, parent rules included, are never exported.
+// The attributes of the process ancestry and of the pod are added to the record.
+// A count is set on the violations deduplicated within a window, it is 0 when the deduplication is disabled.
+func (es *EventScraper) emitViolationEvent(
+	ctx context.Context,
+	info *KubeProcessInfo,
+	attrs []otellog.KeyValue,
+	action string,
+	timestamp time.Time,
+	count int64,
+) {
 	if es.violationLogger == nil {
 		return
 	}
@@ -187,16 +273,21 @@ func (es *EventScraper) emitViolationEvent(ctx context.Context, info *KubeProces
 	rec.SetEventName("policy_violation")
 	rec.SetSeverity(otellog.SeverityWarn)
 	rec.SetBody(otellog.StringValue("policy_violation"))
-	rec.SetTimestamp(time.Now())
+	rec.SetTimestamp(timestamp)
 	rec.AddAttributes(
 		otellog.String("policy.name", info.PolicyName),
 		otellog.String("k8s.namespace.name", info.Namespace),
 		otellog.String("k8s.pod.name", info.PodName),
 		otellog.String("container.name", info.ContainerName),
 		otellog.String("proc.exepath", info.ExecutablePath),
+		otellog.String("proc.pexepath", info.ParentExecutablePath),
 		otellog.String("node.name", es.nodeName),
 		otellog.String("action", action),
 	)
+	if count > 0 {
+		rec.AddAttributes(otellog.Int64("violation.count", count))
+	}
+	rec.AddAttributes(attrs...)
 
 	es.violationLogger.Emit(ctx, rec)
 }
@@ -0,0 +1,585 @@
+package eventscraper
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/severity"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/violationbuf"
+	"github.com/stretchr/testify/require"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/noop"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMonitoringEventAllowedByParent(t *testing.T) {
+	r := resolver.NewTestResolver(t)
+	require.NoError(t, r.ReconcileWP(&v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: policymode.MonitorString,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"main": {Executables: v1alpha1.WorkloadPolicyExecutables{
+					Allowed: []string{"/entrypoint.sh"},
+					AllowedWithParent: []v1alpha1.ExecutableWithParent{
+						{Path: "/bin/sh", Parents: []string{"/entrypoint.sh"}},
+					},
+				}},
+			},
+		},
+	}))
+	require.NoError(t, r.AddPodContainerFromNri(resolver.PodInput{
+		Meta: resolver.PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		Containers: map[resolver.ContainerID]resolver.ContainerInput{
+			"cid": {ContainerMeta: resolver.ContainerMeta{ID: "cid", Name: "main", CgroupID: 100}},
+		},
+	}))
+
+	monitoringChannel := make(chan bpf.ProcessEvent)
+	violationBuffer := violationbuf.NewBuffer()
+	es := NewEventScraper(
+		make(chan bpf.ProcessEvent),
+		monitoringChannel,
+		slog.New(slog.DiscardHandler),
+		r,
+		func(KubeProcessInfo) {},
+		WithViolationBuffer(violationBuffer, "test-node"),
+	)
+	go func() {
+		_ = es.Start(t.Context())
+	}()
+
+	for _, evt := range []bpf.ProcessEvent{
+		// allowed under this parent: not reported.
+		{CgTrackerID: 100, ExePath: "/bin/sh", ParentExePath: "/entrypoint.sh", Mode: policymode.MonitorString},
+		// not allowed under this parent.
+		{CgTrackerID: 100, ExePath: "/bin/sh", ParentExePath: "/usr/bin/bash", Mode: policymode.MonitorString},
+		// without a mode the action would be ambiguous: not reported.
+		{CgTrackerID: 100, ExePath: "/bin/ls", ParentExePath: "/entrypoint.sh"},
+		// the execution was blocked by the kernel, the parent condition doesn't apply.
+		{CgTrackerID: 100, ExePath: "/bin/sh", ParentExePath: "/entrypoint.sh", Mode: policymode.ProtectString},
+	} {
+		monitoringChannel <- evt
+	}
+
+	var records []violationbuf.ViolationRecord
+	require.Eventually(t, func() bool {
+		records = append(records, violationBuffer.Drain()...)
+		return len(records) >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, records, 2)
+	actions := []string{records[0].Action, records[1].Action}
+	require.ElementsMatch(t, []string{policymode.MonitorString, policymode.ProtectString}, actions)
+}
+
+func TestPodAttributes(t *testing.T) {
+	es := NewEventScraper(
+		make(chan bpf.ProcessEvent),
+		make(chan bpf.ProcessEvent),
+		slog.New(slog.DiscardHandler),
+		resolver.NewTestResolver(t),
+		func(KubeProcessInfo) {},
+		WithPodAttributes([]string{"team", "env", "missing"}, []string{"owner.example.com/cmdb-id"}),
+	)
+
+	attrs := es.podAttributes(&resolver.PodMeta{
+		Labels: map[string]string{
+			"team": "payments",
+			"env":  "prod",
+			"app":  "not-selected",
+		},
+		Annotations: map[string]string{"owner.example.com/cmdb-id": "CI-1234"},
+	})
+	require.Equal(t, []otellog.KeyValue{
+		otellog.String("k8s.pod.label.team", "payments"),
+		otellog.String("k8s.pod.label.env", "prod"),
+		otellog.String("k8s.pod.annotation.owner.example.com/cmdb-id", "CI-1234"),
+	}, attrs)
+}
+
+// recordingLogger keeps the policy names and the counts of the emitted violation records.
+type recordingLogger struct {
+	noop.Logger
+
+	// delay simulates a slow exporter.
+	delay    time.Duration
+	mu       sync.Mutex
+	policies []string
+	counts   []int64
+	// ancestors are the proc.aexepath[N] attributes, as "<key>=<value>".
+	ancestors []string
+}
+
+func (l *recordingLogger) Emit(_ context.Context, rec otellog.Record) {
+	time.Sleep(l.delay)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rec.WalkAttributes(func(kv otellog.KeyValue) bool {
+		switch kv.Key {
+		case "policy.name":
+			l.policies = append(l.policies, kv.Value.AsString())
+		case "violation.count":
+			l.counts = append(l.counts, kv.Value.AsInt64())
+		default:
+			if strings.HasPrefix(kv.Key, "proc.aexepath[") {
+				l.ancestors = append(l.ancestors, kv.Key+"="+kv.Value.AsString())
+			}
+		}
+		return true
+	})
+}
+
+func (l *recordingLogger) emittedPolicies() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.policies)
+}
+
+func (l *recordingLogger) emittedAncestors() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.ancestors)
+}
+
+func (l *recordingLogger) emittedCounts() []int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.counts)
+}
+
+func TestDedupWindow(t *testing.T) {
+	r := resolver.NewTestResolver(t)
+	require.NoError(t, r.ReconcileWP(&v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: policymode.ProtectString,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"main": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+			},
+		},
+	}))
+	require.NoError(t, r.AddPodContainerFromNri(resolver.PodInput{
+		Meta: resolver.PodMeta{
+			ID:        "pod",
+			Namespace: "test-ns",
+			Name:      "pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "policy"},
+		},
+		Containers: map[resolver.ContainerID]resolver.ContainerInput{
+			"pod": {ContainerMeta: resolver.ContainerMeta{ID: "pod", Name: "main", CgroupID: 100}},
+		},
+	}))
+
+	monitoringChannel := make(chan bpf.ProcessEvent)
+	violationBuffer := violationbuf.NewBuffer()
+	violationLogger := &recordingLogger{}
+	es := NewEventScraper(
+		make(chan bpf.ProcessEvent),
+		monitoringChannel,
+		slog.New(slog.DiscardHandler),
+		r,
+		func(KubeProcessInfo) {},
+		WithViolationLogger(violationLogger, "test-node"),
+		WithViolationBuffer(violationBuffer, "test-node"),
+		WithDedupWindow(500*time.Millisecond),
+	)
+	go func() {
+		_ = es.Start(t.Context())
+	}()
+
+	const burst = 50
+	for range burst {
+		monitoringChannel <- bpf.ProcessEvent{CgTrackerID: 100, ExePath: "/bin/cat", Mode: policymode.ProtectString}
+	}
+	monitoringChannel <- bpf.ProcessEvent{CgTrackerID: 100, ExePath: "/bin/ls", Mode: policymode.ProtectString}
+
+	// the burst is exported as one counted event once the window ended, the other executable apart.
+	require.Eventually(t, func() bool {
+		return len(violationLogger.emittedCounts()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.ElementsMatch(t, []int64{burst, 1}, violationLogger.emittedCounts())
+
+	// every violation is still reported in the status.
+	var records []violationbuf.ViolationRecord
+	require.Eventually(t, func() bool {
+		records = append(records, violationBuffer.Drain()...)
+		return len(records) == burst+1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestMinExportSeverity(t *testing.T) {
+	r := resolver.NewTestResolver(t)
+	for i, policySeverity := range []string{severity.LowString, severity.HighString} {
+		require.NoError(t, r.ReconcileWP(&v1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: policySeverity, Namespace: "test-ns"},
+			Spec: v1alpha1.WorkloadPolicySpec{
+				Mode:     policymode.ProtectString,
+				Severity: policySeverity,
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					"main": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+				},
+			},
+		}))
+		podID := "pod-" + policySeverity
+		require.NoError(t, r.AddPodContainerFromNri(resolver.PodInput{
+			Meta: resolver.PodMeta{
+				ID:        podID,
+				Namespace: "test-ns",
+				Name:      podID,
+				Labels:    map[string]string{v1alpha1.PolicyLabelKey: policySeverity},
+			},
+			Containers: map[resolver.ContainerID]resolver.ContainerInput{
+				podID: {ContainerMeta: resolver.ContainerMeta{ID: podID, Name: "main", CgroupID: uint64(100 + i)}},
+			},
+		}))
+	}
+
+	monitoringChannel := make(chan bpf.ProcessEvent)
+	violationBuffer := violationbuf.NewBuffer()
+	violationLogger := &recordingLogger{}
+	es := NewEventScraper(
+		make(chan bpf.ProcessEvent),
+		monitoringChannel,
+		slog.New(slog.DiscardHandler),
+		r,
+		func(KubeProcessInfo) {},
+		WithViolationLogger(violationLogger, "test-node"),
+		WithViolationBuffer(violationBuffer, "test-node"),
+		WithMinExportSeverity(severity.High),
+	)
+	go func() {
+		_ = es.Start(t.Context())
+	}()
+
+	monitoringChannel <- bpf.ProcessEvent{CgTrackerID: 100, ExePath: "/bin/cat", Mode: policymode.ProtectString}
+	monitoringChannel <- bpf.ProcessEvent{CgTrackerID: 101, ExePath: "/bin/cat", Mode: policymode.ProtectString}
+
+	// both violations are reported in the status, only the high severity one is exported.
+	var records []violationbuf.ViolationRecord
+	require.Eventually(t, func() bool {
+		records = append(records, violationBuffer.Drain()...)
+		return len(records) >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, records, 2)
+	require.Equal(t, []string{severity.HighString}, violationLogger.emittedPolicies())
+
+	level, err := severity.Parse("")
+	require.NoError(t, err)
+	require.Equal(t, severity.Medium, level)
+	_, err = severity.Parse("urgent")
+	require.Error(t, err)
+}
+
+func TestScriptLearning(t *testing.T) {
+	rootPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(rootPath, "entrypoint.sh"), []byte("#!/bin/sh -e\nexec app\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(rootPath, "app"), []byte("\x7fELF"), 0o755))
+
+	r := resolver.NewTestResolver(t)
+	require.NoError(t, r.AddPodContainerFromNri(resolver.PodInput{
+		Meta: resolver.PodMeta{ID: "test-pod-uid", Namespace: "test-ns", Name: "test-pod"},
+		Containers: map[resolver.ContainerID]resolver.ContainerInput{
+			"cid": {ContainerMeta: resolver.ContainerMeta{ID: "cid", Name: "main", CgroupID: 100, RootPath: rootPath}},
+		},
+	}))
+
+	tests := []struct {
+		learning ScriptLearning
+		expected []string
+	}{
+		{learning: ScriptLearningScript, expected: []string{"/entrypoint.sh", "/app", "/missing.sh"}},
+		{learning: ScriptLearningInterpreter, expected: []string{"/bin/sh", "/app", "/missing.sh"}},
+		{learning: ScriptLearningBoth, expected: []string{"/entrypoint.sh", "/bin/sh", "/app", "/missing.sh"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.learning), func(t *testing.T) {
+			learningChannel := make(chan bpf.ProcessEvent)
+			var mu sync.Mutex
+			var learned []string
+			es := NewEventScraper(
+				learningChannel,
+				make(chan bpf.ProcessEvent),
+				slog.New(slog.DiscardHandler),
+				r,
+				func(evt KubeProcessInfo) {
+					mu.Lock()
+					defer mu.Unlock()
+					learned = append(learned, evt.ExecutablePath)
+				},
+				WithScriptLearning(tt.learning),
+			)
+			go func() {
+				_ = es.Start(t.Context())
+			}()
+
+			// a script that cannot be read anymore is learned as is.
+			for _, exePath := range []string{"/entrypoint.sh", "/app", "/missing.sh"} {
+				learningChannel <- bpf.ProcessEvent{CgTrackerID: 100, ExePath: exePath}
+			}
+			require.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return slices.Equal(tt.expected, learned)
+			}, 5*time.Second, 10*time.Millisecond)
+		})
+	}
+
+	_, err := ParseScriptLearning("none")
+	require.Error(t, err)
+}
+
+func TestInvokedPathLearning(t *testing.T) {
+	r := resolver.NewTestResolver(t)
+	require.NoError(t, r.AddPodContainerFromNri(resolver.PodInput{
+		Meta: resolver.PodMeta{ID: "test-pod-uid", Namespace: "test-ns", Name: "test-pod"},
+		Containers: map[resolver.ContainerID]resolver.ContainerInput{
+			"cid": {ContainerMeta: resolver.ContainerMeta{ID: "cid", Name: "main", CgroupID: 100}},
+		},
+	}))
+
+	learningChannel := make(chan bpf.ProcessEvent)
+	var mu sync.Mutex
+	var learned []string
+	es := NewEventScraper(
+		learningChannel,
+		make(chan bpf.ProcessEvent),
+		slog.New(slog.DiscardHandler),
+		r,
+		func(evt KubeProcessInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			learned = append(learned, evt.ExecutablePath)
+		},
+		WithInvokedPathLearning(true),
+	)
+	go func() {
+		_ = es.Start(t.Context())
+	}()
+
+	for _, evt := range []bpf.ProcessEvent{
+		// a symlink: both paths are learned.
+		{CgTrackerID: 100, ExePath: "/usr/bin/busybox", InvokedPath: "/bin/sh"},
+		// not a symlink: the path is learned once.
+		{CgTrackerID: 100, ExePath: "/usr/bin/sleep", InvokedPath: "/usr/bin/sleep"},
+		// relative to the working directory, it cannot be learned.
+		{CgTrackerID: 100, ExePath: "/app/run", InvokedPath: "./run"},
+	} {
+		learningChannel <- evt
+	}
+	expected := []string{"/usr/bin/busybox", "/bin/sh", "/usr/bin/sleep", "/app/run"}
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Equal(expected, learned)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestMonitoringFloodDoesNotStarveOtherPolicies(t *testing.T) {
+	r := resolver.NewTestResolver(t)
+	for i, policyName := range []string{"noisy", "critical"} {
+		require.NoError(t, r.ReconcileWP(&v1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: policyName, Namespace: "test-ns"},
+			Spec: v1alpha1.WorkloadPolicySpec{
+				Mode: policymode.ProtectString,
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					"main": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+				},
+			},
+		}))
+		podID := "pod-" + policyName
+		require.NoError(t, r.AddPodContainerFromNri(resolver.PodInput{
+			Meta: resolver.PodMeta{
+				ID:        podID,
+				Namespace: "test-ns",
+				Name:      podID,
+				Labels:    map[string]string{v1alpha1.PolicyLabelKey: policyName},
+			},
+			Containers: map[resolver.ContainerID]resolver.ContainerInput{
+				podID: {ContainerMeta: resolver.ContainerMeta{ID: podID, Name: "main", CgroupID: uint64(100 + i)}},
+			},
+		}))
+	}
+
+	monitoringChannel := make(chan bpf.ProcessEvent)
+	violationLogger := &recordingLogger{delay: time.Millisecond}
+	es := NewEventScraper(
+		make(chan bpf.ProcessEvent),
+		monitoringChannel,
+		slog.New(slog.DiscardHandler),
+		r,
+		func(KubeProcessInfo) {},
+		WithViolationLogger(violationLogger, "test-node"),
+		WithViolationBuffer(violationbuf.NewBuffer(), "test-node"),
+	)
+	go func() {
+		_ = es.Start(t.Context())
+	}()
+
+	const flood = 2 * monitoringQueueSize
+	for range flood {
+		monitoringChannel <- bpf.ProcessEvent{CgTrackerID: 100, ExePath: "/bin/cat", Mode: policymode.ProtectString}
+	}
+	monitoringChannel <- bpf.ProcessEvent{CgTrackerID: 101, ExePath: "/bin/cat", Mode: policymode.ProtectString}
+
+	// the violation of the critical policy is exported right after the ones of the noisy policy
+	// already being processed, instead of waiting for the whole flood.
+	var emitted []string
+	require.Eventually(t, func() bool {
+		emitted = violationLogger.emittedPolicies()
+		return slices.Contains(emitted, "critical")
+	}, 5*time.Second, time.Millisecond)
+	require.Less(t, slices.Index(emitted, "critical"), monitoringQueueSize/10)
+}
+
+func TestProcessAncestry(t *testing.T) {
+	r := resolver.NewTestResolver(t)
+	require.NoError(t, r.ReconcileWP(&v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: policymode.ProtectString,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"main": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+			},
+		},
+	}))
+	require.NoError(t, r.AddPodContainerFromNri(resolver.PodInput{
+		Meta: resolver.PodMeta{
+			ID:        "pod",
+			Namespace: "test-ns",
+			Name:      "pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "policy"},
+		},
+		Containers: map[resolver.ContainerID]resolver.ContainerInput{
+			"pod": {ContainerMeta: resolver.ContainerMeta{ID: "pod", Name: "main", CgroupID: 100}},
+		},
+	}))
+
+	// a fake procfs where the process 11 already exited.
+	procFSPath := t.TempDir()
+	for pid, exePath := range map[int]string{10: "/usr/bin/bash", 12: "/usr/bin/containerd-shim"} {
+		dir := filepath.Join(procFSPath, strconv.Itoa(pid))
+		require.NoError(t, os.Mkdir(dir, 0o755))
+		require.NoError(t, os.Symlink(exePath, filepath.Join(dir, "exe")))
+	}
+
+	monitoringChannel := make(chan bpf.ProcessEvent)
+	violationLogger := &recordingLogger{}
+	es := NewEventScraper(
+		make(chan bpf.ProcessEvent),
+		monitoringChannel,
+		slog.New(slog.DiscardHandler),
+		r,
+		func(KubeProcessInfo) {},
+		WithViolationLogger(violationLogger, "test-node"),
+		WithViolationBuffer(violationbuf.NewBuffer(), "test-node"),
+		WithProcessAncestry(3),
+	)
+	es.procFSPath = procFSPath
+	go func() {
+		_ = es.Start(t.Context())
+	}()
+
+	// the ancestors beyond the depth are not reported.
+	monitoringChannel <- bpf.ProcessEvent{
+		CgTrackerID:   100,
+		ExePath:       "/bin/cat",
+		ParentExePath: "/bin/sh",
+		AncestorPIDs:  []uint32{10, 11, 12, 1},
+		Mode:          policymode.ProtectString,
+	}
+	require.Eventually(t, func() bool {
+		return len(violationLogger.emittedPolicies()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{
+		"proc.aexepath[2]=/usr/bin/bash",
+		"proc.aexepath[4]=/usr/bin/containerd-shim",
+	}, violationLogger.emittedAncestors())
+}
+
+func TestAutoProtectLearning(t *testing.T) {
+	r := resolver.NewTestResolver(t)
+	for _, policyName := range []string{"auto", "manual"} {
+		wp := &v1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: policyName, Namespace: "test-ns"},
+			Spec: v1alpha1.WorkloadPolicySpec{
+				Mode: policymode.MonitorString,
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					"main": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+				},
+			},
+		}
+		if policyName == "auto" {
+			wp.Spec.AutoProtect = &v1alpha1.WorkloadPolicyAutoProtect{Proposal: "proposal", StableObservations: 1}
+		}
+		require.NoError(t, r.ReconcileWP(wp))
+	}
+	for i, policyName := range []string{"auto", "manual"} {
+		require.NoError(t, r.AddPodContainerFromNri(resolver.PodInput{
+			Meta: resolver.PodMeta{
+				ID:        resolver.PodID(policyName),
+				Namespace: "test-ns",
+				Name:      policyName,
+				Labels:    map[string]string{v1alpha1.PolicyLabelKey: policyName},
+			},
+			Containers: map[resolver.ContainerID]resolver.ContainerInput{
+				resolver.ContainerID(policyName): {ContainerMeta: resolver.ContainerMeta{
+					ID: resolver.ContainerID(policyName), Name: "main", CgroupID: resolver.CgroupID(100 + i),
+				}},
+			},
+		}))
+	}
+
+	monitoringChannel := make(chan bpf.ProcessEvent)
+	violationBuffer := violationbuf.NewBuffer()
+	var mu sync.Mutex
+	var learned []string
+	es := NewEventScraper(
+		make(chan bpf.ProcessEvent),
+		monitoringChannel,
+		slog.New(slog.DiscardHandler),
+		r,
+		func(evt KubeProcessInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			learned = append(learned, evt.PolicyName+":"+evt.ExecutablePath)
+		},
+		WithViolationBuffer(violationBuffer, "test-node"),
+		WithAutoProtectLearning(true),
+	)
+	go func() {
+		_ = es.Start(t.Context())
+	}()
+
+	for _, evt := range []bpf.ProcessEvent{
+		{CgTrackerID: 100, ExePath: "/bin/cat", Mode: policymode.MonitorString},
+		// the policy without autoProtect is not learned.
+		{CgTrackerID: 101, ExePath: "/bin/cat", Mode: policymode.MonitorString},
+	} {
+		monitoringChannel <- evt
+	}
+	var records []violationbuf.ViolationRecord
+	require.Eventually(t, func() bool {
+		records = append(records, violationBuffer.Drain()...)
+		return len(records) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"auto:/bin/cat"}, learned)
+}
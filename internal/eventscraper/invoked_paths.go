@@ -0,0 +1,30 @@
+package eventscraper
+
+import (
+	"path"
+	"slices"
+)
+
+// WithInvokedPathLearning also learns the path passed to execve when it differs from the
+// executable path, e.g. when a symlink is executed. The BPF programs enforce the resolved
+// executable path, the invoked one is learned so that the policy lists the commands as written.
+func WithInvokedPathLearning(enabled bool) Option {
+	return func(es *EventScraper) {
+		es.learnInvokedPaths = enabled
+	}
+}
+
+// withInvokedPath adds the invoked path next to the executable path among the learned paths.
+// Relative invoked paths depend on the working directory of the process, they are not learned.
+func (es *EventScraper) withInvokedPath(learned []string, exePath, invokedPath string) []string {
+	if !es.learnInvokedPaths || !path.IsAbs(invokedPath) {
+		return learned
+	}
+	invokedPath = path.Clean(invokedPath)
+	i := slices.Index(learned, exePath)
+	if i < 0 || slices.Contains(learned, invokedPath) {
+		// the executable itself is not learned, e.g. only the script interpreter is.
+		return learned
+	}
+	return slices.Insert(learned, i+1, invokedPath)
+}
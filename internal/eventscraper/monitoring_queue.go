@@ -0,0 +1,135 @@
+package eventscraper
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+)
+
+const (
+	// monitoringQueueSize is the number of monitoring events kept for each policy,
+	// the oldest events of a policy are dropped when they are not processed fast enough.
+	monitoringQueueSize = 1024
+	queueFullMsg        = "monitoring queue of the policy full, oldest event dropped"
+)
+
+type monitoringEvent struct {
+	event         bpf.ProcessEvent
+	containerView *resolver.ContainerView
+	// ancestorExePaths are resolved when the event is received, before the ancestors exit.
+	ancestorExePaths []string
+}
+
+// monitoringQueue keeps the monitoring events in a queue per policy and serves the policies
+// round robin, so that a policy flooding violations cannot delay the violations of the others.
+type monitoringQueue struct {
+	mu       sync.Mutex
+	capacity int
+	pending  map[string][]monitoringEvent
+	// order lists the policies with pending events, in the order they are served.
+	order []string
+	// ready is signaled when events are pushed.
+	ready chan struct{}
+}
+
+func newMonitoringQueue(capacity int) *monitoringQueue {
+	return &monitoringQueue{
+		capacity: capacity,
+		pending:  make(map[string][]monitoringEvent),
+		ready:    make(chan struct{}, 1),
+	}
+}
+
+// push queues the event of the policy, it returns true when the oldest event of the policy was dropped.
+func (q *monitoringQueue) push(policy string, evt monitoringEvent) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	events := q.pending[policy]
+	if len(events) == 0 {
+		q.order = append(q.order, policy)
+	}
+	dropped := len(events) >= q.capacity
+	if dropped {
+		events = events[1:]
+	}
+	q.pending[policy] = append(events, evt)
+	q.signal()
+	return dropped
+}
+
+// pop returns the next event of the policy whose turn it is.
+func (q *monitoringQueue) pop() (monitoringEvent, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.order) == 0 {
+		return monitoringEvent{}, false
+	}
+	policy := q.order[0]
+	q.order = q.order[1:]
+	events := q.pending[policy]
+	evt := events[0]
+	if len(events) == 1 {
+		delete(q.pending, policy)
+	} else {
+		q.pending[policy] = events[1:]
+		q.order = append(q.order, policy)
+	}
+	// the consumer handles one event at a time, it is woken up again for the remaining ones.
+	if len(q.order) > 0 {
+		q.signal()
+	}
+	return evt, true
+}
+
+func (q *monitoringQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchMonitoringEvents moves the monitoring events reported by the BPF programs to the queue of
+// their policy. It never blocks on the processing of the events, so the channel is drained promptly.
+func (es *EventScraper) dispatchMonitoringEvents(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-es.monitoringChannel:
+			// The mode becomes the action of the violation, without it we could not tell whether
+			// the execution was blocked.
+			if event.Mode != policymode.MonitorString && event.Mode != policymode.ProtectString {
+				es.logger.ErrorContext(ctx, "monitoring event with an unknown mode",
+					"mode", event.Mode,
+					"exe", event.ExePath)
+				continue
+			}
+			// In monitor mode the execution went through, we only need to check if it is
+			// allowed by a rule conditioned on the parent executable before reporting it.
+			if event.Mode == policymode.MonitorString &&
+				es.resolver.IsAllowedByParent(event.CgTrackerID, event.ExePath, event.ParentExePath) {
+				continue
+			}
+			containerView := es.getContainerView(&event)
+			if containerView == nil {
+				continue
+			}
+			policy := containerView.PodMeta.Namespace + "/" + containerView.PolicyName
+			queued := monitoringEvent{
+				event:            event,
+				containerView:    containerView,
+				ancestorExePaths: es.ancestorExePaths(event.AncestorPIDs),
+			}
+			if es.monitoringQueue.push(policy, queued) &&
+				es.queueFullLimiter.shouldLog() {
+				es.queueFullLimiter.flushSuppressed(es.logger, queueFullMsg)
+				es.logger.Warn(queueFullMsg, "policy", policy)
+			}
+		}
+	}
+}
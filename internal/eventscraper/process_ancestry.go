@@ -0,0 +1,58 @@
+package eventscraper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	otellog "go.opentelemetry.io/otel/log"
+)
+
+const (
+	defaultProcFSPath = "/proc"
+	// firstAncestorLevel is the level of the first ancestor reported after the parent executable.
+	// Following the Falco conventions, proc.aexepath[1] is the parent one, i.e. proc.pexepath.
+	firstAncestorLevel = 2
+)
+
+// WithProcessAncestry adds to the violation event records the executables of up to depth ancestors of
+// the parent executable, e.g. 1 for the grandparent. The executables are resolved from the procfs of the
+// host, the ancestors that already exited are skipped.
+func WithProcessAncestry(depth int) Option {
+	return func(es *EventScraper) {
+		es.ancestryDepth = depth
+	}
+}
+
+// ancestorExePaths returns the executables of the ancestors of the parent executable, closest first.
+// An ancestor whose executable cannot be resolved has an empty path, so that the levels are kept.
+func (es *EventScraper) ancestorExePaths(pids []uint32) []string {
+	// the first pid is the parent of the process that required the execution, i.e. the grandparent.
+	pids = pids[:min(len(pids), es.ancestryDepth)]
+	if len(pids) == 0 {
+		return nil
+	}
+	paths := make([]string, len(pids))
+	resolved := 0
+	for i, pid := range pids {
+		exePath, err := os.Readlink(filepath.Join(es.procFSPath, strconv.FormatUint(uint64(pid), 10), "exe"))
+		if err != nil {
+			continue
+		}
+		paths[i] = exePath
+		resolved = i + 1
+	}
+	return paths[:resolved]
+}
+
+// ancestryAttributes returns the ancestor executables as record attributes, named proc.aexepath[<level>].
+func ancestryAttributes(ancestorExePaths []string) []otellog.KeyValue {
+	var attrs []otellog.KeyValue
+	for i, exePath := range ancestorExePaths {
+		if exePath != "" {
+			attrs = append(attrs, otellog.String(fmt.Sprintf("proc.aexepath[%d]", i+firstAncestorLevel), exePath))
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,98 @@
+package eventscraper
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ScriptLearning selects what is learned when a container executes a script starting with a shebang.
+// The kernel only reports the script, the interpreter is read from its shebang line.
+type ScriptLearning string
+
+const (
+	// ScriptLearningScript learns the script path, as enforced by the BPF programs.
+	ScriptLearningScript ScriptLearning = "script"
+	// ScriptLearningInterpreter learns the interpreter instead of the script.
+	// A policy promoted from such a proposal doesn't allow the scripts themselves.
+	ScriptLearningInterpreter ScriptLearning = "interpreter"
+	// ScriptLearningBoth learns the script and its interpreter.
+	ScriptLearningBoth ScriptLearning = "both"
+)
+
+// maxShebangLen is the size of the buffer the kernel reads the shebang line from (BINPRM_BUF_SIZE).
+const maxShebangLen = 256
+
+// ParseScriptLearning parses the value of the --learning-scripts flag.
+func ParseScriptLearning(s string) (ScriptLearning, error) {
+	switch learning := ScriptLearning(s); learning {
+	case ScriptLearningScript, ScriptLearningInterpreter, ScriptLearningBoth:
+		return learning, nil
+	default:
+		return "", fmt.Errorf("unknown script learning %q, must be one of: %s, %s, %s",
+			s, ScriptLearningScript, ScriptLearningInterpreter, ScriptLearningBoth)
+	}
+}
+
+// WithScriptLearning sets what is learned when a container executes a script.
+func WithScriptLearning(learning ScriptLearning) Option {
+	return func(es *EventScraper) {
+		es.scriptLearning = learning
+	}
+}
+
+// learnedPaths returns the executables learned for the execution of exePath in the container.
+// When the interpreter cannot be read, e.g. because the container already exited, the script is learned.
+func (es *EventScraper) learnedPaths(rootPath, exePath string) []string {
+	if es.scriptLearning == ScriptLearningScript || rootPath == "" {
+		return []string{exePath}
+	}
+	interpreter, err := scriptInterpreter(rootPath, exePath)
+	if err != nil {
+		es.logger.Debug("failed to read the script interpreter", "exe", exePath, "error", err)
+		return []string{exePath}
+	}
+	switch {
+	case interpreter == "":
+		return []string{exePath}
+	case es.scriptLearning == ScriptLearningInterpreter:
+		return []string{interpreter}
+	default:
+		return []string{exePath, interpreter}
+	}
+}
+
+// scriptInterpreter returns the interpreter of the script inside the container root filesystem,
+// or an empty string when the file doesn't start with a shebang.
+func scriptInterpreter(rootPath, path string) (string, error) {
+	root, err := os.OpenRoot(rootPath)
+	if err != nil {
+		return "", err
+	}
+	defer root.Close()
+
+	f, err := root.Open(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReaderSize(io.LimitReader(f, maxShebangLen), maxShebangLen).ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	interpreterLine, ok := bytes.CutPrefix(line, []byte("#!"))
+	if !ok {
+		return "", nil
+	}
+	// the interpreter is followed by at most one argument, e.g. "#!/usr/bin/env python3".
+	fields := strings.Fields(string(interpreterLine))
+	if len(fields) == 0 {
+		return "", nil
+	}
+	return fields[0], nil
+}
@@ -0,0 +1,23 @@
+package eventscraper
+
+import (
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/severity"
+)
+
+// WithMinExportSeverity only exports to the violation logger the violations of the policies
+// with at least the given severity.
+func WithMinExportSeverity(level severity.Level) Option {
+	return func(es *EventScraper) {
+		es.minExportSeverity = level
+	}
+}
+
+// isSevereEnough reports whether the violations of a policy with the given severity are exported.
+func (es *EventScraper) isSevereEnough(policySeverity string) bool {
+	level, err := severity.Parse(policySeverity)
+	if err != nil {
+		// the severity is validated by the CRD, export rather than silently drop the violation.
+		return true
+	}
+	return level >= es.minExportSeverity
+}
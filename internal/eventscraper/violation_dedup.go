@@ -0,0 +1,109 @@
+package eventscraper
+
+import (
+	"context"
+	"time"
+
+	otellog "go.opentelemetry.io/otel/log"
+)
+
+// maxDedupFlushInterval bounds the delay between the end of a deduplication window and the export of its violation.
+const maxDedupFlushInterval = time.Second
+
+// WithDedupWindow collapses the identical violations, same pod, executable and action, exported within
+// the window into a single event with their count. The event is exported once the window of the first
+// violation ended. Zero disables the deduplication, the violation buffer is never deduplicated.
+func WithDedupWindow(window time.Duration) Option {
+	return func(es *EventScraper) {
+		if window > 0 {
+			es.dedup = newViolationDedup(window)
+		}
+	}
+}
+
+// violationKey identifies the identical violations.
+type violationKey struct {
+	namespace string
+	pod       string
+	exePath   string
+	action    string
+}
+
+// pendingViolation is the first violation of a window, waiting for the end of the window to be exported.
+type pendingViolation struct {
+	info      KubeProcessInfo
+	attrs     []otellog.KeyValue
+	action    string
+	firstSeen time.Time
+	count     int64
+}
+
+// violationDedup coalesces the identical violations. It is only used by the goroutine of Start.
+type violationDedup struct {
+	window  time.Duration
+	pending map[violationKey]*pendingViolation
+	now     func() time.Time
+}
+
+func newViolationDedup(window time.Duration) *violationDedup {
+	return &violationDedup{
+		window:  window,
+		pending: make(map[violationKey]*pendingViolation),
+		now:     time.Now,
+	}
+}
+
+// flushInterval is how often the windows are checked for expiration.
+func (d *violationDedup) flushInterval() time.Duration {
+	return min(d.window, maxDedupFlushInterval)
+}
+
+// add counts the violation in the window of the identical ones, opening a window if there is none.
+func (d *violationDedup) add(info *KubeProcessInfo, attrs []otellog.KeyValue, action string) {
+	key := violationKey{namespace: info.Namespace, pod: info.PodName, exePath: info.ExecutablePath, action: action}
+	if pending, ok := d.pending[key]; ok {
+		pending.count++
+		return
+	}
+	d.pending[key] = &pendingViolation{
+		info:      *info,
+		attrs:     attrs,
+		action:    action,
+		firstSeen: d.now(),
+		count:     1,
+	}
+}
+
+// expired removes and returns the violations whose window ended, all of them when force is set.
+func (d *violationDedup) expired(force bool) []*pendingViolation {
+	now := d.now()
+	var expired []*pendingViolation
+	for key, pending := range d.pending {
+		if force || now.Sub(pending.firstSeen) >= d.window {
+			expired = append(expired, pending)
+			delete(d.pending, key)
+		}
+	}
+	return expired
+}
+
+// exportViolation exports the violation, or counts it in its deduplication window when enabled.
+func (es *EventScraper) exportViolation(
+	ctx context.Context,
+	info *KubeProcessInfo,
+	attrs []otellog.KeyValue,
+	action string,
+) {
+	if es.dedup == nil {
+		es.emitViolationEvent(ctx, info, attrs, action, time.Now(), 0)
+		return
+	}
+	es.dedup.add(info, attrs, action)
+}
+
+// flushDedup exports the violations whose deduplication window ended, all of them when force is set.
+func (es *EventScraper) flushDedup(ctx context.Context, force bool) {
+	for _, pending := range es.dedup.expired(force) {
+		es.emitViolationEvent(ctx, &pending.info, pending.attrs, pending.action, pending.firstSeen, pending.count)
+	}
+}
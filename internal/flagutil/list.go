@@ -0,0 +1,16 @@
+// Package flagutil provides helpers to parse the command line flags of the agent and the controller.
+package flagutil
+
+import "strings"
+
+// ParseList parses a comma separated list, e.g. of namespaces, label keys or executable paths.
+// The items are trimmed and the empty ones are skipped.
+func ParseList(s string) []string {
+	var items []string
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
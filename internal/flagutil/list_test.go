@@ -0,0 +1,13 @@
+package flagutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseList(t *testing.T) {
+	require.Nil(t, ParseList(""))
+	require.Nil(t, ParseList(" , "))
+	require.Equal(t, []string{"kube-system", "runtime-enforcer"}, ParseList("kube-system, ,runtime-enforcer ,"))
+}
@@ -6,17 +6,28 @@ import (
 
 	pb "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
 	agentClientTimeout = 30 * time.Second
 )
 
+// IsResponseTooLarge reports whether the call failed because the response of the agent exceeds
+// the maximum receive message size. The connection is healthy, reconnecting doesn't help.
+func IsResponseTooLarge(err error) bool {
+	return status.Code(err) == codes.ResourceExhausted
+}
+
 // AgentClientAPI this interface could be used to mock clients in tests.
 type AgentClientAPI interface {
 	ListPoliciesStatus(ctx context.Context) (map[string]*pb.PolicyStatus, error)
 	ScrapeViolations(ctx context.Context) ([]*pb.ViolationRecord, error)
 	ListPodCache(ctx context.Context) ([]*pb.PodView, error)
+	GetAgentInfo(ctx context.Context) (*pb.GetAgentInfoResponse, error)
+	// SetEnforcementOverride returns whether the agent switched to or from the override.
+	SetEnforcementOverride(ctx context.Context, enabled bool) (bool, error)
 	Close() error
 }
 
@@ -60,6 +71,24 @@ func (c *AgentClient) ListPodCache(ctx context.Context) ([]*pb.PodView, error) {
 	return resp.GetPods(), nil
 }
 
+func (c *AgentClient) GetAgentInfo(ctx context.Context) (*pb.GetAgentInfoResponse, error) {
+	timeoutCtx, timeoutCancel := context.WithTimeout(ctx, c.timeout)
+	defer timeoutCancel()
+
+	return c.client.GetAgentInfo(timeoutCtx, &pb.GetAgentInfoRequest{})
+}
+
+func (c *AgentClient) SetEnforcementOverride(ctx context.Context, enabled bool) (bool, error) {
+	timeoutCtx, timeoutCancel := context.WithTimeout(ctx, c.timeout)
+	defer timeoutCancel()
+
+	resp, err := c.client.SetEnforcementOverride(timeoutCtx, &pb.SetEnforcementOverrideRequest{Enabled: enabled})
+	if err != nil {
+		return false, err
+	}
+	return resp.GetChanged(), nil
+}
+
 func (c *AgentClient) Close() error {
 	if c.conn != nil {
 		return c.conn.Close()
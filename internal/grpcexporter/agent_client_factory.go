@@ -2,10 +2,12 @@ package grpcexporter
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/rancher-sandbox/runtime-enforcer/internal/tlsutil"
 	pb "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
@@ -21,18 +23,35 @@ type AgentClientFactory struct {
 	tlsCertPath string
 	tlsKeyPath  string
 	caCertPath  string
+	idleTimeout time.Duration
+	maxRecvSize int
+	// expectedIdentity is the identity template the agent certificates must present, empty to not check it.
+	expectedIdentity string
 }
 
 type AgentFactoryConfig struct {
 	MTLSEnabled bool
 	CertDirPath string
 	Port        int
+	// IdleTimeout is how long a connection to an agent is kept without any call before it is closed.
+	// The connection is established again by the next call, zero keeps the connections open.
+	IdleTimeout time.Duration
+	// MaxRecvMsgSize is the largest response accepted from an agent, in bytes.
+	// Zero uses DefaultAgentMaxRecvMsgSize.
+	MaxRecvMsgSize int
+	// ExpectedIdentity, if set, is the URI or DNS SAN the certificate of every agent must have,
+	// e.g. spiffe://cluster.local/ns/{namespace}/sa/runtime-enforcer-agent. The {namespace}, {pod}
+	// and {node} placeholders are replaced with the ones of the agent pod. It requires mTLS.
+	ExpectedIdentity string
 }
 
 func NewAgentClientFactory(conf *AgentFactoryConfig) (*AgentClientFactory, error) {
 	if conf.Port == 0 {
 		return nil, fmt.Errorf("invalid gRPC port: %d", conf.Port)
 	}
+	if conf.ExpectedIdentity != "" && !conf.MTLSEnabled {
+		return nil, errors.New("the agent identity can only be verified with mTLS")
+	}
 
 	var tlsCertPath string
 	var tlsKeyPath string
@@ -45,16 +64,26 @@ func NewAgentClientFactory(conf *AgentFactoryConfig) (*AgentClientFactory, error
 		tlsKeyPath = filepath.Join(conf.CertDirPath, tlsutil.KeyFile)
 		caCertPath = filepath.Join(conf.CertDirPath, tlsutil.CAFile)
 	}
+	maxRecvSize := conf.MaxRecvMsgSize
+	if maxRecvSize == 0 {
+		maxRecvSize = DefaultAgentMaxRecvMsgSize
+	}
 	return &AgentClientFactory{
 		port:        strconv.Itoa(conf.Port),
 		tlsCertPath: tlsCertPath,
 		tlsKeyPath:  tlsKeyPath,
 		caCertPath:  caCertPath,
 		mTLSEnabled: conf.MTLSEnabled,
+		idleTimeout: conf.IdleTimeout,
+		maxRecvSize: maxRecvSize,
+
+		expectedIdentity: conf.ExpectedIdentity,
 	}, nil
 }
 
-func (f *AgentClientFactory) getConnCredentials(podNamespacedName string) (credentials.TransportCredentials, error) {
+func (f *AgentClientFactory) getConnCredentials(
+	podName, podNamespace, nodeName string,
+) (credentials.TransportCredentials, error) {
 	if !f.mTLSEnabled {
 		return insecure.NewCredentials(), nil
 	}
@@ -75,19 +104,28 @@ func (f *AgentClientFactory) getConnCredentials(podNamespacedName string) (crede
 		RootCAs:      certPool,
 		MinVersion:   tls.VersionTLS13,
 		// the service name in the server certificate will be in this form
-		ServerName: podNamespacedName,
+		ServerName: fmt.Sprintf("%s.%s", podName, podNamespace),
+	}
+	if f.expectedIdentity != "" {
+		tlsConfig.VerifyConnection = verifyAgentIdentity(
+			expandAgentIdentity(f.expectedIdentity, podName, podNamespace, nodeName),
+		)
 	}
 	return credentials.NewTLS(tlsConfig), nil
 }
 
-func (f *AgentClientFactory) NewClient(podIP, podName, podNamespace string) (*AgentClient, error) {
-	creds, err := f.getConnCredentials(fmt.Sprintf("%s.%s", podName, podNamespace))
+func (f *AgentClientFactory) NewClient(podIP, podName, podNamespace, nodeName string) (*AgentClient, error) {
+	creds, err := f.getConnCredentials(podName, podNamespace, nodeName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get connection credentials: %w", err)
 	}
 
 	host := net.JoinHostPort(podIP, f.port)
-	conn, err := grpc.NewClient(host, grpc.WithTransportCredentials(creds))
+	conn, err := grpc.NewClient(host,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithIdleTimeout(f.idleTimeout),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(f.maxRecvSize)),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("grpc dial failed host %s: %w", host, err)
 	}
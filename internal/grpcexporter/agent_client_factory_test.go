@@ -0,0 +1,55 @@
+package grpcexporter
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// fakeAgentObserver answers GetAgentInfo with a fixed kernel version.
+type fakeAgentObserver struct {
+	pb.UnimplementedAgentObserverServer
+}
+
+func (fakeAgentObserver) GetAgentInfo(context.Context, *pb.GetAgentInfoRequest) (*pb.GetAgentInfoResponse, error) {
+	return &pb.GetAgentInfoResponse{KernelVersion: "6.8.0"}, nil
+}
+
+func TestAgentClientIdleTimeout(t *testing.T) {
+	lis, err := (&net.ListenConfig{}).Listen(t.Context(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	pb.RegisterAgentObserverServer(server, fakeAgentObserver{})
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	factory, err := NewAgentClientFactory(&AgentFactoryConfig{
+		Port:        lis.Addr().(*net.TCPAddr).Port,
+		IdleTimeout: 100 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	client, err := factory.NewClient("127.0.0.1", "agent", "default", "test-node")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	info, err := client.GetAgentInfo(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "6.8.0", info.GetKernelVersion())
+
+	// the connection is closed once no call is made for the idle timeout.
+	require.Eventually(t, func() bool {
+		return client.conn.GetState() == connectivity.Idle
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// and established again by the next call.
+	info, err = client.GetAgentInfo(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "6.8.0", info.GetKernelVersion())
+	require.Equal(t, connectivity.Ready, client.conn.GetState())
+}
@@ -10,6 +10,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -43,11 +44,43 @@ func convertLabelStringToSelector(labelString string) (map[string]string, error)
 				label,
 				labelString)
 		}
-		agentLabelSelector[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+			return nil, fmt.Errorf("invalid label key %q in selector %s: %s", key, labelString, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+			return nil, fmt.Errorf("invalid label value %q in selector %s: %s", value, labelString, strings.Join(errs, "; "))
+		}
+		agentLabelSelector[key] = value
 	}
 	return agentLabelSelector, nil
 }
 
+// Validate checks the configuration without creating the pool, so that errors can be reported at startup.
+func (c *AgentClientPoolConfig) Validate() error {
+	if _, err := convertLabelStringToSelector(c.LabelSelectorString); err != nil {
+		return fmt.Errorf("invalid agent label selector: %w", err)
+	}
+	if c.Namespace != "" {
+		if errs := validation.IsDNS1123Label(c.Namespace); len(errs) != 0 {
+			return fmt.Errorf("invalid agent namespace %q: %s", c.Namespace, strings.Join(errs, "; "))
+		}
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid agent port: %d", c.Port)
+	}
+	if c.IdleTimeout < 0 {
+		return fmt.Errorf("invalid agent idle timeout: %v", c.IdleTimeout)
+	}
+	if c.MaxRecvMsgSize < 0 {
+		return fmt.Errorf("invalid agent max receive message size: %d", c.MaxRecvMsgSize)
+	}
+	if c.ExpectedIdentity != "" && !c.MTLSEnabled {
+		return errors.New("the agent identity can only be verified with mTLS")
+	}
+	return nil
+}
+
 func getNamespace() (string, error) {
 	const namespaceNamePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
 	// Get the agent namespace from the system.
@@ -137,7 +170,7 @@ func (p *AgentClientPool) getOrCreateClient(pod *corev1.Pod) (AgentClientAPI, er
 		return agentClient, nil
 	}
 
-	c, err := p.factory.NewClient(pod.Status.PodIP, pod.Name, pod.Namespace)
+	c, err := p.factory.NewClient(pod.Status.PodIP, pod.Name, pod.Namespace, node)
 	if err != nil {
 		p.clients[node] = nil
 		return nil, fmt.Errorf("failed to create connection to pod %s: %w", pod.Name, err)
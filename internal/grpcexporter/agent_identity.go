@@ -0,0 +1,79 @@
+package grpcexporter
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// The placeholders of AgentFactoryConfig.ExpectedIdentity replaced with the ones of the agent pod.
+const (
+	identityNamespacePlaceholder = "{namespace}"
+	identityPodPlaceholder       = "{pod}"
+	identityNodePlaceholder      = "{node}"
+)
+
+// expandAgentIdentity returns the identity expected from the agent pod.
+func expandAgentIdentity(template, podName, podNamespace, nodeName string) string {
+	return strings.NewReplacer(
+		identityNamespacePlaceholder, podNamespace,
+		identityPodPlaceholder, podName,
+		identityNodePlaceholder, nodeName,
+	).Replace(template)
+}
+
+// verifyAgentIdentity returns a tls.Config VerifyConnection callback refusing the agents whose certificate,
+// already verified against the CA, has no URI or DNS SAN equal to the expected identity,
+// e.g. a SPIFFE ID like spiffe://cluster.local/ns/runtime-enforcer/sa/runtime-enforcer-agent.
+func verifyAgentIdentity(expected string) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return errors.New("the agent presented no certificate")
+		}
+		if hasIdentity(state.PeerCertificates[0], expected) {
+			return nil
+		}
+		return fmt.Errorf("the agent certificate doesn't have the expected identity %q", expected)
+	}
+}
+
+// hasIdentity reports whether the certificate has an URI or DNS SAN equal to the identity.
+func hasIdentity(cert *x509.Certificate, identity string) bool {
+	for _, uri := range cert.URIs {
+		if uri.String() == identity {
+			return true
+		}
+	}
+	return slices.Contains(cert.DNSNames, identity)
+}
+
+// authorizedIdentity returns the first of the identities found in the client certificate of the gRPC call.
+// The call is refused without mTLS, since the client cannot be identified.
+func authorizedIdentity(ctx context.Context, mTLSEnabled bool, identities []string) (string, error) {
+	if !mTLSEnabled {
+		return "", status.Error(codes.FailedPrecondition, "the caller can only be authorized with mTLS")
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "the caller presented no certificate")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		return "", status.Error(codes.Unauthenticated, "the caller presented no certificate")
+	}
+	for _, identity := range identities {
+		if hasIdentity(tlsInfo.State.PeerCertificates[0], identity) {
+			return identity, nil
+		}
+	}
+	return "", status.Error(codes.PermissionDenied, "the caller certificate has none of the authorized identities")
+}
@@ -0,0 +1,75 @@
+package grpcexporter
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestExpandAgentIdentity(t *testing.T) {
+	require.Equal(t,
+		"spiffe://cluster.local/ns/runtime-enforcer/node/worker-1/pod/agent-abc",
+		expandAgentIdentity("spiffe://cluster.local/ns/{namespace}/node/{node}/pod/{pod}",
+			"agent-abc", "runtime-enforcer", "worker-1"),
+	)
+	require.Equal(t, "agent-abc.runtime-enforcer",
+		expandAgentIdentity("{pod}.{namespace}", "agent-abc", "runtime-enforcer", "worker-1"))
+}
+
+func TestVerifyAgentIdentity(t *testing.T) {
+	spiffeID, err := url.Parse("spiffe://cluster.local/ns/runtime-enforcer/sa/runtime-enforcer-agent")
+	require.NoError(t, err)
+	state := func(cert *x509.Certificate) tls.ConnectionState {
+		return tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	}
+	agentCert := &x509.Certificate{
+		URIs:     []*url.URL{spiffeID},
+		DNSNames: []string{"agent-abc.runtime-enforcer"},
+	}
+
+	require.NoError(t, verifyAgentIdentity(spiffeID.String())(state(agentCert)))
+	require.NoError(t, verifyAgentIdentity("agent-abc.runtime-enforcer")(state(agentCert)))
+
+	// another agent, or any other workload with a certificate from the same CA, is refused.
+	require.Error(t, verifyAgentIdentity("agent-xyz.runtime-enforcer")(state(agentCert)))
+	require.Error(t, verifyAgentIdentity("spiffe://cluster.local/ns/default/sa/default")(state(agentCert)))
+	require.Error(t, verifyAgentIdentity(spiffeID.String())(tls.ConnectionState{}))
+}
+
+func TestAuthorizedIdentity(t *testing.T) {
+	operatorID, err := url.Parse("spiffe://cluster.local/ns/ops/sa/debugger")
+	require.NoError(t, err)
+	callFrom := func(cert *x509.Certificate) context.Context {
+		state := tls.ConnectionState{}
+		if cert != nil {
+			state.PeerCertificates = []*x509.Certificate{cert}
+		}
+		return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+	}
+	identities := []string{"debugger.ops", operatorID.String()}
+
+	identity, err := authorizedIdentity(callFrom(&x509.Certificate{URIs: []*url.URL{operatorID}}), true, identities)
+	require.NoError(t, err)
+	require.Equal(t, operatorID.String(), identity)
+
+	// the other clients of the CA, e.g. the controller, are refused.
+	_, err = authorizedIdentity(callFrom(&x509.Certificate{DNSNames: []string{"controller.runtime-enforcer"}}),
+		true, identities)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = authorizedIdentity(callFrom(nil), true, identities)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = authorizedIdentity(context.Background(), true, identities)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// without mTLS, the caller cannot be identified.
+	_, err = authorizedIdentity(callFrom(&x509.Certificate{URIs: []*url.URL{operatorID}}), false, identities)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
@@ -2,13 +2,23 @@ package grpcexporter
 
 import (
 	"context"
+	"time"
 
 	"log/slog"
 
+	"github.com/rancher-sandbox/runtime-enforcer/internal/kernels"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/violationbuf"
 	pb "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+)
+
+const (
+	execBypassReason = "ExecBypassGranted"
+	execBypassAction = "GrantExecBypass"
 )
 
 // agentObserver implements the AgentObserver gRPC server.
@@ -16,19 +26,43 @@ type agentObserver struct {
 	pb.UnimplementedAgentObserverServer
 
 	logger          *slog.Logger
+	mTLSEnabled     bool
 	resolver        *resolver.Resolver
 	violationBuffer *violationbuf.Buffer
+	kernelFeatures  []*pb.KernelFeature
+	bpfLoadConfig   *pb.BpfLoadConfig
+	// containerRuntime, if set, returns the container runtime the agent is connected to.
+	containerRuntime func() *pb.ContainerRuntime
+	// cgroupResolution, if set, returns the outcome of the last cgroup resolutions.
+	cgroupResolution func() *pb.CgroupResolution
+	// execBypassIdentities are the client identities allowed to grant exec bypasses.
+	execBypassIdentities []string
+	// recorder, if set, records an Event on the pods whose containers are granted an exec bypass.
+	recorder events.EventRecorder
 }
 
 func newAgentObserver(
 	logger *slog.Logger,
+	conf *Config,
 	resolver *resolver.Resolver,
 	violationBuffer *violationbuf.Buffer,
+	kernelFeatures []*pb.KernelFeature,
+	bpfLoadConfig *pb.BpfLoadConfig,
+	containerRuntime func() *pb.ContainerRuntime,
+	cgroupResolution func() *pb.CgroupResolution,
+	recorder events.EventRecorder,
 ) *agentObserver {
 	return &agentObserver{
-		logger:          logger.With("component", "agent_observer"),
-		resolver:        resolver,
-		violationBuffer: violationBuffer,
+		logger:               logger.With("component", "agent_observer"),
+		mTLSEnabled:          conf.MTLSEnabled,
+		resolver:             resolver,
+		violationBuffer:      violationBuffer,
+		kernelFeatures:       kernelFeatures,
+		bpfLoadConfig:        bpfLoadConfig,
+		containerRuntime:     containerRuntime,
+		cgroupResolution:     cgroupResolution,
+		execBypassIdentities: conf.ExecBypassIdentities,
+		recorder:             recorder,
 	}
 }
 
@@ -44,9 +78,11 @@ func (s *agentObserver) ListPoliciesStatus(
 	statuses := s.resolver.GetPolicyStatuses()
 	for policyName, ps := range statuses {
 		out.Policies[policyName] = &pb.PolicyStatus{
-			State:   ps.State,
-			Mode:    ps.Mode,
-			Message: ps.Message,
+			State:              ps.State,
+			Mode:               ps.Mode,
+			Message:            ps.Message,
+			MatchedContainers:  ps.MatchedContainers,
+			EnforcedContainers: ps.EnforcedContainers,
 		}
 	}
 
@@ -121,3 +157,91 @@ func (s *agentObserver) ScrapeViolations(
 	s.logger.DebugContext(ctx, "scraped violations", "count", len(out.GetViolations()))
 	return out, nil
 }
+
+// GetAgentInfo returns the kernel version, the eBPF features probed at startup, the configuration
+// the eBPF programs were loaded with, the container runtime the agent is connected to and
+// the outcome of the last cgroup resolutions.
+func (s *agentObserver) GetAgentInfo(
+	_ context.Context,
+	_ *pb.GetAgentInfoRequest,
+) (*pb.GetAgentInfoResponse, error) {
+	info := &pb.GetAgentInfoResponse{
+		KernelVersion:  kernels.GetCurrKernelVersionStr(),
+		KernelFeatures: s.kernelFeatures,
+		BpfLoadConfig:  s.bpfLoadConfig,
+	}
+	if s.containerRuntime != nil {
+		info.ContainerRuntime = s.containerRuntime()
+	}
+	if s.cgroupResolution != nil {
+		info.CgroupResolution = s.cgroupResolution()
+	}
+	return info, nil
+}
+
+// SelfCheck verifies the invariants of the resolver cache, so that an inconsistent
+// state can be detected without restarting the agent.
+func (s *agentObserver) SelfCheck(
+	ctx context.Context,
+	_ *pb.SelfCheckRequest,
+) (*pb.SelfCheckResponse, error) {
+	anomalies := s.resolver.SelfCheck()
+	if len(anomalies) > 0 {
+		s.logger.WarnContext(ctx, "resolver self check found anomalies", "anomalies", anomalies)
+	}
+	return &pb.SelfCheckResponse{Anomalies: anomalies}, nil
+}
+
+// GrantExecBypass detaches a container from its policy until the TTL of the request expires.
+// Only the mTLS clients with one of the exec bypass identities are allowed to call it.
+func (s *agentObserver) GrantExecBypass(
+	ctx context.Context,
+	req *pb.GrantExecBypassRequest,
+) (*pb.GrantExecBypassResponse, error) {
+	identity, err := authorizedIdentity(ctx, s.mTLSEnabled, s.execBypassIdentities)
+	if err != nil {
+		s.logger.WarnContext(ctx, "refused exec bypass",
+			"namespace", req.GetNamespace(),
+			"pod", req.GetPodName(),
+			"container", req.GetContainerName(),
+			"error", err,
+		)
+		return nil, err
+	}
+	expiresAt, err := s.resolver.GrantExecBypass(
+		req.GetNamespace(),
+		req.GetPodName(),
+		req.GetContainerName(),
+		req.GetTtl().AsDuration(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "granted exec bypass",
+		"namespace", req.GetNamespace(),
+		"pod", req.GetPodName(),
+		"container", req.GetContainerName(),
+		"identity", identity,
+		"expiresAt", expiresAt,
+	)
+	if s.recorder != nil {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: req.GetNamespace(), Name: req.GetPodName()}}
+		s.recorder.Eventf(pod, nil, corev1.EventTypeWarning, execBypassReason, execBypassAction,
+			"Exec bypass granted to %s on container %s until %s, it is not enforced",
+			identity, req.GetContainerName(), expiresAt.UTC().Format(time.RFC3339))
+	}
+	return &pb.GrantExecBypassResponse{ExpiresAt: timestamppb.New(expiresAt)}, nil
+}
+
+// SetEnforcementOverride switches all the policies to monitor mode while the override is enabled.
+// The controller calls it at each status sync, following the EnforcementOverrides of the cluster.
+func (s *agentObserver) SetEnforcementOverride(
+	ctx context.Context,
+	req *pb.SetEnforcementOverrideRequest,
+) (*pb.SetEnforcementOverrideResponse, error) {
+	changed := s.resolver.SetEnforcementOverride(req.GetEnabled())
+	if changed {
+		s.logger.InfoContext(ctx, "enforcement override switched", "enabled", req.GetEnabled())
+	}
+	return &pb.SetEnforcementOverrideResponse{Changed: changed}, nil
+}
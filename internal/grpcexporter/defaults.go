@@ -1,7 +1,14 @@
 package grpcexporter
 
+import "time"
+
 const (
 	DefaultAgentLabelSelectorString = "app.kubernetes.io/component=agent"
 	DefaultAgentPort                = 50051
 	DefaultCertDirPath              = "/etc/runtime-enforcer/certs"
+	// DefaultAgentIdleTimeout is the idle timeout gRPC uses when none is set.
+	DefaultAgentIdleTimeout = 30 * time.Minute
+	// DefaultAgentMaxRecvMsgSize is the largest response accepted from an agent, in bytes.
+	// The pod cache and the violations of a busy node don't fit in the 4MB gRPC default.
+	DefaultAgentMaxRecvMsgSize = 128 << 20
 )
@@ -3,6 +3,7 @@ package grpcexporter
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
@@ -15,6 +16,8 @@ import (
 	pb "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
+	"k8s.io/client-go/tools/events"
 )
 
 const gracefulGRPCTimeout = 5 * time.Second
@@ -23,13 +26,29 @@ type Config struct {
 	MTLSEnabled bool
 	CertDirPath string
 	Port        int
+	// ReflectionEnabled registers the gRPC reflection service, so that tools like grpcurl
+	// can be used against the agent without the proto files.
+	ReflectionEnabled bool
+	// TLS restricts the TLS versions and cipher suites of the mTLS connections, TLS 1.3 only when unset.
+	TLS tlsutil.Options
+	// ExecBypassIdentities are the URI or DNS SANs of the client certificates allowed to grant exec bypasses.
+	// It requires mTLS, no client can grant them when empty.
+	ExecBypassIdentities []string
 }
 
 type Server struct {
 	logger          *slog.Logger
 	resolver        *resolver.Resolver
 	violationBuffer *violationbuf.Buffer
-	conf            *Config
+	kernelFeatures  []*pb.KernelFeature
+	bpfLoadConfig   *pb.BpfLoadConfig
+	// containerRuntime returns the container runtime reported by GetAgentInfo.
+	containerRuntime func() *pb.ContainerRuntime
+	// cgroupResolution returns the cgroup resolutions reported by GetAgentInfo.
+	cgroupResolution func() *pb.CgroupResolution
+	// recorder records an Event on the pods whose containers are granted an exec bypass.
+	recorder events.EventRecorder
+	conf     *Config
 }
 
 func (s *Server) getConnCredentials() grpc.ServerOption {
@@ -40,6 +59,10 @@ func (s *Server) getConnCredentials() grpc.ServerOption {
 	caCertPath := filepath.Join(s.conf.CertDirPath, tlsutil.CAFile)
 	tlsCertPath := filepath.Join(s.conf.CertDirPath, tlsutil.CertFile)
 	tlsKeyPath := filepath.Join(s.conf.CertDirPath, tlsutil.KeyFile)
+	tlsOpts := s.conf.TLS
+	if tlsOpts.MinVersion == 0 {
+		tlsOpts.MinVersion = tls.VersionTLS13
+	}
 
 	tlsConfig := &tls.Config{
 		// gosec: wants the version specified also here
@@ -64,14 +87,17 @@ func (s *Server) getConnCredentials() grpc.ServerOption {
 			}
 
 			// Return a new config for the connection
-			return &tls.Config{
+			connConfig := &tls.Config{
 				Certificates: []tls.Certificate{cert},
 				ClientAuth:   tls.RequireAndVerifyClientCert,
 				ClientCAs:    certPool,
 				MinVersion:   tls.VersionTLS13,
-			}, nil
+			}
+			tlsOpts.Apply(connConfig)
+			return connConfig, nil
 		},
 	}
+	tlsOpts.Apply(tlsConfig)
 	return grpc.Creds(credentials.NewTLS(tlsConfig))
 }
 
@@ -80,6 +106,11 @@ func New(
 	conf *Config,
 	resolver *resolver.Resolver,
 	violationBuffer *violationbuf.Buffer,
+	kernelFeatures []*pb.KernelFeature,
+	bpfLoadConfig *pb.BpfLoadConfig,
+	containerRuntime func() *pb.ContainerRuntime,
+	cgroupResolution func() *pb.CgroupResolution,
+	recorder events.EventRecorder,
 ) (*Server, error) {
 	if conf.MTLSEnabled {
 		// Check that the certificate path is valid before starting the server
@@ -87,11 +118,19 @@ func New(
 			return nil, fmt.Errorf("invalid certificate directory: %w", err)
 		}
 	}
+	if len(conf.ExecBypassIdentities) > 0 && !conf.MTLSEnabled {
+		return nil, errors.New("the exec bypass identities can only be verified with mTLS")
+	}
 	return &Server{
-		logger:          logger.With("component", "grpc_exporter"),
-		conf:            conf,
-		resolver:        resolver,
-		violationBuffer: violationBuffer,
+		logger:           logger.With("component", "grpc_exporter"),
+		conf:             conf,
+		resolver:         resolver,
+		violationBuffer:  violationBuffer,
+		kernelFeatures:   kernelFeatures,
+		bpfLoadConfig:    bpfLoadConfig,
+		containerRuntime: containerRuntime,
+		cgroupResolution: cgroupResolution,
+		recorder:         recorder,
 	}, nil
 }
 
@@ -106,8 +145,17 @@ func (s *Server) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to listen on %s: %w", addr, err)
 	}
 	grpcServer := grpc.NewServer(s.getConnCredentials())
-	pb.RegisterAgentObserverServer(grpcServer, newAgentObserver(s.logger, s.resolver, s.violationBuffer))
-	s.logger.InfoContext(ctx, "Starting gRPC exporter", "addr", addr, "mTLS", s.conf.MTLSEnabled)
+	pb.RegisterAgentObserverServer(grpcServer, newAgentObserver(
+		s.logger, s.conf, s.resolver, s.violationBuffer, s.kernelFeatures, s.bpfLoadConfig, s.containerRuntime,
+		s.cgroupResolution, s.recorder,
+	))
+	if s.conf.ReflectionEnabled {
+		reflection.Register(grpcServer)
+	}
+	s.logger.InfoContext(ctx, "Starting gRPC exporter",
+		"addr", addr,
+		"mTLS", s.conf.MTLSEnabled,
+		"reflection", s.conf.ReflectionEnabled)
 
 	serveErrCh := make(chan error, 1)
 	go func() {
@@ -17,6 +17,8 @@ func newPolicyCmd(deps commonCmdDeps) *cobra.Command {
 	cmd.AddCommand(newPolicyShowCmd(deps))
 	cmd.AddCommand(newPolicyExecAllowCmd(deps))
 	cmd.AddCommand(newPolicyExecDenyCmd(deps))
+	cmd.AddCommand(newPolicyExportCmd(deps))
+	cmd.AddCommand(newPolicyMigrateCmd(deps))
 
 	return cmd
 }
@@ -0,0 +1,120 @@
+package kubectlplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/opaexport"
+	securityclient "github.com/rancher-sandbox/runtime-enforcer/pkg/generated/clientset/versioned/typed/api/v1alpha1"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type policyExportOptions struct {
+	commonOptions
+
+	PolicyName       string
+	AllNamespaces    bool
+	IncludeProposals bool
+}
+
+func newPolicyExportCmd(deps commonCmdDeps) *cobra.Command {
+	opts := &policyExportOptions{
+		commonOptions: newCommonOptions(deps),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "export [POLICY_NAME]",
+		Short: "Export WorkloadPolicy as an OPA data document",
+		Long: "Export the WorkloadPolicies, and optionally the WorkloadPolicyProposals, as a JSON document " +
+			"that can be loaded as data in an OPA bundle. The format is described in docs/opa_export.adoc. " +
+			"Without POLICY_NAME, every WorkloadPolicy of the namespace is exported.",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: newPolicyModeCmdValidArgsFunction(deps),
+		RunE:              runPolicyExportCmd(opts),
+	}
+
+	cmd.SetUsageTemplate(subcommandUsageTemplate)
+
+	cmd.Flags().BoolVarP(
+		&opts.AllNamespaces,
+		"all-namespaces",
+		"A",
+		false,
+		"If present, export the policies across all namespaces",
+	)
+	cmd.Flags().BoolVar(
+		&opts.IncludeProposals,
+		"include-proposals",
+		false,
+		"If present, export the WorkloadPolicyProposals of the same namespaces too",
+	)
+
+	return cmd
+}
+
+func runPolicyExportCmd(opts *policyExportOptions) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			opts.PolicyName = args[0]
+		}
+
+		return withRuntimeEnforcerClient(cmd, &opts.commonOptions, func(
+			ctx context.Context,
+			client securityclient.SecurityV1alpha1Interface,
+		) error {
+			return runPolicyExport(ctx, client, opts, opts.ioStreams.Out)
+		})
+	}
+}
+
+func runPolicyExport(
+	ctx context.Context,
+	client securityclient.SecurityV1alpha1Interface,
+	opts *policyExportOptions,
+	out io.Writer,
+) error {
+	doc := opaexport.NewDocument()
+
+	if opts.PolicyName != "" {
+		if opts.AllNamespaces || opts.IncludeProposals {
+			return errors.New("POLICY_NAME cannot be used with --all-namespaces or --include-proposals")
+		}
+		policy, err := client.WorkloadPolicies(opts.Namespace).Get(ctx, opts.PolicyName, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Errorf("workloadpolicy %q not found in namespace %q", opts.PolicyName, opts.Namespace)
+			}
+			return fmt.Errorf("failed to get WorkloadPolicy %q in namespace %q: %w", opts.PolicyName, opts.Namespace, err)
+		}
+		doc.AddPolicy(policy)
+		return doc.Write(out)
+	}
+
+	namespace := opts.Namespace
+	if opts.AllNamespaces {
+		namespace = metav1.NamespaceAll
+	}
+	policies, err := client.WorkloadPolicies(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list WorkloadPolicies in namespace %q: %w", namespace, err)
+	}
+	for i := range policies.Items {
+		doc.AddPolicy(&policies.Items[i])
+	}
+
+	if opts.IncludeProposals {
+		proposals, listErr := client.WorkloadPolicyProposals(namespace).List(ctx, metav1.ListOptions{})
+		if listErr != nil {
+			return fmt.Errorf("failed to list WorkloadPolicyProposals in namespace %q: %w", namespace, listErr)
+		}
+		for i := range proposals.Items {
+			doc.AddProposal(&proposals.Items[i])
+		}
+	}
+
+	return doc.Write(out)
+}
@@ -0,0 +1,83 @@
+package kubectlplugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/opaexport"
+	fakeclient "github.com/rancher-sandbox/runtime-enforcer/pkg/generated/clientset/versioned/fake"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRunPolicyExport(t *testing.T) {
+	t.Parallel()
+
+	rules := map[string]*securityv1alpha1.WorkloadPolicyRules{
+		"main": {Executables: securityv1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/bash"}}},
+	}
+	client := fakeclient.NewClientset(
+		&securityv1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "ubuntu", Namespace: "test"},
+			Spec:       securityv1alpha1.WorkloadPolicySpec{Mode: "protect", RulesByContainer: rules},
+		},
+		&securityv1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "test"},
+			Spec:       securityv1alpha1.WorkloadPolicySpec{Mode: "monitor", RulesByContainer: rules},
+		},
+		&securityv1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "other"},
+			Spec:       securityv1alpha1.WorkloadPolicySpec{Mode: "monitor", RulesByContainer: rules},
+		},
+		&securityv1alpha1.WorkloadPolicyProposal{
+			ObjectMeta: metav1.ObjectMeta{Name: "deploy-ubuntu", Namespace: "test"},
+			Spec:       securityv1alpha1.WorkloadPolicyProposalSpec{RulesByContainer: rules},
+		},
+	).SecurityV1alpha1()
+
+	export := func(opts *policyExportOptions) (*opaexport.Document, error) {
+		opts.Namespace = "test"
+		var out bytes.Buffer
+		if err := runPolicyExport(t.Context(), client, opts, &out); err != nil {
+			return nil, err
+		}
+		var doc opaexport.Document
+		require.NoError(t, json.Unmarshal(out.Bytes(), &doc))
+		return &doc, nil
+	}
+
+	t.Run("exports the policies of the namespace", func(t *testing.T) {
+		t.Parallel()
+		doc, err := export(&policyExportOptions{})
+		require.NoError(t, err)
+		require.Equal(t, opaexport.Format, doc.Format)
+		require.Len(t, doc.Policies, 1)
+		require.Len(t, doc.Policies["test"], 2)
+		require.Equal(t, "protect", doc.Policies["test"]["ubuntu"].Mode)
+		require.Empty(t, doc.Proposals)
+	})
+
+	t.Run("exports a single policy", func(t *testing.T) {
+		t.Parallel()
+		doc, err := export(&policyExportOptions{PolicyName: "nginx"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"/bin/bash"}, doc.Policies["test"]["nginx"].Containers["main"].Allowed)
+		require.Len(t, doc.Policies["test"], 1)
+
+		_, err = export(&policyExportOptions{PolicyName: "missing"})
+		require.ErrorContains(t, err, `workloadpolicy "missing" not found in namespace "test"`)
+		_, err = export(&policyExportOptions{PolicyName: "nginx", AllNamespaces: true})
+		require.ErrorContains(t, err, "POLICY_NAME cannot be used")
+	})
+
+	t.Run("exports the proposals and every namespace", func(t *testing.T) {
+		t.Parallel()
+		doc, err := export(&policyExportOptions{AllNamespaces: true, IncludeProposals: true})
+		require.NoError(t, err)
+		require.Len(t, doc.Policies, 2)
+		require.Contains(t, doc.Policies["other"], "redis")
+		require.Contains(t, doc.Proposals["test"], "deploy-ubuntu")
+	})
+}
@@ -0,0 +1,203 @@
+package kubectlplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	apiv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	securityclient "github.com/rancher-sandbox/runtime-enforcer/pkg/generated/clientset/versioned/typed/api/v1alpha1"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/cli-runtime/pkg/printers"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+const workloadSecurityPolicyKind = "WorkloadSecurityPolicy"
+
+type policyMigrateOptions struct {
+	commonOptions
+
+	PolicyFile string
+	Containers []string
+}
+
+// workloadSecurityPolicy is the WorkloadSecurityPolicy of docs/rfc/0001-workloadgroup.md,
+// whose single set of rules applies to every container of the selected pods.
+type workloadSecurityPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec struct {
+		Mode     string                `json:"mode,omitempty"`
+		Selector *metav1.LabelSelector `json:"selector,omitempty"`
+		Rules    struct {
+			Executables struct {
+				Allowed         []string `json:"allowed,omitempty"`
+				AllowedPrefixes []string `json:"allowedPrefixes,omitempty"`
+			} `json:"executables"`
+		} `json:"rules"`
+	} `json:"spec"`
+}
+
+func newPolicyMigrateCmd(deps commonCmdDeps) *cobra.Command {
+	opts := &policyMigrateOptions{
+		commonOptions: newCommonOptions(deps),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "migrate --filename FILE [--container NAME]...",
+		Short: "Migrate a WorkloadSecurityPolicy to a WorkloadPolicy",
+		Long: "Convert the manifest of a WorkloadSecurityPolicy, whose single rule set applies to every container, " +
+			"into a WorkloadPolicy with the same rules for each container of the workload, printed as YAML. " +
+			"The containers are the ones given with --container, or the containers and init containers " +
+			"of the pods matching the selector of the policy. The selector, the severity, the tags and the message " +
+			"are not migrated: the pods are bound to the WorkloadPolicy with the " + apiv1alpha1.PolicyLabelKey +
+			" label.",
+		Args: cobra.NoArgs,
+		RunE: runPolicyMigrateCmd(opts),
+	}
+
+	cmd.SetUsageTemplate(subcommandUsageTemplate)
+
+	cmd.Flags().StringVarP(&opts.PolicyFile, "filename", "f", "", "Manifest of the WorkloadSecurityPolicy to migrate")
+	cmd.Flags().StringSliceVar(&opts.Containers, "container", nil,
+		"Name of a container of the workload, instead of the ones of the pods matching the selector")
+	_ = cmd.MarkFlagRequired("filename")
+
+	return cmd
+}
+
+func runPolicyMigrateCmd(opts *policyMigrateOptions) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, _ []string) error {
+		return withRuntimeEnforcerAndCoreClient(cmd, &opts.commonOptions, func(
+			ctx context.Context,
+			_ securityclient.SecurityV1alpha1Interface,
+			coreClient corev1client.CoreV1Interface,
+		) error {
+			return runPolicyMigrate(ctx, coreClient, opts, opts.ioStreams.Out)
+		})
+	}
+}
+
+func runPolicyMigrate(
+	ctx context.Context,
+	coreClient corev1client.CoreV1Interface,
+	opts *policyMigrateOptions,
+	out io.Writer,
+) error {
+	legacy, err := readWorkloadSecurityPolicy(opts.PolicyFile)
+	if err != nil {
+		return err
+	}
+	if legacy.Namespace == "" {
+		legacy.Namespace = opts.Namespace
+	}
+
+	containers := opts.Containers
+	if len(containers) == 0 {
+		if containers, err = workloadContainers(ctx, coreClient, legacy); err != nil {
+			return err
+		}
+	}
+
+	policy, err := migrateWorkloadSecurityPolicy(legacy, containers)
+	if err != nil {
+		return err
+	}
+	printer := printers.YAMLPrinter{}
+	return printer.PrintObj(policy, out)
+}
+
+func readWorkloadSecurityPolicy(path string) (*workloadSecurityPolicy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the WorkloadSecurityPolicy manifest: %w", err)
+	}
+	defer f.Close()
+	legacy := &workloadSecurityPolicy{}
+	if err = utilyaml.NewYAMLOrJSONDecoder(f, manifestDecoderBufferSize).Decode(legacy); err != nil {
+		return nil, fmt.Errorf("failed to decode the WorkloadSecurityPolicy manifest %q: %w", path, err)
+	}
+	if legacy.Kind != workloadSecurityPolicyKind {
+		return nil, fmt.Errorf("manifest %q is a %s, expected a %s", path, legacy.Kind, workloadSecurityPolicyKind)
+	}
+	return legacy, nil
+}
+
+// workloadContainers returns the names of the containers and init containers of the pods
+// matching the selector of the policy.
+func workloadContainers(
+	ctx context.Context,
+	coreClient corev1client.CoreV1Interface,
+	legacy *workloadSecurityPolicy,
+) ([]string, error) {
+	if legacy.Spec.Selector == nil {
+		return nil, fmt.Errorf("WorkloadSecurityPolicy %q has no selector, the containers must be given with --container",
+			legacy.Name)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(legacy.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector of WorkloadSecurityPolicy %q: %w", legacy.Name, err)
+	}
+	pods, err := coreClient.Pods(legacy.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the pods of WorkloadSecurityPolicy %q in namespace %q: %w",
+			legacy.Name, legacy.Namespace, err)
+	}
+
+	var containers []string
+	for _, pod := range pods.Items {
+		for _, container := range slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers) {
+			if !slices.Contains(containers, container.Name) {
+				containers = append(containers, container.Name)
+			}
+		}
+	}
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("no pod matches the selector of WorkloadSecurityPolicy %q in namespace %q, "+
+			"the containers must be given with --container", legacy.Name, legacy.Namespace)
+	}
+	slices.Sort(containers)
+	return containers, nil
+}
+
+// migrateWorkloadSecurityPolicy returns the WorkloadPolicy applying the single rule set of the legacy policy
+// to each of the containers.
+func migrateWorkloadSecurityPolicy(
+	legacy *workloadSecurityPolicy,
+	containers []string,
+) (*apiv1alpha1.WorkloadPolicy, error) {
+	if len(containers) == 0 {
+		return nil, errors.New("at least one container is required")
+	}
+	executables := legacy.Spec.Rules.Executables
+
+	policy := &apiv1alpha1.WorkloadPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: apiv1alpha1.GroupVersion.String(),
+			Kind:       "WorkloadPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      legacy.Name,
+			Namespace: legacy.Namespace,
+		},
+		Spec: apiv1alpha1.WorkloadPolicySpec{
+			Mode:             legacy.Spec.Mode,
+			RulesByContainer: make(map[string]*apiv1alpha1.WorkloadPolicyRules, len(containers)),
+		},
+	}
+	for _, container := range containers {
+		policy.Spec.RulesByContainer[container] = &apiv1alpha1.WorkloadPolicyRules{
+			Executables: apiv1alpha1.WorkloadPolicyExecutables{
+				Allowed:         slices.Clone(executables.Allowed),
+				AllowedPrefixes: slices.Clone(executables.AllowedPrefixes),
+			},
+		}
+	}
+	return policy, nil
+}
@@ -0,0 +1,108 @@
+package kubectlplugin
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const workloadSecurityPolicyManifest = `apiVersion: security.rancher.io/v1alpha1
+kind: WorkloadSecurityPolicy
+metadata:
+  name: nginx-ingress-controller
+spec:
+  mode: monitor
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: ingress-nginx
+  rules:
+    executables:
+      allowed:
+        - /nginx-ingress-controller
+`
+
+func TestRunPolicyMigrate(t *testing.T) {
+	t.Parallel()
+
+	const ns = "test"
+
+	manifest := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(manifest, []byte(workloadSecurityPolicyManifest), 0o600))
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress-nginx-controller-abcde",
+			Namespace: ns,
+			Labels:    map[string]string{"app.kubernetes.io/name": "ingress-nginx"},
+		},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init"}},
+			Containers:     []corev1.Container{{Name: "controller"}},
+		},
+	}
+	other := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: ns},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "other"}}},
+	}
+	coreClient := fake.NewClientset(pod, other).CoreV1()
+	ctx, cancel := context.WithTimeout(context.Background(), defaultOperationTimeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	opts := &policyMigrateOptions{commonOptions: commonOptions{Namespace: ns}, PolicyFile: manifest}
+	require.NoError(t, runPolicyMigrate(ctx, coreClient, opts, &out))
+	require.Equal(t, `apiVersion: security.rancher.io/v1alpha1
+kind: WorkloadPolicy
+metadata:
+  name: nginx-ingress-controller
+  namespace: test
+spec:
+  mode: monitor
+  rulesByContainer:
+    controller:
+      executables:
+        allowed:
+        - /nginx-ingress-controller
+    init:
+      executables:
+        allowed:
+        - /nginx-ingress-controller
+status: {}
+`, out.String())
+
+	// the containers given explicitly replace the ones of the pods.
+	out.Reset()
+	opts.Containers = []string{"main"}
+	require.NoError(t, runPolicyMigrate(ctx, coreClient, opts, &out))
+	require.Contains(t, out.String(), "    main:\n")
+	require.NotContains(t, out.String(), "controller:")
+}
+
+func TestMigrateWorkloadSecurityPolicy(t *testing.T) {
+	t.Parallel()
+
+	legacy := &workloadSecurityPolicy{ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "test"}}
+	legacy.Spec.Mode = "protect"
+	legacy.Spec.Rules.Executables.Allowed = []string{"/bin/sh"}
+
+	policy, err := migrateWorkloadSecurityPolicy(legacy, []string{"a", "b"})
+	require.NoError(t, err)
+	require.Equal(t, "protect", policy.Spec.Mode)
+	require.Len(t, policy.Spec.RulesByContainer, 2)
+	require.Equal(t, []string{"/bin/sh"}, policy.Spec.RulesByContainer["a"].Executables.Allowed)
+	require.Equal(t, []string{"/bin/sh"}, policy.Spec.RulesByContainer["b"].Executables.Allowed)
+
+	_, err = migrateWorkloadSecurityPolicy(legacy, nil)
+	require.Error(t, err)
+
+	legacy.Spec.Rules.Executables.AllowedPrefixes = []string{"/usr/bin/"}
+	policy, err = migrateWorkloadSecurityPolicy(legacy, []string{"a"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/usr/bin/"}, policy.Spec.RulesByContainer["a"].Executables.AllowedPrefixes)
+}
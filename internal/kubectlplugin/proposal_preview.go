@@ -0,0 +1,289 @@
+package kubectlplugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+
+	apiv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	securityclient "github.com/rancher-sandbox/runtime-enforcer/pkg/generated/clientset/versioned/typed/api/v1alpha1"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+const (
+	proposalPreviewOutputTable = "table"
+	proposalPreviewOutputJSON  = "json"
+
+	// manifestDecoderBufferSize is the number of bytes read to guess whether the manifest is YAML or JSON.
+	manifestDecoderBufferSize = 4096
+
+	previewReasonNotAllowed        = "NotAllowed"
+	previewReasonParentOnly        = "AllowedWithParentOnly"
+	previewReasonUnlistedContainer = "UnlistedContainer"
+)
+
+type proposalPreviewOptions struct {
+	commonOptions
+
+	ProposalName string
+	PolicyName   string
+	PolicyFile   string
+	Output       string
+}
+
+// blockedExecutableRow is a learned executable the policy would block in protect mode.
+type blockedExecutableRow struct {
+	Container  string `json:"container"`
+	Executable string `json:"executable"`
+	Reason     string `json:"reason"`
+}
+
+func newProposalPreviewCmd(deps commonCmdDeps) *cobra.Command {
+	opts := &proposalPreviewOptions{
+		commonOptions: newCommonOptions(deps),
+		Output:        proposalPreviewOutputTable,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "preview PROPOSAL_NAME (--policy POLICY_NAME | --filename FILE)",
+		Short: "Preview the learned executables a WorkloadPolicy would block",
+		Long: "Preview the executables learned in a WorkloadPolicyProposal that a WorkloadPolicy would block " +
+			"in protect mode, before promoting the proposal or switching the policy to protect. " +
+			"The policy is an existing WorkloadPolicy or a candidate manifest. " +
+			"The executables allowed by the global allow list of the agents are not known and may be reported.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: newProposalPromoteCmdValidArgsFunction(deps),
+		RunE:              runProposalPreviewCmd(opts),
+	}
+
+	cmd.SetUsageTemplate(subcommandUsageTemplate)
+
+	cmd.Flags().StringVar(&opts.PolicyName, "policy", "", "Name of the WorkloadPolicy to preview")
+	cmd.Flags().StringVarP(&opts.PolicyFile, "filename", "f", "", "Manifest of the candidate WorkloadPolicy to preview")
+	cmd.MarkFlagsMutuallyExclusive("policy", "filename")
+	cmd.MarkFlagsOneRequired("policy", "filename")
+	cmd.Flags().StringVarP(
+		&opts.Output,
+		"output",
+		"o",
+		proposalPreviewOutputTable,
+		"Output format. One of: table|json",
+	)
+
+	return cmd
+}
+
+func runProposalPreviewCmd(opts *proposalPreviewOptions) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		opts.ProposalName = args[0]
+
+		return withRuntimeEnforcerClient(cmd, &opts.commonOptions, func(
+			ctx context.Context,
+			client securityclient.SecurityV1alpha1Interface,
+		) error {
+			return runProposalPreview(ctx, client, opts, opts.ioStreams.Out)
+		})
+	}
+}
+
+func runProposalPreview(
+	ctx context.Context,
+	client securityclient.SecurityV1alpha1Interface,
+	opts *proposalPreviewOptions,
+	out io.Writer,
+) error {
+	proposal, err := client.WorkloadPolicyProposals(opts.Namespace).Get(ctx, opts.ProposalName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("workloadpolicyproposal %q not found in namespace %q", opts.ProposalName, opts.Namespace)
+		}
+		return fmt.Errorf("failed to get WorkloadPolicyProposal %q in namespace %q: %w",
+			opts.ProposalName, opts.Namespace, err)
+	}
+
+	policy, err := previewedPolicy(ctx, client, opts)
+	if err != nil {
+		return err
+	}
+	rulesByContainer, err := inheritedRulesByContainer(ctx, client, policy, opts.Namespace)
+	if err != nil {
+		return err
+	}
+
+	rows := blockedExecutables(proposal, rulesByContainer, policy.Spec.UnlistedContainerPolicy)
+	return renderProposalPreview(opts.Output, out, rows)
+}
+
+// previewedPolicy returns the existing policy or the candidate one read from the manifest.
+func previewedPolicy(
+	ctx context.Context,
+	client securityclient.SecurityV1alpha1Interface,
+	opts *proposalPreviewOptions,
+) (*apiv1alpha1.WorkloadPolicy, error) {
+	if opts.PolicyFile == "" {
+		policy, err := client.WorkloadPolicies(opts.Namespace).Get(ctx, opts.PolicyName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get WorkloadPolicy %q in namespace %q: %w", opts.PolicyName, opts.Namespace, err)
+		}
+		return policy, nil
+	}
+
+	f, err := os.Open(opts.PolicyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the WorkloadPolicy manifest: %w", err)
+	}
+	defer f.Close()
+	policy := &apiv1alpha1.WorkloadPolicy{}
+	if err = utilyaml.NewYAMLOrJSONDecoder(f, manifestDecoderBufferSize).Decode(policy); err != nil {
+		return nil, fmt.Errorf("failed to decode the WorkloadPolicy manifest %q: %w", opts.PolicyFile, err)
+	}
+	if policy.Kind != "" && policy.Kind != "WorkloadPolicy" {
+		return nil, fmt.Errorf("manifest %q is a %s, expected a WorkloadPolicy", opts.PolicyFile, policy.Kind)
+	}
+	return policy, nil
+}
+
+// inheritedRulesByContainer returns the rules of the policy merged with the ones of its base policy,
+// the rules of a container defined in both are the ones of the policy with the higher priority,
+// of the policy itself on a tie, as the agents do.
+func inheritedRulesByContainer(
+	ctx context.Context,
+	client securityclient.SecurityV1alpha1Interface,
+	policy *apiv1alpha1.WorkloadPolicy,
+	namespace string,
+) (map[string]*apiv1alpha1.WorkloadPolicyRules, error) {
+	if policy.Spec.BasePolicy == "" || policy.Spec.BasePolicy == policy.Name {
+		return policy.Spec.RulesByContainer, nil
+	}
+	base, err := client.WorkloadPolicies(namespace).Get(ctx, policy.Spec.BasePolicy, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the base WorkloadPolicy %q in namespace %q: %w",
+			policy.Spec.BasePolicy, namespace, err)
+	}
+	lower, higher := base.Spec.RulesByContainer, policy.Spec.RulesByContainer
+	if base.Spec.Priority > policy.Spec.Priority {
+		lower, higher = higher, lower
+	}
+	merged := maps.Clone(lower)
+	if merged == nil {
+		merged = make(map[string]*apiv1alpha1.WorkloadPolicyRules, len(higher))
+	}
+	maps.Copy(merged, higher)
+	return merged, nil
+}
+
+// blockedExecutables returns the executables learned in the proposal that the rules would block in protect mode.
+func blockedExecutables(
+	proposal *apiv1alpha1.WorkloadPolicyProposal,
+	rulesByContainer map[string]*apiv1alpha1.WorkloadPolicyRules,
+	unlistedContainerPolicy string,
+) []blockedExecutableRow {
+	var rows []blockedExecutableRow
+	for _, container := range slices.Sorted(maps.Keys(proposal.Spec.RulesByContainer)) {
+		learned := proposal.Spec.RulesByContainer[container]
+		if learned == nil {
+			continue
+		}
+		rules, listed := rulesByContainer[container]
+		if !listed {
+			// the containers without rules are not enforced, unless the policy denies them.
+			if unlistedContainerPolicy != apiv1alpha1.UnlistedContainerDeny {
+				continue
+			}
+			for _, exe := range learned.Executables.Allowed {
+				rows = append(rows, blockedExecutableRow{
+					Container:  container,
+					Executable: exe,
+					Reason:     previewReasonUnlistedContainer,
+				})
+			}
+			continue
+		}
+
+		allowed := make(map[string]struct{})
+		parentOnly := make(map[string]struct{})
+		if rules != nil {
+			for _, exe := range rules.Executables.Allowed {
+				allowed[exe] = struct{}{}
+			}
+			for _, exe := range rules.Executables.AllowedHashes {
+				allowed[exe.Path] = struct{}{}
+			}
+			for _, exe := range rules.Executables.AllowedWithParent {
+				parentOnly[exe.Path] = struct{}{}
+			}
+		}
+		for _, exe := range learned.Executables.Allowed {
+			if _, ok := allowed[exe]; ok {
+				continue
+			}
+			reason := previewReasonNotAllowed
+			// the parent condition is only evaluated in monitor mode.
+			if _, ok := parentOnly[exe]; ok {
+				reason = previewReasonParentOnly
+			}
+			rows = append(rows, blockedExecutableRow{Container: container, Executable: exe, Reason: reason})
+		}
+	}
+	return rows
+}
+
+func renderProposalPreview(outMode string, out io.Writer, rows []blockedExecutableRow) error {
+	switch outMode {
+	case proposalPreviewOutputTable:
+		return renderProposalPreviewTable(out, rows)
+	case proposalPreviewOutputJSON:
+		return renderProposalPreviewJSON(out, rows)
+	default:
+		return fmt.Errorf("invalid output %q, expected %q or %q",
+			outMode,
+			proposalPreviewOutputTable,
+			proposalPreviewOutputJSON,
+		)
+	}
+}
+
+func renderProposalPreviewTable(out io.Writer, rows []blockedExecutableRow) error {
+	if len(rows) == 0 {
+		fmt.Fprintln(out, "No learned executable would be blocked")
+		return nil
+	}
+	printer := printers.NewTablePrinter(printers.PrintOptions{})
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "CONTAINER", Type: "string", Description: "Container name"},
+			{Name: "EXECUTABLE", Type: "string", Description: "Learned executable"},
+			{Name: "REASON", Type: "string", Description: "Why the executable would be blocked"},
+		},
+		Rows: make([]metav1.TableRow, 0, len(rows)),
+	}
+	for _, row := range rows {
+		table.Rows = append(table.Rows, metav1.TableRow{Cells: []any{row.Container, row.Executable, row.Reason}})
+	}
+	if err := printer.PrintObj(table, out); err != nil {
+		return fmt.Errorf("failed to write table output: %w", err)
+	}
+	return nil
+}
+
+func renderProposalPreviewJSON(out io.Writer, rows []blockedExecutableRow) error {
+	if rows == nil {
+		rows = []blockedExecutableRow{}
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(rows); err != nil {
+		return fmt.Errorf("failed to write JSON output: %w", err)
+	}
+
+	return nil
+}
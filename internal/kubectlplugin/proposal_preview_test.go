@@ -0,0 +1,171 @@
+package kubectlplugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	securityv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	fakeclient "github.com/rancher-sandbox/runtime-enforcer/pkg/generated/clientset/versioned/fake"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRunProposalPreview(t *testing.T) {
+	t.Parallel()
+
+	const ns = "test"
+
+	proposal := &securityv1alpha1.WorkloadPolicyProposal{
+		ObjectMeta: metav1.ObjectMeta{Name: "deploy-ubuntu", Namespace: ns},
+		Spec: securityv1alpha1.WorkloadPolicyProposalSpec{
+			RulesByContainer: map[string]*securityv1alpha1.WorkloadPolicyRules{
+				"main": {Executables: securityv1alpha1.WorkloadPolicyExecutables{
+					Allowed: []string{"/bin/bash", "/usr/bin/curl", "/usr/bin/ls", "/usr/bin/sleep"},
+				}},
+				"sidecar": {Executables: securityv1alpha1.WorkloadPolicyExecutables{
+					Allowed: []string{"/usr/bin/tail"},
+				}},
+			},
+		},
+	}
+	base := &securityv1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "base", Namespace: ns},
+		Spec: securityv1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*securityv1alpha1.WorkloadPolicyRules{
+				"sidecar": {Executables: securityv1alpha1.WorkloadPolicyExecutables{
+					Allowed: []string{"/usr/bin/tail"},
+				}},
+			},
+		},
+	}
+	policy := &securityv1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "ubuntu", Namespace: ns},
+		Spec: securityv1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*securityv1alpha1.WorkloadPolicyRules{
+				"main": {Executables: securityv1alpha1.WorkloadPolicyExecutables{
+					Allowed: []string{"/bin/bash"},
+					AllowedHashes: []securityv1alpha1.ExecutableHash{
+						{Path: "/usr/bin/sleep", SHA256: "0000000000000000000000000000000000000000000000000000000000000000"},
+					},
+					AllowedWithParent: []securityv1alpha1.ExecutableWithParent{
+						{Path: "/usr/bin/curl", Parents: []string{"/bin/bash"}},
+					},
+				}},
+			},
+		},
+	}
+	child := policy.DeepCopy()
+	child.Name = "ubuntu-child"
+	child.Spec.BasePolicy = "base"
+	deny := policy.DeepCopy()
+	deny.Name = "ubuntu-deny"
+	deny.Spec.UnlistedContainerPolicy = securityv1alpha1.UnlistedContainerDeny
+
+	tests := []struct {
+		name       string
+		policyName string
+		expected   []blockedExecutableRow
+	}{
+		{
+			name:       "unlisted container is not enforced",
+			policyName: "ubuntu",
+			expected: []blockedExecutableRow{
+				{Container: "main", Executable: "/usr/bin/curl", Reason: previewReasonParentOnly},
+				{Container: "main", Executable: "/usr/bin/ls", Reason: previewReasonNotAllowed},
+			},
+		},
+		{
+			name:       "unlisted container is denied",
+			policyName: "ubuntu-deny",
+			expected: []blockedExecutableRow{
+				{Container: "main", Executable: "/usr/bin/curl", Reason: previewReasonParentOnly},
+				{Container: "main", Executable: "/usr/bin/ls", Reason: previewReasonNotAllowed},
+				{Container: "sidecar", Executable: "/usr/bin/tail", Reason: previewReasonUnlistedContainer},
+			},
+		},
+		{
+			name:       "container rules inherited from the base policy",
+			policyName: "ubuntu-child",
+			expected: []blockedExecutableRow{
+				{Container: "main", Executable: "/usr/bin/curl", Reason: previewReasonParentOnly},
+				{Container: "main", Executable: "/usr/bin/ls", Reason: previewReasonNotAllowed},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			securityClient := fakeclient.NewClientset(proposal, base, policy, child, deny).SecurityV1alpha1()
+
+			var out bytes.Buffer
+			opts := &proposalPreviewOptions{
+				commonOptions: commonOptions{Namespace: ns},
+				ProposalName:  proposal.Name,
+				PolicyName:    tt.policyName,
+				Output:        proposalPreviewOutputJSON,
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), defaultOperationTimeout)
+			defer cancel()
+
+			require.NoError(t, runProposalPreview(ctx, securityClient, opts, &out))
+
+			var rows []blockedExecutableRow
+			require.NoError(t, json.Unmarshal(out.Bytes(), &rows))
+			require.Equal(t, tt.expected, rows)
+		})
+	}
+}
+
+func TestRunProposalPreviewFromManifest(t *testing.T) {
+	t.Parallel()
+
+	const ns = "test"
+
+	proposal := &securityv1alpha1.WorkloadPolicyProposal{
+		ObjectMeta: metav1.ObjectMeta{Name: "deploy-ubuntu", Namespace: ns},
+		Spec: securityv1alpha1.WorkloadPolicyProposalSpec{
+			RulesByContainer: map[string]*securityv1alpha1.WorkloadPolicyRules{
+				"main": {Executables: securityv1alpha1.WorkloadPolicyExecutables{
+					Allowed: []string{"/bin/bash", "/usr/bin/ls"},
+				}},
+			},
+		},
+	}
+	manifest := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(manifest, []byte(`apiVersion: security.rancher.io/v1alpha1
+kind: WorkloadPolicy
+metadata:
+  name: ubuntu
+spec:
+  mode: protect
+  rulesByContainer:
+    main:
+      executables:
+        allowed:
+          - /bin/bash
+          - /usr/bin/ls
+`), 0o600))
+
+	securityClient := fakeclient.NewClientset(proposal).SecurityV1alpha1()
+
+	var out bytes.Buffer
+	opts := &proposalPreviewOptions{
+		commonOptions: commonOptions{Namespace: ns},
+		ProposalName:  proposal.Name,
+		PolicyFile:    manifest,
+		Output:        proposalPreviewOutputTable,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultOperationTimeout)
+	defer cancel()
+
+	require.NoError(t, runProposalPreview(ctx, securityClient, opts, &out))
+	require.Equal(t, "No learned executable would be blocked\n", out.String())
+}
@@ -18,6 +18,8 @@ type proposalPromoteOptions struct {
 	commonOptions
 
 	ProposalName string
+	// ApprovalLabelKey is the label the controller promotes the proposals on, ApprovalLabelKey when empty.
+	ApprovalLabelKey string
 }
 
 func newProposalPromoteCmdValidArgsFunction(
@@ -55,6 +57,8 @@ func newProposalPromoteCmd(deps commonCmdDeps) *cobra.Command {
 
 	// Plugin-specific flags
 	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Show what would happen without making any changes")
+	cmd.Flags().StringVar(&opts.ApprovalLabelKey, "approval-label-key", apiv1alpha1.ApprovalLabelKey,
+		"Label the controller promotes the proposals on, when it runs with a custom approval-label-key")
 
 	return cmd
 }
@@ -91,12 +95,16 @@ func runProposalPromote(
 		)
 	}
 
+	approvalLabelKey := opts.ApprovalLabelKey
+	if approvalLabelKey == "" {
+		approvalLabelKey = apiv1alpha1.ApprovalLabelKey
+	}
 	labels := proposal.GetLabels()
 	if labels == nil {
 		labels = map[string]string{}
 	}
 
-	if labels[apiv1alpha1.ApprovalLabelKey] == "true" {
+	if labels[approvalLabelKey] == "true" {
 		fmt.Fprintf(
 			out,
 			"WorkloadPolicyProposal %q in namespace %q is already promoted to WorkloadPolicy.\n",
@@ -111,7 +119,7 @@ func runProposalPromote(
 		updateOptions.DryRun = []string{metav1.DryRunAll}
 	}
 
-	labels[apiv1alpha1.ApprovalLabelKey] = "true"
+	labels[approvalLabelKey] = "true"
 	proposal.SetLabels(labels)
 
 	if _, err = client.WorkloadPolicyProposals(opts.Namespace).
@@ -0,0 +1,93 @@
+package nri
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/cgroups"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+)
+
+// DefaultCgroupCacheTTL is how long a resolved container cgroup is reused by default.
+const DefaultCgroupCacheTTL = 5 * time.Minute
+
+type cachedCgroup struct {
+	// cgroupsPath is the runtime cgroups path the entry was resolved from.
+	cgroupsPath string
+	cgroupID    resolver.CgroupID
+	path        string
+	expiresAt   time.Time
+}
+
+// cgroupCache keeps the cgroups of the containers resolved recently.
+// It outlives the NRI connections, so that the Synchronize call that follows a reconnection
+// doesn't need to resolve again the cgroups of all the containers of the node.
+type cgroupCache struct {
+	mu  sync.Mutex
+	ttl time.Duration
+	now func() time.Time
+	// stat returns the cgroup ID of a path, cgroups.GetCgroupIDFromPath outside of the tests.
+	stat    func(path string) (resolver.CgroupID, error)
+	entries map[resolver.ContainerID]cachedCgroup
+}
+
+func newCgroupCache(ttl time.Duration) *cgroupCache {
+	return &cgroupCache{
+		ttl:     ttl,
+		now:     time.Now,
+		stat:    cgroups.GetCgroupIDFromPath,
+		entries: make(map[resolver.ContainerID]cachedCgroup),
+	}
+}
+
+// get returns the cgroup of the container if it was resolved from the same cgroups path less than ttl ago
+// and the cgroup directory still has the same ID: a container restarted in place while the plugin was
+// disconnected gets a new cgroup at the same path.
+func (c *cgroupCache) get(containerID resolver.ContainerID, cgroupsPath string) (resolver.CgroupID, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[containerID]
+	if !ok {
+		return 0, "", false
+	}
+	if entry.cgroupsPath != cgroupsPath || !c.now().Before(entry.expiresAt) {
+		delete(c.entries, containerID)
+		return 0, "", false
+	}
+	if cgroupID, err := c.stat(entry.path); err != nil || cgroupID != entry.cgroupID {
+		delete(c.entries, containerID)
+		return 0, "", false
+	}
+	return entry.cgroupID, entry.path, true
+}
+
+// put records the cgroup of the container, a cgroup without a path cannot be checked and is not cached.
+func (c *cgroupCache) put(containerID resolver.ContainerID, cgroupsPath string, cgroupID resolver.CgroupID, path string) {
+	if path == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.entries[containerID] = cachedCgroup{
+		cgroupsPath: cgroupsPath,
+		cgroupID:    cgroupID,
+		path:        path,
+		expiresAt:   now.Add(c.ttl),
+	}
+	// drop the expired entries, so that the cache doesn't grow with the containers we never hear about again.
+	for id, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, id)
+		}
+	}
+}
+
+func (c *cgroupCache) forget(containerID resolver.ContainerID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, containerID)
+}
@@ -0,0 +1,122 @@
+package nri
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	"github.com/stretchr/testify/require"
+)
+
+// statPaths returns a stat function resolving the given paths, the others don't exist.
+func statPaths(pathIDs map[string]resolver.CgroupID) func(string) (resolver.CgroupID, error) {
+	return func(path string) (resolver.CgroupID, error) {
+		cgroupID, ok := pathIDs[path]
+		if !ok {
+			return 0, errors.New("no such cgroup")
+		}
+		return cgroupID, nil
+	}
+}
+
+func TestCgroupCache(t *testing.T) {
+	now := time.Now()
+	c := newCgroupCache(time.Minute)
+	c.now = func() time.Time { return now }
+	pathIDs := map[string]resolver.CgroupID{
+		"/sys/fs/cgroup/kubepods/c1": 100,
+		"/sys/fs/cgroup/kubepods/c2": 200,
+	}
+	c.stat = statPaths(pathIDs)
+
+	c.put("c1", "/kubepods/c1", 100, "/sys/fs/cgroup/kubepods/c1")
+	cgroupID, path, ok := c.get("c1", "/kubepods/c1")
+	require.True(t, ok)
+	require.Equal(t, resolver.CgroupID(100), cgroupID)
+	require.Equal(t, "/sys/fs/cgroup/kubepods/c1", path)
+
+	// a different cgroups path is a miss.
+	_, _, ok = c.get("c1", "/kubepods/other")
+	require.False(t, ok)
+
+	c.put("c1", "/kubepods/c1", 100, "/sys/fs/cgroup/kubepods/c1")
+	now = now.Add(time.Minute)
+	_, _, ok = c.get("c1", "/kubepods/c1")
+	require.False(t, ok, "expired entries are not served")
+
+	c.put("c2", "/kubepods/c2", 200, "/sys/fs/cgroup/kubepods/c2")
+	c.forget("c2")
+	_, _, ok = c.get("c2", "/kubepods/c2")
+	require.False(t, ok)
+
+	// the container was restarted in place, its cgroup was recreated at the same path.
+	c.put("c2", "/kubepods/c2", 200, "/sys/fs/cgroup/kubepods/c2")
+	pathIDs["/sys/fs/cgroup/kubepods/c2"] = 201
+	_, _, ok = c.get("c2", "/kubepods/c2")
+	require.False(t, ok, "stale cgroups are not served")
+	pathIDs["/sys/fs/cgroup/kubepods/c2"] = 200
+	_, _, ok = c.get("c2", "/kubepods/c2")
+	require.False(t, ok, "stale entries are dropped")
+
+	// the cgroup was removed.
+	c.put("c2", "/kubepods/c2", 200, "/sys/fs/cgroup/kubepods/c2")
+	delete(pathIDs, "/sys/fs/cgroup/kubepods/c2")
+	_, _, ok = c.get("c2", "/kubepods/c2")
+	require.False(t, ok)
+
+	// a cgroup without a path cannot be checked.
+	c.put("c3", "/kubepods/c3", 300, "")
+	_, _, ok = c.get("c3", "/kubepods/c3")
+	require.False(t, ok)
+}
+
+func TestPluginCgroupCache(t *testing.T) {
+	const path = "/sys/fs/cgroup/kubepods.slice/cri-containerd-container-id.scope"
+
+	cache := newCgroupCache(time.Minute)
+	pathIDs := map[string]resolver.CgroupID{path: 100}
+	cache.stat = statPaths(pathIDs)
+	resolutions := 0
+	newPlugin := func() *plugin {
+		p := newTestPlugin(t, false, 100)
+		p.cgroups = cache
+		p.resolveCgroupID = func(*api.Container) (resolver.CgroupID, string, error) {
+			resolutions++
+			return pathIDs[path], path, nil
+		}
+		return p
+	}
+	pod := testPodSandbox()
+	container := testContainer()
+	container.PodSandboxId = pod.GetId()
+	container.State = api.ContainerState_CONTAINER_RUNNING
+
+	_, err := newPlugin().Synchronize(t.Context(), []*api.PodSandbox{pod}, []*api.Container{container})
+	require.NoError(t, err)
+	require.Equal(t, 1, resolutions)
+
+	// after a reconnection, a new plugin synchronizes the same container from the cache.
+	p := newPlugin()
+	_, err = p.Synchronize(t.Context(), []*api.PodSandbox{pod}, []*api.Container{container})
+	require.NoError(t, err)
+	require.Equal(t, 1, resolutions)
+	view, err := p.resolver.GetContainerView(100)
+	require.NoError(t, err)
+	require.Equal(t, container.GetId(), view.Meta.ID)
+
+	// a starting container is always resolved, it could be restarting in place.
+	require.NoError(t, p.StartContainer(t.Context(), pod, container))
+	require.Equal(t, 2, resolutions)
+
+	// the container was restarted in place while the plugin was disconnected.
+	pathIDs[path] = 200
+	p = newPlugin()
+	_, err = p.Synchronize(t.Context(), []*api.PodSandbox{pod}, []*api.Container{container})
+	require.NoError(t, err)
+	require.Equal(t, 3, resolutions)
+	view, err = p.resolver.GetContainerView(200)
+	require.NoError(t, err)
+	require.Equal(t, container.GetId(), view.Meta.ID)
+}
@@ -0,0 +1,124 @@
+package nri
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/cgroups"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+)
+
+// DefaultCgroupMoveCheckInterval is how often the cgroups of the running containers are checked by default.
+const DefaultCgroupMoveCheckInterval = 30 * time.Second
+
+type watchedCgroup struct {
+	podID    resolver.PodID
+	path     string
+	cgroupID resolver.CgroupID
+}
+
+// cgroupWatch keeps the cgroup path of the running containers, so that a cgroup recreated at the same path,
+// e.g. when systemd moves the scope of a container during a reload, is detected and enforced again.
+// Like the cgroup cache, it outlives the NRI connections.
+type cgroupWatch struct {
+	mu       sync.Mutex
+	interval time.Duration
+	// stat returns the cgroup ID of a path, cgroups.GetCgroupIDFromPath outside of the tests.
+	stat    func(path string) (resolver.CgroupID, error)
+	entries map[resolver.ContainerID]watchedCgroup
+}
+
+func newCgroupWatch(interval time.Duration) *cgroupWatch {
+	return &cgroupWatch{
+		interval: interval,
+		stat:     cgroups.GetCgroupIDFromPath,
+		entries:  make(map[resolver.ContainerID]watchedCgroup),
+	}
+}
+
+// WithCgroupMoveDetection checks every interval that the cgroup path of each running container
+// still has the cgroup ID the container is enforced with. When systemd recreates the cgroup of a container,
+// e.g. during a reload, the new cgroup is resolved and the policy of the pod is applied to it.
+// Zero or a negative value disables the detection.
+func WithCgroupMoveDetection(interval time.Duration) Option {
+	return func(h *Handler) {
+		h.cgroupMoves = nil
+		if interval > 0 {
+			h.cgroupMoves = newCgroupWatch(interval)
+		}
+	}
+}
+
+// watch records the cgroup a container was resolved to, a container without a cgroup path is not watched.
+func (w *cgroupWatch) watch(podID resolver.PodID, containerID resolver.ContainerID, cgroupID resolver.CgroupID,
+	path string) {
+	if path == "" {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.entries[containerID] = watchedCgroup{podID: podID, path: path, cgroupID: cgroupID}
+}
+
+func (w *cgroupWatch) forget(containerID resolver.ContainerID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.entries, containerID)
+}
+
+// run checks the watched cgroups every interval until the context is done.
+func (w *cgroupWatch) run(ctx context.Context, logger *slog.Logger, r *resolver.Resolver, cache *cgroupCache) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check(ctx, logger, r, cache)
+		}
+	}
+}
+
+// check re-resolves the cgroup ID of every watched path and moves the containers whose cgroup changed.
+// A path that cannot be resolved is skipped: the container is stopping and will be removed by NRI.
+func (w *cgroupWatch) check(ctx context.Context, logger *slog.Logger, r *resolver.Resolver, cache *cgroupCache) {
+	w.mu.Lock()
+	entries := make(map[resolver.ContainerID]watchedCgroup, len(w.entries))
+	for containerID, entry := range w.entries {
+		entries[containerID] = entry
+	}
+	w.mu.Unlock()
+
+	for containerID, entry := range entries {
+		cgroupID, err := w.stat(entry.path)
+		if err != nil {
+			logger.DebugContext(ctx, "failed to re-resolve the container cgroup",
+				"id", containerID, "path", entry.path, "error", err)
+			continue
+		}
+		if cgroupID == entry.cgroupID {
+			continue
+		}
+		// the cached cgroup is stale, the next synchronization must not restore it.
+		if cache != nil {
+			cache.forget(containerID)
+		}
+		if err = r.MoveContainerCgroup(entry.podID, containerID, cgroupID, entry.path); err != nil {
+			logger.ErrorContext(ctx, "failed to enforce the new cgroup of the container",
+				"id", containerID, "path", entry.path, "cgroupID", cgroupID, "error", err)
+			continue
+		}
+		w.mu.Lock()
+		// the container could have been removed or restarted in the meantime.
+		if current, ok := w.entries[containerID]; ok && current == entry {
+			entry.cgroupID = cgroupID
+			w.entries[containerID] = entry
+		}
+		w.mu.Unlock()
+	}
+}
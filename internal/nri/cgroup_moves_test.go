@@ -0,0 +1,61 @@
+package nri
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCgroupMoveDetection(t *testing.T) {
+	const path = "/sys/fs/cgroup/kubepods.slice/cri-containerd-container-id.scope"
+
+	p := newTestPlugin(t, false, 100)
+	p.resolveCgroupID = func(*api.Container) (resolver.CgroupID, string, error) {
+		return 100, path, nil
+	}
+	p.cgroups = newCgroupCache(time.Minute)
+	p.cgroupMoves = newCgroupWatch(time.Minute)
+	pathIDs := map[string]resolver.CgroupID{path: 100}
+	p.cgroupMoves.stat = func(path string) (resolver.CgroupID, error) {
+		cgroupID, ok := pathIDs[path]
+		if !ok {
+			return 0, errors.New("no such cgroup")
+		}
+		return cgroupID, nil
+	}
+	p.cgroups.stat = p.cgroupMoves.stat
+
+	pod := testPodSandbox()
+	container := testContainer()
+	require.NoError(t, p.StartContainer(t.Context(), pod, container))
+
+	// nothing changed, the container keeps its cgroup.
+	p.cgroupMoves.check(t.Context(), p.logger, p.resolver, p.cgroups)
+	_, err := p.resolver.GetContainerView(100)
+	require.NoError(t, err)
+
+	// systemd recreated the scope of the container at the same path.
+	pathIDs[path] = 200
+	p.cgroupMoves.check(t.Context(), p.logger, p.resolver, p.cgroups)
+	view, err := p.resolver.GetContainerView(200)
+	require.NoError(t, err)
+	require.Equal(t, container.GetId(), view.Meta.ID)
+	_, err = p.resolver.GetContainerView(100)
+	require.Error(t, err)
+	_, _, cached := p.cgroups.get(container.GetId(), container.GetLinux().GetCgroupsPath())
+	require.False(t, cached)
+	require.Equal(t, resolver.CgroupID(200), p.cgroupMoves.entries[container.GetId()].cgroupID)
+
+	// a cgroup that disappeared is left to the removal of the container.
+	delete(pathIDs, path)
+	p.cgroupMoves.check(t.Context(), p.logger, p.resolver, p.cgroups)
+	_, err = p.resolver.GetContainerView(200)
+	require.NoError(t, err)
+
+	require.NoError(t, p.RemoveContainer(t.Context(), pod, container))
+	require.Empty(t, p.cgroupMoves.entries)
+}
@@ -0,0 +1,153 @@
+package nri
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/cgroups"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+)
+
+// CgroupResolveStrategy is a way to find the cgroup of a container.
+type CgroupResolveStrategy string
+
+const (
+	// CgroupResolveNRI resolves the cgroups path reported by the runtime through NRI.
+	CgroupResolveNRI CgroupResolveStrategy = "nri"
+	// CgroupResolveFS searches the cgroup filesystem for the cgroup named after the container ID.
+	// It doesn't depend on the syntax of the cgroups path of the runtime but walks the whole hierarchy.
+	CgroupResolveFS CgroupResolveStrategy = "fs"
+)
+
+// DefaultCgroupResolveStrategies is the default value of the --cgroup-resolve-strategy flag.
+const DefaultCgroupResolveStrategies = "nri"
+
+type cgroupResolveFunc func(container *api.Container) (resolver.CgroupID, string, error)
+
+// ParseCgroupResolveStrategies parses a comma separated list of strategies, e.g. "nri,fs".
+func ParseCgroupResolveStrategies(s string) ([]CgroupResolveStrategy, error) {
+	var strategies []CgroupResolveStrategy
+	for value := range strings.SplitSeq(s, ",") {
+		strategy := CgroupResolveStrategy(strings.TrimSpace(value))
+		switch strategy {
+		case CgroupResolveNRI, CgroupResolveFS:
+		default:
+			return nil, fmt.Errorf("unknown cgroup resolve strategy %q, must be one of: %s, %s",
+				value, CgroupResolveNRI, CgroupResolveFS)
+		}
+		if slices.Contains(strategies, strategy) {
+			return nil, fmt.Errorf("cgroup resolve strategy %q is listed twice", strategy)
+		}
+		strategies = append(strategies, strategy)
+	}
+	return strategies, nil
+}
+
+// WithCgroupResolveStrategies sets the strategies tried, in order, to find the cgroup of a container.
+// The first strategy that succeeds wins.
+func WithCgroupResolveStrategies(strategies []CgroupResolveStrategy) Option {
+	return func(h *Handler) {
+		h.cgroupResolveStrategies = strategies
+	}
+}
+
+func (s CgroupResolveStrategy) resolveFunc() cgroupResolveFunc {
+	switch s {
+	case CgroupResolveFS:
+		return cgroupFromFilesystem
+	case CgroupResolveNRI:
+		return cgroupFromContainer
+	default:
+		panic(fmt.Sprintf("unhandled cgroup resolve strategy: %s", s))
+	}
+}
+
+// chainCgroupResolvers returns a resolver trying the given ones in order until one succeeds.
+func chainCgroupResolvers(resolvers []cgroupResolveFunc) cgroupResolveFunc {
+	return func(container *api.Container) (resolver.CgroupID, string, error) {
+		var errs []error
+		for _, resolve := range resolvers {
+			cgroupID, path, err := resolve(container)
+			if err == nil {
+				return cgroupID, path, nil
+			}
+			errs = append(errs, err)
+		}
+		return 0, "", errors.Join(errs...)
+	}
+}
+
+func cgroupResolverFromStrategies(strategies []CgroupResolveStrategy) cgroupResolveFunc {
+	if len(strategies) == 1 {
+		return strategies[0].resolveFunc()
+	}
+	resolvers := make([]cgroupResolveFunc, 0, len(strategies))
+	for _, strategy := range strategies {
+		resolvers = append(resolvers, strategy.resolveFunc())
+	}
+	return chainCgroupResolvers(resolvers)
+}
+
+func cgroupFromFilesystem(container *api.Container) (resolver.CgroupID, string, error) {
+	if container == nil {
+		// safety check, this should never happen
+		return 0, "", errors.New("received empty container")
+	}
+
+	path, err := findContainerCgroup(cgroups.GetCgroupResolutionPrefix(), container.GetId())
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to find the cgroup of container '%s(%s)': %w",
+			container.GetName(),
+			container.GetId(),
+			err,
+		)
+	}
+	cgroupID, err := cgroups.GetCgroupIDFromPath(path)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get cgroup ID from path '%s' for container '%s(%s)': %w",
+			path,
+			container.GetName(),
+			container.GetId(),
+			err,
+		)
+	}
+	return cgroupID, path, nil
+}
+
+// findContainerCgroup returns the outermost cgroup under root whose name contains the container ID,
+// e.g. cri-containerd-<id>.scope with the systemd driver or <id> with cgroupfs.
+func findContainerCgroup(root, containerID string) (string, error) {
+	if containerID == "" {
+		return "", errors.New("empty container ID")
+	}
+	var found string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// the cgroups of other containers can disappear while we walk the hierarchy.
+			if path != root {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if strings.Contains(d.Name(), containerID) {
+			found = path
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if found == "" {
+		return "", fmt.Errorf("no cgroup named after the container under '%s'", root)
+	}
+	return found, nil
+}
@@ -0,0 +1,95 @@
+package nri
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCgroupResolveStrategies(t *testing.T) {
+	strategies, err := ParseCgroupResolveStrategies("fs, nri")
+	require.NoError(t, err)
+	require.Equal(t, []CgroupResolveStrategy{CgroupResolveFS, CgroupResolveNRI}, strategies)
+
+	_, err = ParseCgroupResolveStrategies("nri,cri")
+	require.ErrorContains(t, err, `unknown cgroup resolve strategy "cri"`)
+	_, err = ParseCgroupResolveStrategies("nri,nri")
+	require.ErrorContains(t, err, "listed twice")
+	_, err = ParseCgroupResolveStrategies("")
+	require.Error(t, err)
+}
+
+func TestChainCgroupResolvers(t *testing.T) {
+	var calls []string
+	mock := func(name string, cgroupID resolver.CgroupID) cgroupResolveFunc {
+		return func(*api.Container) (resolver.CgroupID, string, error) {
+			calls = append(calls, name)
+			if cgroupID == 0 {
+				return 0, "", errors.New(name + " failed")
+			}
+			return cgroupID, "/" + name, nil
+		}
+	}
+
+	tests := []struct {
+		name          string
+		resolvers     []cgroupResolveFunc
+		expectedCalls []string
+		expectedID    resolver.CgroupID
+		expectedErr   string
+	}{
+		{
+			name:          "the first success wins",
+			resolvers:     []cgroupResolveFunc{mock("nri", 100), mock("fs", 200)},
+			expectedCalls: []string{"nri"},
+			expectedID:    100,
+		},
+		{
+			name:          "falls back to the next strategy",
+			resolvers:     []cgroupResolveFunc{mock("fs", 0), mock("nri", 100)},
+			expectedCalls: []string{"fs", "nri"},
+			expectedID:    100,
+		},
+		{
+			name:          "reports the errors of all the strategies",
+			resolvers:     []cgroupResolveFunc{mock("nri", 0), mock("fs", 0)},
+			expectedCalls: []string{"nri", "fs"},
+			expectedErr:   "nri failed\nfs failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			cgroupID, _, err := chainCgroupResolvers(tt.resolvers)(testContainer())
+			require.Equal(t, tt.expectedCalls, calls)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedID, cgroupID)
+		})
+	}
+}
+
+func TestFindContainerCgroup(t *testing.T) {
+	root := t.TempDir()
+	containerID := "18b2adc8507104e412c946bec11679590801f547eee513fa298054f14fbf4240"
+	scope := filepath.Join(root, "kubepods.slice", "kubepods-besteffort.slice", "cri-containerd-"+containerID+".scope")
+	require.NoError(t, os.MkdirAll(filepath.Join(scope, "init.scope"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "kubepods.slice", "cri-containerd-other.scope"), 0o755))
+
+	path, err := findContainerCgroup(root, containerID)
+	require.NoError(t, err)
+	require.Equal(t, scope, path)
+
+	_, err = findContainerCgroup(root, "missing")
+	require.ErrorContains(t, err, "no cgroup named after the container")
+	_, err = findContainerCgroup(root, "")
+	require.Error(t, err)
+}
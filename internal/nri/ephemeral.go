@@ -0,0 +1,102 @@
+package nri
+
+import (
+	"context"
+	"time"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// podLookupTimeout bounds the lookups of the pod specs.
+const podLookupTimeout = 2 * time.Second
+
+// lookupPod reads the spec of the pod, nil when it was recreated with the same name, e.g. by a StatefulSet.
+func (p *plugin) lookupPod(ctx context.Context, pod *api.PodSandbox) (*corev1.Pod, error) {
+	// the runtime waits for us, don't hold it when the API server is slow.
+	lookupCtx, cancel := context.WithTimeout(ctx, podLookupTimeout)
+	defer cancel()
+
+	var apiPod corev1.Pod
+	err := p.podReader.Get(lookupCtx, client.ObjectKey{Namespace: pod.GetNamespace(), Name: pod.GetName()}, &apiPod)
+	if err != nil {
+		return nil, err
+	}
+	if string(apiPod.UID) != pod.GetUid() {
+		return nil, nil
+	}
+	return &apiPod, nil
+}
+
+// ephemeralContainersOf returns the names of the ephemeral containers of the pod.
+// The runtime doesn't tell them apart from the regular containers, so they are read from the pod spec.
+// A failed lookup is only logged, the containers are then enforced as regular ones.
+func (p *plugin) ephemeralContainersOf(ctx context.Context, pod *api.PodSandbox) map[resolver.ContainerName]struct{} {
+	apiPod, err := p.lookupPod(ctx, pod)
+	if err != nil {
+		p.podLogger(pod).WarnContext(ctx, "failed to look up the ephemeral containers, enforcing them as regular ones",
+			"error", err)
+		return nil
+	}
+	if apiPod == nil {
+		return nil
+	}
+	names := make(map[resolver.ContainerName]struct{}, len(apiPod.Spec.EphemeralContainers))
+	for _, container := range apiPod.Spec.EphemeralContainers {
+		names[container.Name] = struct{}{}
+	}
+	return names
+}
+
+// markEphemeralContainers flags the ephemeral containers of the pod, when they are looked up.
+func (p *plugin) markEphemeralContainers(
+	ctx context.Context,
+	pod *api.PodSandbox,
+	containers map[resolver.ContainerID]resolver.ContainerInput,
+) {
+	if p.podReader == nil {
+		return
+	}
+	ephemeral := p.ephemeralContainersOf(ctx, pod)
+	for containerID, container := range containers {
+		if _, ok := ephemeral[container.Name]; ok {
+			container.Ephemeral = true
+			containers[containerID] = container
+		}
+	}
+}
+
+// deletedFromPodSpec returns whether the container is not in the pod spec anymore. The containers are kept
+// when the pod specs are not looked up or the lookup fails, the removal of the pod forgets them.
+func (p *plugin) deletedFromPodSpec(ctx context.Context, pod *api.PodSandbox, name resolver.ContainerName) bool {
+	if p.podReader == nil {
+		return false
+	}
+	apiPod, err := p.lookupPod(ctx, pod)
+	if apierrors.IsNotFound(err) || (err == nil && apiPod == nil) {
+		return true
+	}
+	if err != nil {
+		p.podLogger(pod).DebugContext(ctx, "failed to look up the pod spec", "error", err)
+		return false
+	}
+	for _, container := range apiPod.Spec.InitContainers {
+		if container.Name == name {
+			return false
+		}
+	}
+	for _, container := range apiPod.Spec.Containers {
+		if container.Name == name {
+			return false
+		}
+	}
+	for _, container := range apiPod.Spec.EphemeralContainers {
+		if container.Name == name {
+			return false
+		}
+	}
+	return true
+}
@@ -49,3 +49,36 @@ func cgroupFromContainer(container *api.Container) (resolver.CgroupID, string, e
 	}
 	return cgroupID, path, nil
 }
+
+// sandboxCgroupFromPod returns the cgroup of the pause container of the pod.
+// The sandbox cgroup path is reported with the same driver syntax as the containers,
+// so it is resolved from the same prefix, whatever the cgroup namespace of the agent.
+func sandboxCgroupFromPod(pod *api.PodSandbox) (resolver.CgroupID, error) {
+	cgroupsPath := pod.GetLinux().GetCgroupsPath()
+	if cgroupsPath == "" {
+		// e.g. CRI-O can run the pods without an infra container.
+		return 0, fmt.Errorf("no sandbox cgroup reported for pod '%s/%s'", pod.GetNamespace(), pod.GetName())
+	}
+
+	parsedPath, err := cgroups.ParseCgroupsPath(cgroupsPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse sandbox cgroup path '%s' for pod '%s/%s': %w",
+			cgroupsPath,
+			pod.GetNamespace(),
+			pod.GetName(),
+			err,
+		)
+	}
+
+	path := filepath.Join(cgroups.GetCgroupResolutionPrefix(), parsedPath)
+	cgroupID, err := cgroups.GetCgroupIDFromPath(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sandbox cgroup ID from path '%s' for pod '%s/%s': %w",
+			path,
+			pod.GetNamespace(),
+			pod.GetName(),
+			err,
+		)
+	}
+	return cgroupID, nil
+}
@@ -11,11 +11,30 @@ import (
 
 	retry "github.com/avast/retry-go/v4"
 	"github.com/containerd/nri/pkg/stub"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/podworkload"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	"golang.org/x/sync/semaphore"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 const (
-	maxDelay = time.Minute * 1
+	// DefaultRetryInitialDelay is the default delay before the first reconnection to the runtime.
+	DefaultRetryInitialDelay = time.Second
+	// DefaultRetryMaxDelay is the default upper bound of the delay between two reconnections.
+	DefaultRetryMaxDelay = time.Minute * 1
+	// DefaultMaxConcurrentResolutions is the default number of containers whose cgroup can be resolved at the same time.
+	DefaultMaxConcurrentResolutions = 8
+	// DefaultLivenessTimeout is the default time the runtime has to answer the liveness probe.
+	DefaultLivenessTimeout = 10 * time.Second
+)
+
+var (
+	// errNRIConnectionClosed is returned when the connection to the runtime is lost.
+	errNRIConnectionClosed = errors.New("the NRI connection to the container runtime was closed")
+	// errNRIUnresponsive is returned when the runtime doesn't answer the liveness probe in time.
+	errNRIUnresponsive = errors.New("the container runtime didn't answer the NRI liveness probe")
 )
 
 type Handler struct {
@@ -23,11 +42,111 @@ type Handler struct {
 	pluginIndex string
 	logger      *slog.Logger
 	resolver    *resolver.Resolver
+	// maxConcurrentResolutions is the maximum number of containers whose cgroup is resolved at the same time.
+	maxConcurrentResolutions int64
+	status                   *registrationStatus
+	// podAnnotationKeys are the pod annotations cached in the resolver.
+	podAnnotationKeys []string
+	applyLatency      prometheus.Histogram
+	// cgroups is shared by the successive NRI plugins, nil when the cache is disabled.
+	cgroups *cgroupCache
+	// cgroupMoves is shared by the successive NRI plugins, nil when the move detection is disabled.
+	cgroupMoves *cgroupWatch
+	// retryInitialDelay and retryMaxDelay bound the exponential backoff of the reconnections.
+	retryInitialDelay time.Duration
+	retryMaxDelay     time.Duration
+	// trackSandboxCgroups resolves and caches the cgroup of the pause container of each pod.
+	trackSandboxCgroups bool
+	// cgroupResolveStrategies are tried in order to find the cgroup of the containers.
+	cgroupResolveStrategies []CgroupResolveStrategy
+	// workloadOwners, if set, resolves the workload of the pods from their owner references.
+	workloadOwners *podworkload.OwnerResolver
+	// podReader, if set, reads the pod specs to tell the ephemeral containers apart.
+	podReader client.Reader
+	// livenessInterval and livenessTimeout configure the liveness probe of the connection, zero disables it.
+	livenessInterval time.Duration
+	livenessTimeout  time.Duration
+}
+
+type Option func(*Handler)
+
+// WithLivenessProbe sends an empty container update to the runtime every interval once the plugin is
+// synchronized, and reconnects when the runtime doesn't answer within the timeout.
+// This detects a connection that stalls without being closed. A zero interval disables the probe.
+func WithLivenessProbe(interval, timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.livenessInterval = interval
+		h.livenessTimeout = timeout
+	}
+}
+
+// WithMaxConcurrentResolutions limits the number of containers whose cgroup is resolved at the same time.
+// Zero or a negative value means no limit.
+func WithMaxConcurrentResolutions(limit int) Option {
+	return func(h *Handler) {
+		h.maxConcurrentResolutions = int64(limit)
+	}
+}
+
+// WithRetryBackoff sets the delay before the first reconnection to the runtime
+// and the maximum delay the exponential backoff grows to.
+func WithRetryBackoff(initialDelay, maxDelay time.Duration) Option {
+	return func(h *Handler) {
+		h.retryInitialDelay = initialDelay
+		h.retryMaxDelay = maxDelay
+	}
+}
+
+// WithCgroupCacheTTL sets how long the resolved cgroup of a container is reused,
+// e.g. when the runtime sends again all the containers after a reconnection.
+// Zero or a negative value disables the cache.
+func WithCgroupCacheTTL(ttl time.Duration) Option {
+	return func(h *Handler) {
+		h.cgroups = nil
+		if ttl > 0 {
+			h.cgroups = newCgroupCache(ttl)
+		}
+	}
+}
+
+// WithPodAnnotations caches the given pod annotations in the resolver, so that they can be exported with the events.
+// The other annotations are not kept.
+func WithPodAnnotations(keys []string) Option {
+	return func(h *Handler) {
+		h.podAnnotationKeys = keys
+	}
+}
+
+// WithSandboxCgroupTracking resolves the cgroup of the pause container of each pod
+// and stores it with the pod in the resolver.
+func WithSandboxCgroupTracking(enabled bool) Option {
+	return func(h *Handler) {
+		h.trackSandboxCgroups = enabled
+	}
+}
+
+// WithWorkloadOwnerResolver resolves the workload of the pods by walking their owner references
+// instead of parsing their name. The name heuristics are still used when the owners can't be resolved,
+// e.g. when the API server is unreachable or the pod is controlled by a custom resource.
+func WithWorkloadOwnerResolver(owners *podworkload.OwnerResolver) Option {
+	return func(h *Handler) {
+		h.workloadOwners = owners
+	}
+}
+
+// WithEphemeralContainerLookup reads the spec of the pods from the API server to flag their ephemeral
+// containers, e.g. the ones attached by `kubectl debug`, so that the resolver can exempt them from
+// the unlisted containers policy. Without it, the ephemeral containers are enforced as regular ones.
+func WithEphemeralContainerLookup(reader client.Reader) Option {
+	return func(h *Handler) {
+		h.podReader = reader
+	}
 }
 
 func newNRIPlugin(
 	logger *slog.Logger,
 	resolver *resolver.Resolver,
+	maxConcurrentResolutions int64,
 	opts ...stub.Option,
 ) (*plugin, error) {
 	var err error
@@ -37,8 +156,11 @@ func newNRIPlugin(
 		failOpen:        os.Getenv("NRI_FAILOPEN") == "true",
 		resolveCgroupID: cgroupFromContainer,
 	}
+	if maxConcurrentResolutions > 0 {
+		p.resolutions = semaphore.NewWeighted(maxConcurrentResolutions)
+	}
 
-	p.stub, err = stub.New(p, opts...)
+	p.stub, err = stub.New(p, append(opts, stub.WithOnClose(p.connectionClosed))...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create NRI plugin stub: %w", err)
 	}
@@ -50,34 +172,142 @@ func (p *plugin) Run(ctx context.Context) error {
 	go func() {
 		select {
 		case <-ctx.Done():
-			p.stub.Stop()
+			p.stop()
 		case <-done:
 		}
 	}()
 
+	if p.livenessInterval > 0 {
+		go p.probeLiveness(done)
+	}
+
 	err := p.stub.Run(ctx)
 	close(done) // prevent goroutine leak if Run() returns naturally
 
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
+	if p.connClosed.Load() {
+		return errNRIConnectionClosed
+	}
+	if p.unresponsive.Load() {
+		return errNRIUnresponsive
+	}
 	return err
 }
 
+// probeLiveness sends an empty container update to the runtime every liveness interval,
+// a round-trip on the NRI connection. The plugin is stopped when the runtime doesn't answer in time,
+// so that the handler reconnects and resynchronizes the state.
+func (p *plugin) probeLiveness(done <-chan struct{}) {
+	ticker := time.NewTicker(p.livenessInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		// The runtime can't be probed before the registration, and it may be busy until the synchronization.
+		if !p.synchronized.Load() {
+			continue
+		}
+
+		// UpdateContainers has no context, the call is abandoned on timeout and returns once the stub is stopped.
+		answered := make(chan error, 1)
+		go func() {
+			_, err := p.stub.UpdateContainers(nil)
+			answered <- err
+		}()
+		timer := time.NewTimer(p.livenessTimeout)
+		select {
+		case <-done:
+			timer.Stop()
+			return
+		case err := <-answered:
+			timer.Stop()
+			if err != nil {
+				p.logger.Warn("NRI liveness probe failed", "error", err)
+			}
+		case <-timer.C:
+			p.logger.Warn("the container runtime didn't answer the NRI liveness probe, reconnecting",
+				"timeout", p.livenessTimeout,
+			)
+			p.unresponsive.Store(true)
+			p.stop()
+			return
+		}
+	}
+}
+
+// stop closes the connection to the runtime on our side.
+func (p *plugin) stop() {
+	p.stopping.Store(true)
+	p.stub.Stop()
+}
+
+// connectionClosed is called by the stub when the connection to the runtime goes down, e.g. when the runtime
+// restarts. The plugin is stopped so that the handler reconnects and resynchronizes the state.
+// An idle runtime, e.g. on a node without any container change, keeps its connection.
+// The stub calls it as well when we stop the plugin, these closes are ignored.
+func (p *plugin) connectionClosed() {
+	if p.stopping.Load() {
+		return
+	}
+	p.logger.Warn("NRI connection closed by the container runtime, reconnecting")
+	p.connClosed.Store(true)
+	p.stub.Stop()
+}
+
 func NewNRIHandler(
 	socketPath, pluginIndex string,
 	logger *slog.Logger,
 	r *resolver.Resolver,
+	opts ...Option,
 ) (*Handler, error) {
 	h := &Handler{
 		socketPath:  socketPath,
 		pluginIndex: pluginIndex,
 		logger:      logger.With("component", "nri-handler"),
 		resolver:    r,
+		status:      newRegistrationStatus(),
+		applyLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "runtime_enforcer_policy_apply_latency_seconds",
+			Help: "Time between the start of the cgroup resolution of a starting container " +
+				"and the application of its policy in the BPF maps.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 12), //nolint:mnd // from 1ms to ~2s.
+		}),
+		cgroups:           newCgroupCache(DefaultCgroupCacheTTL),
+		retryInitialDelay: DefaultRetryInitialDelay,
+		retryMaxDelay:     DefaultRetryMaxDelay,
+
+		cgroupResolveStrategies: []CgroupResolveStrategy{CgroupResolveNRI},
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if len(h.cgroupResolveStrategies) == 0 {
+		return nil, errors.New("at least one cgroup resolve strategy is required")
+	}
+	if h.retryInitialDelay <= 0 {
+		return nil, fmt.Errorf("invalid NRI retry initial delay %s: must be positive", h.retryInitialDelay)
+	}
+	if h.retryMaxDelay < h.retryInitialDelay {
+		return nil, fmt.Errorf("invalid NRI retry max delay %s: must not be lower than the initial delay %s",
+			h.retryMaxDelay, h.retryInitialDelay)
+	}
+	if h.livenessInterval > 0 && h.livenessTimeout <= 0 {
+		return nil, fmt.Errorf("invalid NRI liveness timeout %s: must be positive", h.livenessTimeout)
 	}
 	if err := h.checkNRISupport(); err != nil {
 		return nil, fmt.Errorf("NRI support check failed: %w", err)
 	}
+	if err := metrics.Registry.Register(h.status.gauge); err != nil {
+		return nil, fmt.Errorf("failed to register NRI metrics: %w", err)
+	}
+	if err := metrics.Registry.Register(h.applyLatency); err != nil {
+		return nil, fmt.Errorf("failed to register NRI metrics: %w", err)
+	}
 	return h, nil
 }
 
@@ -118,6 +348,7 @@ func (h *Handler) startNRIPlugin(ctx context.Context) error {
 	p, err := newNRIPlugin(
 		h.logger,
 		h.resolver,
+		h.maxConcurrentResolutions,
 		stub.WithLogger(newNRILogger(h.logger)),
 		stub.WithPluginName("runtime-enforcer-agent"),
 		stub.WithPluginIdx(h.pluginIndex),
@@ -126,6 +357,21 @@ func (h *Handler) startNRIPlugin(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to create NRI plugin: %w", err)
 	}
+	p.onSynchronized = h.status.setRegistered
+	p.onConfigured = h.setRuntime
+	p.onCgroupResolved = h.status.recordCgroupResolution
+	p.podAnnotationKeys = h.podAnnotationKeys
+	p.applyLatency = h.applyLatency
+	p.cgroups = h.cgroups
+	p.cgroupMoves = h.cgroupMoves
+	p.workloadOwners = h.workloadOwners
+	p.podReader = h.podReader
+	p.livenessInterval = h.livenessInterval
+	p.livenessTimeout = h.livenessTimeout
+	p.resolveCgroupID = cgroupResolverFromStrategies(h.cgroupResolveStrategies)
+	if h.trackSandboxCgroups {
+		p.resolveSandboxCgroupID = sandboxCgroupFromPod
+	}
 
 	err = p.Run(ctx)
 	if err != nil {
@@ -134,8 +380,11 @@ func (h *Handler) startNRIPlugin(ctx context.Context) error {
 			// is usually not very helpful, e.g., `ttrpc: server closed`.
 			err = p.lastErr
 		}
-		return fmt.Errorf("NRI plugin exited with error: %w", err)
+		err = fmt.Errorf("NRI plugin exited with error: %w", err)
+		h.status.setDisconnected(err)
+		return err
 	}
+	h.status.setDisconnected(errors.New("NRI plugin stopped"))
 	return nil
 }
 
@@ -143,6 +392,9 @@ func (h *Handler) Start(ctx context.Context) error {
 	defer func() {
 		h.logger.InfoContext(ctx, "NRI handler has stopped")
 	}()
+	if h.cgroupMoves != nil {
+		go h.cgroupMoves.run(ctx, h.logger, h.resolver, h.cgroups)
+	}
 
 	// isRetryable is called only in case of err != nil
 	isRetryable := func(err error) bool {
@@ -161,9 +413,9 @@ func (h *Handler) Start(ctx context.Context) error {
 		},
 		retry.Context(ctx),
 		retry.Attempts(0), // infinite attempts
-		retry.Delay(time.Second),
+		retry.Delay(h.retryInitialDelay),
 		retry.DelayType(retry.BackOffDelay),
-		retry.MaxDelay(maxDelay),
+		retry.MaxDelay(h.retryMaxDelay),
 		retry.RetryIf(isRetryable),
 		retry.OnRetry(func(n uint, err error) {
 			// n = 0 for the first retry
@@ -0,0 +1,137 @@
+package nri
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/containerd/nri/pkg/stub"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStub simulates a connection to the runtime, closed by the runtime when closeConn is called.
+// Like the NRI stub, it calls onClose when it is stopped as well.
+// When stalled is set, the container updates are not answered until the stub is stopped.
+type fakeStub struct {
+	stub.Stub
+
+	onClose   func()
+	stopOnce  sync.Once
+	stopped   chan struct{}
+	closeConn chan struct{}
+	stalled   bool
+	updates   atomic.Int32
+}
+
+func newFakeStub(onClose func()) *fakeStub {
+	return &fakeStub{onClose: onClose, stopped: make(chan struct{}), closeConn: make(chan struct{})}
+}
+
+func (s *fakeStub) Run(_ context.Context) error {
+	select {
+	case <-s.stopped:
+	case <-s.closeConn:
+	}
+	s.onClose()
+	return nil
+}
+
+func (s *fakeStub) Stop() {
+	s.stopOnce.Do(func() { close(s.stopped) })
+}
+
+func (s *fakeStub) UpdateContainers([]*api.ContainerUpdate) ([]*api.ContainerUpdate, error) {
+	s.updates.Add(1)
+	if s.stalled {
+		<-s.stopped
+		return nil, errors.New("ttrpc: closed")
+	}
+	return nil, nil
+}
+
+func TestPluginRunConnectionClosed(t *testing.T) {
+	t.Run("reports a connection closed by the runtime", func(t *testing.T) {
+		p := newTestPlugin(t, false, 100)
+		s := newFakeStub(p.connectionClosed)
+		p.stub = s
+
+		close(s.closeConn)
+		err := p.Run(t.Context())
+		require.ErrorIs(t, err, errNRIConnectionClosed)
+	})
+
+	t.Run("keeps an idle connection", func(t *testing.T) {
+		p := newTestPlugin(t, false, 100)
+		p.stub = newFakeStub(p.connectionClosed)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+		defer cancel()
+		err := p.Run(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.False(t, p.connClosed.Load(), "stopping the plugin is not a close by the runtime")
+	})
+}
+
+func TestPluginRunLivenessProbe(t *testing.T) {
+	t.Run("reconnects a runtime not answering the probe", func(t *testing.T) {
+		p := newTestPlugin(t, false, 100)
+		s := newFakeStub(p.connectionClosed)
+		s.stalled = true
+		p.stub = s
+		p.synchronized.Store(true)
+		p.livenessInterval = 10 * time.Millisecond
+		p.livenessTimeout = 20 * time.Millisecond
+
+		err := p.Run(t.Context())
+		require.ErrorIs(t, err, errNRIUnresponsive)
+		require.False(t, p.connClosed.Load())
+	})
+
+	t.Run("keeps a runtime answering the probe", func(t *testing.T) {
+		p := newTestPlugin(t, false, 100)
+		s := newFakeStub(p.connectionClosed)
+		p.stub = s
+		p.synchronized.Store(true)
+		p.livenessInterval = 10 * time.Millisecond
+		p.livenessTimeout = 20 * time.Millisecond
+
+		ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+		defer cancel()
+		err := p.Run(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Positive(t, s.updates.Load())
+	})
+
+	t.Run("waits for the synchronization", func(t *testing.T) {
+		p := newTestPlugin(t, false, 100)
+		s := newFakeStub(p.connectionClosed)
+		s.stalled = true
+		p.stub = s
+		p.livenessInterval = 10 * time.Millisecond
+		p.livenessTimeout = 20 * time.Millisecond
+
+		ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+		defer cancel()
+		err := p.Run(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Zero(t, s.updates.Load())
+	})
+}
+
+func TestNewNRIHandlerRetryBackoffValidation(t *testing.T) {
+	logger := testutil.NewTestLogger(t)
+
+	_, err := NewNRIHandler("/nonexistent.sock", "00", logger, nil, WithRetryBackoff(0, time.Minute))
+	require.ErrorContains(t, err, "initial delay")
+
+	_, err = NewNRIHandler("/nonexistent.sock", "00", logger, nil, WithRetryBackoff(time.Minute, time.Second))
+	require.ErrorContains(t, err, "max delay")
+
+	_, err = NewNRIHandler("/nonexistent.sock", "00", logger, nil, WithLivenessProbe(time.Minute, 0))
+	require.ErrorContains(t, err, "liveness timeout")
+}
@@ -2,19 +2,29 @@ package nri
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync/atomic"
+	"time"
 
 	retry "github.com/avast/retry-go/v4"
 	"github.com/containerd/nri/pkg/api"
 	"github.com/containerd/nri/pkg/stub"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/podworkload"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/workloadkind"
+	"golang.org/x/sync/semaphore"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const nriSyncRetryMsg = "NRI pod/container sync not ready yet, will retry"
+const (
+	nriSyncRetryMsg = "NRI pod/container sync not ready yet, will retry"
+	// workloadOwnerTimeout bounds the lookup of the owner references of a pod.
+	workloadOwnerTimeout = 2 * time.Second
+)
 
 type plugin struct {
 	stub            stub.Stub
@@ -23,6 +33,84 @@ type plugin struct {
 	lastErr         error
 	failOpen        bool
 	resolveCgroupID func(container *api.Container) (resolver.CgroupID, string, error)
+	// resolveSandboxCgroupID, if set, resolves the cgroup of the pause container of the pods.
+	resolveSandboxCgroupID func(pod *api.PodSandbox) (resolver.CgroupID, error)
+	// connClosed is set when the runtime closed the connection of the plugin.
+	connClosed atomic.Bool
+	// synchronized is set once the runtime synchronized the plugin.
+	synchronized atomic.Bool
+	// unresponsive is set when the runtime didn't answer the liveness probe in time.
+	unresponsive atomic.Bool
+	// stopping is set when the plugin stops its own connection, so that the close isn't blamed on the runtime.
+	stopping atomic.Bool
+	// livenessInterval is the period of the liveness probe, zero disables it.
+	livenessInterval time.Duration
+	// livenessTimeout is how long the runtime has to answer the liveness probe.
+	livenessTimeout time.Duration
+	// resolutions bounds the number of concurrent cgroup resolutions, nil means unlimited.
+	resolutions *semaphore.Weighted
+	// onSynchronized, if set, is called once the runtime registered and synchronized the plugin.
+	onSynchronized func()
+	// onConfigured, if set, is called with the name and the version of the runtime configuring the plugin.
+	onConfigured func(runtime, version string)
+	// onCgroupResolved, if set, is called with the outcome of each cgroup resolution not served from the cache.
+	onCgroupResolved func(err error)
+	// podAnnotationKeys are the pod annotations copied into the pod metadata.
+	podAnnotationKeys []string
+	// applyLatency, if set, observes how long it takes to enforce a starting container.
+	applyLatency prometheus.Observer
+	// cgroups, if set, caches the cgroups resolved during the previous connections.
+	cgroups *cgroupCache
+	// cgroupMoves, if set, watches the cgroup paths of the running containers.
+	cgroupMoves *cgroupWatch
+	// workloadOwners, if set, resolves the workload of the pods from their owner references.
+	workloadOwners *podworkload.OwnerResolver
+	// podReader, if set, reads the pod specs to tell the ephemeral containers apart.
+	podReader client.Reader
+}
+
+// cgroupOf resolves the cgroup of the container. The number of concurrent resolutions is limited,
+// so that a burst of container starts, e.g. during node boot, doesn't stampede the filesystem.
+// Recently resolved cgroups are served from the cache without any resolution.
+func (p *plugin) cgroupOf(ctx context.Context, container *api.Container) (resolver.CgroupID, string, error) {
+	cgroupsPath := container.GetLinux().GetCgroupsPath()
+	if p.cgroups != nil {
+		if cgroupID, path, ok := p.cgroups.get(container.GetId(), cgroupsPath); ok {
+			return cgroupID, path, nil
+		}
+	}
+
+	if p.resolutions != nil {
+		if err := p.resolutions.Acquire(ctx, 1); err != nil {
+			return 0, "", fmt.Errorf("failed to wait for cgroup resolution: %w", err)
+		}
+		defer p.resolutions.Release(1)
+	}
+	cgroupID, path, err := p.resolveCgroupID(container)
+	if p.onCgroupResolved != nil {
+		p.onCgroupResolved(err)
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	if p.cgroups != nil {
+		p.cgroups.put(container.GetId(), cgroupsPath, cgroupID, path)
+	}
+	return cgroupID, path, nil
+}
+
+// sandboxCgroupOf resolves the cgroup of the pause container of the pod when the sandboxes are tracked.
+// The sandbox cgroup isn't needed to enforce the containers, so a failed resolution only leaves it unknown.
+func (p *plugin) sandboxCgroupOf(ctx context.Context, pod *api.PodSandbox) resolver.CgroupID {
+	if p.resolveSandboxCgroupID == nil {
+		return 0
+	}
+	cgroupID, err := p.resolveSandboxCgroupID(pod)
+	if err != nil {
+		p.podLogger(pod).DebugContext(ctx, "failed to resolve the pod sandbox cgroup", "error", err)
+		return 0
+	}
+	return cgroupID
 }
 
 // podLogger returns a logger pre-enriched with the pod fields.
@@ -53,6 +141,22 @@ func (p *plugin) containerLogger(pod *api.PodSandbox, container *api.Container)
 }
 
 func (p *plugin) getWorkloadInfoAndLog(ctx context.Context, pod *api.PodSandbox) (string, workloadkind.Kind) {
+	if p.workloadOwners != nil {
+		// the runtime waits for us to start the container, don't hold it when the API server is slow.
+		ownerCtx, cancel := context.WithTimeout(ctx, workloadOwnerTimeout)
+		workloadName, workloadKind, err := p.workloadOwners.GetWorkloadInfo(ownerCtx, pod.GetNamespace(), pod.GetName())
+		cancel()
+		if err == nil {
+			return workloadName, workloadKind
+		}
+		logger := p.podLogger(pod)
+		if errors.Is(err, podworkload.ErrUnknownOwner) {
+			logger.DebugContext(ctx, "workload not resolved from the owner references, using the pod name", "error", err)
+		} else {
+			logger.WarnContext(ctx, "failed to resolve the workload from the owner references, using the pod name",
+				"error", err)
+		}
+	}
 	workloadName, workloadKind, truncated := podworkload.GetTruncatedWorkloadInfo(pod.GetName(), pod.GetLabels())
 	if truncated {
 		p.podLogger(pod).WarnContext(ctx, "Detected truncated workload name",
@@ -63,7 +167,26 @@ func (p *plugin) getWorkloadInfoAndLog(ctx context.Context, pod *api.PodSandbox)
 	return workloadName, workloadKind
 }
 
-func podSandboxToPodMeta(pod *api.PodSandbox, workloadName string, workloadKind workloadkind.Kind) resolver.PodMeta {
+func selectAnnotations(annotations map[string]string, keys []string) map[string]string {
+	var selected map[string]string
+	for _, key := range keys {
+		value, ok := annotations[key]
+		if !ok {
+			continue
+		}
+		if selected == nil {
+			selected = make(map[string]string, len(keys))
+		}
+		selected[key] = value
+	}
+	return selected
+}
+
+func (p *plugin) podSandboxToPodMeta(
+	pod *api.PodSandbox,
+	workloadName string,
+	workloadKind workloadkind.Kind,
+) resolver.PodMeta {
 	return resolver.PodMeta{
 		// K8s static pods are created by the Kubelet with a pod uid that is different from the one
 		// assigned by the API server. The pod uid created by the kubelet will be put in the `kubernetes.io/config.hash`
@@ -86,9 +209,19 @@ func podSandboxToPodMeta(pod *api.PodSandbox, workloadName string, workloadKind
 		WorkloadName: workloadName,
 		WorkloadType: string(workloadKind),
 		Labels:       pod.GetLabels(),
+		Annotations:  selectAnnotations(pod.GetAnnotations(), p.podAnnotationKeys),
 	}
 }
 
+// Configure is called by the runtime when the plugin registers, before the synchronization.
+// Returning an empty event mask subscribes the plugin to all the events it implements.
+func (p *plugin) Configure(_ context.Context, _, runtime, version string) (api.EventMask, error) {
+	if p.onConfigured != nil {
+		p.onConfigured(runtime, version)
+	}
+	return 0, nil
+}
+
 // Synchronize synchronizes the state of the NRI plugin with the current state of the pods and containers.
 func (p *plugin) Synchronize(
 	ctx context.Context,
@@ -102,9 +235,28 @@ func (p *plugin) Synchronize(
 
 	// we store the container for now and we associate them later with the pod sandbox
 	tmpSandboxes := make(map[string]map[resolver.ContainerID]resolver.ContainerInput)
+	// unresolvedSandboxes are the containers created but not started yet, by pod sandbox.
+	// The stopped containers won't get a cgroup anymore, they are not reported.
+	unresolvedSandboxes := make(map[string][]*api.Container)
 	for _, container := range containers {
+		if !hasCgroup(container) {
+			if container.GetState() == api.ContainerState_CONTAINER_CREATED {
+				unresolvedSandboxes[container.GetPodSandboxId()] = append(
+					unresolvedSandboxes[container.GetPodSandboxId()], container)
+			}
+			// Containers that are not started yet (e.g. pods still pulling images) or already stopped
+			// don't have a cgroup, so the resolution would fail.
+			// Created containers are added later through the StartContainer hook.
+			p.logger.DebugContext(ctx, "skipping container without cgroup",
+				"container", container.GetName(),
+				"id", container.GetId(),
+				"state", container.GetState().String(),
+			)
+			continue
+		}
+
 		// We need to take also the cgroupPath in synchronize because it is possible that we already have nested containers and we need to iterate over them inside the resolver.
-		cgroupID, cgroupPath, err := p.resolveCgroupID(container)
+		cgroupID, cgroupPath, err := p.cgroupOf(ctx, container)
 		if err != nil {
 			// When this happens, we can't retrieve the cgroup ID in the target system.
 			// This is a critical error.
@@ -128,11 +280,13 @@ func (p *plugin) Synchronize(
 				CgroupID: cgroupID,
 				Name:     container.GetName(),
 				ID:       container.GetId(),
+				RootPath: containerRootPath(container),
 			},
 			CgroupPath: cgroupPath,
 		}
 	}
 
+	podsData := make([]resolver.PodInput, 0, len(pods))
 	for _, pod := range pods {
 		if pod == nil {
 			// safety check, this should never happen
@@ -140,6 +294,10 @@ func (p *plugin) Synchronize(
 			continue
 		}
 
+		for _, container := range unresolvedSandboxes[pod.GetId()] {
+			p.resolver.MarkContainerCreated(pod.GetUid(), pod.GetNamespace(), pod.GetName(), container.GetName(),
+				container.GetId())
+		}
 		podLogger := p.podLogger(pod)
 		containers, ok := tmpSandboxes[pod.GetId()]
 		if !ok {
@@ -153,28 +311,99 @@ func (p *plugin) Synchronize(
 			continue
 		}
 
+		// an ephemeral container is only ever added to a running pod, it never is the only container.
+		if len(containers) > 1 {
+			p.markEphemeralContainers(ctx, pod, containers)
+		}
+
 		workloadName, workloadKind := p.getWorkloadInfoAndLog(ctx, pod)
 		podData := resolver.PodInput{
-			Meta:       podSandboxToPodMeta(pod, workloadName, workloadKind),
-			Containers: containers,
+			Meta:            p.podSandboxToPodMeta(pod, workloadName, workloadKind),
+			Containers:      containers,
+			SandboxCgroupID: p.sandboxCgroupOf(ctx, pod),
 		}
 
 		// Add also the full list for debugging purpose
 		podLogger.DebugContext(ctx, "Synchronize pod with containers",
 			"containers", containers,
 		)
-		if err := p.resolver.AddPodContainerFromNri(podData); err != nil {
-			// This could be recoverable. Returning an error so we can retry.
-			podLogger.InfoContext(ctx, nriSyncRetryMsg, "error", err)
-			return nil, fmt.Errorf("%s: %w", nriSyncRetryMsg, err)
-		}
+		podsData = append(podsData, podData)
+	}
+	// The pods are added at once, so that the pods sharing a policy are enforced with a single map update.
+	if err := p.resolver.AddPodsFromNri(podsData); err != nil {
+		// This could be recoverable. Returning an error so we can retry.
+		p.logger.InfoContext(ctx, nriSyncRetryMsg, "error", err)
+		return nil, fmt.Errorf("%s: %w", nriSyncRetryMsg, err)
 	}
+	p.watchCgroups(podsData)
 	// Mark resolver as synchronized, so old agent can be safely removed.
 	p.resolver.NRISynchronized()
+	p.synchronized.Store(true)
+	if p.onSynchronized != nil {
+		p.onSynchronized()
+	}
 	p.logger.InfoContext(ctx, "Pod sandboxes synchronized")
 	return nil, nil
 }
 
+// watchCgroups records the cgroup paths of the synchronized containers.
+func (p *plugin) watchCgroups(pods []resolver.PodInput) {
+	if p.cgroupMoves == nil {
+		return
+	}
+	for _, pod := range pods {
+		for containerID, container := range pod.Containers {
+			p.cgroupMoves.watch(pod.Meta.ID, containerID, container.CgroupID, container.CgroupPath)
+		}
+	}
+}
+
+// hasCgroup reports whether the container is in a state where its cgroup exists.
+func hasCgroup(container *api.Container) bool {
+	switch container.GetState() {
+	case api.ContainerState_CONTAINER_CREATED, api.ContainerState_CONTAINER_STOPPED:
+		return false
+	case api.ContainerState_CONTAINER_UNKNOWN, api.ContainerState_CONTAINER_PAUSED, api.ContainerState_CONTAINER_RUNNING:
+		return true
+	default:
+		return true
+	}
+}
+
+// containerRootPath returns the root filesystem of the container through its init process,
+// or an empty string when the runtime doesn't report it.
+func containerRootPath(container *api.Container) string {
+	if container.GetPid() == 0 {
+		return ""
+	}
+	return fmt.Sprintf("/proc/%d/root", container.GetPid())
+}
+
+// CreateContainer records the container until StartContainer resolves its cgroup,
+// so that the containers failing before they start are reported as not enforced.
+func (p *plugin) CreateContainer(
+	_ context.Context,
+	pod *api.PodSandbox,
+	container *api.Container,
+) (*api.ContainerAdjustment, []*api.ContainerUpdate, error) {
+	p.resolver.MarkContainerCreated(pod.GetUid(), pod.GetNamespace(), pod.GetName(), container.GetName(),
+		container.GetId())
+	return nil, nil, nil
+}
+
+// StopContainer keeps the containers stopped before their cgroup was resolved: a crash looping container is
+// stopped between its restarts, it stays reported as unresolved since its first creation.
+func (p *plugin) StopContainer(
+	ctx context.Context,
+	pod *api.PodSandbox,
+	container *api.Container,
+) ([]*api.ContainerUpdate, error) {
+	if p.resolver.IsContainerUnresolved(pod.GetUid(), container.GetName()) {
+		p.containerLogger(pod, container).DebugContext(ctx, "container stopped before its cgroup was resolved")
+	}
+	return nil, nil
+}
+
 func (p *plugin) StartContainer(
 	ctx context.Context,
 	pod *api.PodSandbox,
@@ -202,8 +431,15 @@ func (p *plugin) StartContainer(
 		return nriErr
 	}
 
-	// Here we can ignore the cgroupPath because the container is not yet running so we cannot have nested cgroups.
-	cgroupID, _, err := p.resolveCgroupID(container)
+	// A container restarting in place keeps its ID but gets a new cgroup, so it is always resolved again.
+	if p.cgroups != nil {
+		p.cgroups.forget(container.GetId())
+	}
+
+	// The cgroupPath is not given to the resolver because the container is not yet running
+	// so we cannot have nested cgroups, it is only watched for cgroup moves.
+	resolutionStart := time.Now()
+	cgroupID, cgroupPath, err := p.cgroupOf(ctx, container)
 	if err != nil {
 		// this should never happen because we've succeeded before in Synchronize() call.
 		// When this happens, it indicates a serious inconsistency in the system.
@@ -212,22 +448,33 @@ func (p *plugin) StartContainer(
 
 	workloadName, workloadKind := p.getWorkloadInfoAndLog(ctx, pod)
 	podData := resolver.PodInput{
-		Meta: podSandboxToPodMeta(pod, workloadName, workloadKind),
+		Meta: p.podSandboxToPodMeta(pod, workloadName, workloadKind),
 		Containers: map[resolver.ContainerID]resolver.ContainerInput{
 			container.GetId(): {
 				ContainerMeta: resolver.ContainerMeta{
 					CgroupID: cgroupID,
 					Name:     container.GetName(),
 					ID:       container.GetId(),
+					RootPath: containerRootPath(container),
 				},
 				CgroupPath: "",
 			},
 		},
+		SandboxCgroupID: p.sandboxCgroupOf(ctx, pod),
+	}
+	if p.resolver.HasPod(pod.GetUid()) {
+		p.markEphemeralContainers(ctx, pod, podData.Containers)
 	}
 
 	if err = p.resolver.AddPodContainerFromNri(podData); err != nil {
 		return handleError("failed to add pod container from NRI", err)
 	}
+	if p.cgroupMoves != nil {
+		p.cgroupMoves.watch(pod.GetUid(), container.GetId(), cgroupID, cgroupPath)
+	}
+	if p.applyLatency != nil {
+		p.applyLatency.Observe(time.Since(resolutionStart).Seconds())
+	}
 	return nil
 }
 
@@ -238,6 +485,16 @@ func (p *plugin) StartContainer(
 func (p *plugin) RemoveContainer(ctx context.Context, pod *api.PodSandbox, container *api.Container) error {
 	containerLogger := p.containerLogger(pod, container)
 	containerLogger.InfoContext(ctx, "Removing container")
+	if p.resolver.IsContainerUnresolved(pod.GetUid(), container.GetName()) &&
+		p.deletedFromPodSpec(ctx, pod, container.GetName()) {
+		p.resolver.ForgetUnresolvedContainer(pod.GetUid(), container.GetName(), container.GetId())
+	}
+	if p.cgroups != nil {
+		p.cgroups.forget(container.GetId())
+	}
+	if p.cgroupMoves != nil {
+		p.cgroupMoves.forget(container.GetId())
+	}
 	if err := p.resolver.RemovePodContainerFromNri(pod.GetUid(), container.GetId()); err != nil {
 		containerLogger.ErrorContext(ctx, "failed to remove pod container from cache",
 			"error", err,
@@ -245,3 +502,9 @@ func (p *plugin) RemoveContainer(ctx context.Context, pod *api.PodSandbox, conta
 	}
 	return nil
 }
+
+// RemovePodSandbox forgets the containers of the pod that never got a cgroup.
+func (p *plugin) RemovePodSandbox(_ context.Context, pod *api.PodSandbox) error {
+	p.resolver.ForgetUnresolvedContainers(pod.GetUid())
+	return nil
+}
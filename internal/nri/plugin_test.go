@@ -2,13 +2,27 @@ package nri
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/containerd/nri/pkg/api"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/podworkload"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/testutil"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/workloadkind"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/semaphore"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func newTestPlugin(
@@ -81,6 +95,65 @@ func TestPluginStartContainer(t *testing.T) {
 		}, containerView)
 	})
 
+	t.Run("observes the policy apply latency", func(t *testing.T) {
+		var observed []float64
+		p := newTestPlugin(t, false, 100)
+		p.applyLatency = prometheus.ObserverFunc(func(v float64) { observed = append(observed, v) })
+
+		require.NoError(t, p.StartContainer(t.Context(), testPodSandbox(), testContainer()))
+		require.Len(t, observed, 1)
+
+		// containers that fail to start are not observed.
+		p.resolveCgroupID = func(*api.Container) (resolver.CgroupID, string, error) {
+			return 0, "", errors.New("lookup failed")
+		}
+		require.Error(t, p.StartContainer(t.Context(), testPodSandbox(), testContainer()))
+		require.Len(t, observed, 1)
+	})
+
+	t.Run("only caches the selected annotations", func(t *testing.T) {
+		pod := testPodSandbox()
+		pod.Annotations = map[string]string{
+			"owner.example.com/team":                           "payments",
+			"kubectl.kubernetes.io/last-applied-configuration": "{}",
+		}
+
+		p := newTestPlugin(t, false, 100)
+		p.podAnnotationKeys = []string{"owner.example.com/team", "owner.example.com/missing"}
+
+		err := p.StartContainer(t.Context(), pod, testContainer())
+		require.NoError(t, err)
+
+		containerView, err := p.resolver.GetContainerView(100)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"owner.example.com/team": "payments"}, containerView.PodMeta.Annotations)
+	})
+
+	t.Run("stores the sandbox cgroup of the pod when tracked", func(t *testing.T) {
+		pod := testPodSandbox()
+		p := newTestPlugin(t, false, 100)
+
+		// the sandbox is optional, failing to resolve it doesn't prevent the container from starting.
+		p.resolveSandboxCgroupID = func(*api.PodSandbox) (resolver.CgroupID, error) {
+			return 0, errors.New("no sandbox cgroup")
+		}
+		require.NoError(t, p.StartContainer(t.Context(), pod, testContainer()))
+		_, ok := p.resolver.PodSandboxCgroupID(pod.GetUid())
+		require.False(t, ok)
+
+		p.resolveSandboxCgroupID = func(*api.PodSandbox) (resolver.CgroupID, error) {
+			return 42, nil
+		}
+		sidecar := testContainer()
+		sidecar.Id = "sidecar-id"
+		sidecar.Name = "sidecar"
+		require.NoError(t, p.StartContainer(t.Context(), pod, sidecar))
+		sandboxCgroupID, ok := p.resolver.PodSandboxCgroupID(pod.GetUid())
+		require.True(t, ok)
+		require.Equal(t, resolver.CgroupID(42), sandboxCgroupID)
+		require.Equal(t, resolver.CgroupID(42), p.resolver.PodCacheSnapshot()[pod.GetUid()].SandboxCgroupID)
+	})
+
 	t.Run("returns nil in fail-open mode when cgroup lookup fails", func(t *testing.T) {
 		p := newTestPlugin(t, true, 0)
 		pod := testPodSandbox()
@@ -103,3 +176,261 @@ func TestPluginStartContainer(t *testing.T) {
 		require.Empty(t, p.resolver.PodCacheSnapshot())
 	})
 }
+
+func TestPluginConcurrentResolutions(t *testing.T) {
+	const (
+		limit      = 3
+		containers = 30
+	)
+	p := newTestPlugin(t, false, 100)
+	p.resolutions = semaphore.NewWeighted(limit)
+
+	var inFlight, maxInFlight atomic.Int32
+	p.resolveCgroupID = func(container *api.Container) (resolver.CgroupID, string, error) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			observed := maxInFlight.Load()
+			if current <= observed || maxInFlight.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		cgroupID, err := strconv.ParseUint(strings.TrimPrefix(container.GetId(), "container-"), 10, 64)
+		return resolver.CgroupID(cgroupID + 1), "", err
+	}
+
+	pod := testPodSandbox()
+	var wg sync.WaitGroup
+	for i := range containers {
+		wg.Go(func() {
+			container := testContainer()
+			container.Id = fmt.Sprintf("container-%d", i)
+			container.Name = container.Id
+			assert.NoError(t, p.StartContainer(t.Context(), pod, container))
+		})
+	}
+	wg.Wait()
+
+	require.LessOrEqual(t, maxInFlight.Load(), int32(limit))
+	require.Len(t, p.resolver.PodCacheSnapshot()[pod.GetUid()].Containers, containers)
+}
+
+func TestPluginSynchronize(t *testing.T) {
+	t.Run("skips containers without a cgroup", func(t *testing.T) {
+		p := newTestPlugin(t, false, 100)
+		p.resolveCgroupID = func(container *api.Container) (resolver.CgroupID, string, error) {
+			if container.GetState() != api.ContainerState_CONTAINER_RUNNING {
+				return 0, "", errors.New("cgroup does not exist")
+			}
+			return 100, "", nil
+		}
+
+		pod := testPodSandbox()
+		running := testContainer()
+		running.PodSandboxId = pod.GetId()
+		running.State = api.ContainerState_CONTAINER_RUNNING
+
+		created := testContainer()
+		created.Id = "created-container-id"
+		created.Name = "created"
+		created.PodSandboxId = pod.GetId()
+		created.State = api.ContainerState_CONTAINER_CREATED
+
+		stopped := testContainer()
+		stopped.Id = "stopped-container-id"
+		stopped.Name = "stopped"
+		stopped.PodSandboxId = pod.GetId()
+		stopped.State = api.ContainerState_CONTAINER_STOPPED
+
+		_, err := p.Synchronize(t.Context(), []*api.PodSandbox{pod}, []*api.Container{running, created, stopped})
+		require.NoError(t, err)
+		require.True(t, p.resolver.IsNRISynchronized())
+
+		snapshot := p.resolver.PodCacheSnapshot()
+		require.Len(t, snapshot, 1)
+		require.Len(t, snapshot[pod.GetUid()].Containers, 1)
+		require.Contains(t, snapshot[pod.GetUid()].Containers, running.GetId())
+
+		// the created containers are not enforced until they start, the stopped ones won't start anymore.
+		unresolved := p.resolver.UnresolvedContainers(0)
+		require.Len(t, unresolved, 1)
+		require.Equal(t, "created", unresolved[0].Name)
+	})
+
+	t.Run("does not add pods whose containers are not started yet", func(t *testing.T) {
+		p := newTestPlugin(t, false, 0)
+
+		pod := testPodSandbox()
+		created := testContainer()
+		created.PodSandboxId = pod.GetId()
+		created.State = api.ContainerState_CONTAINER_CREATED
+
+		_, err := p.Synchronize(t.Context(), []*api.PodSandbox{pod}, []*api.Container{created})
+		require.NoError(t, err)
+		require.Empty(t, p.resolver.PodCacheSnapshot())
+
+		// once the container starts, the pod is added through the StartContainer hook.
+		p.resolveCgroupID = func(*api.Container) (resolver.CgroupID, string, error) {
+			return 100, "", nil
+		}
+		created.State = api.ContainerState_CONTAINER_RUNNING
+		require.NoError(t, p.StartContainer(t.Context(), pod, created))
+		require.Len(t, p.resolver.PodCacheSnapshot(), 1)
+	})
+}
+
+func TestPluginWorkloadFromOwners(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	controller := true
+	deploymentPod := testPodSandbox()
+	deploymentPod.Name = "web-674bcc58f4-pwvps"
+	deploymentPod.Labels = map[string]string{"pod-template-hash": "674bcc58f4"}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: deploymentPod.Namespace,
+			Name:      deploymentPod.Name,
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "web", Controller: &controller},
+			},
+		}},
+	).Build()
+
+	p := newTestPlugin(t, false, 1)
+	p.workloadOwners = podworkload.NewOwnerResolver(cl)
+
+	// the owner references win over the labels.
+	name, kind := p.getWorkloadInfoAndLog(t.Context(), deploymentPod)
+	require.Equal(t, "web", name)
+	require.Equal(t, workloadkind.DaemonSet, kind)
+
+	// the pod is unknown to the API server: the name heuristics are used.
+	name, kind = p.getWorkloadInfoAndLog(t.Context(), testPodSandbox())
+	require.Equal(t, "demo-pod", name)
+	require.Equal(t, workloadkind.Pod, kind)
+}
+
+func TestPluginEphemeralContainers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	pod := testPodSandbox()
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: pod.GetNamespace(), Name: pod.GetName(), UID: "pod-uid"},
+			Spec: corev1.PodSpec{
+				EphemeralContainers: []corev1.EphemeralContainer{
+					{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger"}},
+				},
+			},
+		},
+	).Build()
+
+	p := newTestPlugin(t, false, 100)
+	p.podReader = cl
+	require.NoError(t, p.StartContainer(t.Context(), pod, testContainer()))
+
+	debugger := testContainer()
+	debugger.Id = "debugger-id"
+	debugger.Name = "debugger"
+	p.resolveCgroupID = func(*api.Container) (resolver.CgroupID, string, error) {
+		return 101, "", nil
+	}
+	require.NoError(t, p.StartContainer(t.Context(), pod, debugger))
+
+	containerView, err := p.resolver.GetContainerView(100)
+	require.NoError(t, err)
+	require.False(t, containerView.Meta.Ephemeral)
+	containerView, err = p.resolver.GetContainerView(101)
+	require.NoError(t, err)
+	require.True(t, containerView.Meta.Ephemeral)
+
+	// the pod is unknown to the API server: its containers are enforced as regular ones.
+	other := testPodSandbox()
+	other.Uid = "other-uid"
+	other.Name = "other-pod"
+	p.resolveCgroupID = func(container *api.Container) (resolver.CgroupID, string, error) {
+		if container.GetName() == "debugger" {
+			return 201, "", nil
+		}
+		return 200, "", nil
+	}
+	debugger.Id = "other-debugger-id"
+	container := testContainer()
+	container.Id = "other-container-id"
+	container.PodSandboxId = other.GetId()
+	debugger.PodSandboxId = other.GetId()
+	container.State = api.ContainerState_CONTAINER_RUNNING
+	debugger.State = api.ContainerState_CONTAINER_RUNNING
+	_, err = p.Synchronize(t.Context(), []*api.PodSandbox{other}, []*api.Container{container, debugger})
+	require.NoError(t, err)
+	containerView, err = p.resolver.GetContainerView(201)
+	require.NoError(t, err)
+	require.False(t, containerView.Meta.Ephemeral)
+}
+
+func TestPluginUnresolvedContainers(t *testing.T) {
+	p := newTestPlugin(t, true, 0)
+	pod := testPodSandbox()
+
+	_, _, err := p.CreateContainer(t.Context(), pod, testContainer())
+	require.NoError(t, err)
+	// in fail-open mode, a container whose cgroup cannot be resolved starts without enforcement.
+	require.NoError(t, p.StartContainer(t.Context(), pod, testContainer()))
+	unresolved := p.resolver.UnresolvedContainers(0)
+	require.Len(t, unresolved, 1)
+	require.Equal(t, "app", unresolved[0].Name)
+	require.Equal(t, pod.GetUid(), unresolved[0].PodID)
+
+	p.resolveCgroupID = func(*api.Container) (resolver.CgroupID, string, error) {
+		return 100, "", nil
+	}
+	require.NoError(t, p.StartContainer(t.Context(), pod, testContainer()))
+	require.Empty(t, p.resolver.UnresolvedContainers(0))
+
+	// a crash looping container is stopped and its dead creations removed between its restarts,
+	// it stays reported since its first creation.
+	p.resolveCgroupID = func(*api.Container) (resolver.CgroupID, string, error) {
+		return 0, "", errors.New("lookup failed")
+	}
+	var since time.Time
+	for restart := range 3 {
+		container := testContainer()
+		container.Id = fmt.Sprintf("container-id-%d", restart)
+		_, _, err = p.CreateContainer(t.Context(), pod, container)
+		require.NoError(t, err)
+		unresolved = p.resolver.UnresolvedContainers(0)
+		require.Len(t, unresolved, 1)
+		if restart == 0 {
+			since = unresolved[0].Since
+		}
+		require.Equal(t, since, unresolved[0].Since)
+		_, err = p.StopContainer(t.Context(), pod, container)
+		require.NoError(t, err)
+		require.Len(t, p.resolver.UnresolvedContainers(0), 1)
+		require.NoError(t, p.RemoveContainer(t.Context(), pod, container))
+		require.Len(t, p.resolver.UnresolvedContainers(0), 1)
+	}
+
+	// the container is forgotten once it is deleted from the pod spec.
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	apiPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: pod.GetNamespace(), Name: pod.GetName(), UID: "pod-uid"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+	}
+	p.podReader = fake.NewClientBuilder().WithScheme(scheme).WithObjects(apiPod).Build()
+	require.NoError(t, p.RemoveContainer(t.Context(), pod, testContainer()))
+	require.Len(t, p.resolver.UnresolvedContainers(0), 1)
+	apiPod.Spec.Containers = []corev1.Container{{Name: "other"}}
+	p.podReader = fake.NewClientBuilder().WithScheme(scheme).WithObjects(apiPod).Build()
+	container := testContainer()
+	container.Id = "container-id-2"
+	require.NoError(t, p.RemoveContainer(t.Context(), pod, container))
+	require.Empty(t, p.resolver.UnresolvedContainers(0))
+
+	_, _, err = p.CreateContainer(t.Context(), pod, testContainer())
+	require.NoError(t, err)
+	require.NoError(t, p.RemovePodSandbox(t.Context(), pod))
+	require.Empty(t, p.resolver.UnresolvedContainers(0))
+}
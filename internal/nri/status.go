@@ -0,0 +1,131 @@
+package nri
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// registrationStatus tracks whether the NRI plugin is currently registered with the container runtime.
+// Without a registration the agent doesn't receive any pod, so it can't enforce policies.
+type registrationStatus struct {
+	mu         sync.Mutex
+	registered bool
+	// lastErr is the reason of the last disconnection, nil if the plugin was never registered.
+	lastErr error
+	// runtime is the container runtime that configured the plugin last.
+	runtime RuntimeInfo
+	// cgroupResolution tracks the cgroup resolutions of all the plugins.
+	cgroupResolution CgroupResolutionInfo
+	gauge            prometheus.Gauge
+}
+
+// RuntimeInfo describes the container runtime the NRI plugin is connected to.
+type RuntimeInfo struct {
+	Endpoint string
+	Name     string
+	Version  string
+}
+
+// CgroupResolutionInfo describes the last cgroup resolutions of the containers.
+type CgroupResolutionInfo struct {
+	// ConsecutiveFailures is the number of resolutions that failed since the last successful one,
+	// it keeps growing when the cgroup configuration of the node is not supported.
+	// Only a successful resolution resets it, an idle node keeps reporting its failures.
+	ConsecutiveFailures uint64
+	// LastError is the error of the last failed resolution.
+	LastError string
+}
+
+func newRegistrationStatus() *registrationStatus {
+	return &registrationStatus{
+		gauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "runtime_enforcer_nri_registered",
+			Help: "Set to 1 when the NRI plugin is registered with the container runtime and synchronized.",
+		}),
+	}
+}
+
+func (s *registrationStatus) setRegistered() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.registered = true
+	s.lastErr = nil
+	s.gauge.Set(1)
+}
+
+func (s *registrationStatus) setRuntime(runtime RuntimeInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runtime = runtime
+}
+
+func (s *registrationStatus) recordCgroupResolution(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		s.cgroupResolution.ConsecutiveFailures = 0
+		return
+	}
+	s.cgroupResolution.ConsecutiveFailures++
+	s.cgroupResolution.LastError = err.Error()
+}
+
+func (s *registrationStatus) setDisconnected(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.registered = false
+	s.lastErr = err
+	s.gauge.Set(0)
+}
+
+func (s *registrationStatus) check() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case s.registered:
+		return nil
+	case s.lastErr != nil:
+		return fmt.Errorf("NRI plugin is not registered with the container runtime: %w", s.lastErr)
+	default:
+		return errors.New("NRI plugin is not registered with the container runtime yet")
+	}
+}
+
+// setRuntime records the container runtime that configured the plugin.
+func (h *Handler) setRuntime(runtime, version string) {
+	h.logger.Info("connected to container runtime",
+		"endpoint", h.socketPath,
+		"runtime", runtime,
+		"version", version,
+	)
+	h.status.setRuntime(RuntimeInfo{Endpoint: h.socketPath, Name: runtime, Version: version})
+}
+
+// RuntimeInfo returns the container runtime the NRI plugin is connected to.
+// It is empty until a runtime configured the plugin.
+func (h *Handler) RuntimeInfo() RuntimeInfo {
+	h.status.mu.Lock()
+	defer h.status.mu.Unlock()
+	return h.status.runtime
+}
+
+// CgroupResolution returns the outcome of the last cgroup resolutions of the containers.
+func (h *Handler) CgroupResolution() CgroupResolutionInfo {
+	h.status.mu.Lock()
+	defer h.status.mu.Unlock()
+	return h.status.cgroupResolution
+}
+
+// Ping is a readiness check failing while the NRI plugin is not registered with the container runtime,
+// e.g. when the registration is rejected or the connection is lost.
+func (h *Handler) Ping(req *http.Request) error {
+	if err := h.status.check(); err != nil {
+		h.logger.InfoContext(req.Context(), "NRI plugin not ready", "reason", err)
+		return err
+	}
+	return nil
+}
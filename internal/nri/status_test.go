@@ -0,0 +1,80 @@
+package nri
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containerd/nri/pkg/api"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerPing(t *testing.T) {
+	h := &Handler{
+		logger: testutil.NewTestLogger(t),
+		status: newRegistrationStatus(),
+	}
+	req := httptest.NewRequestWithContext(t.Context(), "GET", "/readyz", nil)
+
+	// the plugin was never registered.
+	require.ErrorContains(t, h.Ping(req), "not registered with the container runtime yet")
+	require.InDelta(t, 0, promtestutil.ToFloat64(h.status.gauge), 0)
+
+	// the runtime synchronized the plugin.
+	p := newTestPlugin(t, false, 100)
+	p.onSynchronized = h.status.setRegistered
+	_, err := p.Synchronize(t.Context(), nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, h.Ping(req))
+	require.InDelta(t, 1, promtestutil.ToFloat64(h.status.gauge), 0)
+
+	// the registration is lost, the reason is reported.
+	h.status.setDisconnected(errors.New("plugin registration rejected"))
+	require.ErrorContains(t, h.Ping(req), "plugin registration rejected")
+	require.InDelta(t, 0, promtestutil.ToFloat64(h.status.gauge), 0)
+}
+
+func TestHandlerRuntimeInfo(t *testing.T) {
+	h := &Handler{
+		socketPath: "/var/run/nri/nri.sock",
+		logger:     testutil.NewTestLogger(t),
+		status:     newRegistrationStatus(),
+	}
+	require.Equal(t, RuntimeInfo{}, h.RuntimeInfo())
+
+	// the runtime configures the plugin when it registers.
+	p := newTestPlugin(t, false, 100)
+	p.onConfigured = h.setRuntime
+	mask, err := p.Configure(t.Context(), "", "containerd", "v2.1.4")
+	require.NoError(t, err)
+	require.Zero(t, mask, "the plugin subscribes to all the events it implements")
+	require.Equal(t, RuntimeInfo{
+		Endpoint: "/var/run/nri/nri.sock",
+		Name:     "containerd",
+		Version:  "v2.1.4",
+	}, h.RuntimeInfo())
+}
+
+func TestHandlerCgroupResolution(t *testing.T) {
+	h := &Handler{status: newRegistrationStatus()}
+	failing := newTestPlugin(t, false, 0)
+	failing.onCgroupResolved = h.status.recordCgroupResolution
+	container := &api.Container{Id: "container-id"}
+
+	for range 3 {
+		_, _, err := failing.cgroupOf(t.Context(), container)
+		require.Error(t, err)
+	}
+	require.Equal(t, CgroupResolutionInfo{ConsecutiveFailures: 3, LastError: "lookup failed"}, h.CgroupResolution())
+	// the failures are not forgotten while no container is resolved, however long the node stays idle.
+	require.Equal(t, uint64(3), h.CgroupResolution().ConsecutiveFailures)
+
+	// a successful resolution, e.g. by the plugin of the next connection, resets the failures.
+	resolving := newTestPlugin(t, false, 100)
+	resolving.onCgroupResolved = h.status.recordCgroupResolution
+	_, _, err := resolving.cgroupOf(t.Context(), container)
+	require.NoError(t, err)
+	require.Zero(t, h.CgroupResolution().ConsecutiveFailures)
+}
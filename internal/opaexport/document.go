@@ -0,0 +1,138 @@
+// Package opaexport renders the WorkloadPolicies and the WorkloadPolicyProposals as an OPA data document,
+// so that they can be loaded in a bundle and queried from Rego, e.g.
+//
+//	data.runtime_enforcer.policies[namespace][name].containers[container].allowed
+//
+// The format is documented in docs/opa_export.adoc. It is versioned by Format:
+// fields can be added in the same version, any other change requires a new one.
+package opaexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	apiv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+)
+
+// Format identifies the version of the document layout.
+const Format = "runtime-enforcer.rancher.io/opa/v1"
+
+// Document is the root of the exported data.
+type Document struct {
+	Format string `json:"format"`
+	// Policies maps the namespaces to their WorkloadPolicies by name.
+	Policies map[string]map[string]Policy `json:"policies"`
+	// Proposals maps the namespaces to their WorkloadPolicyProposals by name, when they are exported.
+	Proposals map[string]map[string]Policy `json:"proposals,omitempty"`
+}
+
+// Policy is the exported form of a WorkloadPolicy or of a WorkloadPolicyProposal.
+// Only the own rules of a policy are exported, the ones it inherits are found through BasePolicy.
+type Policy struct {
+	// Mode is "monitor" or "protect", it is empty for the proposals.
+	Mode       string `json:"mode,omitempty"`
+	BasePolicy string `json:"basePolicy,omitempty"`
+	// Priority orders the rules of the policy and the ones of its BasePolicy.
+	Priority                int32  `json:"priority,omitempty"`
+	UnlistedContainerPolicy string `json:"unlistedContainerPolicy,omitempty"`
+	// Containers maps the container names to their rules.
+	Containers map[string]Container `json:"containers"`
+}
+
+// Container are the executables allowed in a container.
+type Container struct {
+	// Allowed are the executables allowed under any parent, sorted.
+	Allowed []string `json:"allowed"`
+	// AllowedWithParent maps the executables allowed only under specific parents to those parents, sorted.
+	AllowedWithParent map[string][]string `json:"allowedWithParent,omitempty"`
+	// AllowedHashes maps the executables allowed only with a given content to their SHA-256 digest.
+	AllowedHashes map[string]string `json:"allowedHashes,omitempty"`
+	// AllowedPrefixes are the path prefixes under which every executable is allowed, sorted.
+	AllowedPrefixes []string `json:"allowedPrefixes,omitempty"`
+}
+
+// NewDocument returns an empty document.
+func NewDocument() *Document {
+	return &Document{
+		Format:   Format,
+		Policies: make(map[string]map[string]Policy),
+	}
+}
+
+// AddPolicy adds the WorkloadPolicy to the document.
+func (d *Document) AddPolicy(policy *apiv1alpha1.WorkloadPolicy) {
+	d.Policies = addTo(d.Policies, policy.Namespace, policy.Name, Policy{
+		Mode:                    policy.Spec.Mode,
+		BasePolicy:              policy.Spec.BasePolicy,
+		Priority:                policy.Spec.Priority,
+		UnlistedContainerPolicy: policy.Spec.UnlistedContainerPolicy,
+		Containers:              containers(policy.Spec.RulesByContainer),
+	})
+}
+
+// AddProposal adds the WorkloadPolicyProposal to the document.
+func (d *Document) AddProposal(proposal *apiv1alpha1.WorkloadPolicyProposal) {
+	d.Proposals = addTo(d.Proposals, proposal.Namespace, proposal.Name, Policy{
+		Containers: containers(proposal.Spec.RulesByContainer),
+	})
+}
+
+// Write encodes the document as indented JSON.
+func (d *Document) Write(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(d); err != nil {
+		return fmt.Errorf("failed to write the OPA document: %w", err)
+	}
+	return nil
+}
+
+func addTo(policies map[string]map[string]Policy, namespace, name string, policy Policy) map[string]map[string]Policy {
+	if policies == nil {
+		policies = make(map[string]map[string]Policy)
+	}
+	if policies[namespace] == nil {
+		policies[namespace] = make(map[string]Policy)
+	}
+	policies[namespace][name] = policy
+	return policies
+}
+
+func containers(rulesByContainer map[string]*apiv1alpha1.WorkloadPolicyRules) map[string]Container {
+	exported := make(map[string]Container, len(rulesByContainer))
+	for name, rules := range rulesByContainer {
+		if rules == nil {
+			continue
+		}
+		container := Container{Allowed: sorted(rules.Executables.Allowed)}
+		for _, exe := range rules.Executables.AllowedWithParent {
+			if container.AllowedWithParent == nil {
+				container.AllowedWithParent = make(map[string][]string)
+			}
+			container.AllowedWithParent[exe.Path] = sorted(append(container.AllowedWithParent[exe.Path], exe.Parents...))
+		}
+		for _, exe := range rules.Executables.AllowedHashes {
+			if container.AllowedHashes == nil {
+				container.AllowedHashes = make(map[string]string)
+			}
+			container.AllowedHashes[exe.Path] = exe.SHA256
+		}
+		if len(rules.Executables.AllowedPrefixes) > 0 {
+			container.AllowedPrefixes = sorted(rules.Executables.AllowedPrefixes)
+		}
+		exported[name] = container
+	}
+	return exported
+}
+
+// sorted returns a sorted copy without duplicates, never nil so that it is encoded as an empty array.
+func sorted(values []string) []string {
+	result := slices.Clone(values)
+	if result == nil {
+		result = []string{}
+	}
+	slices.Sort(result)
+	return slices.Compact(result)
+}
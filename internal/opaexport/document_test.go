@@ -0,0 +1,70 @@
+package opaexport
+
+import (
+	"bytes"
+	"testing"
+
+	apiv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDocumentWrite(t *testing.T) {
+	doc := NewDocument()
+	doc.AddPolicy(&apiv1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "ubuntu", Namespace: "default"},
+		Spec: apiv1alpha1.WorkloadPolicySpec{
+			Mode:                    "protect",
+			BasePolicy:              "base",
+			UnlistedContainerPolicy: apiv1alpha1.UnlistedContainerDeny,
+			RulesByContainer: map[string]*apiv1alpha1.WorkloadPolicyRules{
+				"main": {Executables: apiv1alpha1.WorkloadPolicyExecutables{
+					Allowed: []string{"/usr/bin/sleep", "/bin/bash", "/usr/bin/sleep"},
+					AllowedWithParent: []apiv1alpha1.ExecutableWithParent{
+						{Path: "/usr/bin/curl", Parents: []string{"/bin/sh", "/bin/bash"}},
+					},
+					AllowedHashes:   []apiv1alpha1.ExecutableHash{{Path: "/usr/bin/ls", SHA256: "abc"}},
+					AllowedPrefixes: []string{"/opt/app/", "/opt/lib/"},
+				}},
+				"sidecar": {},
+			},
+		},
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, doc.Write(&out))
+	require.JSONEq(t, `{
+		"format": "runtime-enforcer.rancher.io/opa/v1",
+		"policies": {
+			"default": {
+				"ubuntu": {
+					"mode": "protect",
+					"basePolicy": "base",
+					"unlistedContainerPolicy": "deny",
+					"containers": {
+						"main": {
+							"allowed": ["/bin/bash", "/usr/bin/sleep"],
+							"allowedWithParent": {"/usr/bin/curl": ["/bin/bash", "/bin/sh"]},
+							"allowedHashes": {"/usr/bin/ls": "abc"},
+							"allowedPrefixes": ["/opt/app/", "/opt/lib/"]
+						},
+						"sidecar": {"allowed": []}
+					}
+				}
+			}
+		}
+	}`, out.String())
+
+	// the proposals are only in the document when they are added.
+	doc.AddProposal(&apiv1alpha1.WorkloadPolicyProposal{
+		ObjectMeta: metav1.ObjectMeta{Name: "deploy-ubuntu", Namespace: "default"},
+		Spec: apiv1alpha1.WorkloadPolicyProposalSpec{
+			RulesByContainer: map[string]*apiv1alpha1.WorkloadPolicyRules{
+				"main": {Executables: apiv1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/bash"}}},
+			},
+		},
+	})
+	require.Equal(t, Policy{
+		Containers: map[string]Container{"main": {Allowed: []string{"/bin/bash"}}},
+	}, doc.Proposals["default"]["deploy-ubuntu"])
+}
@@ -0,0 +1,174 @@
+package podannotator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultInterval is how often the annotations are compared with the view of the resolver.
+	DefaultInterval = 30 * time.Second
+	// DefaultQPS bounds the pod updates, so that a policy change on a large node doesn't flood the API server.
+	DefaultQPS = 5
+)
+
+// summary is the value of the EnforcementSummaryAnnotationKey annotation.
+type summary struct {
+	Policy             string   `json:"policy"`
+	Mode               string   `json:"mode"`
+	Containers         []string `json:"containers"`
+	BypassedContainers []string `json:"bypassedContainers,omitempty"`
+}
+
+// Annotator maintains the EnforcementSummaryAnnotationKey annotation on the pods of the node,
+// so that `kubectl get pod -o yaml` shows how the agent enforces the pod.
+// The pods are only patched when their summary changes.
+type Annotator struct {
+	client   client.Client
+	logger   *slog.Logger
+	source   func() []resolver.PodEnforcement
+	interval time.Duration
+	limiter  *rate.Limiter
+	// written maps the pods to the last annotation written on them, empty when it was removed.
+	written map[resolver.PodID]string
+}
+
+type Option func(*Annotator)
+
+// WithInterval sets how often the annotations are synchronized.
+func WithInterval(interval time.Duration) Option {
+	return func(a *Annotator) {
+		a.interval = interval
+	}
+}
+
+// WithQPS sets the maximum number of pod updates per second.
+func WithQPS(qps float64) Option {
+	return func(a *Annotator) {
+		a.limiter = rate.NewLimiter(rate.Limit(qps), 1)
+	}
+}
+
+// NewAnnotator creates an Annotator, source returns how the pods of the node are enforced,
+// e.g. resolver.PodEnforcements.
+func NewAnnotator(
+	c client.Client,
+	logger *slog.Logger,
+	source func() []resolver.PodEnforcement,
+	opts ...Option,
+) *Annotator {
+	a := &Annotator{
+		client:   c,
+		logger:   logger.With("component", "pod-annotator"),
+		source:   source,
+		interval: DefaultInterval,
+		limiter:  rate.NewLimiter(DefaultQPS, 1),
+		written:  make(map[resolver.PodID]string),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Start synchronizes the annotations until the context is done.
+func (a *Annotator) Start(ctx context.Context) error {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			a.sync(ctx)
+		}
+	}
+}
+
+// sync patches the pods whose summary changed since the last write.
+// A failed patch is retried at the next sync.
+func (a *Annotator) sync(ctx context.Context) {
+	seen := make(map[resolver.PodID]struct{})
+	for _, enforcement := range a.source() {
+		seen[enforcement.ID] = struct{}{}
+		value, err := annotationValue(&enforcement)
+		if err != nil {
+			a.logger.ErrorContext(ctx, "failed to build the enforcement summary",
+				"pod", enforcement.Name, "namespace", enforcement.Namespace, "error", err)
+			continue
+		}
+		written, ok := a.written[enforcement.ID]
+		// a pod never annotated doesn't need the annotation to be removed.
+		if written == value && (ok || value == "") {
+			continue
+		}
+		if err = a.limiter.Wait(ctx); err != nil {
+			return
+		}
+		if err = a.patch(ctx, &enforcement, value); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			a.logger.WarnContext(ctx, "failed to update the enforcement summary of the pod",
+				"pod", enforcement.Name, "namespace", enforcement.Namespace, "error", err)
+			continue
+		}
+		a.written[enforcement.ID] = value
+	}
+	for podID := range a.written {
+		if _, ok := seen[podID]; !ok {
+			delete(a.written, podID)
+		}
+	}
+}
+
+// patch sets the annotation of the pod, an empty value removes it.
+func (a *Annotator) patch(ctx context.Context, enforcement *resolver.PodEnforcement, value string) error {
+	var annotation any
+	if value != "" {
+		annotation = value
+	}
+	data, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{v1alpha1.EnforcementSummaryAnnotationKey: annotation},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal the patch: %w", err)
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: enforcement.Namespace, Name: enforcement.Name}}
+	return a.client.Patch(ctx, pod, client.RawPatch(types.MergePatchType, data))
+}
+
+// annotationValue returns the summary of the enforcement, empty when the pod is not enforced.
+func annotationValue(enforcement *resolver.PodEnforcement) (string, error) {
+	if enforcement.Policy == "" {
+		return "", nil
+	}
+	containers := enforcement.Containers
+	if containers == nil {
+		containers = []string{}
+	}
+	data, err := json.Marshal(summary{
+		Policy:             enforcement.Policy,
+		Mode:               enforcement.Mode,
+		Containers:         containers,
+		BypassedContainers: enforcement.BypassedContainers,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
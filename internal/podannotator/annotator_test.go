@@ -0,0 +1,91 @@
+package podannotator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestAnnotatorSync(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	patches := 0
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ubuntu"}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "unprotected"}},
+		).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch,
+				opts ...client.PatchOption) error {
+				patches++
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+		}).
+		Build()
+
+	enforcements := []resolver.PodEnforcement{
+		{
+			ID:                 "uid-ubuntu",
+			Namespace:          "default",
+			Name:               "ubuntu",
+			Policy:             "ubuntu-policy",
+			Mode:               "protect",
+			Containers:         []string{"main"},
+			BypassedContainers: []string{"debug"},
+		},
+		{ID: "uid-unprotected", Namespace: "default", Name: "unprotected"},
+		// the pod was deleted from the API server, the container has not been removed yet.
+		{ID: "uid-gone", Namespace: "default", Name: "gone", Policy: "ubuntu-policy", Mode: "protect"},
+	}
+	a := NewAnnotator(cl, testutil.NewTestLogger(t), func() []resolver.PodEnforcement { return enforcements },
+		WithQPS(1000))
+
+	annotation := func(name string) (string, bool) {
+		var pod corev1.Pod
+		require.NoError(t, cl.Get(t.Context(), client.ObjectKey{Namespace: "default", Name: name}, &pod))
+		value, ok := pod.Annotations[v1alpha1.EnforcementSummaryAnnotationKey]
+		return value, ok
+	}
+
+	a.sync(t.Context())
+	value, ok := annotation("ubuntu")
+	require.True(t, ok)
+	require.JSONEq(t,
+		`{"policy":"ubuntu-policy","mode":"protect","containers":["main"],"bypassedContainers":["debug"]}`, value)
+	_, ok = annotation("unprotected")
+	require.False(t, ok)
+	require.Equal(t, 2, patches)
+
+	// nothing changed: the pods are not patched again, the missing pod is retried.
+	a.sync(t.Context())
+	require.Equal(t, 3, patches)
+
+	// the policy switched to monitor outside of its active windows.
+	enforcements[0].Mode = "monitor"
+	enforcements[0].BypassedContainers = nil
+	enforcements = enforcements[:2]
+	a.sync(t.Context())
+	value, _ = annotation("ubuntu")
+	require.JSONEq(t, `{"policy":"ubuntu-policy","mode":"monitor","containers":["main"]}`, value)
+	require.Equal(t, 4, patches)
+
+	// the pod is not enforced anymore: the annotation is removed.
+	enforcements[0].Policy = ""
+	a.sync(t.Context())
+	_, ok = annotation("ubuntu")
+	require.False(t, ok)
+	require.Equal(t, 5, patches)
+}
@@ -0,0 +1,94 @@
+package podworkload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/workloadkind"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrUnknownOwner is returned when the pod is controlled by something else than the standard controllers,
+// e.g. a custom resource or, for static pods, the node.
+var ErrUnknownOwner = errors.New("pod is not controlled by a standard workload controller")
+
+// OwnerResolver finds the workload of a pod by walking its owner references, e.g. Pod → ReplicaSet → Deployment.
+// Unlike the name heuristics it is not fooled by truncated names, but it reads the metadata of the pod
+// and of its owners from the API server.
+type OwnerResolver struct {
+	reader client.Reader
+}
+
+func NewOwnerResolver(reader client.Reader) *OwnerResolver {
+	return &OwnerResolver{reader: reader}
+}
+
+// GetWorkloadInfo returns the workload name and kind of the pod.
+// A pod without controller is its own workload, ErrUnknownOwner is returned for the other controllers.
+func (o *OwnerResolver) GetWorkloadInfo(ctx context.Context, namespace, podName string) (string, workloadkind.Kind, error) {
+	pod, err := o.getMetadata(ctx, corev1.SchemeGroupVersion.WithKind("Pod"), namespace, podName)
+	if err != nil {
+		return "", "", err
+	}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return podName, workloadkind.Pod, nil
+	}
+
+	switch ownerKind(owner) {
+	case appsv1.SchemeGroupVersion.WithKind("ReplicaSet").GroupKind():
+		replicaSet, getErr := o.getMetadata(ctx, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"), namespace, owner.Name)
+		if getErr != nil {
+			return "", "", getErr
+		}
+		if deployment := metav1.GetControllerOf(replicaSet); deployment != nil &&
+			ownerKind(deployment) == appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind() {
+			return deployment.Name, workloadkind.Deployment, nil
+		}
+		return owner.Name, workloadkind.ReplicaSet, nil
+	case appsv1.SchemeGroupVersion.WithKind("DaemonSet").GroupKind():
+		return owner.Name, workloadkind.DaemonSet, nil
+	case appsv1.SchemeGroupVersion.WithKind("StatefulSet").GroupKind():
+		return owner.Name, workloadkind.StatefulSet, nil
+	case batchv1.SchemeGroupVersion.WithKind("Job").GroupKind():
+		job, getErr := o.getMetadata(ctx, batchv1.SchemeGroupVersion.WithKind("Job"), namespace, owner.Name)
+		if getErr != nil {
+			return "", "", getErr
+		}
+		if cronJob := metav1.GetControllerOf(job); cronJob != nil &&
+			ownerKind(cronJob) == batchv1.SchemeGroupVersion.WithKind("CronJob").GroupKind() {
+			return cronJob.Name, workloadkind.CronJob, nil
+		}
+		return owner.Name, workloadkind.Job, nil
+	default:
+		return "", "", fmt.Errorf("%w: %s %s", ErrUnknownOwner, owner.Kind, owner.Name)
+	}
+}
+
+// getMetadata only reads the metadata of the object, its owner references are all we need.
+func (o *OwnerResolver) getMetadata(
+	ctx context.Context,
+	gvk schema.GroupVersionKind,
+	namespace, name string,
+) (*metav1.PartialObjectMetadata, error) {
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(gvk)
+	if err := o.reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, obj); err != nil {
+		return nil, fmt.Errorf("failed to get %s %s/%s: %w", gvk.Kind, namespace, name, err)
+	}
+	return obj, nil
+}
+
+func ownerKind(owner *metav1.OwnerReference) schema.GroupKind {
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return schema.GroupKind{Kind: owner.Kind}
+	}
+	return schema.GroupKind{Group: gv.Group, Kind: owner.Kind}
+}
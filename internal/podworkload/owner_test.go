@@ -0,0 +1,188 @@
+package podworkload
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/workloadkind"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func controlledBy(apiVersion, kind, name string) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{{APIVersion: apiVersion, Kind: kind, Name: name, Controller: &controller}}
+}
+
+func TestOwnerResolverGetWorkloadInfo(t *testing.T) {
+	longDeployment := "ubuntu-deployment" + strings.Repeat("t", 50)
+	objects := []client.Object{
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default", Name: "ubuntu-deployment-674bcc58f4",
+			OwnerReferences: controlledBy("apps/v1", "Deployment", "ubuntu-deployment"),
+		}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default", Name: longDeployment + "-674bcc58f4",
+			OwnerReferences: controlledBy("apps/v1", "Deployment", longDeployment),
+		}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bare-rs"}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default", Name: "backup-29123456",
+			OwnerReferences: controlledBy("batch/v1", "CronJob", "backup"),
+		}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "migrate-12345678"}},
+	}
+
+	tests := []struct {
+		name   string
+		pod    *corev1.Pod
+		labels map[string]string
+		// wantName and wantKind are resolved from the owner references.
+		wantName string
+		wantKind workloadkind.Kind
+		// heuristicName is what the name heuristics find for the same pod.
+		heuristicName string
+		heuristicKind workloadkind.Kind
+	}{
+		{
+			name: "deployment",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            "ubuntu-deployment-674bcc58f4-pwvps",
+				OwnerReferences: controlledBy("apps/v1", "ReplicaSet", "ubuntu-deployment-674bcc58f4"),
+			}},
+			labels:        map[string]string{podTemplateHashLabel: "674bcc58f4"},
+			wantName:      "ubuntu-deployment",
+			wantKind:      workloadkind.Deployment,
+			heuristicName: "ubuntu-deployment",
+			heuristicKind: workloadkind.Deployment,
+		},
+		{
+			name: "deployment with a truncated pod name",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            longDeployment[:58] + "q8fcg",
+				OwnerReferences: controlledBy("apps/v1", "ReplicaSet", longDeployment+"-674bcc58f4"),
+			}},
+			labels:        map[string]string{podTemplateHashLabel: "674bcc58f4"},
+			wantName:      longDeployment,
+			wantKind:      workloadkind.Deployment,
+			heuristicName: longDeployment[:58] + truncatedSuffix,
+			heuristicKind: workloadkind.Deployment,
+		},
+		{
+			name: "replicaset without deployment",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            "bare-rs-pwvps",
+				OwnerReferences: controlledBy("apps/v1", "ReplicaSet", "bare-rs"),
+			}},
+			wantName:      "bare-rs",
+			wantKind:      workloadkind.ReplicaSet,
+			heuristicName: "bare-rs-pwvps",
+			heuristicKind: workloadkind.Pod,
+		},
+		{
+			name: "daemonset",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            "ubuntu-daemonset-6qq8v",
+				OwnerReferences: controlledBy("apps/v1", "DaemonSet", "ubuntu-daemonset"),
+			}},
+			labels:        map[string]string{daemonsetLabel: "5d8f9c7b6"},
+			wantName:      "ubuntu-daemonset",
+			wantKind:      workloadkind.DaemonSet,
+			heuristicName: "ubuntu-daemonset",
+			heuristicKind: workloadkind.DaemonSet,
+		},
+		{
+			name: "statefulset",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            "db-0",
+				OwnerReferences: controlledBy("apps/v1", "StatefulSet", "db"),
+			}},
+			labels:        map[string]string{statefulsetLabel: "db-0", daemonsetLabel: "db-7c9f"},
+			wantName:      "db",
+			wantKind:      workloadkind.StatefulSet,
+			heuristicName: "db",
+			heuristicKind: workloadkind.StatefulSet,
+		},
+		{
+			name: "cronjob",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            "backup-29123456-x7k2p",
+				OwnerReferences: controlledBy("batch/v1", "Job", "backup-29123456"),
+			}},
+			labels:        map[string]string{newJobNameLabel: "backup-29123456"},
+			wantName:      "backup",
+			wantKind:      workloadkind.CronJob,
+			heuristicName: "backup",
+			heuristicKind: workloadkind.CronJob,
+		},
+		{
+			// the heuristics mistake the job for a cronjob because of its numeric suffix.
+			name: "job with a numeric suffix",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            "migrate-12345678-x7k2p",
+				OwnerReferences: controlledBy("batch/v1", "Job", "migrate-12345678"),
+			}},
+			labels:        map[string]string{newJobNameLabel: "migrate-12345678"},
+			wantName:      "migrate-12345678",
+			wantKind:      workloadkind.Job,
+			heuristicName: "migrate",
+			heuristicKind: workloadkind.CronJob,
+		},
+		{
+			name:          "bare pod",
+			pod:           &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "debug"}},
+			wantName:      "debug",
+			wantKind:      workloadkind.Pod,
+			heuristicName: "debug",
+			heuristicKind: workloadkind.Pod,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	require.NoError(t, batchv1.AddToScheme(scheme))
+	builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...)
+	for _, tt := range tests {
+		tt.pod.Namespace = "default"
+		builder.WithObjects(tt.pod)
+	}
+	resolver := NewOwnerResolver(builder.Build())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, kind, err := resolver.GetWorkloadInfo(t.Context(), "default", tt.pod.Name)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantName, name)
+			require.Equal(t, tt.wantKind, kind)
+
+			name, kind = getWorkloadInfo(tt.pod.Name, tt.labels)
+			require.Equal(t, tt.heuristicName, name)
+			require.Equal(t, tt.heuristicKind, kind)
+		})
+	}
+}
+
+func TestOwnerResolverFallback(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	resolver := NewOwnerResolver(fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "kube-system",
+			Name:            "kube-scheduler-node-1",
+			OwnerReferences: controlledBy("v1", "Node", "node-1"),
+		}},
+	).Build())
+
+	_, _, err := resolver.GetWorkloadInfo(t.Context(), "kube-system", "kube-scheduler-node-1")
+	require.ErrorIs(t, err, ErrUnknownOwner)
+
+	_, _, err = resolver.GetWorkloadInfo(t.Context(), "kube-system", "missing")
+	require.Error(t, err)
+}
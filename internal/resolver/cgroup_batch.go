@@ -0,0 +1,37 @@
+package resolver
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+)
+
+// cgroupBatch collects the cgroups to attach to each policy, so that the cgroup→policy map
+// is updated once per policy instead of once per container, e.g. when many pods of the same
+// policy are synchronized or when a policy is reconciled over all its pods.
+type cgroupBatch map[PolicyID][]CgroupID
+
+func (b cgroupBatch) add(polID PolicyID, cgroupID CgroupID) {
+	b[polID] = append(b[polID], cgroupID)
+}
+
+// flushCgroupBatch attaches the collected cgroups to their policy and empties the batch.
+// The cgroups with an exec bypass stay detached until it expires, the one of the agent is never attached.
+// This must be called with the resolver lock held.
+func (r *Resolver) flushCgroupBatch(b cgroupBatch) error {
+	for _, polID := range slices.Sorted(maps.Keys(b)) {
+		cgroupIDs := slices.DeleteFunc(b[polID], func(cgroupID CgroupID) bool {
+			_, bypassed := r.execBypasses[cgroupID]
+			return bypassed || r.isSelfCgroup(cgroupID)
+		})
+		if len(cgroupIDs) > 0 {
+			if err := r.cgroupToPolicyMapUpdateFunc(polID, cgroupIDs, bpf.AddPolicyToCgroups); err != nil {
+				return fmt.Errorf("failed to add policy %d to cgroups %v: %w", polID, cgroupIDs, err)
+			}
+		}
+		delete(b, polID)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package resolver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const massPodStartPods = 100
+
+func massPodStartInputs(n int) []PodInput {
+	pods := make([]PodInput, 0, n)
+	for i := range n {
+		name := fmt.Sprintf("pod%d", i)
+		pods = append(pods, PodInput{
+			Meta: PodMeta{
+				ID:        name + "-uid",
+				Namespace: "test-ns",
+				Name:      name,
+				Labels:    map[string]string{v1alpha1.PolicyLabelKey: "mass-policy"},
+			},
+			Containers: map[ContainerID]ContainerInput{
+				name + "-c1": {ContainerMeta: ContainerMeta{CgroupID: CgroupID(100 + i), Name: c1, ID: name + "-c1"}},
+			},
+		})
+	}
+	return pods
+}
+
+// newMassPodStartResolver returns a resolver enforcing a single policy and a counter of
+// the writes to the cgroup to policy map.
+func newMassPodStartResolver(tb testing.TB) (*Resolver, fakeCgroupPolicyMap, *int) {
+	tb.Helper()
+	r := NewTestResolver(tb)
+	cgToPolicy := make(fakeCgroupPolicyMap)
+	writes := 0
+	r.cgroupToPolicyMapUpdateFunc = func(polID PolicyID, cgroupIDs []CgroupID, op bpf.CgroupPolicyOperation) error {
+		if op == bpf.AddPolicyToCgroups {
+			writes++
+		}
+		return cgToPolicy.update(polID, cgroupIDs, op)
+	}
+	require.NoError(tb, r.ReconcileWP(&v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "mass-policy", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:             "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+		},
+	}))
+	return r, cgToPolicy, &writes
+}
+
+func TestAddPodsFromNriBatchesMapUpdates(t *testing.T) {
+	r, cgToPolicy, writes := newMassPodStartResolver(t)
+	polID := r.wpState["test-ns/mass-policy"].polByContainer[c1]
+
+	require.NoError(t, r.AddPodsFromNri(massPodStartInputs(massPodStartPods)))
+	require.Equal(t, 1, *writes)
+	require.Len(t, cgToPolicy, massPodStartPods)
+	for cgroupID, id := range cgToPolicy {
+		require.Equal(t, polID, id, "cgroup %d", cgroupID)
+	}
+
+	// a reconcile of the policy attaches all its pods at once too.
+	clear(cgToPolicy)
+	*writes = 0
+	require.NoError(t, r.ReconcileWP(r.wpState["test-ns/mass-policy"].policy))
+	require.Equal(t, 1, *writes)
+	require.Len(t, cgToPolicy, massPodStartPods)
+}
+
+func BenchmarkMassPodStart(b *testing.B) {
+	b.Run("one-by-one", func(b *testing.B) {
+		for b.Loop() {
+			r, _, writes := newMassPodStartResolver(b)
+			for _, pod := range massPodStartInputs(massPodStartPods) {
+				require.NoError(b, r.AddPodContainerFromNri(pod))
+			}
+			b.ReportMetric(float64(*writes), "writes/op")
+		}
+	})
+	b.Run("batched", func(b *testing.B) {
+		for b.Loop() {
+			r, _, writes := newMassPodStartResolver(b)
+			require.NoError(b, r.AddPodsFromNri(massPodStartInputs(massPodStartPods)))
+			b.ReportMetric(float64(*writes), "writes/op")
+		}
+	})
+}
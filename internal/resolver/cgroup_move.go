@@ -0,0 +1,38 @@
+package resolver
+
+// MoveContainerCgroup replaces the cgroup of a container that was moved to a new cgroup while running,
+// e.g. when systemd recreates the scope of the container during a reload.
+// The old cgroup is detached and the policy of the pod is applied again to the new one.
+// Nothing is done when the container is not in the cache anymore or already has this cgroup.
+func (r *Resolver) MoveContainerCgroup(podID PodID, containerID ContainerID, cgroupID CgroupID, path string) error {
+	r.mu.Lock()
+	state, ok := r.podCache[podID]
+	if !ok {
+		r.mu.Unlock()
+		return nil
+	}
+	old, ok := state.containers[containerID]
+	if !ok || old.CgroupID == cgroupID {
+		r.mu.Unlock()
+		return nil
+	}
+	container := *old
+	meta := *state.meta
+	r.mu.Unlock()
+
+	r.logger.Info("container moved to a new cgroup",
+		"pod", meta.Name,
+		"container", container.Name,
+		"id", containerID,
+		"oldCgroupID", container.CgroupID,
+		"cgroupID", cgroupID,
+	)
+	container.CgroupID = cgroupID
+	// the new cgroup is handled like the one of a container restarted in place.
+	return r.AddPodContainerFromNri(PodInput{
+		Meta: meta,
+		Containers: map[ContainerID]ContainerInput{
+			containerID: {ContainerMeta: container, CgroupPath: path},
+		},
+	})
+}
@@ -0,0 +1,53 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMoveContainerCgroup(t *testing.T) {
+	r := NewTestResolver(t)
+	cgToPolicy := make(fakeCgroupPolicyMap)
+	r.cgroupToPolicyMapUpdateFunc = cgToPolicy.update
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:             "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.NoError(t, r.AddPodsFromNri([]PodInput{{
+		Meta: PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		Containers: map[ContainerID]ContainerInput{
+			cid1: {ContainerMeta: ContainerMeta{CgroupID: 100, Name: c1, ID: cid1}},
+		},
+	}}))
+	policyID := cgToPolicy[100]
+	require.NotZero(t, policyID)
+
+	require.NoError(t, r.MoveContainerCgroup("test-pod-uid", cid1, 200, "/sys/fs/cgroup/moved"))
+	require.NotContains(t, cgToPolicy, CgroupID(100))
+	require.Equal(t, policyID, cgToPolicy[200])
+	view, err := r.GetContainerView(200)
+	require.NoError(t, err)
+	require.Equal(t, c1, view.Meta.Name)
+	_, err = r.GetContainerView(100)
+	require.Error(t, err)
+	require.Empty(t, r.SelfCheck())
+
+	// the same cgroup or an unknown container are ignored.
+	require.NoError(t, r.MoveContainerCgroup("test-pod-uid", cid1, 200, "/sys/fs/cgroup/moved"))
+	require.NoError(t, r.MoveContainerCgroup("test-pod-uid", "unknown-id", 300, "/sys/fs/cgroup/unknown"))
+	require.NoError(t, r.MoveContainerCgroup("unknown-uid", cid1, 300, "/sys/fs/cgroup/unknown"))
+	require.Equal(t, map[CgroupID]PolicyID{200: policyID}, map[CgroupID]PolicyID(cgToPolicy))
+}
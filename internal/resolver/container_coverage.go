@@ -0,0 +1,74 @@
+package resolver
+
+import (
+	agentv1 "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+)
+
+// containerCoverage counts the containers a policy applies to on the node.
+type containerCoverage struct {
+	matched  uint32
+	enforced uint32
+}
+
+// containerCoverages counts, for each policy, the containers of the pods bound to it that the policy
+// covers, and the ones actually enforced: their cgroup is resolved, the maps of the policy are loaded
+// and they are not detached by an exec bypass.
+// This must be called with the resolver lock held.
+func (r *Resolver) containerCoverages() map[NamespacedPolicyName]containerCoverage {
+	coverages := make(map[NamespacedPolicyName]containerCoverage)
+	for _, pod := range r.podCache {
+		key, info := r.podPolicy(pod)
+		if info == nil {
+			continue
+		}
+		coverage := coverages[key]
+		for _, container := range pod.containers {
+			if _, listed := info.polByContainer[container.Name]; !listed && !r.unlistedPolicyApplies(info, container) {
+				continue
+			}
+			coverage.matched++
+			if _, bypassed := r.execBypasses[container.CgroupID]; bypassed || container.CgroupID == 0 {
+				continue
+			}
+			if info.status.State == agentv1.PolicyState_POLICY_STATE_READY {
+				coverage.enforced++
+			}
+		}
+		coverages[key] = coverage
+	}
+
+	// The containers created by the runtime without a resolved cgroup are matched but not enforced.
+	for unresolved := range r.unresolved {
+		pod, ok := r.podCache[unresolved.podID]
+		if !ok || pod.hasContainer(unresolved.name) {
+			continue
+		}
+		key, info := r.podPolicy(pod)
+		if info == nil {
+			continue
+		}
+		container := &ContainerMeta{Name: unresolved.name}
+		if _, listed := info.polByContainer[container.Name]; !listed && !r.unlistedPolicyApplies(info, container) {
+			continue
+		}
+		coverage := coverages[key]
+		coverage.matched++
+		coverages[key] = coverage
+	}
+	return coverages
+}
+
+// podPolicy returns the policy enforced on the pod, nil when the pod is not bound to a reconciled policy
+// or its index is not selected.
+// This must be called with the resolver lock held.
+func (r *Resolver) podPolicy(pod *podEntry) (NamespacedPolicyName, *wpInfo) {
+	if pod.policyName() == "" {
+		return "", nil
+	}
+	key := pod.podNamespace() + "/" + pod.policyName()
+	info := r.wpState[key]
+	if info == nil || info.policy == nil || !pod.matchPodIndexes(info.policy.Spec.PodIndexes) {
+		return "", nil
+	}
+	return key, info
+}
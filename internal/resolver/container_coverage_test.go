@@ -0,0 +1,69 @@
+package resolver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPolicyStatusContainerCoverage(t *testing.T) {
+	r := NewTestResolver(t)
+	r.cgroupToPolicyMapUpdateFunc = make(fakeCgroupPolicyMap).update
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:             "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	labels := map[string]string{v1alpha1.PolicyLabelKey: "policy"}
+	require.NoError(t, r.AddPodsFromNri([]PodInput{
+		{
+			Meta: PodMeta{ID: "running-uid", Namespace: "test-ns", Name: "running", Labels: labels},
+			Containers: map[ContainerID]ContainerInput{
+				"c1-id": {ContainerMeta: ContainerMeta{CgroupID: 100, Name: c1, ID: "c1-id"}},
+				"c2-id": {ContainerMeta: ContainerMeta{CgroupID: 101, Name: c2, ID: "c2-id"}},
+			},
+		},
+		{
+			Meta: PodMeta{ID: "starting-uid", Namespace: "test-ns", Name: "starting", Labels: labels},
+		},
+		{
+			Meta: PodMeta{ID: "unlabeled-uid", Namespace: "test-ns", Name: "unlabeled"},
+			Containers: map[ContainerID]ContainerInput{
+				"c3-id": {ContainerMeta: ContainerMeta{CgroupID: 200, Name: c1, ID: "c3-id"}},
+			},
+		},
+	}))
+	// the container of the starting pod never got a cgroup.
+	r.MarkContainerCreated("starting-uid", "test-ns", "starting", c1, cid1)
+
+	coverageOf := func() (uint32, uint32) {
+		status := r.GetPolicyStatuses()[wp.NamespacedName()]
+		return status.EnforcedContainers, status.MatchedContainers
+	}
+
+	// only the containers with rules are matched, the unresolved one is not enforced.
+	enforced, matched := coverageOf()
+	require.Equal(t, uint32(1), enforced)
+	require.Equal(t, uint32(2), matched)
+
+	// with the unlisted containers denied, every container of the bound pods is matched.
+	wp.Spec.UnlistedContainerPolicy = v1alpha1.UnlistedContainerDeny
+	require.NoError(t, r.ReconcileWP(wp))
+	enforced, matched = coverageOf()
+	require.Equal(t, uint32(2), enforced)
+	require.Equal(t, uint32(3), matched)
+
+	// the bypassed containers are not enforced.
+	_, err := r.GrantExecBypass("test-ns", "running", c2, time.Minute)
+	require.NoError(t, err)
+	enforced, matched = coverageOf()
+	require.Equal(t, uint32(1), enforced)
+	require.Equal(t, uint32(3), matched)
+}
@@ -0,0 +1,89 @@
+package resolver
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultDeletedContainerRetention is how long a removed container is still resolved by default.
+	DefaultDeletedContainerRetention = time.Minute
+	// DefaultDeletedContainerCompactionInterval is how often the expired removed containers are dropped by default.
+	DefaultDeletedContainerCompactionInterval = 30 * time.Second
+)
+
+// deletedContainer is the view of a removed container, kept to resolve the events read after its removal.
+type deletedContainer struct {
+	view      ContainerView
+	expiresAt time.Time
+}
+
+// SetDeletedContainerRetention keeps resolving a removed container for the retention, e.g. for the violations
+// of a short-lived container read after NRI removed it. The expired containers are dropped every interval
+// by RunDeletedContainerCompaction. Zero or a negative retention disables the cache.
+// It must be called before any pod is added.
+func (r *Resolver) SetDeletedContainerRetention(retention, compactionInterval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deletedRetention = retention
+	r.deletedCompactionInterval = compactionInterval
+}
+
+// rememberDeletedContainer keeps the view of the container about to be removed.
+// This must be called with the resolver lock held.
+func (r *Resolver) rememberDeletedContainer(cgID CgroupID) {
+	if r.deletedRetention <= 0 {
+		return
+	}
+	view, err := r.containerView(cgID)
+	if err != nil {
+		return
+	}
+	r.deletedContainers[cgID] = deletedContainer{view: *view, expiresAt: r.now().Add(r.deletedRetention)}
+}
+
+// deletedContainerView returns the view of a removed container, until its retention expires.
+// This must be called with the resolver lock held.
+func (r *Resolver) deletedContainerView(cgID CgroupID) (*ContainerView, bool) {
+	deleted, ok := r.deletedContainers[cgID]
+	if !ok || !r.now().Before(deleted.expiresAt) {
+		return nil, false
+	}
+	view := deleted.view
+	return &view, true
+}
+
+// compactDeletedContainers drops the removed containers whose retention expired.
+// This must be called with the resolver lock held.
+func (r *Resolver) compactDeletedContainers() {
+	now := r.now()
+	for cgID, deleted := range r.deletedContainers {
+		if !now.Before(deleted.expiresAt) {
+			delete(r.deletedContainers, cgID)
+		}
+	}
+}
+
+// RunDeletedContainerCompaction drops the expired removed containers on schedule, so that the cache doesn't grow
+// with the pod churn. It returns right away when the cache is disabled.
+func (r *Resolver) RunDeletedContainerCompaction(ctx context.Context) error {
+	r.mu.Lock()
+	retention, interval := r.deletedRetention, r.deletedCompactionInterval
+	r.mu.Unlock()
+	if retention <= 0 || interval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.mu.Lock()
+			r.compactDeletedContainers()
+			r.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package resolver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeletedContainerRetention(t *testing.T) {
+	r := NewTestResolver(t)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	r.SetDeletedContainerRetention(time.Minute, 30*time.Second)
+
+	for uid, cgID := range map[PodID]CgroupID{"pod-a": 100, "pod-b": 200} {
+		require.NoError(t, r.AddPodContainerFromNri(PodInput{
+			Meta: PodMeta{ID: uid, Namespace: "test-ns", Name: string(uid)},
+			Containers: map[ContainerID]ContainerInput{
+				ContainerID(uid): {ContainerMeta: ContainerMeta{CgroupID: cgID, Name: c1, ID: ContainerID(uid)}},
+			},
+		}))
+	}
+
+	require.NoError(t, r.RemovePodContainerFromNri("pod-a", "pod-a"))
+	now = now.Add(45 * time.Second)
+	require.NoError(t, r.RemovePodContainerFromNri("pod-b", "pod-b"))
+
+	// the removed containers are still resolved until their retention expires.
+	view, err := r.GetContainerView(100)
+	require.NoError(t, err)
+	require.Equal(t, "pod-a", view.PodMeta.Name)
+	require.False(t, r.HasPod("pod-a"))
+
+	now = now.Add(30 * time.Second)
+	_, err = r.GetContainerView(100)
+	require.Error(t, err)
+	_, err = r.GetContainerView(200)
+	require.NoError(t, err)
+
+	// the compaction drops only the expired containers.
+	r.mu.Lock()
+	r.compactDeletedContainers()
+	require.Len(t, r.deletedContainers, 1)
+	require.Contains(t, r.deletedContainers, CgroupID(200))
+	r.mu.Unlock()
+
+	now = now.Add(time.Minute)
+	_, err = r.GetContainerView(200)
+	require.Error(t, err)
+}
+
+func TestRunDeletedContainerCompaction(t *testing.T) {
+	r := NewTestResolver(t)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	r.SetDeletedContainerRetention(time.Minute, time.Millisecond)
+	r.deletedContainers[100] = deletedContainer{expiresAt: now}
+	r.deletedContainers[200] = deletedContainer{expiresAt: now.Add(time.Minute)}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() { done <- r.RunDeletedContainerCompaction(ctx) }()
+	require.Eventually(t, func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return len(r.deletedContainers) == 1
+	}, time.Second, time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	// a disabled cache has no compaction.
+	r.SetDeletedContainerRetention(0, time.Millisecond)
+	require.NoError(t, r.RunDeletedContainerCompaction(t.Context()))
+}
@@ -0,0 +1,23 @@
+package resolver
+
+// EnforceEphemeralContainers applies the unlisted containers policy to the ephemeral containers too.
+// Without it, the ephemeral containers attached with `kubectl debug` are only enforced when the policy
+// has rules for them, so that denying the unlisted containers doesn't block debugging sessions.
+// It must be called before any policy is reconciled.
+func (r *Resolver) EnforceEphemeralContainers() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enforceEphemeral = true
+}
+
+// unlistedPolicyApplies reports whether the unlisted containers policy of the pod applies to the container.
+// This must be called with the resolver lock held.
+func (r *Resolver) unlistedPolicyApplies(info *wpInfo, container *ContainerMeta) bool {
+	if info.unlistedPolicyID == PolicyIDNone {
+		return false
+	}
+	if _, listed := info.polByContainer[container.Name]; listed {
+		return false
+	}
+	return !container.Ephemeral || r.enforceEphemeral
+}
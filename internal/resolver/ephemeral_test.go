@@ -0,0 +1,66 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUnlistedPolicyEphemeralContainers(t *testing.T) {
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:                    "protect",
+			UnlistedContainerPolicy: v1alpha1.UnlistedContainerDeny,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1:      rules("/bin/sleep"),
+				"trace": rules("/usr/bin/strace"),
+			},
+		},
+	}
+	pod := PodInput{
+		Meta: PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		Containers: map[ContainerID]ContainerInput{
+			cid1:       {ContainerMeta: ContainerMeta{CgroupID: 100, Name: c1, ID: cid1}},
+			cid2:       {ContainerMeta: ContainerMeta{CgroupID: 101, Name: c2, ID: cid2}},
+			"debug-id": {ContainerMeta: ContainerMeta{CgroupID: 102, Name: "debug", ID: "debug-id", Ephemeral: true}},
+			"trace-id": {ContainerMeta: ContainerMeta{CgroupID: 103, Name: "trace", ID: "trace-id", Ephemeral: true}},
+		},
+	}
+
+	t.Run("unlisted ephemeral containers are not enforced by default", func(t *testing.T) {
+		r := NewTestResolver(t)
+		cgToPolicy := make(fakeCgroupPolicyMap)
+		r.cgroupToPolicyMapUpdateFunc = cgToPolicy.update
+		require.NoError(t, r.ReconcileWP(wp))
+		require.NoError(t, r.AddPodsFromNri([]PodInput{pod}))
+
+		info := r.wpState[wp.NamespacedName()]
+		require.Equal(t, info.unlistedPolicyID, cgToPolicy[101])
+		require.NotContains(t, cgToPolicy, CgroupID(102))
+		// an ephemeral container listed in the policy is enforced with its rules.
+		require.Equal(t, info.polByContainer["trace"], cgToPolicy[103])
+		require.Equal(t, []ContainerName{c1, c2, "trace"}, r.PodEnforcements()[0].Containers)
+		require.Empty(t, r.SelfCheck())
+	})
+
+	t.Run("unlisted ephemeral containers are denied when enforced", func(t *testing.T) {
+		r := NewTestResolver(t)
+		cgToPolicy := make(fakeCgroupPolicyMap)
+		r.cgroupToPolicyMapUpdateFunc = cgToPolicy.update
+		r.EnforceEphemeralContainers()
+		require.NoError(t, r.ReconcileWP(wp))
+		require.NoError(t, r.AddPodsFromNri([]PodInput{pod}))
+
+		info := r.wpState[wp.NamespacedName()]
+		require.Equal(t, info.unlistedPolicyID, cgToPolicy[102])
+		require.Equal(t, []ContainerName{c1, c2, "debug", "trace"}, r.PodEnforcements()[0].Containers)
+	})
+}
@@ -0,0 +1,109 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+)
+
+const (
+	// MaxExecBypassTTL bounds how long a container can run without enforcement.
+	MaxExecBypassTTL      = time.Hour
+	execBypassCheckPeriod = 5 * time.Second
+)
+
+// GrantExecBypass detaches the container from its policy until the TTL expires, so that any
+// execution is allowed while an operator troubleshoots it, e.g. through kubectl exec.
+// Granting it again for the same container replaces the expiration. It returns when the bypass expires.
+func (r *Resolver) GrantExecBypass(namespace, podName, containerName string, ttl time.Duration) (time.Time, error) {
+	if ttl <= 0 || ttl > MaxExecBypassTTL {
+		return time.Time{}, fmt.Errorf("exec bypass TTL must be between 0 and %s, got %s", MaxExecBypassTTL, ttl)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	container := r.findContainer(namespace, podName, containerName)
+	if container == nil {
+		return time.Time{}, fmt.Errorf("container %s not found in pod %s/%s", containerName, namespace, podName)
+	}
+	if _, granted := r.execBypasses[container.CgroupID]; !granted {
+		if err := r.cgroupToPolicyMapUpdateFunc(
+			PolicyIDNone, []CgroupID{container.CgroupID}, bpf.RemoveCgroups,
+		); err != nil {
+			return time.Time{}, fmt.Errorf("failed to detach container %s of pod %s/%s from its policy: %w",
+				containerName, namespace, podName, err)
+		}
+	}
+	expiresAt := r.now().Add(ttl)
+	r.execBypasses[container.CgroupID] = expiresAt
+	r.logger.Warn("exec bypass granted, the container is not enforced",
+		"pod", podName,
+		"namespace", namespace,
+		"container", containerName,
+		"expiresAt", expiresAt,
+	)
+	return expiresAt, nil
+}
+
+// findContainer returns the container of the pod with the given name, nil if it is unknown.
+// This must be called with the resolver lock held.
+func (r *Resolver) findContainer(namespace, podName, containerName string) *ContainerMeta {
+	for _, pod := range r.podCache {
+		if pod.podNamespace() != namespace || pod.podName() != podName {
+			continue
+		}
+		for _, container := range pod.containers {
+			if container.Name == containerName {
+				return container
+			}
+		}
+	}
+	return nil
+}
+
+// revokeExpiredExecBypasses attaches back the containers whose exec bypass expired to their policy.
+// This must be called with the resolver lock held.
+func (r *Resolver) revokeExpiredExecBypasses() {
+	now := r.now()
+	batch := make(cgroupBatch)
+	for cgroupID, expiresAt := range r.execBypasses {
+		if now.Before(expiresAt) {
+			continue
+		}
+		delete(r.execBypasses, cgroupID)
+		state, ok := r.podCache[r.cgroupIDToPodID[cgroupID]]
+		if !ok {
+			continue
+		}
+		r.logger.Info("exec bypass expired", "pod", state.podName(), "namespace", state.podNamespace())
+		if err := r.applyPolicyToPodIfPresent(state, batch); err != nil {
+			r.logger.Error("failed to enforce the policy again after the exec bypass",
+				"pod", state.podName(),
+				"namespace", state.podNamespace(),
+				"error", err,
+			)
+		}
+	}
+	if err := r.flushCgroupBatch(batch); err != nil {
+		r.logger.Error("failed to enforce the policy again after the exec bypass", "error", err)
+	}
+}
+
+// RunExecBypassExpiry periodically revokes the expired exec bypasses until ctx is done.
+func (r *Resolver) RunExecBypassExpiry(ctx context.Context) error {
+	ticker := time.NewTicker(execBypassCheckPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.mu.Lock()
+			r.revokeExpiredExecBypasses()
+			r.mu.Unlock()
+		}
+	}
+}
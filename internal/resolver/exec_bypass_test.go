@@ -0,0 +1,60 @@
+package resolver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExecBypass(t *testing.T) {
+	r := NewTestResolver(t)
+	cgToPolicy := make(fakeCgroupPolicyMap)
+	r.cgroupToPolicyMapUpdateFunc = cgToPolicy.update
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:             "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	polID := r.wpState[wp.NamespacedName()].polByContainer[c1]
+	require.NoError(t, r.AddPodContainerFromNri(PodInput{
+		Meta: PodMeta{
+			ID:        "pod-uid",
+			Namespace: "test-ns",
+			Name:      "pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "policy"},
+		},
+		Containers: map[ContainerID]ContainerInput{
+			"c1-id": {ContainerMeta: ContainerMeta{CgroupID: 100, Name: c1, ID: "c1-id"}},
+		},
+	}))
+	require.Equal(t, fakeCgroupPolicyMap{100: polID}, cgToPolicy)
+
+	_, err := r.GrantExecBypass("test-ns", "pod", c1, 2*MaxExecBypassTTL)
+	require.Error(t, err)
+	_, err = r.GrantExecBypass("test-ns", "pod", "unknown", time.Minute)
+	require.Error(t, err)
+
+	expiresAt, err := r.GrantExecBypass("test-ns", "pod", c1, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(time.Minute), expiresAt)
+	require.Empty(t, cgToPolicy)
+
+	// a reconcile of the policy doesn't enforce the container before the bypass expires.
+	require.NoError(t, r.ReconcileWP(wp))
+	r.revokeExpiredExecBypasses()
+	require.Empty(t, cgToPolicy)
+
+	now = now.Add(time.Minute)
+	r.revokeExpiredExecBypasses()
+	require.Equal(t, fakeCgroupPolicyMap{100: polID}, cgToPolicy)
+	require.Empty(t, r.execBypasses)
+}
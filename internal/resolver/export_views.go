@@ -2,12 +2,27 @@ package resolver
 
 import (
 	"fmt"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
 )
 
 func (r *Resolver) GetContainerView(cgID CgroupID) (*ContainerView, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	view, err := r.containerView(cgID)
+	if err != nil {
+		// the violations of a container can be read after it is removed.
+		if deleted, ok := r.deletedContainerView(cgID); ok {
+			return deleted, nil
+		}
+	}
+	return view, err
+}
+
+// containerView returns the view of the container in the cache with this cgroup.
+// This must be called with the resolver lock held.
+func (r *Resolver) containerView(cgID CgroupID) (*ContainerView, error) {
 	podID, ok := r.cgroupIDToPodID[cgID]
 	if !ok {
 		return nil, fmt.Errorf("no pod UID associated with cgroup ID: %d", cgID)
@@ -21,13 +36,25 @@ func (r *Resolver) GetContainerView(cgID CgroupID) (*ContainerView, error) {
 	for containerID, meta := range pod.containers {
 		// we should find a container matching the cgroup ID, otherwise we have an error.
 		if cgID == meta.CgroupID {
+			var policySeverity string
+			var autoProtect bool
+			if info := r.wpState[pod.podNamespace()+"/"+pod.policyName()]; info != nil && info.policy != nil {
+				policySeverity = info.policy.Spec.Severity
+				autoProtect = info.policy.Spec.AutoProtect != nil &&
+					info.policy.Spec.Mode == policymode.MonitorString
+			}
 			return &ContainerView{
 				PodMeta: *pod.meta,
 				Meta: ContainerMeta{
-					ID:       containerID,
-					Name:     meta.Name,
-					CgroupID: cgID,
+					ID:        containerID,
+					Name:      meta.Name,
+					CgroupID:  cgID,
+					RootPath:  meta.RootPath,
+					Ephemeral: meta.Ephemeral,
 				},
+				PolicyName:        pod.policyName(),
+				PolicySeverity:    policySeverity,
+				PolicyAutoProtect: autoProtect,
 			}, nil
 		}
 	}
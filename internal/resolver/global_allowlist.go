@@ -0,0 +1,47 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// SetGlobalAllowList sets the executables allowed in every container enforced by a policy,
+// e.g. the init process of the containers, whatever the rules of the policy.
+// The policies already known are enforced again with the new list.
+func (r *Resolver) SetGlobalAllowList(executables []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if slices.Equal(r.globalAllowList, executables) {
+		return nil
+	}
+	r.logger.Info("update global allow list", "executables", executables)
+	r.globalAllowList = slices.Clone(executables)
+
+	var errs []error
+	for wpKey, info := range r.wpState {
+		if info.policy == nil {
+			continue
+		}
+		if err := r.reconcileWP(info.policy); err != nil {
+			errs = append(errs, fmt.Errorf("failed to apply global allow list to wp %s: %w", wpKey, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// withGlobalAllowList returns the allowed executables of a policy completed with the global allow list.
+// This must be called with the resolver lock held.
+func (r *Resolver) withGlobalAllowList(allowed []string) []string {
+	if len(r.globalAllowList) == 0 {
+		return allowed
+	}
+	effective := slices.Clone(allowed)
+	for _, executable := range r.globalAllowList {
+		if !slices.Contains(effective, executable) {
+			effective = append(effective, executable)
+		}
+	}
+	return effective
+}
@@ -0,0 +1,59 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGlobalAllowList(t *testing.T) {
+	r := NewTestResolver(t)
+	allowedByPolicyID := make(map[PolicyID][]string)
+	r.policyUpdateBinariesFunc = func(policyID PolicyID, values []string, op bpf.PolicyValuesOperation) error {
+		if op == bpf.RemoveValuesFromPolicy {
+			delete(allowedByPolicyID, policyID)
+			return nil
+		}
+		allowedByPolicyID[policyID] = values
+		return nil
+	}
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:                    "protect",
+			UnlistedContainerPolicy: v1alpha1.UnlistedContainerDeny,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: rules("/bin/sleep", "/pause"),
+			},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	info := r.wpState[wp.NamespacedName()]
+	polID := info.polByContainer[c1]
+	require.Equal(t, []string{"/bin/sleep", "/pause"}, allowedByPolicyID[polID])
+	require.Empty(t, allowedByPolicyID[info.unlistedPolicyID])
+
+	// the policies already enforced get the global entries, without duplicates.
+	require.NoError(t, r.SetGlobalAllowList([]string{"/pause", "/sbin/tini"}))
+	require.Equal(t, []string{"/bin/sleep", "/pause", "/sbin/tini"}, allowedByPolicyID[polID])
+	require.Equal(t, []string{"/pause", "/sbin/tini"}, allowedByPolicyID[info.unlistedPolicyID])
+
+	// so do the policies reconciled later.
+	other := wp.DeepCopy()
+	other.Name = "other"
+	other.Spec.RulesByContainer = map[string]*v1alpha1.WorkloadPolicyRules{c2: rules("/bin/ls")}
+	require.NoError(t, r.ReconcileWP(other))
+	otherPolID := r.wpState[other.NamespacedName()].polByContainer[c2]
+	require.Equal(t, []string{"/bin/ls", "/pause", "/sbin/tini"}, allowedByPolicyID[otherPolID])
+
+	// the rules of the policies are not changed.
+	require.Equal(t, []string{"/bin/sleep", "/pause"}, wp.Spec.RulesByContainer[c1].Executables.Allowed)
+
+	require.NoError(t, r.SetGlobalAllowList(nil))
+	require.Equal(t, []string{"/bin/sleep", "/pause"}, allowedByPolicyID[polID])
+	require.Empty(t, allowedByPolicyID[info.unlistedPolicyID])
+}
@@ -0,0 +1,368 @@
+package resolver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+)
+
+// EnableHashMatching allows the executables of the allowedHashes rules in the containers where their content
+// matches the expected digest: the matching files are pinned with execPinsUpdateFunc, so that they are only allowed
+// while they are unchanged. Without it, the executables of the allowedHashes rules are never allowed.
+// It must be called before any policy is reconciled.
+func (r *Resolver) EnableHashMatching(execPinsUpdateFunc func(cgroupID CgroupID, pins []bpf.ExecPin) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hashMatching = true
+	r.execPinsUpdateFunc = execPinsUpdateFunc
+}
+
+// maxSymlinks is the maximum number of symlinks followed to resolve a path, as MAXSYMLINKS in the kernel.
+const maxSymlinks = 40
+
+// minPinAge is how long a file must be unchanged to be pinned: the change time of the inodes is taken from a
+// coarse clock, so a write right after the verification may keep it.
+const minPinAge = time.Second
+
+// fileDigest returns the hex encoded SHA-256 digest of the file at path inside the given root filesystem, and the
+// pin identifying the hashed file.
+// The path is resolved inside the root, so that a symlink in the container cannot point to a file of the host.
+func fileDigest(rootPath, path string) (string, bpf.ExecPin, error) {
+	root, err := os.OpenRoot(rootPath)
+	if err != nil {
+		return "", bpf.ExecPin{}, err
+	}
+	defer root.Close()
+
+	resolved, err := resolveInRoot(root, path)
+	if err != nil {
+		return "", bpf.ExecPin{}, err
+	}
+	f, err := root.Open(rootRelative(resolved))
+	if err != nil {
+		return "", bpf.ExecPin{}, err
+	}
+	defer f.Close()
+
+	pin, err := execPin(f)
+	if err != nil {
+		return "", bpf.ExecPin{}, err
+	}
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", bpf.ExecPin{}, err
+	}
+	// the digest only belongs to the pinned file if it didn't change while it was read.
+	after, err := execPin(f)
+	if err != nil {
+		return "", bpf.ExecPin{}, err
+	}
+	if after != pin {
+		return "", bpf.ExecPin{}, fmt.Errorf("%s changed while it was hashed", path)
+	}
+	return hex.EncodeToString(h.Sum(nil)), pin, nil
+}
+
+// execPin returns the inode and change time of the open file.
+func execPin(f *os.File) (bpf.ExecPin, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return bpf.ExecPin{}, err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return bpf.ExecPin{}, fmt.Errorf("cannot get the inode of %s", f.Name())
+	}
+	return bpf.ExecPin{
+		Inode:     st.Ino,
+		CtimeSec:  st.Ctim.Sec,
+		CtimeNsec: uint32(st.Ctim.Nsec), //nolint:gosec // the nanoseconds are less than a second
+	}, nil
+}
+
+// resolveInRoot resolves the symlinks of path as the container sees them: their absolute targets are relative
+// to the root filesystem of the container, while os.Root rejects them as escaping the root.
+func resolveInRoot(root *os.Root, p string) (string, error) {
+	resolved := "/"
+	rest := strings.Split(p, "/")
+	links := 0
+	for len(rest) > 0 {
+		name := rest[0]
+		rest = rest[1:]
+		switch name {
+		case "", ".":
+			continue
+		case "..":
+			resolved = path.Dir(resolved)
+			continue
+		}
+		next := path.Join(resolved, name)
+		info, err := root.Lstat(rootRelative(next))
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+		if links++; links > maxSymlinks {
+			return "", fmt.Errorf("too many levels of symbolic links resolving %s", p)
+		}
+		target, err := root.Readlink(rootRelative(next))
+		if err != nil {
+			return "", err
+		}
+		if path.IsAbs(target) {
+			resolved = "/"
+		}
+		rest = append(strings.Split(target, "/"), rest...)
+	}
+	return resolved, nil
+}
+
+// rootRelative returns the absolute path of the container as a path relative to its root filesystem.
+func rootRelative(p string) string {
+	if p = strings.TrimPrefix(p, "/"); p == "" {
+		return "."
+	}
+	return p
+}
+
+// hashRulesKey identifies the allowedHashes rules a container is verified against, so that the containers
+// are verified again when the rules change. It is empty without rules.
+func hashRulesKey(hashRules []v1alpha1.ExecutableHash) string {
+	var b strings.Builder
+	for _, exe := range hashRules {
+		b.WriteString(exe.Path)
+		b.WriteByte('=')
+		b.WriteString(exe.SHA256)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// hashRulesByContainer collects the executables allowed only with a specific content for each container.
+func hashRulesByContainer(wp *v1alpha1.WorkloadPolicy) map[ContainerName][]v1alpha1.ExecutableHash {
+	var hashRules map[ContainerName][]v1alpha1.ExecutableHash
+	for containerName, rules := range wp.Spec.RulesByContainer {
+		if rules == nil || len(rules.Executables.AllowedHashes) == 0 {
+			continue
+		}
+		if hashRules == nil {
+			hashRules = make(map[ContainerName][]v1alpha1.ExecutableHash)
+		}
+		hashRules[containerName] = rules.Executables.AllowedHashes
+	}
+	return hashRules
+}
+
+// containerHashRules returns the allowedHashes rules of the policy that will be enforced on the container.
+func (r *Resolver) containerHashRules(meta *PodMeta, containerName ContainerName) []v1alpha1.ExecutableHash {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	policyName := meta.Labels[v1alpha1.PolicyLabelKey]
+	if policyName == "" {
+		policyName = r.nsDefaultPolicies[meta.Namespace]
+	}
+	info := r.wpState[meta.Namespace+"/"+policyName]
+	if !r.hashMatching || info == nil || info.fallbackPolicyID != PolicyIDNone {
+		return nil
+	}
+	return info.hashRules[containerName]
+}
+
+// verifiedExecs are the executables of a container matching the allowedHashes rules identified by key.
+type verifiedExecs struct {
+	key  string
+	pins []bpf.ExecPin
+}
+
+// verifiedContainers hashes the files of the allowedHashes rules in each new container.
+// Hashing reads the files of the container, so it must be called without the resolver lock held.
+func (r *Resolver) verifiedContainers(
+	pod PodInput,
+	containers map[ContainerID]ContainerInput,
+) map[ContainerID]verifiedExecs {
+	verified := make(map[ContainerID]verifiedExecs)
+	for containerID, container := range containers {
+		hashRules := r.containerHashRules(&pod.Meta, container.Name)
+		if len(hashRules) == 0 {
+			continue
+		}
+		verified[containerID] = verifiedExecs{
+			key:  hashRulesKey(hashRules),
+			pins: r.verifyHashes(&pod.Meta, &container.ContainerMeta, hashRules),
+		}
+	}
+	return verified
+}
+
+// verifyHashes returns the pins of the files of the container matching their allowedHashes rule.
+// A mismatching file is left out, the other ones are still allowed.
+func (r *Resolver) verifyHashes(
+	pod *PodMeta,
+	container *ContainerMeta,
+	hashRules []v1alpha1.ExecutableHash,
+) []bpf.ExecPin {
+	logger := r.logger.With("pod", pod.Name, "namespace", pod.Namespace, "container", container.Name)
+	if container.RootPath == "" {
+		logger.Warn("cannot verify the allowed hashes, the container root filesystem is unknown")
+		return nil
+	}
+	var pins []bpf.ExecPin
+	for _, exe := range hashRules {
+		digest, pin, err := r.fileDigestFunc(container.RootPath, exe.Path)
+		if err != nil {
+			logger.Warn("cannot verify the allowed hash", "exe", exe.Path, "error", err)
+			continue
+		}
+		if digest != exe.SHA256 {
+			logger.Warn("executable doesn't match the allowed hash", "exe", exe.Path, "sha256", digest)
+			continue
+		}
+		if r.now().Sub(time.Unix(pin.CtimeSec, int64(pin.CtimeNsec))) < minPinAge {
+			logger.Warn("executable changed too recently to be pinned", "exe", exe.Path)
+			continue
+		}
+		pins = append(pins, pin)
+	}
+	return pins
+}
+
+// pinExecutables pins the verified executables of the container in BPF.
+// This must be called with the resolver lock held.
+func (r *Resolver) pinExecutables(state *podEntry, container *ContainerMeta, verified verifiedExecs) error {
+	if err := r.execPinsUpdateFunc(container.CgroupID, verified.pins); err != nil {
+		return fmt.Errorf("failed to pin the executables of pod %s, container %s: %w",
+			state.podName(), container.Name, err)
+	}
+	if state.verified == nil {
+		state.verified = make(map[ContainerID]string)
+	}
+	state.verified[container.ID] = verified.key
+	return nil
+}
+
+// unpinExecutables removes the pinned executables of the container, if any.
+// This must be called with the resolver lock held.
+func (r *Resolver) unpinExecutables(state *podEntry, container *ContainerMeta) error {
+	if _, ok := state.verified[container.ID]; !ok {
+		return nil
+	}
+	delete(state.verified, container.ID)
+	if err := r.execPinsUpdateFunc(container.CgroupID, nil); err != nil {
+		return fmt.Errorf("failed to unpin the executables of pod %s, container %s: %w",
+			state.podName(), container.Name, err)
+	}
+	return nil
+}
+
+// podHashRules returns the allowedHashes rules of the container in the policy enforced on the pod.
+// This must be called with the resolver lock held.
+func (r *Resolver) podHashRules(state *podEntry, containerName ContainerName) []v1alpha1.ExecutableHash {
+	if !r.hashMatching {
+		return nil
+	}
+	_, info := r.podPolicy(state)
+	if info == nil || info.fallbackPolicyID != PolicyIDNone {
+		return nil
+	}
+	return info.hashRules[containerName]
+}
+
+// hashVerification is a container whose files are hashed against the allowedHashes rules of its policy.
+type hashVerification struct {
+	podID     PodID
+	pod       PodMeta
+	container ContainerMeta
+	hashRules []v1alpha1.ExecutableHash
+	pins      []bpf.ExecPin
+}
+
+// verifyPendingContainers pins the executables of the containers not verified against the current allowedHashes
+// rules of their policy, e.g. the ones started before the rules. The containers verified against other rules are
+// unpinned first, so that their executables are not allowed until they match the new rules.
+// Hashing reads the files of the containers, so it must be called without the resolver lock held.
+func (r *Resolver) verifyPendingContainers() error {
+	verifications, err := r.pendingHashVerifications()
+	if err != nil || len(verifications) == 0 {
+		return err
+	}
+	for i := range verifications {
+		v := &verifications[i]
+		v.pins = r.verifyHashes(&v.pod, &v.container, v.hashRules)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.applyHashVerifications(verifications)
+}
+
+// pendingHashVerifications unpins the containers whose allowedHashes rules changed and returns the containers
+// to verify.
+func (r *Resolver) pendingHashVerifications() ([]hashVerification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var verifications []hashVerification
+	for podID, state := range r.podCache {
+		for containerID, container := range state.containers {
+			hashRules := r.podHashRules(state, container.Name)
+			key, verified := state.verified[containerID]
+			if verified && key == hashRulesKey(hashRules) {
+				continue
+			}
+			if err := r.unpinExecutables(state, container); err != nil {
+				return nil, err
+			}
+			if len(hashRules) == 0 {
+				continue
+			}
+			verifications = append(verifications, hashVerification{
+				podID:     podID,
+				pod:       *state.meta,
+				container: *container,
+				hashRules: hashRules,
+			})
+		}
+	}
+	return verifications, nil
+}
+
+// applyHashVerifications pins the executables verified in each container.
+// The verifications of the containers gone or verified in the meantime are dropped, as well as the ones made
+// against rules changed in the meantime: the reconciliation of the policy verifies the containers again.
+// This must be called with the resolver lock held.
+func (r *Resolver) applyHashVerifications(verifications []hashVerification) error {
+	for _, v := range verifications {
+		state := r.podCache[v.podID]
+		if state == nil {
+			continue
+		}
+		container := state.containers[v.container.ID]
+		if container == nil || container.CgroupID != v.container.CgroupID {
+			continue
+		}
+		key := hashRulesKey(v.hashRules)
+		if key != hashRulesKey(r.podHashRules(state, container.Name)) {
+			continue
+		}
+		if prev, ok := state.verified[container.ID]; ok && prev == key {
+			continue
+		}
+		if err := r.pinExecutables(state, container, verifiedExecs{key: key, pins: v.pins}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
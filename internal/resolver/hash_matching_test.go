@@ -0,0 +1,269 @@
+package resolver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	appDigest      = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	replacedDigest = "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"
+)
+
+func hashMatchingPod(id PodID, cgroupID CgroupID, rootPath string) PodInput {
+	return PodInput{
+		Meta: PodMeta{
+			ID:        id,
+			Namespace: "test-ns",
+			Name:      id,
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		Containers: map[ContainerID]ContainerInput{
+			ContainerID(id + "-c1"): {
+				ContainerMeta: ContainerMeta{CgroupID: cgroupID, Name: c1, ID: ContainerID(id + "-c1"), RootPath: rootPath},
+			},
+		},
+	}
+}
+
+// fakeExecPins records the executables pinned in each cgroup, as the pinned exec map.
+type fakeExecPins map[CgroupID][]bpf.ExecPin
+
+func (f fakeExecPins) update(cgroupID CgroupID, pins []bpf.ExecPin) error {
+	if len(pins) == 0 {
+		delete(f, cgroupID)
+		return nil
+	}
+	f[cgroupID] = pins
+	return nil
+}
+
+// fakeFile is a file of a container root filesystem in the tests.
+type fakeFile struct {
+	digest string
+	pin    bpf.ExecPin
+}
+
+func fakeFileDigest(files map[string]fakeFile) func(rootPath, path string) (string, bpf.ExecPin, error) {
+	return func(rootPath, path string) (string, bpf.ExecPin, error) {
+		f, ok := files[rootPath+path]
+		if !ok {
+			return "", bpf.ExecPin{}, errors.New("no such file")
+		}
+		return f.digest, f.pin, nil
+	}
+}
+
+func TestReconcileWP_HashMatching(t *testing.T) {
+	r := NewTestResolver(t)
+	cgToPolicy := make(fakeCgroupPolicyMap)
+	r.cgroupToPolicyMapUpdateFunc = cgToPolicy.update
+	allowedByPolicyID := make(map[PolicyID][]string)
+	r.policyUpdateBinariesFunc = func(policyID PolicyID, values []string, op bpf.PolicyValuesOperation) error {
+		if op == bpf.RemoveValuesFromPolicy {
+			delete(allowedByPolicyID, policyID)
+			return nil
+		}
+		allowedByPolicyID[policyID] = values
+		return nil
+	}
+	appPin := bpf.ExecPin{Inode: 10, CtimeSec: 1000}
+	toolPin := bpf.ExecPin{Inode: 11, CtimeSec: 1000}
+	r.fileDigestFunc = fakeFileDigest(map[string]fakeFile{
+		"/proc/1/root/bin/app":  {digest: appDigest, pin: appPin},
+		"/proc/1/root/bin/tool": {digest: replacedDigest, pin: toolPin},
+		"/proc/2/root/bin/app":  {digest: replacedDigest, pin: appPin},
+		"/proc/2/root/bin/tool": {digest: replacedDigest, pin: toolPin},
+		// the file changed right before it was hashed: its change time may not change on the next write.
+		"/proc/5/root/bin/app":  {digest: appDigest, pin: bpf.ExecPin{Inode: 10, CtimeSec: time.Now().Unix()}},
+		"/proc/5/root/bin/tool": {digest: replacedDigest, pin: toolPin},
+	})
+	execPins := make(fakeExecPins)
+	r.EnableHashMatching(execPins.update)
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{
+					Allowed: []string{"/bin/sleep"},
+					AllowedHashes: []v1alpha1.ExecutableHash{
+						{Path: "/bin/app", SHA256: appDigest},
+						{Path: "/bin/tool", SHA256: replacedDigest},
+					},
+				}},
+			},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	state := r.wpState[wp.NamespacedName()]
+	// The executables of the allowedHashes rules are only allowed through their pins.
+	require.Equal(t, []string{"/bin/sleep"}, allowedByPolicyID[state.polByContainer[c1]])
+
+	// The binaries match the digests: they are pinned.
+	require.NoError(t, r.AddPodContainerFromNri(hashMatchingPod("pod-1", 100, "/proc/1/root")))
+	require.Equal(t, state.polByContainer[c1], cgToPolicy[100])
+	require.Equal(t, []bpf.ExecPin{appPin, toolPin}, execPins[100])
+
+	// One binary was replaced by a different content at the same path: only the other one is pinned.
+	require.NoError(t, r.AddPodContainerFromNri(hashMatchingPod("pod-2", 101, "/proc/2/root")))
+	require.Equal(t, state.polByContainer[c1], cgToPolicy[101])
+	require.Equal(t, []bpf.ExecPin{toolPin}, execPins[101])
+
+	// The files can't be read or the root filesystem is unknown: nothing is pinned.
+	require.NoError(t, r.AddPodContainerFromNri(hashMatchingPod("pod-3", 102, "/proc/3/root")))
+	require.NoError(t, r.AddPodContainerFromNri(hashMatchingPod("pod-4", 103, "")))
+	require.NotContains(t, execPins, CgroupID(102))
+	require.NotContains(t, execPins, CgroupID(103))
+	for _, cgroupID := range []CgroupID{102, 103} {
+		require.Equal(t, state.polByContainer[c1], cgToPolicy[cgroupID])
+	}
+
+	// A file changed too recently is not pinned.
+	require.NoError(t, r.AddPodContainerFromNri(hashMatchingPod("pod-5", 104, "/proc/5/root")))
+	require.Equal(t, []bpf.ExecPin{toolPin}, execPins[104])
+
+	// Removing the container removes its pins.
+	require.NoError(t, r.RemovePodContainerFromNri("pod-5", "pod-5-c1"))
+	require.NotContains(t, execPins, CgroupID(104))
+
+	// Dropping the allowedHashes rules unpins every container.
+	wp.Spec.RulesByContainer[c1].Executables.AllowedHashes = nil
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Empty(t, execPins)
+	for _, cgroupID := range []CgroupID{100, 101, 102, 103} {
+		require.Equal(t, state.polByContainer[c1], cgToPolicy[cgroupID])
+	}
+
+	// Adding them back pins the matching containers again.
+	wp.Spec.RulesByContainer[c1].Executables.AllowedHashes = []v1alpha1.ExecutableHash{
+		{Path: "/bin/app", SHA256: appDigest},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, fakeExecPins{100: {appPin}}, execPins)
+
+	require.NoError(t, r.HandleWPDelete(wp))
+	require.Empty(t, cgToPolicy)
+	require.Empty(t, allowedByPolicyID)
+	require.Empty(t, execPins)
+}
+
+func TestReconcileWP_HashReverification(t *testing.T) {
+	r := NewTestResolver(t)
+	cgToPolicy := make(fakeCgroupPolicyMap)
+	r.cgroupToPolicyMapUpdateFunc = cgToPolicy.update
+	appPin := bpf.ExecPin{Inode: 10, CtimeSec: 1000}
+	r.fileDigestFunc = fakeFileDigest(map[string]fakeFile{
+		"/proc/1/root/bin/app": {digest: appDigest, pin: appPin},
+		"/proc/2/root/bin/app": {digest: appDigest, pin: bpf.ExecPin{Inode: 20, CtimeSec: 1000}},
+	})
+	execPins := make(fakeExecPins)
+	r.EnableHashMatching(execPins.update)
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:             "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.NoError(t, r.AddPodContainerFromNri(hashMatchingPod("pod-1", 100, "/proc/1/root")))
+	require.Empty(t, execPins)
+
+	// The container started before the allowedHashes rules: it is verified when they are added.
+	wp.Spec.RulesByContainer[c1].Executables.AllowedHashes = []v1alpha1.ExecutableHash{
+		{Path: "/bin/app", SHA256: appDigest},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	state := r.wpState[wp.NamespacedName()]
+	require.Equal(t, state.polByContainer[c1], cgToPolicy[100])
+	require.Equal(t, []bpf.ExecPin{appPin}, execPins[100])
+
+	// The digest of the rules changes: the container is verified again against it.
+	wp.Spec.RulesByContainer[c1].Executables.AllowedHashes[0].SHA256 = replacedDigest
+	require.NoError(t, r.ReconcileWP(wp))
+	require.NotContains(t, execPins, CgroupID(100))
+	wp.Spec.RulesByContainer[c1].Executables.AllowedHashes[0].SHA256 = appDigest
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, []bpf.ExecPin{appPin}, execPins[100])
+
+	// The container restarts in place with a new cgroup: the old one is unpinned and the new one verified.
+	restarted := hashMatchingPod("pod-1", 101, "/proc/2/root")
+	require.NoError(t, r.AddPodContainerFromNri(restarted))
+	require.Equal(t, fakeExecPins{101: {{Inode: 20, CtimeSec: 1000}}}, execPins)
+}
+
+func TestReconcileWP_HashMatchingDisabled(t *testing.T) {
+	r := NewTestResolver(t)
+	cgToPolicy := make(fakeCgroupPolicyMap)
+	r.cgroupToPolicyMapUpdateFunc = cgToPolicy.update
+	allowedByPolicyID := make(map[PolicyID][]string)
+	r.policyUpdateBinariesFunc = func(policyID PolicyID, values []string, _ bpf.PolicyValuesOperation) error {
+		allowedByPolicyID[policyID] = values
+		return nil
+	}
+	r.fileDigestFunc = func(_, _ string) (string, bpf.ExecPin, error) {
+		t.Fatal("files must not be hashed when hash matching is disabled")
+		return "", bpf.ExecPin{}, nil
+	}
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{
+					AllowedHashes: []v1alpha1.ExecutableHash{{Path: "/bin/app", SHA256: appDigest}},
+				}},
+			},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.NoError(t, r.AddPodContainerFromNri(hashMatchingPod("pod-1", 100, "/proc/2/root")))
+
+	// Without hash matching the executables of the allowedHashes rules are never allowed.
+	state := r.wpState[wp.NamespacedName()]
+	require.Equal(t, state.polByContainer[c1], cgToPolicy[100])
+	require.Empty(t, allowedByPolicyID[state.polByContainer[c1]])
+}
+
+func TestFileDigest(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "bin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "bin", "app"), []byte("foo"), 0o755))
+	// the absolute symlinks are resolved inside the root filesystem of the container.
+	require.NoError(t, os.Symlink("/bin/app", filepath.Join(root, "bin", "link")))
+	require.NoError(t, os.Symlink("/etc/hostname", filepath.Join(root, "bin", "escape")))
+	require.NoError(t, os.Symlink("../../../etc/hostname", filepath.Join(root, "bin", "relative-escape")))
+
+	var st syscall.Stat_t
+	require.NoError(t, syscall.Stat(filepath.Join(root, "bin", "app"), &st))
+	pin := bpf.ExecPin{Inode: st.Ino, CtimeSec: st.Ctim.Sec, CtimeNsec: uint32(st.Ctim.Nsec)}
+
+	digest, gotPin, err := fileDigest(root, "/bin/app")
+	require.NoError(t, err)
+	require.Equal(t, appDigest, digest)
+	require.Equal(t, pin, gotPin)
+	digest, gotPin, err = fileDigest(root, "/bin/link")
+	require.NoError(t, err)
+	require.Equal(t, appDigest, digest)
+	require.Equal(t, pin, gotPin)
+
+	_, _, err = fileDigest(root, "/bin/escape")
+	require.Error(t, err)
+	_, _, err = fileDigest(root, "/bin/relative-escape")
+	require.Error(t, err)
+	_, _, err = fileDigest(root, "/bin/missing")
+	require.Error(t, err)
+}
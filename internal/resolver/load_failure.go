@@ -0,0 +1,138 @@
+package resolver
+
+import (
+	"fmt"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	agentv1 "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+)
+
+// failsClosed reports whether the containers of the policy must be blocked while it fails to load.
+func failsClosed(wp *v1alpha1.WorkloadPolicy) bool {
+	return wp != nil && wp.Spec.OnLoadFailure == v1alpha1.LoadFailureFailClosed
+}
+
+// fallbackApplies reports whether the container is enforced by the policy, so that it is blocked
+// while the policy fails to load. The rules are taken from the spec, since the state of a policy
+// that failed to load may be incomplete.
+// This must be called with the resolver lock held.
+func (r *Resolver) fallbackApplies(wp *v1alpha1.WorkloadPolicy, container *ContainerMeta) bool {
+	if _, listed := wp.Spec.RulesByContainer[container.Name]; listed {
+		return true
+	}
+	return wp.Spec.UnlistedContainerPolicy == v1alpha1.UnlistedContainerDeny &&
+		(!container.Ephemeral || r.enforceEphemeral)
+}
+
+// handleLoadFailure applies the onLoadFailure behavior of a policy that failed to be reconciled.
+// This must be called with the resolver lock held.
+func (r *Resolver) handleLoadFailure(info *wpInfo) {
+	wpKey := info.policy.NamespacedName()
+	if !failsClosed(info.policy) {
+		// The policy could have been switched to fail-open while it was failing closed.
+		if err := r.releaseFallbackPolicy(info); err != nil {
+			r.logger.Error("failed to release the fallback policy", "wp", wpKey, "error", err)
+		}
+		return
+	}
+	if err := r.failClosed(info); err != nil {
+		r.logger.Error("failed to block the containers of the policy", "wp", wpKey, "error", err)
+	}
+}
+
+// handlePodAttachFailure applies the onLoadFailure behavior of the policies of the pods that could not be
+// attached to them, e.g. when the cgroup map is full: the containers of a policy failing closed are blocked
+// until it is reconciled successfully, instead of running without enforcement.
+// This must be called with the resolver lock held.
+func (r *Resolver) handlePodAttachFailure(pods []PodInput, cause error) {
+	handled := make(map[*wpInfo]struct{})
+	for _, pod := range pods {
+		state, ok := r.podCache[pod.Meta.ID]
+		if !ok {
+			continue
+		}
+		_, info := r.podPolicy(state)
+		if info == nil || !failsClosed(info.policy) {
+			continue
+		}
+		if _, ok = handled[info]; ok {
+			continue
+		}
+		handled[info] = struct{}{}
+		info.setPolicyStatus(agentv1.PolicyState_POLICY_STATE_ERROR,
+			policymode.ParsePolicyModeToProto(info.policy.Spec.Mode), cause.Error())
+		r.handleLoadFailure(info)
+	}
+}
+
+// failClosed replaces, on every pod bound to the policy, the policies of its containers with a deny-all
+// policy, until the policy is reconciled successfully. The deny-all policy follows the mode of the policy,
+// its active windows and the enforcement override like the policies it replaces.
+// This must be called with the resolver lock held.
+func (r *Resolver) failClosed(info *wpInfo) error {
+	wpKey := info.policy.NamespacedName()
+	mode, err := r.currentMode(info.policy)
+	if err != nil {
+		// the active windows cannot be evaluated, the containers are blocked.
+		mode = policymode.Protect
+	}
+	info.enforcedMode = mode
+	op := bpf.ReplaceValuesInPolicy
+	if info.fallbackPolicyID == PolicyIDNone {
+		info.fallbackPolicyID = r.allocPolicyID()
+		r.logger.Warn("policy failed to load, blocking its containers",
+			"id", info.fallbackPolicyID,
+			"wp", wpKey,
+		)
+		op = bpf.AddValuesToPolicy
+	}
+	err = r.upsertPolicyIDInBPF(info.fallbackPolicyID, r.withGlobalAllowList(nil), nil, mode, op)
+	if err != nil {
+		return fmt.Errorf("failed to populate fallback policy for wp %s: %w", wpKey, err)
+	}
+
+	batch := make(cgroupBatch)
+	for _, podEntry := range r.podCache {
+		if !podEntry.matchPolicy(info.policy.Name, info.policy.Namespace) ||
+			!podEntry.matchPodIndexes(info.policy.Spec.PodIndexes) {
+			continue
+		}
+		if err = r.detachPolicyFromPod(podEntry); err != nil {
+			return err
+		}
+		r.applyFallbackPolicyToPod(podEntry, info, batch)
+	}
+	return r.flushCgroupBatch(batch)
+}
+
+// applyFallbackPolicyToPod applies the deny-all policy of a policy that failed to load to the pod containers.
+// This must be called with the resolver lock held.
+func (r *Resolver) applyFallbackPolicyToPod(state *podEntry, info *wpInfo, batch cgroupBatch) {
+	wp := r.withInheritedRules(info.policy)
+	for _, container := range state.containers {
+		if r.fallbackApplies(wp, container) {
+			batch.add(info.fallbackPolicyID, container.CgroupID)
+		}
+	}
+}
+
+// releaseFallbackPolicy removes the deny-all policy once the policy loads, so that its own policies
+// can be applied to the containers.
+// This must be called with the resolver lock held.
+func (r *Resolver) releaseFallbackPolicy(info *wpInfo) error {
+	if info.fallbackPolicyID == PolicyIDNone {
+		return nil
+	}
+	wpKey := info.policy.NamespacedName()
+	if err := r.cgroupToPolicyMapUpdateFunc(info.fallbackPolicyID, []CgroupID{}, bpf.RemovePolicy); err != nil {
+		return fmt.Errorf("failed to remove fallback policy from cgroup map for wp %s: %w", wpKey, err)
+	}
+	if err := r.clearPolicyIDFromBPF(info.fallbackPolicyID); err != nil {
+		return fmt.Errorf("failed to clear fallback policy for wp %s: %w", wpKey, err)
+	}
+	r.logger.Info("policy loaded, releasing its fallback policy", "id", info.fallbackPolicyID, "wp", wpKey)
+	info.fallbackPolicyID = PolicyIDNone
+	return nil
+}
@@ -0,0 +1,219 @@
+package resolver
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	agentv1 "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReconcileWP_OnLoadFailure(t *testing.T) {
+	r := NewTestResolver(t)
+	cgToPolicy := make(fakeCgroupPolicyMap)
+	r.cgroupToPolicyMapUpdateFunc = cgToPolicy.update
+	// the maps of the policies allowing the broken executable cannot be populated.
+	const broken = "/bin/broken"
+	allowedByPolicyID := make(map[PolicyID][]string)
+	r.policyUpdateBinariesFunc = func(policyID PolicyID, values []string, op bpf.PolicyValuesOperation) error {
+		if op == bpf.RemoveValuesFromPolicy {
+			delete(allowedByPolicyID, policyID)
+			return nil
+		}
+		if slices.Contains(values, broken) {
+			return errors.New("map is full")
+		}
+		allowedByPolicyID[policyID] = values
+		return nil
+	}
+	modeByPolicyID := make(map[PolicyID]policymode.Mode)
+	r.policyModeUpdateFunc = func(policyID PolicyID, mode policymode.Mode, op bpf.PolicyModeOperation) error {
+		if op == bpf.DeleteMode {
+			delete(modeByPolicyID, policyID)
+			return nil
+		}
+		modeByPolicyID[policyID] = mode
+		return nil
+	}
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:             "monitor",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+		},
+	}
+	key := wp.NamespacedName()
+	require.NoError(t, r.ReconcileWP(wp))
+	require.NoError(t, r.AddPodsFromNri([]PodInput{{
+		Meta: PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		Containers: map[ContainerID]ContainerInput{
+			cid1: {ContainerMeta: ContainerMeta{CgroupID: 100, Name: c1, ID: cid1}},
+			cid2: {ContainerMeta: ContainerMeta{CgroupID: 101, Name: c2, ID: cid2}},
+		},
+	}}))
+	info := r.wpState[key]
+	require.Equal(t, fakeCgroupPolicyMap{100: info.polByContainer[c1]}, cgToPolicy)
+
+	// with fail-open, the containers keep their previous policy.
+	wp.Spec.RulesByContainer[c1] = rules("/bin/sleep", broken)
+	require.Error(t, r.ReconcileWP(wp))
+	require.Equal(t, PolicyIDNone, info.fallbackPolicyID)
+	require.Equal(t, fakeCgroupPolicyMap{100: info.polByContainer[c1]}, cgToPolicy)
+
+	// with fail-closed, the containers with rules are switched to a deny-all policy in the mode of the policy.
+	wp.Spec.OnLoadFailure = v1alpha1.LoadFailureFailClosed
+	require.Error(t, r.ReconcileWP(wp))
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_ERROR, r.GetPolicyStatuses()[key].State)
+	fallback := info.fallbackPolicyID
+	require.NotEqual(t, PolicyIDNone, fallback)
+	require.Equal(t, fakeCgroupPolicyMap{100: fallback}, cgToPolicy)
+	require.Empty(t, allowedByPolicyID[fallback])
+	require.Equal(t, policymode.Monitor, modeByPolicyID[fallback])
+	require.Empty(t, r.SelfCheck())
+
+	// the deny-all policy follows the mode switches of the policy, e.g. the enforcement override.
+	wp.Spec.Mode = "protect"
+	require.Error(t, r.ReconcileWP(wp))
+	require.Equal(t, fallback, info.fallbackPolicyID)
+	require.Equal(t, policymode.Protect, modeByPolicyID[fallback])
+	r.SetEnforcementOverride(true)
+	require.Equal(t, policymode.Monitor, modeByPolicyID[fallback])
+	r.SetEnforcementOverride(false)
+	require.Equal(t, policymode.Protect, modeByPolicyID[fallback])
+
+	// a container starting while the policy fails to load is blocked too.
+	wp.Spec.UnlistedContainerPolicy = v1alpha1.UnlistedContainerDeny
+	require.Error(t, r.ReconcileWP(wp))
+	require.Equal(t, fallback, info.fallbackPolicyID)
+	require.Equal(t, fakeCgroupPolicyMap{100: fallback, 101: fallback}, cgToPolicy)
+	require.NoError(t, r.AddPodContainerFromNri(PodInput{
+		Meta: *r.podCache["test-pod-uid"].meta,
+		Containers: map[ContainerID]ContainerInput{
+			cid3: {ContainerMeta: ContainerMeta{CgroupID: 102, Name: c3, ID: cid3}},
+		},
+	}))
+	require.Equal(t, fallback, cgToPolicy[102])
+
+	// once the policy loads, its own policies replace the fallback one.
+	wp.Spec.RulesByContainer[c1] = rules("/bin/sleep")
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, PolicyIDNone, info.fallbackPolicyID)
+	require.NotContains(t, modeByPolicyID, fallback)
+	require.Equal(t, fakeCgroupPolicyMap{
+		100: info.polByContainer[c1],
+		101: info.unlistedPolicyID,
+		102: info.unlistedPolicyID,
+	}, cgToPolicy)
+
+	// deleting a policy failing closed removes the fallback policy.
+	wp.Spec.RulesByContainer[c1] = rules(broken)
+	require.Error(t, r.ReconcileWP(wp))
+	fallback = info.fallbackPolicyID
+	require.NotEqual(t, PolicyIDNone, fallback)
+	require.NoError(t, r.HandleWPDelete(wp))
+	require.Empty(t, cgToPolicy)
+	require.NotContains(t, modeByPolicyID, fallback)
+}
+
+func TestReconcileWP_OnLoadFailureOverPolicyLimit(t *testing.T) {
+	r := NewTestResolver(t)
+	r.SetMaxPolicies(1)
+	modeByPolicyID := make(map[PolicyID]policymode.Mode)
+	r.policyModeUpdateFunc = func(policyID PolicyID, mode policymode.Mode, _ bpf.PolicyModeOperation) error {
+		modeByPolicyID[policyID] = mode
+		return nil
+	}
+
+	newPolicy := func(name string) *v1alpha1.WorkloadPolicy {
+		return &v1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+			Spec: v1alpha1.WorkloadPolicySpec{
+				Mode:             "protect",
+				OnLoadFailure:    v1alpha1.LoadFailureFailClosed,
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+			},
+		}
+	}
+	require.NoError(t, r.ReconcileWP(newPolicy("first")))
+	loaded := len(modeByPolicyID)
+
+	// a policy rejected by the cap doesn't load a fallback policy beyond it.
+	second := newPolicy("second")
+	require.Error(t, r.ReconcileWP(second))
+	require.Equal(t, PolicyIDNone, r.wpState[second.NamespacedName()].fallbackPolicyID)
+	require.Len(t, modeByPolicyID, loaded)
+}
+
+func TestAddPodsFromNri_OnLoadFailure(t *testing.T) {
+	r := NewTestResolver(t)
+	cgToPolicy := make(fakeCgroupPolicyMap)
+	// the cgroups cannot be attached to the policies of the containers, e.g. the map is full.
+	var failingPolicies []PolicyID
+	r.cgroupToPolicyMapUpdateFunc = func(polID PolicyID, cgroupIDs []CgroupID, op bpf.CgroupPolicyOperation) error {
+		if op == bpf.AddPolicyToCgroups && slices.Contains(failingPolicies, polID) {
+			return errors.New("map is full")
+		}
+		return cgToPolicy.update(polID, cgroupIDs, op)
+	}
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:             "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+		},
+	}
+	key := wp.NamespacedName()
+	require.NoError(t, r.ReconcileWP(wp))
+	info := r.wpState[key]
+	failingPolicies = []PolicyID{info.polByContainer[c1]}
+	newPod := func(uid PodID, cgroupID CgroupID) PodInput {
+		return PodInput{
+			Meta: PodMeta{
+				ID:        uid,
+				Namespace: "test-ns",
+				Name:      string(uid),
+				Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+			},
+			Containers: map[ContainerID]ContainerInput{
+				ContainerID(uid): {ContainerMeta: ContainerMeta{CgroupID: cgroupID, Name: c1, ID: ContainerID(uid)}},
+			},
+		}
+	}
+
+	// with fail-open, the container is left without enforcement.
+	require.Error(t, r.AddPodsFromNri([]PodInput{newPod("pod-1", 100)}))
+	require.Equal(t, PolicyIDNone, info.fallbackPolicyID)
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_READY, r.GetPolicyStatuses()[key].State)
+	require.Empty(t, cgToPolicy)
+
+	// with fail-closed, the containers of the policy are switched to its deny-all policy.
+	wp.Spec.OnLoadFailure = v1alpha1.LoadFailureFailClosed
+	failingPolicies = nil
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, fakeCgroupPolicyMap{100: info.polByContainer[c1]}, cgToPolicy)
+	failingPolicies = []PolicyID{info.polByContainer[c1]}
+	require.ErrorContains(t, r.AddPodsFromNri([]PodInput{newPod("pod-2", 101)}), "map is full")
+	fallback := info.fallbackPolicyID
+	require.NotEqual(t, PolicyIDNone, fallback)
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_ERROR, r.GetPolicyStatuses()[key].State)
+	require.Equal(t, fakeCgroupPolicyMap{100: fallback, 101: fallback}, cgToPolicy)
+
+	// once the policy is reconciled successfully, its own policies replace the fallback one.
+	failingPolicies = nil
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, PolicyIDNone, info.fallbackPolicyID)
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_READY, r.GetPolicyStatuses()[key].State)
+	require.Equal(t, fakeCgroupPolicyMap{100: info.polByContainer[c1], 101: info.polByContainer[c1]}, cgToPolicy)
+}
@@ -13,6 +13,10 @@ func mockPolicyUpdateBinariesFunc(_ PolicyID, _ []string, _ bpf.PolicyValuesOper
 	return nil
 }
 
+func mockPolicyUpdatePrefixesFunc(_ PolicyID, _ []string, _ bpf.PolicyValuesOperation) error {
+	return nil
+}
+
 func mockPolicyModeUpdateFunc(_ PolicyID, _ policymode.Mode, _ bpf.PolicyModeOperation) error {
 	return nil
 }
@@ -42,6 +46,7 @@ func NewTestResolver(t testing.TB) *Resolver {
 		mockCgTrackerUpdateFunc,
 		mockCgroupToPolicyMapUpdateFunc,
 		mockPolicyUpdateBinariesFunc,
+		mockPolicyUpdatePrefixesFunc,
 		mockPolicyModeUpdateFunc,
 	)
 	require.NoError(t, err)
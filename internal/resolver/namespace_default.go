@@ -0,0 +1,75 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+)
+
+// detachPolicyFromPod removes every cgroup→policyID association of the pod containers.
+// This must be called with the resolver lock held.
+func (r *Resolver) detachPolicyFromPod(state *podEntry) error {
+	cgroupIDs := make([]CgroupID, 0, len(state.containers))
+	for _, container := range state.containers {
+		cgroupIDs = append(cgroupIDs, container.CgroupID)
+	}
+	if len(cgroupIDs) == 0 {
+		return nil
+	}
+	if err := r.cgroupToPolicyMapUpdateFunc(PolicyIDNone, cgroupIDs, bpf.RemoveCgroups); err != nil {
+		return fmt.Errorf("failed to remove cgroups for pod %s, policy %s: %w",
+			state.podName(), state.policyName(), err)
+	}
+	return nil
+}
+
+// SetNamespaceDefaultPolicy sets the policy enforced on the pods of the namespace without a policy label.
+// An empty policy name removes the default policy of the namespace.
+func (r *Resolver) SetNamespaceDefaultPolicy(namespace, policyName string) error {
+	err := r.setNamespaceDefaultPolicy(namespace, policyName)
+	// the pods of the namespace may get other allowedHashes rules.
+	return errors.Join(err, r.verifyPendingContainers())
+}
+
+func (r *Resolver) setNamespaceDefaultPolicy(namespace, policyName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.nsDefaultPolicies[namespace] == policyName {
+		return nil
+	}
+	r.logger.Info("update namespace default policy",
+		"namespace", namespace,
+		"policy", policyName,
+	)
+	if policyName == "" {
+		delete(r.nsDefaultPolicies, namespace)
+	} else {
+		r.nsDefaultPolicies[namespace] = policyName
+	}
+
+	var errs []error
+	batch := make(cgroupBatch)
+	for _, state := range r.podCache {
+		if state.podNamespace() != namespace {
+			continue
+		}
+		state.defaultPolicy = policyName
+		if state.labeledPolicyName() != "" {
+			// the pod label always wins over the namespace default.
+			continue
+		}
+		if err := r.detachPolicyFromPod(state); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := r.applyPolicyToPodIfPresent(state, batch); err != nil {
+			errs = append(errs, fmt.Errorf("failed to apply default policy to pod %s: %w", state.podName(), err))
+		}
+	}
+	if err := r.flushCgroupBatch(batch); err != nil {
+		errs = append(errs, fmt.Errorf("failed to apply default policy: %w", err))
+	}
+	return errors.Join(errs...)
+}
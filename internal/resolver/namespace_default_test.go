@@ -0,0 +1,83 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNamespaceDefaultPolicy(t *testing.T) {
+	r := NewTestResolver(t)
+	policyByCgroup := make(fakeCgroupPolicyMap)
+	r.cgroupToPolicyMapUpdateFunc = policyByCgroup.update
+
+	newPolicy := func(name string) *v1alpha1.WorkloadPolicy {
+		return &v1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+			Spec: v1alpha1.WorkloadPolicySpec{
+				Mode:             "protect",
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+			},
+		}
+	}
+	baseline := newPolicy("baseline")
+	explicit := newPolicy("explicit")
+	policyID := func(wp *v1alpha1.WorkloadPolicy) PolicyID {
+		return r.wpState[wp.NamespacedName()].polByContainer[c1]
+	}
+	addPod := func(uid string, cgID CgroupID, labels map[string]string) {
+		require.NoError(t, r.AddPodContainerFromNri(PodInput{
+			Meta: PodMeta{ID: uid, Namespace: "test-ns", Name: uid, Labels: labels},
+			Containers: map[ContainerID]ContainerInput{
+				uid + "-c1": {ContainerMeta: ContainerMeta{CgroupID: cgID, Name: c1, ID: uid + "-c1"}},
+			},
+		}))
+	}
+
+	require.NoError(t, r.ReconcileWP(explicit))
+	addPod("labeled", 101, map[string]string{v1alpha1.PolicyLabelKey: "explicit"})
+
+	// the default policy is not reconciled yet: the pods are not enforced but can start.
+	require.NoError(t, r.SetNamespaceDefaultPolicy("test-ns", "baseline"))
+	addPod("unlabeled", 100, nil)
+	require.Equal(t, fakeCgroupPolicyMap{101: policyID(explicit)}, policyByCgroup)
+
+	require.NoError(t, r.ReconcileWP(baseline))
+	require.Equal(t, fakeCgroupPolicyMap{
+		100: policyID(baseline),
+		101: policyID(explicit),
+	}, policyByCgroup)
+
+	view, err := r.GetContainerView(100)
+	require.NoError(t, err)
+	require.Equal(t, "baseline", view.PolicyName)
+
+	// pods created afterwards get the default policy right away.
+	addPod("late", 102, nil)
+	require.Equal(t, policyID(baseline), policyByCgroup[102])
+
+	// removing the default only affects the unlabeled pods.
+	require.NoError(t, r.SetNamespaceDefaultPolicy("test-ns", ""))
+	require.Equal(t, fakeCgroupPolicyMap{101: policyID(explicit)}, policyByCgroup)
+
+	// the default policy can be switched to another one.
+	require.NoError(t, r.SetNamespaceDefaultPolicy("test-ns", "explicit"))
+	require.Equal(t, fakeCgroupPolicyMap{
+		100: policyID(explicit),
+		101: policyID(explicit),
+		102: policyID(explicit),
+	}, policyByCgroup)
+
+	// the removed containers are detached from the default policy.
+	require.NoError(t, r.RemovePodContainerFromNri("late", "late-c1"))
+	require.Equal(t, fakeCgroupPolicyMap{
+		100: policyID(explicit),
+		101: policyID(explicit),
+	}, policyByCgroup)
+
+	// deleting the default policy detaches the unlabeled pods as well.
+	require.NoError(t, r.HandleWPDelete(explicit))
+	require.Empty(t, policyByCgroup)
+}
@@ -1,11 +1,15 @@
 package resolver
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const nriSyncInProgressMsg = "waiting for NRI synchronization to complete"
@@ -17,57 +21,233 @@ func convertPodData(pod PodInput) *podEntry {
 	}
 }
 
+// endSpan records the error, if any, on the span before ending it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// knownContainer reports whether the container is already in the cache of the pod with the same cgroup.
+// A container restarted in place keeps its ID but gets a new cgroup, in that case it is not known
+// so that its new cgroup is resolved and enforced again.
+// This must be called with the resolver lock held.
+func knownContainer(state *podEntry, pod PodInput, containerID ContainerID, container ContainerInput) (bool, error) {
+	info, exists := state.containers[containerID]
+	if !exists {
+		return false, nil
+	}
+	if info.Name == container.Name {
+		// this is possible for example when there is a restart in the NRI plugin and we receive all the data again.
+		return info.CgroupID == container.CgroupID, nil
+	}
+	// The container name should never change, this is unexpected and we return an error to avoid potential issues
+	// with wrong cgroupID -> pod association in the cache.
+	return true, fmt.Errorf("containerID %s for pod %s already exists. old (name: %s,cID: %d) new (name: %s,cID: %d)",
+		containerID,
+		pod.Meta.Name,
+		info.Name,
+		info.CgroupID,
+		container.Name,
+		container.CgroupID)
+}
+
+// forgetRestartedContainer drops the old cgroup of a container restarted in place.
+// This must be called with the resolver lock held.
+func (r *Resolver) forgetRestartedContainer(state *podEntry, containerID ContainerID) error {
+	old, exists := state.containers[containerID]
+	if !exists {
+		return nil
+	}
+	r.logger.Info("container restarted with a new cgroup",
+		"pod", state.podName(),
+		"container", old.Name,
+		"id", containerID,
+		"oldCgroupID", old.CgroupID,
+	)
+	delete(r.cgroupIDToPodID, old.CgroupID)
+	delete(r.execBypasses, old.CgroupID)
+	if err := r.unpinExecutables(state, old); err != nil {
+		return err
+	}
+	return r.cgroupToPolicyMapUpdateFunc(PolicyIDNone, []CgroupID{old.CgroupID}, bpf.RemoveCgroups)
+}
+
+// newContainersFromNri returns the containers of the pod that are not in the cache yet.
+func (r *Resolver) newContainersFromNri(pod PodInput) (map[ContainerID]ContainerInput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.podCache[pod.Meta.ID]
+	if !ok {
+		return pod.Containers, nil
+	}
+	containers := make(map[ContainerID]ContainerInput, len(pod.Containers))
+	for containerID, container := range pod.Containers {
+		known, err := knownContainer(state, pod, containerID, container)
+		if err != nil {
+			return nil, err
+		}
+		if !known {
+			containers[containerID] = container
+		}
+	}
+	return containers, nil
+}
+
 func (r *Resolver) AddPodContainerFromNri(pod PodInput) error {
+	return r.AddPodsFromNri([]PodInput{pod})
+}
+
+// AddPodsFromNri adds the containers of several pods at once, e.g. when NRI is synchronized.
+// The cgroups of the pods sharing a policy are attached to it with a single map update.
+// Each pod gets an addPod span, with a podContainersResolveCgroups child, under an addPods span providing also
+// the applyPoliciesToPod span of the map update.
+func (r *Resolver) AddPodsFromNri(pods []PodInput) (err error) {
+	ctx, span := r.tracer.Start(context.Background(), "addPods", trace.WithAttributes(
+		attribute.Int("pods", len(pods)),
+	))
+	defer func() { endSpan(span, err) }()
+
+	podSpans := make([]trace.Span, 0, len(pods))
+	defer func() {
+		// the spans of the pods not added because of an error.
+		for _, podSpan := range podSpans {
+			if podSpan != nil {
+				endSpan(podSpan, err)
+			}
+		}
+	}()
+
+	newContainers := make([]map[ContainerID]ContainerInput, len(pods))
+	verified := make([]map[ContainerID]verifiedExecs, len(pods))
+	for i, pod := range pods {
+		podCtx, podSpan := r.tracer.Start(ctx, "addPod", trace.WithAttributes(
+			attribute.String("pod", pod.Meta.Name),
+			attribute.String("namespace", pod.Meta.Namespace),
+			attribute.Int("containers", len(pod.Containers)),
+		))
+		podSpans = append(podSpans, podSpan)
+		if newContainers[i], verified[i], err = r.podContainersResolveCgroups(podCtx, pod); err != nil {
+			return err
+		}
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	batch := make(cgroupBatch)
+	for i, pod := range pods {
+		if err = r.addPodContainers(pod, newContainers[i], verified[i], batch); err != nil {
+			// the pods added so far are in the cache, their policy must be applied anyway.
+			err = errors.Join(err, r.applyPoliciesToPod(ctx, batch))
+			r.handlePodAttachFailure(pods, err)
+			return err
+		}
+		podSpans[i].End()
+		podSpans[i] = nil
+	}
+	if err = r.applyPoliciesToPod(ctx, batch); err != nil {
+		err = fmt.Errorf("failed to apply policy to pods: %w", err)
+		r.handlePodAttachFailure(pods, err)
+		return err
+	}
+	return nil
+}
+
+// podContainersResolveCgroups returns the new containers of the pod, after adding their cgroups to the
+// cgtracker map, and the ones whose executables are verified.
+func (r *Resolver) podContainersResolveCgroups(
+	ctx context.Context,
+	pod PodInput,
+) (_ map[ContainerID]ContainerInput, _ map[ContainerID]verifiedExecs, err error) {
+	_, span := r.tracer.Start(ctx, "podContainersResolveCgroups")
+	defer func() { endSpan(span, err) }()
+
+	containers, err := r.newContainersFromNri(pod)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Updating the cgtracker map walks the nested cgroups of the container, so it is done
+	// without holding the resolver lock: a burst of container starts would otherwise
+	// serialize every other resolver operation behind the filesystem.
+	for _, container := range containers {
+		if err = r.cgTrackerUpdateFunc(container.CgroupID, container.CgroupPath); err != nil {
+			return nil, nil, fmt.Errorf(
+				"failed to update cgroup tracker map for pod %s, container %s: %w",
+				pod.Meta.Name,
+				container.Name,
+				err,
+			)
+		}
+	}
+	span.SetAttributes(attribute.Int("newContainers", len(containers)))
+	return containers, r.verifiedContainers(pod, containers), nil
+}
+
+// applyPoliciesToPod attaches the cgroups collected in the batch to their policy.
+// This must be called with the resolver lock held.
+func (r *Resolver) applyPoliciesToPod(ctx context.Context, batch cgroupBatch) (err error) {
+	_, span := r.tracer.Start(ctx, "applyPoliciesToPod", trace.WithAttributes(
+		attribute.Int("policies", len(batch)),
+	))
+	defer func() { endSpan(span, err) }()
+
+	return r.flushCgroupBatch(batch)
+}
+
+// addPodContainers adds the new containers of the pod to the cache, their cgroups are collected in the batch.
+// This must be called with the resolver lock held.
+func (r *Resolver) addPodContainers(
+	pod PodInput,
+	containers map[ContainerID]ContainerInput,
+	verified map[ContainerID]verifiedExecs,
+	batch cgroupBatch,
+) error {
 	// NRI provides just one container of a pod, so it's possible we already have some containers for this pod.
 	podID := pod.Meta.ID
 	state, ok := r.podCache[podID]
 	if !ok {
 		// we need to add the pod to the cache from 0
 		state = convertPodData(pod)
+		state.defaultPolicy = r.nsDefaultPolicies[pod.Meta.Namespace]
+	}
+	if pod.SandboxCgroupID != 0 {
+		state.sandboxCgroupID = pod.SandboxCgroupID
 	}
 
-	for containerID, container := range pod.Containers {
-		if info, exists := state.containers[containerID]; exists {
-			// this is possible for example when there is a restart in the NRI plugin and we receive all the data again.
-			// cID and containerName should never change but as an extra check we return an error for now.
-			if info.CgroupID == container.CgroupID && info.Name == container.Name {
-				// If everything is identical, as expected, we can just continue
-				continue
-			}
-			// If it's different, this is unexpected and we return an error to avoid potential issues
-			// with wrong cgroupID -> pod association in the cache.
-			return fmt.Errorf("containerID %s for pod %s already exists. old (name: %s,cID: %d) new (name: %s,cID: %d)",
-				containerID,
-				pod.Meta.Name,
-				info.Name,
-				info.CgroupID,
-				container.Name,
-				container.CgroupID)
+	for containerID, container := range containers {
+		r.containerResolved(podID, container.Name)
+		// the container could have been added concurrently while the lock was released.
+		known, err := knownContainer(state, pod, containerID, container)
+		if err != nil {
+			return err
+		}
+		if known {
+			continue
+		}
+		if err = r.forgetRestartedContainer(state, containerID); err != nil {
+			return fmt.Errorf("failed to remove the old cgroup of container %s: %w", container.Name, err)
 		}
 
 		state.containers[containerID] = &container.ContainerMeta
+		if execs, ok := verified[containerID]; ok {
+			if err = r.pinExecutables(state, &container.ContainerMeta, execs); err != nil {
+				return err
+			}
+		}
 
 		// populate the cgroup cache
 		r.cgroupIDToPodID[container.CgroupID] = podID
-
-		// update the cgtracker map
-		if err := r.cgTrackerUpdateFunc(container.CgroupID, container.CgroupPath); err != nil {
-			return fmt.Errorf(
-				"failed to update cgroup tracker map for pod %s, container %s: %w",
-				pod.Meta.Name,
-				container.Name,
-				err,
-			)
-		}
+		delete(r.deletedContainers, container.CgroupID)
 	}
 
 	// we update back the cache
 	r.podCache[podID] = state
 
-	if err := r.applyPolicyToPodIfPresent(state); err != nil {
+	if err := r.applyPolicyToPodIfPresent(state, batch); err != nil {
 		return fmt.Errorf("failed to apply policy to pod: %w", err)
 	}
 	return nil
@@ -97,6 +277,8 @@ func (r *Resolver) RemovePodContainerFromNri(podID PodID, containerID ContainerI
 		return nil
 	}
 
+	unpinErr := r.unpinExecutables(state, container)
+	r.rememberDeletedContainer(container.CgroupID)
 	if len(state.containers) == 1 {
 		// if this was the last container, we need to remove the pod from the cache
 		delete(r.podCache, podID)
@@ -107,8 +289,31 @@ func (r *Resolver) RemovePodContainerFromNri(podID PodID, containerID ContainerI
 
 	// remove the cgroup ID from the cache
 	delete(r.cgroupIDToPodID, container.CgroupID)
+	delete(r.execBypasses, container.CgroupID)
+
+	return errors.Join(unpinErr,
+		r.cgroupToPolicyMapUpdateFunc(PolicyIDNone, []CgroupID{container.CgroupID}, bpf.RemoveCgroups))
+}
 
-	return r.cgroupToPolicyMapUpdateFunc(PolicyIDNone, []CgroupID{container.CgroupID}, bpf.RemoveCgroups)
+// HasPod reports whether the pod has containers in the cache.
+func (r *Resolver) HasPod(podID PodID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.podCache[podID]
+	return ok
+}
+
+// PodSandboxCgroupID returns the cgroup of the pause container of the pod, if it is known.
+func (r *Resolver) PodSandboxCgroupID(podID PodID) (CgroupID, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.podCache[podID]
+	if !ok || state.sandboxCgroupID == 0 {
+		return 0, false
+	}
+	return state.sandboxCgroupID, true
 }
 
 func (r *Resolver) NRISynchronized() {
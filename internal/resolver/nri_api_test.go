@@ -0,0 +1,70 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAddPodContainerFromNri_RestartInPlace(t *testing.T) {
+	r := NewTestResolver(t)
+	policyByCgroup := make(fakeCgroupPolicyMap)
+	r.cgroupToPolicyMapUpdateFunc = policyByCgroup.update
+	var trackedCgroups []CgroupID
+	r.cgTrackerUpdateFunc = func(cgID uint64, _ string) error {
+		trackedCgroups = append(trackedCgroups, cgID)
+		return nil
+	}
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:             "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	polID := r.wpState[wp.NamespacedName()].polByContainer[c1]
+
+	podWithCgroup := func(cgID CgroupID, name string) PodInput {
+		return PodInput{
+			Meta: PodMeta{
+				ID:        "test-pod-uid",
+				Namespace: "test-ns",
+				Name:      "test-pod",
+				Labels:    map[string]string{v1alpha1.PolicyLabelKey: "test-policy"},
+			},
+			Containers: map[ContainerID]ContainerInput{
+				cid1: {ContainerMeta: ContainerMeta{CgroupID: cgID, Name: name, ID: cid1}},
+			},
+		}
+	}
+
+	require.NoError(t, r.AddPodContainerFromNri(podWithCgroup(100, c1)))
+	require.Equal(t, fakeCgroupPolicyMap{100: polID}, policyByCgroup)
+
+	// receiving the same container again, e.g. after an NRI reconnection, is a no-op.
+	require.NoError(t, r.AddPodContainerFromNri(podWithCgroup(100, c1)))
+	require.Equal(t, []CgroupID{100}, trackedCgroups)
+
+	// the container restarts in place: same ID, new cgroup.
+	require.NoError(t, r.AddPodContainerFromNri(podWithCgroup(200, c1)))
+	require.Equal(t, fakeCgroupPolicyMap{200: polID}, policyByCgroup)
+	require.Equal(t, []CgroupID{100, 200}, trackedCgroups)
+
+	view, err := r.GetContainerView(200)
+	require.NoError(t, err)
+	require.Equal(t, cid1, view.Meta.ID)
+	_, err = r.GetContainerView(100)
+	require.Error(t, err)
+
+	// a different container name for the same ID is still rejected.
+	require.ErrorContains(t, r.AddPodContainerFromNri(podWithCgroup(300, c2)), "already exists")
+	require.Equal(t, fakeCgroupPolicyMap{200: polID}, policyByCgroup)
+
+	// removing the container detaches its current cgroup.
+	require.NoError(t, r.RemovePodContainerFromNri("test-pod-uid", cid1))
+	require.Empty(t, policyByCgroup)
+}
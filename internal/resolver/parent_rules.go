@@ -0,0 +1,52 @@
+package resolver
+
+import (
+	"slices"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+)
+
+// parentRulesByContainer collects the executables allowed only under specific parents for each container.
+func parentRulesByContainer(wp *v1alpha1.WorkloadPolicy) map[ContainerName]map[string][]string {
+	var parentRules map[ContainerName]map[string][]string
+	for containerName, rules := range wp.Spec.RulesByContainer {
+		if rules == nil || len(rules.Executables.AllowedWithParent) == 0 {
+			continue
+		}
+		if parentRules == nil {
+			parentRules = make(map[ContainerName]map[string][]string)
+		}
+		byExecutable := make(map[string][]string, len(rules.Executables.AllowedWithParent))
+		for _, exe := range rules.Executables.AllowedWithParent {
+			byExecutable[exe.Path] = append(byExecutable[exe.Path], exe.Parents...)
+		}
+		parentRules[containerName] = byExecutable
+	}
+	return parentRules
+}
+
+// IsAllowedByParent reports whether the policy of the container tracked by cgID allows
+// exePath to run when it is executed by parentExePath.
+func (r *Resolver) IsAllowedByParent(cgID CgroupID, exePath, parentExePath string) bool {
+	if parentExePath == "" {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pod, ok := r.podCache[r.cgroupIDToPodID[cgID]]
+	if !ok || pod.policyName() == "" {
+		return false
+	}
+	info := r.wpState[pod.podNamespace()+"/"+pod.policyName()]
+	if info == nil {
+		return false
+	}
+	for _, container := range pod.containers {
+		if container.CgroupID == cgID {
+			return slices.Contains(info.parentRules[container.Name][exePath], parentExePath)
+		}
+	}
+	return false
+}
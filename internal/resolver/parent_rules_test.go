@@ -0,0 +1,83 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsAllowedByParent(t *testing.T) {
+	r := NewTestResolver(t)
+	base := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "base", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "monitor",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c2: {Executables: v1alpha1.WorkloadPolicyExecutables{
+					AllowedWithParent: []v1alpha1.ExecutableWithParent{
+						{Path: "/bin/cat", Parents: []string{"/bin/sh"}},
+					},
+				}},
+			},
+		},
+	}
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:       "monitor",
+			BasePolicy: "base",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{
+					Allowed: []string{"/entrypoint.sh"},
+					AllowedWithParent: []v1alpha1.ExecutableWithParent{
+						{Path: "/bin/sh", Parents: []string{"/entrypoint.sh", "/usr/bin/tini"}},
+					},
+				}},
+			},
+		},
+	}
+
+	r.mu.Lock()
+	r.podCache["test-pod-uid"] = &podEntry{
+		meta: &PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		containers: map[ContainerID]*ContainerMeta{
+			cid1: {CgroupID: 100, Name: c1, ID: cid1},
+			cid2: {CgroupID: 101, Name: c2, ID: cid2},
+		},
+	}
+	r.cgroupIDToPodID[100] = "test-pod-uid"
+	r.cgroupIDToPodID[101] = "test-pod-uid"
+	r.mu.Unlock()
+
+	require.NoError(t, r.ReconcileWP(base))
+	require.NoError(t, r.ReconcileWP(wp))
+
+	tests := []struct {
+		name     string
+		cgID     CgroupID
+		exe      string
+		parent   string
+		expected bool
+	}{
+		{name: "allowed parent", cgID: 100, exe: "/bin/sh", parent: "/entrypoint.sh", expected: true},
+		{name: "second allowed parent", cgID: 100, exe: "/bin/sh", parent: "/usr/bin/tini", expected: true},
+		{name: "other parent", cgID: 100, exe: "/bin/sh", parent: "/usr/bin/bash"},
+		{name: "unknown parent", cgID: 100, exe: "/bin/sh"},
+		{name: "executable without parent rules", cgID: 100, exe: "/bin/cat", parent: "/entrypoint.sh"},
+		{name: "rules inherited from the base policy", cgID: 101, exe: "/bin/cat", parent: "/bin/sh", expected: true},
+		{name: "rules of another container", cgID: 101, exe: "/bin/sh", parent: "/entrypoint.sh"},
+		{name: "unknown cgroup", cgID: 200, exe: "/bin/sh", parent: "/entrypoint.sh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, r.IsAllowedByParent(tt.cgID, tt.exe, tt.parent))
+		})
+	}
+}
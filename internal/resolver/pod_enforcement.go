@@ -0,0 +1,64 @@
+package resolver
+
+import (
+	"slices"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+)
+
+// PodEnforcement is the view of the agent on the enforcement of a pod.
+type PodEnforcement struct {
+	ID        PodID
+	Namespace string
+	Name      string
+	// Policy is the name of the policy enforced on the pod, empty when the pod is not enforced.
+	Policy string
+	// Mode is the mode currently enforced, it differs from the one of the policy spec
+	// outside of its active windows or during an enforcement override.
+	Mode string
+	// Containers are the enforced containers, sorted by name.
+	Containers []ContainerName
+	// BypassedContainers are the containers detached from the policy by an exec bypass, sorted by name.
+	BypassedContainers []ContainerName
+}
+
+// PodEnforcements returns how every pod of the cache is enforced.
+func (r *Resolver) PodEnforcements() []PodEnforcement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enforcements := make([]PodEnforcement, 0, len(r.podCache))
+	for podID, pod := range r.podCache {
+		enforcement := PodEnforcement{
+			ID:        podID,
+			Namespace: pod.podNamespace(),
+			Name:      pod.podName(),
+		}
+		info := r.wpState[pod.podNamespace()+"/"+pod.policyName()]
+		if pod.policyName() == "" || info == nil || info.policy == nil ||
+			!pod.matchPodIndexes(info.policy.Spec.PodIndexes) {
+			enforcements = append(enforcements, enforcement)
+			continue
+		}
+
+		enforcement.Policy = pod.policyName()
+		enforcement.Mode = info.policy.Spec.Mode
+		if info.enforcedMode == policymode.Monitor || info.enforcedMode == policymode.Protect {
+			enforcement.Mode = info.enforcedMode.String()
+		}
+		for _, container := range pod.containers {
+			if _, listed := info.polByContainer[container.Name]; !listed && !r.unlistedPolicyApplies(info, container) {
+				continue
+			}
+			if _, bypassed := r.execBypasses[container.CgroupID]; bypassed {
+				enforcement.BypassedContainers = append(enforcement.BypassedContainers, container.Name)
+				continue
+			}
+			enforcement.Containers = append(enforcement.Containers, container.Name)
+		}
+		slices.Sort(enforcement.Containers)
+		slices.Sort(enforcement.BypassedContainers)
+		enforcements = append(enforcements, enforcement)
+	}
+	return enforcements
+}
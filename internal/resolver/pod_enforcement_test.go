@@ -0,0 +1,80 @@
+package resolver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodEnforcements(t *testing.T) {
+	r := NewTestResolver(t)
+	r.cgroupToPolicyMapUpdateFunc = make(fakeCgroupPolicyMap).update
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:             "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.NoError(t, r.AddPodsFromNri([]PodInput{
+		{
+			Meta: PodMeta{
+				ID:        "enforced-uid",
+				Namespace: "test-ns",
+				Name:      "enforced",
+				Labels:    map[string]string{v1alpha1.PolicyLabelKey: "policy"},
+			},
+			Containers: map[ContainerID]ContainerInput{
+				"c1-id":    {ContainerMeta: ContainerMeta{CgroupID: 100, Name: c1, ID: "c1-id"}},
+				"c2-id":    {ContainerMeta: ContainerMeta{CgroupID: 101, Name: c2, ID: "c2-id"}},
+				"debug-id": {ContainerMeta: ContainerMeta{CgroupID: 102, Name: "debug", ID: "debug-id"}},
+			},
+		},
+		{
+			Meta: PodMeta{ID: "unlabeled-uid", Namespace: "test-ns", Name: "unlabeled"},
+			Containers: map[ContainerID]ContainerInput{
+				"c3-id": {ContainerMeta: ContainerMeta{CgroupID: 200, Name: c1, ID: "c3-id"}},
+			},
+		},
+	}))
+
+	enforcementOf := func(podID PodID) PodEnforcement {
+		for _, enforcement := range r.PodEnforcements() {
+			if enforcement.ID == podID {
+				return enforcement
+			}
+		}
+		t.Fatalf("pod %s not found", podID)
+		return PodEnforcement{}
+	}
+
+	// only the containers with rules are enforced.
+	require.Equal(t, PodEnforcement{
+		ID:         "enforced-uid",
+		Namespace:  "test-ns",
+		Name:       "enforced",
+		Policy:     "policy",
+		Mode:       "protect",
+		Containers: []ContainerName{c1},
+	}, enforcementOf("enforced-uid"))
+	require.Equal(t, PodEnforcement{ID: "unlabeled-uid", Namespace: "test-ns", Name: "unlabeled"},
+		enforcementOf("unlabeled-uid"))
+
+	// with the unlisted containers denied, every container is enforced but the bypassed ones.
+	wp.Spec.UnlistedContainerPolicy = v1alpha1.UnlistedContainerDeny
+	require.NoError(t, r.ReconcileWP(wp))
+	_, err := r.GrantExecBypass("test-ns", "enforced", "debug", time.Minute)
+	require.NoError(t, err)
+	enforcement := enforcementOf("enforced-uid")
+	require.Equal(t, []ContainerName{c1, c2}, enforcement.Containers)
+	require.Equal(t, []ContainerName{"debug"}, enforcement.BypassedContainers)
+
+	// the enforced mode is reported, not the one of the spec.
+	r.SetEnforcementOverride(true)
+	require.Equal(t, "monitor", enforcementOf("enforced-uid").Mode)
+}
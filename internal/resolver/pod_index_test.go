@@ -0,0 +1,68 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPolicyPodIndexes(t *testing.T) {
+	r := NewTestResolver(t)
+	policyByCgroup := make(fakeCgroupPolicyMap)
+	r.cgroupToPolicyMapUpdateFunc = policyByCgroup.update
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sts-policy", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:             "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+			PodIndexes:       []int32{0},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	polID := r.wpState[wp.NamespacedName()].polByContainer[c1]
+
+	addPod := func(name string, cgID CgroupID, podIndex string) {
+		labels := map[string]string{v1alpha1.PolicyLabelKey: "sts-policy"}
+		if podIndex != "" {
+			labels[appsv1.PodIndexLabel] = podIndex
+		}
+		require.NoError(t, r.AddPodContainerFromNri(PodInput{
+			Meta: PodMeta{ID: name + "-uid", Namespace: "test-ns", Name: name, Labels: labels},
+			Containers: map[ContainerID]ContainerInput{
+				name + "-c1": {ContainerMeta: ContainerMeta{CgroupID: cgID, Name: c1, ID: name + "-c1"}},
+			},
+		}))
+	}
+	addPod("sts-0", 100, "0")
+	addPod("sts-1", 101, "1")
+	addPod("no-index", 102, "")
+
+	// only the pod with index 0 is enforced.
+	require.Equal(t, fakeCgroupPolicyMap{100: polID}, policyByCgroup)
+
+	// the policy now targets the pod with index 1 instead.
+	wp.Spec.PodIndexes = []int32{1}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, fakeCgroupPolicyMap{101: polID}, policyByCgroup)
+
+	// without indexes every pod is enforced.
+	wp.Spec.PodIndexes = nil
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, fakeCgroupPolicyMap{
+		100: polID,
+		101: polID,
+		102: polID,
+	}, policyByCgroup)
+
+	// narrowing the indexes again detaches the other pods.
+	wp.Spec.PodIndexes = []int32{0}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, fakeCgroupPolicyMap{100: polID}, policyByCgroup)
+
+	require.NoError(t, r.HandleWPDelete(wp))
+	require.Empty(t, policyByCgroup)
+}
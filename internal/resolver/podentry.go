@@ -2,14 +2,24 @@ package resolver
 
 import (
 	"maps"
+	"slices"
+	"strconv"
 
 	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
 )
 
 // podEntry is the internal representation of a pod inside our cache.
 type podEntry struct {
 	meta       *PodMeta
 	containers map[ContainerID]*ContainerMeta
+	// defaultPolicy is the default policy of the pod namespace, used when the pod has no policy label.
+	defaultPolicy string
+	// verified maps the containers whose files were hashed against the allowedHashes rules of their policy to
+	// those rules, as returned by hashRulesKey. The matching files of these containers are pinned in BPF.
+	verified map[ContainerID]string
+	// sandboxCgroupID is the cgroup of the pause container, 0 when it is not tracked or unknown.
+	sandboxCgroupID CgroupID
 }
 
 func (pod *podEntry) matchPolicy(policyName, policyNamespace string) bool {
@@ -17,10 +27,51 @@ func (pod *podEntry) matchPolicy(policyName, policyNamespace string) bool {
 	return pod.policyName() == policyName && pod.podNamespace() == policyNamespace
 }
 
+// matchPodIndexes reports whether the pod ordinal is one of the given indexes.
+// Every pod matches an empty list, pods without an ordinal only match an empty list.
+func (pod *podEntry) matchPodIndexes(indexes []int32) bool {
+	if len(indexes) == 0 {
+		return true
+	}
+	index, err := strconv.ParseInt(pod.meta.Labels[appsv1.PodIndexLabel], 10, 32)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(indexes, int32(index))
+}
+
 func (pod *podEntry) policyName() string {
+	if name := pod.labeledPolicyName(); name != "" {
+		return name
+	}
+	return pod.defaultPolicy
+}
+
+// labeledPolicyName returns the policy explicitly requested by the pod label.
+func (pod *podEntry) labeledPolicyName() string {
 	return pod.meta.Labels[v1alpha1.PolicyLabelKey]
 }
 
+// hasCgroup reports whether one of the containers of the pod runs in the given cgroup.
+func (pod *podEntry) hasCgroup(cgroupID CgroupID) bool {
+	for _, container := range pod.containers {
+		if container.CgroupID == cgroupID {
+			return true
+		}
+	}
+	return false
+}
+
+// hasContainer reports whether the pod has a container with the given name.
+func (pod *podEntry) hasContainer(name ContainerName) bool {
+	for _, container := range pod.containers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (pod *podEntry) podName() string {
 	return pod.meta.Name
 }
@@ -31,12 +82,14 @@ func (pod *podEntry) podNamespace() string {
 
 func (pod *podEntry) toView() PodView {
 	view := PodView{
-		Meta:       *pod.meta,
-		Containers: make(map[ContainerID]ContainerMeta),
+		Meta:            *pod.meta,
+		Containers:      make(map[ContainerID]ContainerMeta),
+		SandboxCgroupID: pod.sandboxCgroupID,
 	}
 	// We need a deep copy
 	view.Meta.Labels = make(map[string]string, len(pod.meta.Labels))
 	maps.Copy(view.Meta.Labels, pod.meta.Labels)
+	view.Meta.Annotations = maps.Clone(pod.meta.Annotations)
 	for id, meta := range pod.containers {
 		view.Containers[id] = *meta
 	}
@@ -1,6 +1,7 @@
 package resolver
 
 import (
+	"errors"
 	"fmt"
 	"maps"
 
@@ -20,11 +21,31 @@ type PolicyStatus struct {
 	State   agentv1.PolicyState
 	Mode    agentv1.PolicyMode
 	Message string
+	// MatchedContainers is the number of containers of the pods of the node the policy applies to.
+	MatchedContainers uint32
+	// EnforcedContainers is the number of matched containers with the policy applied to their cgroup.
+	EnforcedContainers uint32
 }
 
 type wpInfo struct {
+	// policy is the last reconciled WorkloadPolicy, before merging the rules inherited from its base policy.
+	policy         *v1alpha1.WorkloadPolicy
 	polByContainer policyByContainer
-	status         PolicyStatus
+	// unlistedPolicyID is the deny-all policy applied to the containers without rules.
+	// It is PolicyIDNone when the unlisted containers are allowed.
+	unlistedPolicyID PolicyID
+	// parentRules maps, for each container, the executables allowed only under specific parents
+	// to the list of those parents.
+	parentRules map[ContainerName]map[string][]string
+	// hashRules are, for each container, the executables allowed only when their content matches a digest.
+	hashRules map[ContainerName][]v1alpha1.ExecutableHash
+	// fallbackPolicyID is the deny-all policy applied to the containers of a "fail-closed" policy
+	// while it fails to load. It is PolicyIDNone when the policy is loaded.
+	fallbackPolicyID PolicyID
+	// enforcedMode is the mode currently applied in BPF, it differs from the spec one
+	// when a protect policy is outside of its active windows.
+	enforcedMode policymode.Mode
+	status       PolicyStatus
 }
 
 const (
@@ -43,13 +64,16 @@ func (r *Resolver) allocPolicyID() PolicyID {
 // This must be called with the resolver lock held.
 func (r *Resolver) upsertPolicyIDInBPF(
 	policyID PolicyID,
-	allowedBinaries []string,
+	allowedBinaries, allowedPrefixes []string,
 	mode policymode.Mode,
 	valuesOp bpf.PolicyValuesOperation,
 ) error {
 	if err := r.policyUpdateBinariesFunc(policyID, allowedBinaries, valuesOp); err != nil {
 		return err
 	}
+	if err := r.policyUpdatePrefixesFunc(policyID, allowedPrefixes, valuesOp); err != nil {
+		return err
+	}
 	if err := r.policyModeUpdateFunc(policyID, mode, bpf.UpdateMode); err != nil {
 		return err
 	}
@@ -64,6 +88,9 @@ func (r *Resolver) clearPolicyIDFromBPF(policyID PolicyID) error {
 	if err := r.policyUpdateBinariesFunc(policyID, nil, bpf.RemoveValuesFromPolicy); err != nil {
 		return err
 	}
+	if err := r.policyUpdatePrefixesFunc(policyID, nil, bpf.RemoveValuesFromPolicy); err != nil {
+		return err
+	}
 	// TODO: refactor the PolicyModeUpdateFunc to not collapse the update and delete operations
 	// behind the same API. By doing that we will not need to pass a dummy mode value here.
 	if err := r.policyModeUpdateFunc(policyID, 0, bpf.DeleteMode); err != nil {
@@ -74,23 +101,16 @@ func (r *Resolver) clearPolicyIDFromBPF(policyID PolicyID) error {
 
 // applyPolicyToPod applies the given policy-by-container (add/update) to the pod's cgroups.
 // This must be called with the resolver lock held.
-func (r *Resolver) applyPolicyToPod(state *podEntry, applied policyByContainer) error {
+// The cgroups are only collected in the batch, the caller flushes it.
+func (r *Resolver) applyPolicyToPod(state *podEntry, info *wpInfo, applied policyByContainer, batch cgroupBatch) {
 	for _, container := range state.containers {
 		polID, ok := applied[container.Name]
 		if !ok {
 			// No entry for this container: either not in policy, or unchanged.
 			continue
 		}
-		if err := r.cgroupToPolicyMapUpdateFunc(
-			polID,
-			[]CgroupID{container.CgroupID},
-			bpf.AddPolicyToCgroups,
-		); err != nil {
-			return fmt.Errorf("failed to add policy to cgroups for pod %s, container %s, policy %s: %w",
-				state.podName(), container.Name, state.policyName(), err)
-		}
+		batch.add(polID, container.CgroupID)
 	}
-	return nil
 }
 
 // removePolicyFromPod removes cgroup→policyID associations for the given containers in the pod.
@@ -120,8 +140,9 @@ func (r *Resolver) removePolicyFromPod(
 	return nil
 }
 
+// The cgroups of the pod are collected in the batch, the caller flushes it.
 // this must be called with the resolver lock held.
-func (r *Resolver) applyPolicyToPodIfPresent(state *podEntry) error {
+func (r *Resolver) applyPolicyToPodIfPresent(state *podEntry, batch cgroupBatch) error {
 	policyName := state.policyName()
 
 	// if the pod doesn't have the label we do nothing
@@ -131,6 +152,15 @@ func (r *Resolver) applyPolicyToPodIfPresent(state *podEntry) error {
 
 	key := fmt.Sprintf("%s/%s", state.podNamespace(), policyName)
 	info := r.wpState[key]
+	if info == nil && state.labeledPolicyName() == "" {
+		// The default policy of the namespace is applied as soon as it is reconciled.
+		r.logger.Warn("namespace default policy not found",
+			"pod", state.podName(),
+			"namespace", state.podNamespace(),
+			"policy", policyName,
+		)
+		return nil
+	}
 	if info == nil {
 		// We couldn't find the policy associated to this pod.
 		//
@@ -147,8 +177,84 @@ func (r *Resolver) applyPolicyToPodIfPresent(state *podEntry) error {
 			policyName,
 		)
 	}
+	if !state.matchPodIndexes(info.policy.Spec.PodIndexes) {
+		return nil
+	}
+	if info.fallbackPolicyID != PolicyIDNone {
+		// The policy failed to load, its own policies may be incomplete.
+		r.applyFallbackPolicyToPod(state, info, batch)
+		return nil
+	}
 
-	return r.applyPolicyToPod(state, info.polByContainer)
+	r.applyPolicyToPod(state, info, info.polByContainer, batch)
+	r.applyUnlistedPolicyToPod(state, info, batch)
+	return nil
+}
+
+// syncUnlistedPolicy creates, updates or removes the deny-all policy used for the containers without rules.
+// This must be called with the resolver lock held.
+func (r *Resolver) syncUnlistedPolicy(wp *v1alpha1.WorkloadPolicy, info *wpInfo) error {
+	wpKey := wp.NamespacedName()
+	if wp.Spec.UnlistedContainerPolicy != v1alpha1.UnlistedContainerDeny {
+		if info.unlistedPolicyID == PolicyIDNone {
+			return nil
+		}
+		if err := r.cgroupToPolicyMapUpdateFunc(info.unlistedPolicyID, []CgroupID{}, bpf.RemovePolicy); err != nil {
+			return fmt.Errorf("failed to remove unlisted containers policy from cgroup map for wp %s: %w", wpKey, err)
+		}
+		if err := r.clearPolicyIDFromBPF(info.unlistedPolicyID); err != nil {
+			return fmt.Errorf("failed to clear unlisted containers policy for wp %s: %w", wpKey, err)
+		}
+		info.unlistedPolicyID = PolicyIDNone
+		return nil
+	}
+
+	op := bpf.ReplaceValuesInPolicy
+	if info.unlistedPolicyID == PolicyIDNone {
+		info.unlistedPolicyID = r.allocPolicyID()
+		r.logger.Info("create unlisted containers policy", "id", info.unlistedPolicyID, "wp", wpKey)
+		op = bpf.AddValuesToPolicy
+	}
+	// Only the global allow list: every other execution is reported or blocked depending on the mode.
+	err := r.upsertPolicyIDInBPF(info.unlistedPolicyID, r.withGlobalAllowList(nil), nil, info.enforcedMode, op)
+	if err != nil {
+		return fmt.Errorf("failed to populate unlisted containers policy for wp %s: %w", wpKey, err)
+	}
+	return nil
+}
+
+// applyUnlistedPolicyToPod applies the deny-all policy to the pod containers without rules.
+// This must be called with the resolver lock held.
+func (r *Resolver) applyUnlistedPolicyToPod(state *podEntry, info *wpInfo, batch cgroupBatch) {
+	if info.unlistedPolicyID == PolicyIDNone {
+		return
+	}
+	for _, container := range state.containers {
+		if r.unlistedPolicyApplies(info, container) {
+			batch.add(info.unlistedPolicyID, container.CgroupID)
+		}
+	}
+}
+
+// detachUnlistedPolicyFromPod removes the deny-all policy from the containers that just got rules,
+// so that their own policy can be applied.
+// This must be called with the resolver lock held.
+func (r *Resolver) detachUnlistedPolicyFromPod(state *podEntry, info *wpInfo, newContainers policyByContainer) error {
+	if info.unlistedPolicyID == PolicyIDNone || len(newContainers) == 0 {
+		return nil
+	}
+	for _, container := range state.containers {
+		if _, ok := newContainers[container.Name]; !ok {
+			continue
+		}
+		if err := r.cgroupToPolicyMapUpdateFunc(
+			PolicyIDNone, []CgroupID{container.CgroupID}, bpf.RemoveCgroups,
+		); err != nil {
+			return fmt.Errorf("failed to remove cgroups for pod %s, container %s, policy %s: %w",
+				state.podName(), container.Name, state.policyName(), err)
+		}
+	}
+	return nil
 }
 
 // syncWorkloadPolicy ensures state and BPF maps match wp.Spec.RulesByContainer:
@@ -157,9 +263,9 @@ func (r *Resolver) applyPolicyToPodIfPresent(state *podEntry) error {
 // This must be called with the resolver lock held.
 func (r *Resolver) syncWorkloadPolicy(wp *v1alpha1.WorkloadPolicy) (policyByContainer, error) {
 	wpKey := wp.NamespacedName()
-	mode := policymode.ParseMode(wp.Spec.Mode)
 	// info is not nil. The caller must ensure the policy exists in wpState before calling.
 	info := r.wpState[wpKey]
+	mode := info.enforcedMode
 	newContainers := make(policyByContainer)
 
 	for containerName, containerRules := range wp.Spec.RulesByContainer {
@@ -173,7 +279,9 @@ func (r *Resolver) syncWorkloadPolicy(wp *v1alpha1.WorkloadPolicy) (policyByCont
 				"container", containerName)
 			op = bpf.AddValuesToPolicy
 		}
-		if err := r.upsertPolicyIDInBPF(polID, containerRules.Executables.Allowed, mode, op); err != nil {
+		allowed := r.withGlobalAllowList(containerRules.Executables.Allowed)
+		prefixes := containerRules.Executables.AllowedPrefixes
+		if err := r.upsertPolicyIDInBPF(polID, allowed, prefixes, mode, op); err != nil {
 			return nil, fmt.Errorf("failed to populate policy for wp %s, container %s: %w", wpKey, containerName, err)
 		}
 	}
@@ -183,6 +291,7 @@ func (r *Resolver) syncWorkloadPolicy(wp *v1alpha1.WorkloadPolicy) (policyByCont
 
 // ReconcileWP enforces the workload policy from the current spec, removes containers
 // that are no longer in the spec, then applies policy to all matching pods.
+// Policies inheriting from this one are reconciled as well.
 func (r *Resolver) ReconcileWP(wp *v1alpha1.WorkloadPolicy) error {
 	r.logger.Info(
 		"reconcile wp-policy",
@@ -190,23 +299,50 @@ func (r *Resolver) ReconcileWP(wp *v1alpha1.WorkloadPolicy) error {
 		"mode", wp.Spec.Mode,
 	)
 	r.mu.Lock()
+	err := r.reconcileWP(wp.DeepCopy())
+	r.reconcileChildPolicies(wp)
+	r.mu.Unlock()
+	// The containers not verified against the current allowedHashes rules, e.g. the ones started before
+	// the policy, don't run the executables of these rules until their files are hashed.
+	return errors.Join(err, r.verifyPendingContainers())
+}
 
+// reconcileWP enforces the given workload policy merged with the rules inherited from its base policy.
+// This must be called with the resolver lock held.
+func (r *Resolver) reconcileWP(policy *v1alpha1.WorkloadPolicy) error {
 	var info *wpInfo
 	var err error
-	mode := policymode.ParsePolicyModeToProto(wp.Spec.Mode)
+	// limited is set when the policy is rejected by the cap on the loaded policies,
+	// it cannot fail closed since its fallback policy would exceed the cap as well.
+	limited := false
+	mode := policymode.ParsePolicyModeToProto(policy.Spec.Mode)
 	defer func() {
 		if err != nil && info != nil {
 			info.setPolicyStatus(agentv1.PolicyState_POLICY_STATE_ERROR, mode, err.Error())
+			if !limited {
+				r.handleLoadFailure(info)
+			}
 		}
-		r.mu.Unlock()
 	}()
 
-	wpKey := wp.NamespacedName()
+	wpKey := policy.NamespacedName()
 	info = r.wpState[wpKey]
 	if info == nil {
-		info = &wpInfo{polByContainer: make(policyByContainer, len(wp.Spec.RulesByContainer))}
+		info = &wpInfo{polByContainer: make(policyByContainer, len(policy.Spec.RulesByContainer))}
 		r.wpState[wpKey] = info
 	}
+	info.policy = policy
+	if err = r.checkPolicyLimit(info); err != nil {
+		limited = true
+		return err
+	}
+
+	wp := r.withInheritedRules(policy)
+	info.parentRules = parentRulesByContainer(wp)
+	info.hashRules = hashRulesByContainer(wp)
+	if info.enforcedMode, err = r.currentMode(wp); err != nil {
+		return err
+	}
 
 	var newContainers policyByContainer
 	if newContainers, err = r.syncWorkloadPolicy(wp); err != nil {
@@ -214,6 +350,14 @@ func (r *Resolver) ReconcileWP(wp *v1alpha1.WorkloadPolicy) error {
 	}
 	maps.Copy(info.polByContainer, newContainers)
 
+	if err = r.syncUnlistedPolicy(wp, info); err != nil {
+		return err
+	}
+
+	if err = r.releaseFallbackPolicy(info); err != nil {
+		return err
+	}
+
 	// Split state into applied (still in spec) vs removed (no longer in spec).
 	appliedMap := make(policyByContainer, len(wp.Spec.RulesByContainer))
 	removedMap := make(policyByContainer, len(info.polByContainer))
@@ -225,16 +369,30 @@ func (r *Resolver) ReconcileWP(wp *v1alpha1.WorkloadPolicy) error {
 		}
 	}
 
+	// The cgroups of all the pods are attached at once, after the stale associations are removed.
+	batch := make(cgroupBatch)
 	for _, podEntry := range r.podCache {
 		if !podEntry.matchPolicy(wp.Name, wp.Namespace) {
 			continue
 		}
+		if !podEntry.matchPodIndexes(wp.Spec.PodIndexes) {
+			// The pod could have been selected by a previous version of the policy.
+			if err = r.detachPolicyFromPod(podEntry); err != nil {
+				return err
+			}
+			continue
+		}
 		if err = r.removePolicyFromPod(wpKey, podEntry, info.polByContainer, removedMap); err != nil {
 			return err
 		}
-		if err = r.applyPolicyToPod(podEntry, appliedMap); err != nil {
+		if err = r.detachUnlistedPolicyFromPod(podEntry, info, newContainers); err != nil {
 			return err
 		}
+		r.applyPolicyToPod(podEntry, info, appliedMap, batch)
+		r.applyUnlistedPolicyToPod(podEntry, info, batch)
+	}
+	if err = r.flushCgroupBatch(batch); err != nil {
+		return err
 	}
 	info.setPolicyStatus(agentv1.PolicyState_POLICY_STATE_READY, mode, "")
 	return nil
@@ -246,6 +404,14 @@ func (r *Resolver) HandleWPDelete(wp *v1alpha1.WorkloadPolicy) error {
 		"delete-wp-policy",
 		"wp", wp.NamespacedName(),
 	)
+	if err := r.deleteWP(wp); err != nil {
+		return err
+	}
+	// The containers of the deleted policy and of the policies inheriting from it lose its allowedHashes rules.
+	return r.verifyPendingContainers()
+}
+
+func (r *Resolver) deleteWP(wp *v1alpha1.WorkloadPolicy) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -272,6 +438,20 @@ func (r *Resolver) HandleWPDelete(wp *v1alpha1.WorkloadPolicy) error {
 			return fmt.Errorf("failed to clear policy for wp %s, container %s: %w", wpKey, containerName, err)
 		}
 	}
+	if info.unlistedPolicyID != PolicyIDNone {
+		if err := r.cgroupToPolicyMapUpdateFunc(info.unlistedPolicyID, []CgroupID{}, bpf.RemovePolicy); err != nil {
+			return fmt.Errorf("failed to remove unlisted containers policy from cgroup map: %w", err)
+		}
+		if err := r.clearPolicyIDFromBPF(info.unlistedPolicyID); err != nil {
+			return fmt.Errorf("failed to clear unlisted containers policy for wp %s: %w", wpKey, err)
+		}
+	}
+	if err := r.releaseFallbackPolicy(info); err != nil {
+		return err
+	}
+
+	// Policies inheriting from the deleted one fall back to their own rules.
+	r.reconcileChildPolicies(wp)
 	return nil
 }
 
@@ -280,10 +460,14 @@ func (r *Resolver) GetPolicyStatuses() map[NamespacedPolicyName]PolicyStatus {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	coverages := r.containerCoverages()
 	statuses := make(map[NamespacedPolicyName]PolicyStatus, len(r.wpState))
 	for k, v := range r.wpState {
 		if v != nil {
-			statuses[k] = v.status
+			status := v.status
+			status.MatchedContainers = coverages[k].matched
+			status.EnforcedContainers = coverages[k].enforced
+			statuses[k] = status
 		}
 	}
 	return statuses
@@ -0,0 +1,58 @@
+package resolver
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// policyCoverageCollector reports how many pods of the cache are matched by 0, 1, ... policies.
+type policyCoverageCollector struct {
+	resolver *Resolver
+	desc     *prometheus.Desc
+}
+
+var _ prometheus.Collector = &policyCoverageCollector{}
+
+// PolicyCoverageCollector returns the collector of the runtime_enforcer_pods_by_matched_policies gauges.
+// The pods are grouped by number of matched policies so that the cardinality doesn't grow with the pods,
+// the ones matched by 0 policies are not protected.
+func (r *Resolver) PolicyCoverageCollector() prometheus.Collector {
+	return &policyCoverageCollector{
+		resolver: r,
+		desc: prometheus.NewDesc(
+			"runtime_enforcer_pods_by_matched_policies",
+			"Number of pods tracked by the agent, by number of WorkloadPolicies matching them.",
+			[]string{"policies"},
+			nil,
+		),
+	}
+}
+
+func (c *policyCoverageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *policyCoverageCollector) Collect(ch chan<- prometheus.Metric) {
+	for matched, count := range c.resolver.podsByMatchedPolicies() {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), strconv.Itoa(matched))
+	}
+}
+
+// podsByMatchedPolicies counts the pods by number of policies matching them.
+// A pod is matched by the policy of its label, or else by the default policy of its namespace,
+// as long as this policy exists.
+func (r *Resolver) podsByMatchedPolicies() map[int]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := make(map[int]int)
+	for _, pod := range r.podCache {
+		matched := 0
+		if name := pod.policyName(); name != "" && r.wpState[pod.podNamespace()+"/"+name] != nil {
+			matched++
+		}
+		counts[matched]++
+	}
+	return counts
+}
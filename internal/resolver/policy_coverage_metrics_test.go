@@ -0,0 +1,47 @@
+package resolver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPolicyCoverageCollector(t *testing.T) {
+	r := NewTestResolver(t)
+	collector := r.PolicyCoverageCollector()
+	require.Zero(t, testutil.CollectAndCount(collector))
+
+	require.NoError(t, r.ReconcileWP(&v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: policymode.ProtectString,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				"container0": {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+			},
+		},
+	}))
+
+	for i, policyLabel := range []string{"example", "missing", ""} {
+		podID, pod := generateMockPodEntry(i)
+		if policyLabel != "" {
+			pod.meta.Labels[v1alpha1.PolicyLabelKey] = policyLabel
+		}
+		r.podCache[podID] = pod
+	}
+	// the pods without label are matched by the default policy of their namespace.
+	podID, pod := generateMockPodEntry(3)
+	pod.defaultPolicy = "example"
+	r.podCache[podID] = pod
+
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP runtime_enforcer_pods_by_matched_policies Number of pods tracked by the agent, by number of WorkloadPolicies matching them.
+# TYPE runtime_enforcer_pods_by_matched_policies gauge
+runtime_enforcer_pods_by_matched_policies{policies="0"} 2
+runtime_enforcer_pods_by_matched_policies{policies="1"} 2
+`)))
+}
@@ -0,0 +1,84 @@
+package resolver
+
+import (
+	"maps"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+)
+
+// mergeRulesByContainer returns the rules of base overridden by the ones of overrides.
+// A container defined in overrides replaces the whole rules of the same container in base.
+func mergeRulesByContainer(
+	base, overrides map[string]*v1alpha1.WorkloadPolicyRules,
+) map[string]*v1alpha1.WorkloadPolicyRules {
+	merged := make(map[string]*v1alpha1.WorkloadPolicyRules, len(base)+len(overrides))
+	maps.Copy(merged, base)
+	maps.Copy(merged, overrides)
+	return merged
+}
+
+// inheritedRulesByContainer merges the rules of the policy with the ones of its base policy.
+// The rules of the policy with the higher priority win, the ones of the child policy on a tie.
+func inheritedRulesByContainer(base, child *v1alpha1.WorkloadPolicy) map[string]*v1alpha1.WorkloadPolicyRules {
+	if base.Spec.Priority > child.Spec.Priority {
+		return mergeRulesByContainer(child.Spec.RulesByContainer, base.Spec.RulesByContainer)
+	}
+	return mergeRulesByContainer(base.Spec.RulesByContainer, child.Spec.RulesByContainer)
+}
+
+func basePolicyKey(wp *v1alpha1.WorkloadPolicy) NamespacedPolicyName {
+	return wp.Namespace + "/" + wp.Spec.BasePolicy
+}
+
+// withInheritedRules returns the policy to enforce once the rules of the base policy are merged.
+// If the base policy is not known yet, only the rules of the policy itself are enforced,
+// the inherited ones will be applied as soon as the base policy is reconciled.
+// This must be called with the resolver lock held.
+func (r *Resolver) withInheritedRules(wp *v1alpha1.WorkloadPolicy) *v1alpha1.WorkloadPolicy {
+	if wp.Spec.BasePolicy == "" || wp.Spec.BasePolicy == wp.Name {
+		return wp
+	}
+
+	base := r.wpState[basePolicyKey(wp)]
+	if base == nil || base.policy == nil {
+		r.logger.Warn("base policy not found, enforcing only the policy rules",
+			"wp", wp.NamespacedName(),
+			"base", wp.Spec.BasePolicy,
+		)
+		return wp
+	}
+
+	effective := wp.DeepCopy()
+	effective.Spec.RulesByContainer = inheritedRulesByContainer(base.policy, wp)
+	return effective
+}
+
+// childPolicies returns the policies that inherit their rules from the given base policy.
+// This must be called with the resolver lock held.
+func (r *Resolver) childPolicies(base *v1alpha1.WorkloadPolicy) []*v1alpha1.WorkloadPolicy {
+	var children []*v1alpha1.WorkloadPolicy
+	for _, info := range r.wpState {
+		if info.policy == nil || info.policy.Name == base.Name {
+			continue
+		}
+		if info.policy.Namespace == base.Namespace && info.policy.Spec.BasePolicy == base.Name {
+			children = append(children, info.policy)
+		}
+	}
+	return children
+}
+
+// reconcileChildPolicies re-applies the policies inheriting from the given base policy,
+// so that changes on the base are propagated to them.
+// This must be called with the resolver lock held.
+func (r *Resolver) reconcileChildPolicies(base *v1alpha1.WorkloadPolicy) {
+	for _, child := range r.childPolicies(base) {
+		if err := r.reconcileWP(child); err != nil {
+			r.logger.Error("failed to propagate base policy to child policy",
+				"base", base.NamespacedName(),
+				"wp", child.NamespacedName(),
+				"error", err,
+			)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func rules(allowed ...string) *v1alpha1.WorkloadPolicyRules {
+	return &v1alpha1.WorkloadPolicyRules{
+		Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: allowed},
+	}
+}
+
+func TestMergeRulesByContainer(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      map[string]*v1alpha1.WorkloadPolicyRules
+		overrides map[string]*v1alpha1.WorkloadPolicyRules
+		expected  map[string]*v1alpha1.WorkloadPolicyRules
+	}{
+		{
+			name:      "no base",
+			overrides: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+			expected:  map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+		},
+		{
+			name:     "no overrides",
+			base:     map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+			expected: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+		},
+		{
+			name:      "containers are inherited from the base",
+			base:      map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+			overrides: map[string]*v1alpha1.WorkloadPolicyRules{c2: rules("/bin/cat")},
+			expected: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: rules("/bin/sleep"),
+				c2: rules("/bin/cat"),
+			},
+		},
+		{
+			name: "child containers override the base ones",
+			base: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: rules("/bin/sleep", "/bin/sh"),
+				c2: rules("/bin/cat"),
+			},
+			overrides: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/ls")},
+			expected: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: rules("/bin/ls"),
+				c2: rules("/bin/cat"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, mergeRulesByContainer(tt.base, tt.overrides))
+		})
+	}
+}
+
+func TestReconcileWP_BasePolicy(t *testing.T) {
+	r := NewTestResolver(t)
+	allowedByPolicyID := make(map[PolicyID][]string)
+	r.policyUpdateBinariesFunc = func(policyID PolicyID, values []string, op bpf.PolicyValuesOperation) error {
+		if op == bpf.RemoveValuesFromPolicy {
+			delete(allowedByPolicyID, policyID)
+			return nil
+		}
+		allowedByPolicyID[policyID] = values
+		return nil
+	}
+
+	base := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "base", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "monitor",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: rules("/bin/sleep"),
+				c2: rules("/bin/cat"),
+			},
+		},
+	}
+	child := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "child", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:       "protect",
+			BasePolicy: "base",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c2: rules("/bin/ls"),
+			},
+		},
+	}
+	// Containers removed from a policy are detached from the matching pods.
+	r.mu.Lock()
+	r.podCache["test-pod-uid"] = &podEntry{
+		meta: &PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "child"},
+		},
+		containers: map[ContainerID]*ContainerMeta{
+			cid1: {CgroupID: 100, Name: c1, ID: cid1},
+			cid2: {CgroupID: 101, Name: c2, ID: cid2},
+			cid3: {CgroupID: 102, Name: c3, ID: cid3},
+		},
+	}
+	r.mu.Unlock()
+
+	childAllowed := func() map[ContainerName][]string {
+		allowed := make(map[ContainerName][]string)
+		for containerName, policyID := range r.wpState[child.NamespacedName()].polByContainer {
+			allowed[containerName] = allowedByPolicyID[policyID]
+		}
+		return allowed
+	}
+
+	// The child is reconciled before its base: only its own rules are enforced.
+	require.NoError(t, r.ReconcileWP(child))
+	require.Equal(t, map[ContainerName][]string{c2: {"/bin/ls"}}, childAllowed())
+
+	// Once the base is known, the inherited rules are applied to the child.
+	require.NoError(t, r.ReconcileWP(base))
+	require.Equal(t, map[ContainerName][]string{
+		c1: {"/bin/sleep"},
+		c2: {"/bin/ls"},
+	}, childAllowed())
+
+	// Changing the base propagates to the child.
+	base.Spec.RulesByContainer[c1] = rules("/bin/sleep", "/bin/echo")
+	base.Spec.RulesByContainer[c3] = rules("/bin/true")
+	require.NoError(t, r.ReconcileWP(base))
+	require.Equal(t, map[ContainerName][]string{
+		c1: {"/bin/sleep", "/bin/echo"},
+		c2: {"/bin/ls"},
+		c3: {"/bin/true"},
+	}, childAllowed())
+
+	// Deleting the base leaves the child with its own rules.
+	require.NoError(t, r.HandleWPDelete(base))
+	require.Equal(t, map[ContainerName][]string{c2: {"/bin/ls"}}, childAllowed())
+}
+
+func TestInheritedRulesByContainer_Priority(t *testing.T) {
+	base := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "base", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: rules("/bin/sleep"),
+				c2: rules("/bin/cat"),
+			},
+		},
+	}
+	child := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "child", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			BasePolicy: "base",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c2: rules("/bin/ls"),
+				c3: rules("/bin/true"),
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		basePriority  int32
+		childPriority int32
+		expected      map[string]*v1alpha1.WorkloadPolicyRules
+	}{
+		{
+			name: "the child wins on a tie",
+			expected: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: rules("/bin/sleep"),
+				c2: rules("/bin/ls"),
+				c3: rules("/bin/true"),
+			},
+		},
+		{
+			name:          "the child wins with a higher priority",
+			basePriority:  -1,
+			childPriority: 10,
+			expected: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: rules("/bin/sleep"),
+				c2: rules("/bin/ls"),
+				c3: rules("/bin/true"),
+			},
+		},
+		{
+			name:         "the base wins with a higher priority",
+			basePriority: 10,
+			expected: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: rules("/bin/sleep"),
+				c2: rules("/bin/cat"),
+				c3: rules("/bin/true"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base.Spec.Priority = tt.basePriority
+			child.Spec.Priority = tt.childPriority
+			// the result doesn't depend on the order of the calls either.
+			for range 2 {
+				require.Equal(t, tt.expected, inheritedRulesByContainer(base, child))
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package resolver
+
+import "fmt"
+
+// SetMaxPolicies caps the number of policies loaded in the BPF maps, 0 means unlimited.
+// Once the cap is reached, the new policies are rejected with an error status instead of failing
+// their map writes halfway. They are loaded when they are reconciled again after others are deleted.
+// It must be called before any policy is reconciled.
+func (r *Resolver) SetMaxPolicies(limit int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxPolicies = limit
+}
+
+// holdsPolicyIDs reports whether the policy has policy IDs loaded in the BPF maps, its fallback one included.
+func (i *wpInfo) holdsPolicyIDs() bool {
+	return len(i.polByContainer) > 0 || i.unlistedPolicyID != PolicyIDNone ||
+		i.fallbackPolicyID != PolicyIDNone
+}
+
+// checkPolicyLimit returns an error when loading the policy would exceed the cap on the loaded policies.
+// The policies already loaded are always updated.
+// This must be called with the resolver lock held.
+func (r *Resolver) checkPolicyLimit(info *wpInfo) error {
+	if r.maxPolicies <= 0 || info.holdsPolicyIDs() {
+		return nil
+	}
+	loaded := 0
+	for _, other := range r.wpState {
+		if other.holdsPolicyIDs() {
+			loaded++
+		}
+	}
+	if loaded >= r.maxPolicies {
+		return fmt.Errorf("the agent already enforces the maximum of %d policies, delete other policies to load this one",
+			r.maxPolicies)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	agentv1 "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReconcileWP_MaxPolicies(t *testing.T) {
+	r := NewTestResolver(t)
+	r.SetMaxPolicies(2)
+
+	policy := func(name string) *v1alpha1.WorkloadPolicy {
+		return &v1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+			Spec: v1alpha1.WorkloadPolicySpec{
+				Mode:             "protect",
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+			},
+		}
+	}
+	first, second, third := policy("first"), policy("second"), policy("third")
+	require.NoError(t, r.ReconcileWP(first))
+	require.NoError(t, r.ReconcileWP(second))
+
+	// the policy beyond the cap is rejected without allocating any policy ID.
+	nextPolicyID := r.nextPolicyID
+	err := r.ReconcileWP(third)
+	require.ErrorContains(t, err, "maximum of 2 policies")
+	status := r.GetPolicyStatuses()[third.NamespacedName()]
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_ERROR, status.State)
+	require.Equal(t, err.Error(), status.Message)
+	require.Equal(t, nextPolicyID, r.nextPolicyID)
+
+	// the loaded policies can still be updated.
+	second.Spec.RulesByContainer[c2] = rules("/bin/ls")
+	require.NoError(t, r.ReconcileWP(second))
+
+	// once a policy is deleted, the rejected one is loaded when it is reconciled again.
+	require.NoError(t, r.HandleWPDelete(first))
+	require.NoError(t, r.ReconcileWP(third))
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_READY, r.GetPolicyStatuses()[third.NamespacedName()].State)
+	require.Empty(t, r.SelfCheck())
+}
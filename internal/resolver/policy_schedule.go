@@ -0,0 +1,171 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+)
+
+const (
+	activeWindowTimeLayout = "15:04"
+	// activeWindowsCheckInterval is how often the policies with active windows are re-evaluated.
+	// Windows have a minute granularity, so the mode switches at most this late.
+	activeWindowsCheckInterval = 15 * time.Second
+)
+
+// minuteOfDay parses a "HH:MM" string. The format is validated by the CRD.
+func minuteOfDay(s string) (int, error) {
+	t, err := time.Parse(activeWindowTimeLayout, s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: %w", s, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// windowContains reports whether now, in UTC, falls within the window.
+func windowContains(window v1alpha1.PolicyActiveWindow, now time.Time) (bool, error) {
+	start, err := minuteOfDay(window.Start)
+	if err != nil {
+		return false, err
+	}
+	end, err := minuteOfDay(window.End)
+	if err != nil {
+		return false, err
+	}
+
+	now = now.UTC()
+	minute := now.Hour()*60 + now.Minute()
+	startDay := now.Weekday()
+	switch {
+	case start < end:
+		if minute < start || minute >= end {
+			return false, nil
+		}
+	case start > end:
+		// the window goes past midnight: after midnight it belongs to the window started the day before.
+		if minute < start && minute >= end {
+			return false, nil
+		}
+		if minute < end {
+			startDay = now.AddDate(0, 0, -1).Weekday()
+		}
+	default:
+		// start == end: the window spans the whole day.
+	}
+	return len(window.Days) == 0 || slices.Contains(window.Days, startDay.String()), nil
+}
+
+// enforcedMode returns the mode to apply in BPF at the given time.
+// A policy in protect mode only monitors outside of its active windows.
+func enforcedMode(wp *v1alpha1.WorkloadPolicy, now time.Time) (policymode.Mode, error) {
+	mode := policymode.ParseMode(wp.Spec.Mode)
+	if mode != policymode.Protect || len(wp.Spec.ActiveWindows) == 0 {
+		return mode, nil
+	}
+	for _, window := range wp.Spec.ActiveWindows {
+		active, err := windowContains(window, now)
+		if err != nil {
+			return 0, err
+		}
+		if active {
+			return policymode.Protect, nil
+		}
+	}
+	return policymode.Monitor, nil
+}
+
+// policyIDs returns all the policy IDs of the workload policy.
+func (i *wpInfo) policyIDs() []PolicyID {
+	ids := make([]PolicyID, 0, len(i.polByContainer)+2)
+	for _, id := range i.polByContainer {
+		ids = append(ids, id)
+	}
+	if i.unlistedPolicyID != PolicyIDNone {
+		ids = append(ids, i.unlistedPolicyID)
+	}
+	if i.fallbackPolicyID != PolicyIDNone {
+		ids = append(ids, i.fallbackPolicyID)
+	}
+	return ids
+}
+
+// currentMode returns the mode to apply in BPF for the policy now.
+// This must be called with the resolver lock held.
+func (r *Resolver) currentMode(wp *v1alpha1.WorkloadPolicy) (policymode.Mode, error) {
+	if r.enforcementOverride {
+		return policymode.Monitor, nil
+	}
+	return enforcedMode(wp, r.now())
+}
+
+// refreshEnforcedModes switches the policies between protect and monitor mode, following their active windows
+// and the enforcement override.
+// This must be called with the resolver lock held.
+func (r *Resolver) refreshEnforcedModes() {
+	for wpKey, info := range r.wpState {
+		if info.policy == nil {
+			continue
+		}
+		mode, err := r.currentMode(info.policy)
+		if err != nil {
+			r.logger.Error("failed to evaluate active windows", "wp", wpKey, "error", err)
+			continue
+		}
+		if mode == info.enforcedMode {
+			continue
+		}
+		r.logger.Info("switching policy mode", "wp", wpKey, "mode", mode)
+		failed := false
+		for _, policyID := range info.policyIDs() {
+			if err = r.policyModeUpdateFunc(policyID, mode, bpf.UpdateMode); err != nil {
+				r.logger.Error("failed to update policy mode", "wp", wpKey, "id", policyID, "error", err)
+				failed = true
+			}
+		}
+		// on failure the switch is retried at the next check.
+		if !failed {
+			info.enforcedMode = mode
+		}
+	}
+}
+
+// SetEnforcementOverride forces every policy in monitor mode while enabled, and restores their mode otherwise.
+// It returns whether the override was switched by this call.
+func (r *Resolver) SetEnforcementOverride(enabled bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.enforcementOverride == enabled {
+		return false
+	}
+	if enabled {
+		r.logger.Warn("enforcement override enabled, all the policies are switched to monitor mode")
+	} else {
+		r.logger.Info("enforcement override disabled, restoring the mode of the policies")
+	}
+	r.enforcementOverride = enabled
+	r.refreshEnforcedModes()
+	return true
+}
+
+// RunActiveWindows periodically switches the mode of the policies with active windows until ctx is done.
+// The switches that failed, e.g. for the enforcement override, are retried as well.
+func (r *Resolver) RunActiveWindows(ctx context.Context) error {
+	ticker := time.NewTicker(activeWindowsCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.mu.Lock()
+			r.refreshEnforcedModes()
+			r.mu.Unlock()
+		}
+	}
+}
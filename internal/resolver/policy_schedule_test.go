@@ -0,0 +1,208 @@
+package resolver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWindowContains(t *testing.T) {
+	// 2026-06-01 is a Monday.
+	at := func(day int, hour, minute int) time.Time {
+		return time.Date(2026, time.June, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		window   v1alpha1.PolicyActiveWindow
+		now      time.Time
+		expected bool
+	}{
+		{
+			name:     "within the window",
+			window:   v1alpha1.PolicyActiveWindow{Start: "18:00", End: "20:00"},
+			now:      at(1, 19, 30),
+			expected: true,
+		},
+		{
+			name:     "end is excluded",
+			window:   v1alpha1.PolicyActiveWindow{Start: "18:00", End: "20:00"},
+			now:      at(1, 20, 0),
+			expected: false,
+		},
+		{
+			name:     "before the window",
+			window:   v1alpha1.PolicyActiveWindow{Start: "18:00", End: "20:00"},
+			now:      at(1, 17, 59),
+			expected: false,
+		},
+		{
+			name:     "window past midnight, before midnight",
+			window:   v1alpha1.PolicyActiveWindow{Start: "18:00", End: "08:00"},
+			now:      at(1, 23, 0),
+			expected: true,
+		},
+		{
+			name:     "window past midnight, after midnight",
+			window:   v1alpha1.PolicyActiveWindow{Start: "18:00", End: "08:00"},
+			now:      at(2, 7, 59),
+			expected: true,
+		},
+		{
+			name:     "window past midnight, during the day",
+			window:   v1alpha1.PolicyActiveWindow{Start: "18:00", End: "08:00"},
+			now:      at(2, 12, 0),
+			expected: false,
+		},
+		{
+			name:     "window starting on another day",
+			window:   v1alpha1.PolicyActiveWindow{Start: "18:00", End: "20:00", Days: []string{"Tuesday"}},
+			now:      at(1, 19, 0),
+			expected: false,
+		},
+		{
+			name:     "window past midnight belongs to the day it started",
+			window:   v1alpha1.PolicyActiveWindow{Start: "18:00", End: "08:00", Days: []string{"Monday"}},
+			now:      at(2, 7, 0),
+			expected: true,
+		},
+		{
+			name:     "same start and end spans the whole day",
+			window:   v1alpha1.PolicyActiveWindow{Start: "00:00", End: "00:00", Days: []string{"Saturday", "Sunday"}},
+			now:      at(7, 13, 0),
+			expected: true,
+		},
+		{
+			name:     "times are evaluated in UTC",
+			window:   v1alpha1.PolicyActiveWindow{Start: "18:00", End: "20:00"},
+			now:      at(1, 19, 0).In(time.FixedZone("UTC+5", 5*60*60)),
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active, err := windowContains(tt.window, tt.now)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, active)
+		})
+	}
+}
+
+func TestReconcileWP_ActiveWindows(t *testing.T) {
+	r := NewTestResolver(t)
+	now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	modeByPolicyID := make(map[PolicyID]policymode.Mode)
+	r.policyModeUpdateFunc = func(policyID PolicyID, mode policymode.Mode, op bpf.PolicyModeOperation) error {
+		if op == bpf.DeleteMode {
+			delete(modeByPolicyID, policyID)
+			return nil
+		}
+		modeByPolicyID[policyID] = mode
+		return nil
+	}
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "windowed", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: policymode.ProtectString,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: rules("/bin/sleep"),
+			},
+			UnlistedContainerPolicy: v1alpha1.UnlistedContainerDeny,
+			ActiveWindows:           []v1alpha1.PolicyActiveWindow{{Start: "18:00", End: "08:00"}},
+		},
+	}
+	requireMode := func(expected policymode.Mode) {
+		t.Helper()
+		info := r.wpState[wp.NamespacedName()]
+		require.Equal(t, expected, modeByPolicyID[info.polByContainer[c1]])
+		require.Equal(t, expected, modeByPolicyID[info.unlistedPolicyID])
+	}
+
+	// outside of the window, the policy only monitors.
+	require.NoError(t, r.ReconcileWP(wp))
+	requireMode(policymode.Monitor)
+	// the reported mode is still the one of the spec.
+	require.Equal(t, policymode.ParsePolicyModeToProto(policymode.ProtectString),
+		r.GetPolicyStatuses()[wp.NamespacedName()].Mode)
+
+	// the window starts.
+	now = now.Add(7 * time.Hour)
+	r.refreshEnforcedModes()
+	requireMode(policymode.Protect)
+
+	// the window ends the next morning.
+	now = now.Add(14 * time.Hour)
+	r.refreshEnforcedModes()
+	requireMode(policymode.Monitor)
+
+	// policies in monitor mode are never enforced.
+	wp.Spec.Mode = policymode.MonitorString
+	now = now.Add(12 * time.Hour)
+	require.NoError(t, r.ReconcileWP(wp))
+	requireMode(policymode.Monitor)
+	r.refreshEnforcedModes()
+	requireMode(policymode.Monitor)
+}
+
+func TestSetEnforcementOverride(t *testing.T) {
+	r := NewTestResolver(t)
+	modeByPolicyID := make(map[PolicyID]policymode.Mode)
+	r.policyModeUpdateFunc = func(policyID PolicyID, mode policymode.Mode, op bpf.PolicyModeOperation) error {
+		if op == bpf.DeleteMode {
+			delete(modeByPolicyID, policyID)
+			return nil
+		}
+		modeByPolicyID[policyID] = mode
+		return nil
+	}
+
+	protected := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "protected", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:             policymode.ProtectString,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+		},
+	}
+	monitored := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "monitored", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:             policymode.MonitorString,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+		},
+	}
+	requireMode := func(wp *v1alpha1.WorkloadPolicy, expected policymode.Mode) {
+		t.Helper()
+		require.Equal(t, expected, modeByPolicyID[r.wpState[wp.NamespacedName()].polByContainer[c1]])
+	}
+	require.NoError(t, r.ReconcileWP(protected))
+	require.NoError(t, r.ReconcileWP(monitored))
+	requireMode(protected, policymode.Protect)
+
+	// the override switches the protected policies to monitor mode.
+	require.True(t, r.SetEnforcementOverride(true))
+	requireMode(protected, policymode.Monitor)
+	requireMode(monitored, policymode.Monitor)
+	// the controller pushes the override at each sync, only the first one switches it.
+	require.False(t, r.SetEnforcementOverride(true))
+
+	// policies reconciled during the override are monitored as well.
+	protected.Spec.RulesByContainer[c1] = rules("/bin/sleep", "/bin/cat")
+	require.NoError(t, r.ReconcileWP(protected))
+	requireMode(protected, policymode.Monitor)
+	// the reported mode is still the one of the spec.
+	require.Equal(t, policymode.ParsePolicyModeToProto(policymode.ProtectString),
+		r.GetPolicyStatuses()[protected.NamespacedName()].Mode)
+
+	// removing the override restores the mode of each policy.
+	require.True(t, r.SetEnforcementOverride(false))
+	requireMode(protected, policymode.Protect)
+	requireMode(monitored, policymode.Monitor)
+}
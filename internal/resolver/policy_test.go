@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
 	agentv1 "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -70,9 +71,11 @@ func TestHandleWP_Lifecycle(t *testing.T) {
 	statuses := r.GetPolicyStatuses()
 	require.Contains(t, statuses, key)
 	require.Equal(t, PolicyStatus{
-		State:   agentv1.PolicyState_POLICY_STATE_READY,
-		Mode:    agentv1.PolicyMode_POLICY_MODE_MONITOR,
-		Message: "",
+		State:              agentv1.PolicyState_POLICY_STATE_READY,
+		Mode:               agentv1.PolicyMode_POLICY_MODE_MONITOR,
+		Message:            "",
+		MatchedContainers:  2,
+		EnforcedContainers: 2,
 	}, statuses[key])
 
 	// Update: remove c1, update c2 allowed list, add c3
@@ -96,3 +99,98 @@ func TestHandleWP_Lifecycle(t *testing.T) {
 	statuses = r.GetPolicyStatuses()
 	require.NotContains(t, statuses, key)
 }
+
+// TestReconcileWP_ExecutablesUpdatedInPlace checks that editing the executables of a container
+// replaces the values of its policy, without detaching the cgroups or recreating the policy.
+func TestReconcileWP_ExecutablesUpdatedInPlace(t *testing.T) {
+	r := NewTestResolver(t)
+	type valuesUpdate struct {
+		policyID PolicyID
+		values   []string
+		op       bpf.PolicyValuesOperation
+	}
+	var updates []valuesUpdate
+	r.policyUpdateBinariesFunc = func(policyID PolicyID, values []string, op bpf.PolicyValuesOperation) error {
+		updates = append(updates, valuesUpdate{policyID: policyID, values: values, op: op})
+		return nil
+	}
+	var cgroupOps []bpf.CgroupPolicyOperation
+	r.cgroupToPolicyMapUpdateFunc = func(_ PolicyID, _ []CgroupID, op bpf.CgroupPolicyOperation) error {
+		cgroupOps = append(cgroupOps, op)
+		return nil
+	}
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:             "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep")},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.NoError(t, r.AddPodContainerFromNri(PodInput{
+		Meta: PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		Containers: map[ContainerID]ContainerInput{
+			cid1: {ContainerMeta: ContainerMeta{ID: cid1, Name: c1, CgroupID: 100}},
+		},
+	}))
+	polID := r.wpState[wp.NamespacedName()].polByContainer[c1]
+	updates, cgroupOps = nil, nil
+
+	wp.Spec.RulesByContainer[c1] = rules("/bin/sleep", "/bin/cat")
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, polID, r.wpState[wp.NamespacedName()].polByContainer[c1])
+	require.Equal(t, []valuesUpdate{
+		{policyID: polID, values: []string{"/bin/sleep", "/bin/cat"}, op: bpf.ReplaceValuesInPolicy},
+	}, updates)
+	require.NotContains(t, cgroupOps, bpf.RemoveCgroups)
+	require.NotContains(t, cgroupOps, bpf.RemovePolicy)
+}
+
+// TestReconcileWP_AllowedPrefixes checks that the prefixes of each container are populated with its policy,
+// and removed with it.
+func TestReconcileWP_AllowedPrefixes(t *testing.T) {
+	r := NewTestResolver(t)
+	prefixes := make(map[PolicyID][]string)
+	r.policyUpdatePrefixesFunc = func(policyID PolicyID, values []string, op bpf.PolicyValuesOperation) error {
+		if op == bpf.RemoveValuesFromPolicy {
+			delete(prefixes, policyID)
+			return nil
+		}
+		prefixes[policyID] = values
+		return nil
+	}
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{
+					Allowed:         []string{"/bin/sleep"},
+					AllowedPrefixes: []string{"/opt/app/"},
+				}},
+			},
+			UnlistedContainerPolicy: v1alpha1.UnlistedContainerDeny,
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	info := r.wpState[wp.NamespacedName()]
+	// the deny-all policy of the unlisted containers allows no prefix.
+	require.Equal(t, map[PolicyID][]string{
+		info.polByContainer[c1]: {"/opt/app/"},
+		info.unlistedPolicyID:   nil,
+	}, prefixes)
+
+	wp.Spec.RulesByContainer[c1].Executables.AllowedPrefixes = []string{"/opt/app/", "/opt/lib/"}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, []string{"/opt/app/", "/opt/lib/"}, prefixes[info.polByContainer[c1]])
+
+	require.NoError(t, r.HandleWPDelete(wp))
+	require.Empty(t, prefixes)
+}
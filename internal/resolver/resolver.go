@@ -4,26 +4,62 @@ import (
 	"log/slog"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const tracerName = "github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+
 type Resolver struct {
 	// let's see if we can split this unique lock in multiple locks later
 	mu              sync.Mutex
 	logger          *slog.Logger
 	nriSynchronized atomic.Bool
-	// todo!: we should add a cache with deleted pods/containers so that we can resolve also recently deleted ones
 	podCache        map[PodID]*podEntry
 	cgroupIDToPodID map[CgroupID]PodID
+	// deletedContainers are the removed containers still resolved until their retention expires.
+	deletedContainers         map[CgroupID]deletedContainer
+	deletedRetention          time.Duration
+	deletedCompactionInterval time.Duration
 
 	nextPolicyID                PolicyID
 	wpState                     map[NamespacedPolicyName]*wpInfo
 	policyUpdateBinariesFunc    func(policyID PolicyID, values []string, op bpf.PolicyValuesOperation) error
+	policyUpdatePrefixesFunc    func(policyID PolicyID, prefixes []string, op bpf.PolicyValuesOperation) error
 	policyModeUpdateFunc        func(policyID PolicyID, mode policymode.Mode, op bpf.PolicyModeOperation) error
 	cgTrackerUpdateFunc         func(cgID uint64, cgroupPath string) error
 	cgroupToPolicyMapUpdateFunc func(polID PolicyID, cgroupIDs []CgroupID, op bpf.CgroupPolicyOperation) error
+	// now returns the current time, it is used to evaluate the active windows of the policies.
+	now func() time.Time
+	// nsDefaultPolicies maps a namespace to the policy enforced on its pods without a policy label.
+	nsDefaultPolicies map[string]string
+	// globalAllowList are the executables allowed by every policy.
+	globalAllowList []string
+	// hashMatching restricts the executables of the allowedHashes rules to the containers where their digest matches.
+	hashMatching bool
+	// execPinsUpdateFunc replaces the executables of the allowedHashes rules verified in a cgroup.
+	execPinsUpdateFunc func(cgroupID CgroupID, pins []bpf.ExecPin) error
+	// enforceEphemeral applies the unlisted containers policy to the ephemeral containers.
+	enforceEphemeral bool
+	// maxPolicies caps the number of policies loaded in the BPF maps, 0 means unlimited.
+	maxPolicies int
+	// unresolved are the containers created by the runtime whose cgroup is not resolved yet.
+	unresolved map[unresolvedKey]*UnresolvedContainer
+	// fileDigestFunc returns the SHA-256 digest of a file inside the root filesystem of a container.
+	fileDigestFunc func(rootPath, path string) (string, bpf.ExecPin, error)
+	// enforcementOverride forces every policy in monitor mode while an EnforcementOverride exists,
+	// the controller pushes it through the SetEnforcementOverride RPC.
+	enforcementOverride bool
+	// execBypasses maps the cgroups detached from their policy for troubleshooting to the time they expire.
+	execBypasses map[CgroupID]time.Time
+	// selfCgroupID is the cgroup of the agent container, it is never attached to a policy.
+	selfCgroupID CgroupID
+	// tracer records the spans of the pod enforcement setup, they are dropped unless a tracer provider is set.
+	tracer trace.Tracer
 }
 
 func NewResolver(
@@ -31,18 +67,27 @@ func NewResolver(
 	cgTrackerUpdateFunc func(cgID uint64, cgroupPath string) error,
 	cgroupToPolicyMapUpdateFunc func(polID PolicyID, cgroupIDs []CgroupID, op bpf.CgroupPolicyOperation) error,
 	policyUpdateBinariesFunc func(policyID uint64, values []string, op bpf.PolicyValuesOperation) error,
+	policyUpdatePrefixesFunc func(policyID uint64, prefixes []string, op bpf.PolicyValuesOperation) error,
 	policyModeUpdateFunc func(policyID uint64, mode policymode.Mode, op bpf.PolicyModeOperation) error,
 ) (*Resolver, error) {
 	r := &Resolver{
 		logger:                      logger.With("component", "resolver"),
 		podCache:                    make(map[PodID]*podEntry),
 		cgroupIDToPodID:             make(map[CgroupID]PodID),
+		deletedContainers:           make(map[CgroupID]deletedContainer),
 		cgTrackerUpdateFunc:         cgTrackerUpdateFunc,
 		cgroupToPolicyMapUpdateFunc: cgroupToPolicyMapUpdateFunc,
 		policyUpdateBinariesFunc:    policyUpdateBinariesFunc,
+		policyUpdatePrefixesFunc:    policyUpdatePrefixesFunc,
 		policyModeUpdateFunc:        policyModeUpdateFunc,
 		wpState:                     make(map[NamespacedPolicyName]*wpInfo),
+		nsDefaultPolicies:           make(map[string]string),
+		execBypasses:                make(map[CgroupID]time.Time),
+		unresolved:                  make(map[unresolvedKey]*UnresolvedContainer),
 		nextPolicyID:                PolicyID(1),
+		now:                         time.Now,
+		fileDigestFunc:              fileDigest,
+		tracer:                      otel.Tracer(tracerName),
 	}
 
 	return r, nil
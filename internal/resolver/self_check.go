@@ -0,0 +1,94 @@
+package resolver
+
+import (
+	"fmt"
+	"slices"
+
+	agentv1 "github.com/rancher-sandbox/runtime-enforcer/proto/agent/v1"
+)
+
+// SelfCheck verifies the invariants of the resolver cache and returns the anomalies found, sorted.
+// An empty list means the cache is consistent.
+func (r *Resolver) SelfCheck() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	anomalies := r.checkCgroups()
+	anomalies = append(anomalies, r.checkPolicies()...)
+	slices.Sort(anomalies)
+	return anomalies
+}
+
+// checkCgroups verifies that the cgroup index and the pod cache describe the same containers.
+// This must be called with the resolver lock held.
+func (r *Resolver) checkCgroups() []string {
+	var anomalies []string
+	for cgroupID, podID := range r.cgroupIDToPodID {
+		pod, ok := r.podCache[podID]
+		if !ok {
+			anomalies = append(anomalies, fmt.Sprintf("cgroup %d belongs to pod %s which is not in the pod cache", cgroupID, podID))
+			continue
+		}
+		if !pod.hasCgroup(cgroupID) {
+			anomalies = append(anomalies, fmt.Sprintf("cgroup %d belongs to pod %s which has no container in it", cgroupID, podID))
+		}
+	}
+	for podID, pod := range r.podCache {
+		for containerID, container := range pod.containers {
+			if owner, ok := r.cgroupIDToPodID[container.CgroupID]; !ok || owner != podID {
+				anomalies = append(anomalies, fmt.Sprintf("cgroup %d of container %s in pod %s is not indexed to the pod",
+					container.CgroupID, containerID, podID))
+			}
+		}
+	}
+	return anomalies
+}
+
+// checkPolicies verifies that every policy has a distinct ID for each container with rules, and only for them.
+// This must be called with the resolver lock held.
+func (r *Resolver) checkPolicies() []string {
+	var anomalies []string
+	owners := make(map[PolicyID]string)
+	claim := func(policyID PolicyID, owner string) {
+		switch prev, taken := owners[policyID]; {
+		case policyID == PolicyIDNone:
+			anomalies = append(anomalies, owner+" has no policy ID")
+		case policyID >= r.nextPolicyID:
+			anomalies = append(anomalies, fmt.Sprintf("%s has policy ID %d which was never allocated", owner, policyID))
+		case taken:
+			anomalies = append(anomalies, fmt.Sprintf("%s and %s share policy ID %d", prev, owner, policyID))
+		default:
+			owners[policyID] = owner
+		}
+	}
+
+	for wpKey, info := range r.wpState {
+		for containerName, policyID := range info.polByContainer {
+			claim(policyID, fmt.Sprintf("policy %s, container %s", wpKey, containerName))
+		}
+		if info.unlistedPolicyID != PolicyIDNone {
+			claim(info.unlistedPolicyID, fmt.Sprintf("policy %s, unlisted containers", wpKey))
+		}
+		if info.fallbackPolicyID != PolicyIDNone {
+			claim(info.fallbackPolicyID, fmt.Sprintf("policy %s, fallback", wpKey))
+		}
+
+		// a policy that failed to reconcile is expected to be partially applied.
+		if info.policy == nil || info.status.State != agentv1.PolicyState_POLICY_STATE_READY {
+			continue
+		}
+		rules := r.withInheritedRules(info.policy).Spec.RulesByContainer
+		for containerName := range rules {
+			if _, ok := info.polByContainer[containerName]; !ok {
+				anomalies = append(anomalies, fmt.Sprintf("policy %s has no policy ID for container %s", wpKey, containerName))
+			}
+		}
+		for containerName := range info.polByContainer {
+			if _, ok := rules[containerName]; !ok {
+				anomalies = append(anomalies, fmt.Sprintf("policy %s has a policy ID for container %s without rules",
+					wpKey, containerName))
+			}
+		}
+	}
+	return anomalies
+}
@@ -0,0 +1,60 @@
+package resolver
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSelfCheck(t *testing.T) {
+	r := NewTestResolver(t)
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:             "monitor",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{c1: rules("/bin/sleep"), c2: rules("/bin/cat")},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.NoError(t, r.AddPodContainerFromNri(PodInput{
+		Meta: PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		Containers: map[ContainerID]ContainerInput{
+			cid1: {ContainerMeta: ContainerMeta{CgroupID: 100, Name: c1, ID: cid1}},
+			cid2: {ContainerMeta: ContainerMeta{CgroupID: 101, Name: c2, ID: cid2}},
+		},
+	}))
+	require.Empty(t, r.SelfCheck())
+
+	// corrupt the cache as a buggy code path would do.
+	r.mu.Lock()
+	r.cgroupIDToPodID[102] = "gone-pod-uid"
+	delete(r.cgroupIDToPodID, 101)
+	info := r.wpState[wp.NamespacedName()]
+	shared := info.polByContainer[c1]
+	info.polByContainer[c2] = shared
+	info.polByContainer[c3] = r.nextPolicyID
+	r.mu.Unlock()
+
+	anomalies := r.SelfCheck()
+	require.Len(t, anomalies, 5)
+	require.Subset(t, anomalies, []string{
+		"cgroup 101 of container cid2 in pod test-pod-uid is not indexed to the pod",
+		"cgroup 102 belongs to pod gone-pod-uid which is not in the pod cache",
+		"policy test-ns/example has a policy ID for container c3 without rules",
+		"policy test-ns/example, container c3 has policy ID 3 which was never allocated",
+	})
+	// the policy map iteration order decides which container is reported first.
+	require.Condition(t, func() bool {
+		return slices.ContainsFunc(anomalies, func(a string) bool { return strings.HasSuffix(a, fmt.Sprintf("share policy ID %d", shared)) })
+	})
+}
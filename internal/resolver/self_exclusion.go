@@ -0,0 +1,16 @@
+package resolver
+
+// SetSelfCgroupID excludes the cgroup of the agent container from the policies: the agent is never
+// blocked by a policy, e.g. a namespace default policy or the one of a label added to the agent pod.
+// Zero disables the exclusion. It must be called before any pod is added.
+func (r *Resolver) SetSelfCgroupID(cgroupID CgroupID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.selfCgroupID = cgroupID
+}
+
+// isSelfCgroup reports whether the cgroup is the one of the agent container.
+// This must be called with the resolver lock held.
+func (r *Resolver) isSelfCgroup(cgroupID CgroupID) bool {
+	return r.selfCgroupID != 0 && cgroupID == r.selfCgroupID
+}
@@ -0,0 +1,47 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSelfCgroupIsNotAttached(t *testing.T) {
+	r := NewTestResolver(t)
+	cgToPolicy := make(fakeCgroupPolicyMap)
+	r.cgroupToPolicyMapUpdateFunc = cgToPolicy.update
+	r.SetSelfCgroupID(100)
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode: "protect",
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: rules("/bin/sleep"),
+				c2: rules("/bin/sleep"),
+			},
+		},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.NoError(t, r.AddPodsFromNri([]PodInput{{
+		Meta: PodMeta{
+			ID:        "agent-pod-uid",
+			Namespace: "test-ns",
+			Name:      "agent-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		Containers: map[ContainerID]ContainerInput{
+			cid1: {ContainerMeta: ContainerMeta{CgroupID: 100, Name: c1, ID: cid1}},
+			cid2: {ContainerMeta: ContainerMeta{CgroupID: 101, Name: c2, ID: cid2}},
+		},
+	}}))
+	require.NotContains(t, cgToPolicy, CgroupID(100))
+	require.Contains(t, cgToPolicy, CgroupID(101))
+
+	// reconciling the policy again doesn't attach it either.
+	wp.Spec.RulesByContainer[c1] = rules("/bin/sleep", "/bin/ls")
+	require.NoError(t, r.ReconcileWP(wp))
+	require.NotContains(t, cgToPolicy, CgroupID(100))
+}
@@ -0,0 +1,48 @@
+package resolver
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// trackedPodsCollector reports the number of pods in the cache of the resolver by workload kind.
+type trackedPodsCollector struct {
+	resolver *Resolver
+	desc     *prometheus.Desc
+}
+
+var _ prometheus.Collector = &trackedPodsCollector{}
+
+// TrackedPodsCollector returns the collector of the runtime_enforcer_tracked_pods gauges.
+// The pods are counted when the metrics are scraped, so the gauges cannot drift from the cache.
+func (r *Resolver) TrackedPodsCollector() prometheus.Collector {
+	return &trackedPodsCollector{
+		resolver: r,
+		desc: prometheus.NewDesc(
+			"runtime_enforcer_tracked_pods",
+			"Number of pods currently tracked by the agent, by workload kind.",
+			[]string{"kind"},
+			nil,
+		),
+	}
+}
+
+func (c *trackedPodsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *trackedPodsCollector) Collect(ch chan<- prometheus.Metric) {
+	for kind, count := range c.resolver.trackedPodsByKind() {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), kind)
+	}
+}
+
+func (r *Resolver) trackedPodsByKind() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := make(map[string]int)
+	for _, pod := range r.podCache {
+		counts[pod.meta.WorkloadType]++
+	}
+	return counts
+}
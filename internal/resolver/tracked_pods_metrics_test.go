@@ -0,0 +1,36 @@
+package resolver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/workloadkind"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackedPodsCollector(t *testing.T) {
+	r := NewTestResolver(t)
+	collector := r.TrackedPodsCollector()
+	require.Zero(t, testutil.CollectAndCount(collector))
+
+	for i, kind := range []workloadkind.Kind{workloadkind.Deployment, workloadkind.Deployment, workloadkind.DaemonSet} {
+		podID, pod := generateMockPodEntry(i)
+		pod.meta.WorkloadType = string(kind)
+		r.podCache[podID] = pod
+	}
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP runtime_enforcer_tracked_pods Number of pods currently tracked by the agent, by workload kind.
+# TYPE runtime_enforcer_tracked_pods gauge
+runtime_enforcer_tracked_pods{kind="DaemonSet"} 1
+runtime_enforcer_tracked_pods{kind="Deployment"} 2
+`)))
+
+	// the pods leaving the cache are not counted anymore.
+	delete(r.podCache, "pod2")
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP runtime_enforcer_tracked_pods Number of pods currently tracked by the agent, by workload kind.
+# TYPE runtime_enforcer_tracked_pods gauge
+runtime_enforcer_tracked_pods{kind="Deployment"} 2
+`)))
+}
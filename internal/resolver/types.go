@@ -13,12 +13,19 @@ type PodMeta struct {
 	WorkloadName string
 	WorkloadType string
 	Labels       Labels
+	// Annotations only contains the pod annotations selected to be exported with the events.
+	Annotations map[string]string
 }
 
 type ContainerMeta struct {
 	ID       ContainerID
 	Name     ContainerName
 	CgroupID CgroupID
+	// RootPath is the root filesystem of the container as seen from the agent, e.g. /proc/<pid>/root.
+	// It is empty when unknown, the files of the container cannot be read then.
+	RootPath string
+	// Ephemeral is true for the ephemeral containers of the pod, e.g. the ones attached by `kubectl debug`.
+	Ephemeral bool
 }
 
 type ContainerInput struct {
@@ -30,14 +37,24 @@ type ContainerInput struct {
 type PodInput struct {
 	Meta       PodMeta
 	Containers map[ContainerID]ContainerInput
+	// SandboxCgroupID is the cgroup of the pod sandbox (the pause container), 0 when unknown.
+	SandboxCgroupID CgroupID
 }
 
 type PodView struct {
-	Meta       PodMeta
-	Containers map[ContainerID]ContainerMeta
+	Meta            PodMeta
+	Containers      map[ContainerID]ContainerMeta
+	SandboxCgroupID CgroupID
 }
 
 type ContainerView struct {
 	Meta    ContainerMeta
 	PodMeta PodMeta
+	// PolicyName is the policy of the pod, from its label or from the default policy of its namespace.
+	PolicyName string
+	// PolicySeverity is the severity of the policy, empty when the policy is unknown or doesn't set it.
+	PolicySeverity string
+	// PolicyAutoProtect is set when the policy is in monitor mode with an autoProtect: the executables
+	// it doesn't allow are still learned into the proposal of the workload.
+	PolicyAutoProtect bool
 }
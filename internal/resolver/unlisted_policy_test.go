@@ -0,0 +1,124 @@
+package resolver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/bpf"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeCgroupPolicyMap mimics the semantics of the cgroup to policy BPF map.
+type fakeCgroupPolicyMap map[CgroupID]PolicyID
+
+func (m fakeCgroupPolicyMap) update(polID PolicyID, cgroupIDs []CgroupID, op bpf.CgroupPolicyOperation) error {
+	switch op {
+	case bpf.AddPolicyToCgroups:
+		for _, cgID := range cgroupIDs {
+			if existing, ok := m[cgID]; ok && existing != polID {
+				return fmt.Errorf("cgroup %d already associated with policy %d: overlapping policies", cgID, existing)
+			}
+			m[cgID] = polID
+		}
+	case bpf.RemovePolicy:
+		for cgID, existing := range m {
+			if existing == polID {
+				delete(m, cgID)
+			}
+		}
+	case bpf.RemoveCgroups:
+		for _, cgID := range cgroupIDs {
+			delete(m, cgID)
+		}
+	}
+	return nil
+}
+
+func TestReconcileWP_UnlistedContainerPolicy(t *testing.T) {
+	r := NewTestResolver(t)
+	cgToPolicy := make(fakeCgroupPolicyMap)
+	r.cgroupToPolicyMapUpdateFunc = cgToPolicy.update
+	allowedByPolicyID := make(map[PolicyID][]string)
+	r.policyUpdateBinariesFunc = func(policyID PolicyID, values []string, op bpf.PolicyValuesOperation) error {
+		if op == bpf.RemoveValuesFromPolicy {
+			delete(allowedByPolicyID, policyID)
+			return nil
+		}
+		allowedByPolicyID[policyID] = values
+		return nil
+	}
+
+	wp := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadPolicySpec{
+			Mode:                    "protect",
+			UnlistedContainerPolicy: v1alpha1.UnlistedContainerDeny,
+			RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+				c1: {Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/sleep"}}},
+			},
+		},
+	}
+	key := wp.NamespacedName()
+
+	r.mu.Lock()
+	r.podCache["test-pod-uid"] = &podEntry{
+		meta: &PodMeta{
+			ID:        "test-pod-uid",
+			Namespace: "test-ns",
+			Name:      "test-pod",
+			Labels:    map[string]string{v1alpha1.PolicyLabelKey: "example"},
+		},
+		containers: map[ContainerID]*ContainerMeta{
+			cid1: {CgroupID: 100, Name: c1, ID: cid1},
+			cid2: {CgroupID: 101, Name: c2, ID: cid2},
+		},
+	}
+	r.mu.Unlock()
+
+	// With deny, the unlisted container gets a policy without allowed binaries.
+	require.NoError(t, r.ReconcileWP(wp))
+	state := r.wpState[key]
+	require.NotEqual(t, PolicyIDNone, state.unlistedPolicyID)
+	require.Equal(t, state.polByContainer[c1], cgToPolicy[100])
+	require.Equal(t, state.unlistedPolicyID, cgToPolicy[101])
+	require.Empty(t, allowedByPolicyID[state.unlistedPolicyID])
+
+	// A container starting later is denied as well.
+	require.NoError(t, r.AddPodContainerFromNri(PodInput{
+		Meta: *r.podCache["test-pod-uid"].meta,
+		Containers: map[ContainerID]ContainerInput{
+			cid3: {ContainerMeta: ContainerMeta{CgroupID: 102, Name: c3, ID: cid3}},
+		},
+	}))
+	require.Equal(t, state.unlistedPolicyID, cgToPolicy[102])
+
+	// Adding rules for a previously unlisted container replaces the deny-all policy.
+	wp.Spec.RulesByContainer[c2] = &v1alpha1.WorkloadPolicyRules{
+		Executables: v1alpha1.WorkloadPolicyExecutables{Allowed: []string{"/bin/cat"}},
+	}
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, state.polByContainer[c2], cgToPolicy[101])
+	require.Equal(t, []string{"/bin/cat"}, allowedByPolicyID[cgToPolicy[101]])
+	require.Equal(t, state.unlistedPolicyID, cgToPolicy[102])
+
+	// Removing the rules of a container denies it again.
+	delete(wp.Spec.RulesByContainer, c1)
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, state.unlistedPolicyID, cgToPolicy[100])
+
+	// Switching to allow detaches the unlisted containers.
+	wp.Spec.UnlistedContainerPolicy = v1alpha1.UnlistedContainerAllow
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Equal(t, PolicyIDNone, state.unlistedPolicyID)
+	require.Equal(t, fakeCgroupPolicyMap{101: state.polByContainer[c2]}, cgToPolicy)
+
+	// Switching back to deny and deleting the policy cleans everything.
+	wp.Spec.UnlistedContainerPolicy = v1alpha1.UnlistedContainerDeny
+	require.NoError(t, r.ReconcileWP(wp))
+	require.Len(t, cgToPolicy, 3)
+	require.NoError(t, r.HandleWPDelete(wp))
+	require.Empty(t, cgToPolicy)
+	require.Empty(t, allowedByPolicyID)
+}
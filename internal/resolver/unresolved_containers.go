@@ -0,0 +1,158 @@
+package resolver
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// UnresolvedContainer is a container created by the runtime whose cgroup was never resolved,
+// e.g. a container crash looping before it starts. It is not enforced.
+type UnresolvedContainer struct {
+	PodID     PodID
+	Namespace string
+	PodName   string
+	Name      ContainerName
+	// Since is when the runtime first created the container, the restarts don't reset it.
+	Since time.Time
+	// containerID is the ID of the last creation of the container.
+	containerID ContainerID
+}
+
+// unresolvedKey identifies a container across its restarts, the runtime creates it with a new ID each time.
+type unresolvedKey struct {
+	podID PodID
+	name  ContainerName
+}
+
+// MarkContainerCreated records a container created by the runtime, until its cgroup is resolved,
+// it is deleted from the pod spec or its pod is removed. The stops between the restarts of a crash looping
+// container don't forget it.
+func (r *Resolver) MarkContainerCreated(
+	podID PodID,
+	namespace, podName string,
+	name ContainerName,
+	containerID ContainerID,
+) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := unresolvedKey{podID: podID, name: name}
+	if container, ok := r.unresolved[key]; ok {
+		container.containerID = containerID
+		return
+	}
+	r.unresolved[key] = &UnresolvedContainer{
+		PodID:       podID,
+		Namespace:   namespace,
+		PodName:     podName,
+		Name:        name,
+		Since:       r.now(),
+		containerID: containerID,
+	}
+}
+
+// ForgetUnresolvedContainer drops a container deleted from the pod spec before its cgroup was resolved.
+// The removal of a previous creation of the container, e.g. the dead container of the last restart,
+// doesn't drop the current one.
+func (r *Resolver) ForgetUnresolvedContainer(podID PodID, name ContainerName, containerID ContainerID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := unresolvedKey{podID: podID, name: name}
+	if container, ok := r.unresolved[key]; ok && container.containerID == containerID {
+		delete(r.unresolved, key)
+	}
+}
+
+// IsContainerUnresolved returns whether the container of the pod is created without a resolved cgroup.
+func (r *Resolver) IsContainerUnresolved(podID PodID, name ContainerName) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.unresolved[unresolvedKey{podID: podID, name: name}]
+	return ok
+}
+
+// ForgetUnresolvedContainers drops the unresolved containers of a removed pod.
+func (r *Resolver) ForgetUnresolvedContainers(podID PodID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key := range r.unresolved {
+		if key.podID == podID {
+			delete(r.unresolved, key)
+		}
+	}
+}
+
+// UnresolvedContainers returns the containers created for at least minAge without a resolved cgroup,
+// sorted by pod and name.
+func (r *Resolver) UnresolvedContainers(minAge time.Duration) []UnresolvedContainer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	var containers []UnresolvedContainer
+	for _, container := range r.unresolved {
+		if now.Sub(container.Since) >= minAge {
+			containers = append(containers, *container)
+		}
+	}
+	slices.SortFunc(containers, func(a, b UnresolvedContainer) int {
+		return cmp.Or(
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.PodName, b.PodName),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	return containers
+}
+
+// containerResolved forgets the container once its cgroup is known.
+// This must be called with the resolver lock held.
+func (r *Resolver) containerResolved(podID PodID, name ContainerName) {
+	delete(r.unresolved, unresolvedKey{podID: podID, name: name})
+}
+
+// unresolvedContainersCollector reports the containers that never got a cgroup by namespace.
+type unresolvedContainersCollector struct {
+	resolver  *Resolver
+	threshold time.Duration
+	desc      *prometheus.Desc
+}
+
+var _ prometheus.Collector = &unresolvedContainersCollector{}
+
+// UnresolvedContainersCollector returns the collector of the runtime_enforcer_unresolved_containers gauges.
+// Only the containers unresolved for at least threshold are counted, so that the containers
+// being started are not reported.
+func (r *Resolver) UnresolvedContainersCollector(threshold time.Duration) prometheus.Collector {
+	return &unresolvedContainersCollector{
+		resolver:  r,
+		threshold: threshold,
+		desc: prometheus.NewDesc(
+			"runtime_enforcer_unresolved_containers",
+			"Number of containers created by the runtime without a resolved cgroup, which are not enforced, "+
+				"by namespace.",
+			[]string{"namespace"},
+			nil,
+		),
+	}
+}
+
+func (c *unresolvedContainersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *unresolvedContainersCollector) Collect(ch chan<- prometheus.Metric) {
+	counts := make(map[string]int)
+	for _, container := range c.resolver.UnresolvedContainers(c.threshold) {
+		counts[container.Namespace]++
+	}
+	for namespace, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), namespace)
+	}
+}
@@ -0,0 +1,65 @@
+package resolver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnresolvedContainers(t *testing.T) {
+	r := NewTestResolver(t)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	r.MarkContainerCreated("pod-uid", "test-ns", "test-pod", c1, "c1-first")
+	r.MarkContainerCreated("pod-uid", "test-ns", "test-pod", c2, cid2)
+	now = now.Add(time.Minute)
+	// the restarts of a crash looping container don't reset its creation time.
+	r.MarkContainerCreated("pod-uid", "test-ns", "test-pod", c1, cid1)
+	r.MarkContainerCreated("other-uid", "other-ns", "other-pod", c1, cid3)
+	require.Len(t, r.UnresolvedContainers(0), 3)
+
+	// the containers being started are not reported.
+	unresolved := r.UnresolvedContainers(time.Minute)
+	require.Equal(t, []UnresolvedContainer{
+		{
+			PodID: "pod-uid", Namespace: "test-ns", PodName: "test-pod", Name: c1,
+			Since: now.Add(-time.Minute), containerID: cid1,
+		},
+		{
+			PodID: "pod-uid", Namespace: "test-ns", PodName: "test-pod", Name: c2,
+			Since: now.Add(-time.Minute), containerID: cid2,
+		},
+	}, unresolved)
+	require.Equal(t, 1, testutil.CollectAndCount(r.UnresolvedContainersCollector(time.Minute)))
+
+	// the removal of the previous creation of a container doesn't forget the current one.
+	r.ForgetUnresolvedContainer("pod-uid", c1, "c1-first")
+	require.Len(t, r.UnresolvedContainers(0), 3)
+
+	// a container whose cgroup is resolved is not unresolved anymore.
+	require.NoError(t, r.AddPodContainerFromNri(PodInput{
+		Meta: PodMeta{ID: "pod-uid", Namespace: "test-ns", Name: "test-pod"},
+		Containers: map[ContainerID]ContainerInput{
+			cid1: {ContainerMeta: ContainerMeta{CgroupID: 100, Name: c1, ID: cid1}},
+		},
+	}))
+	unresolved = r.UnresolvedContainers(0)
+	require.Len(t, unresolved, 2)
+	require.Equal(t, c2, unresolved[1].Name)
+
+	// a container deleted from the pod spec before its cgroup is resolved is forgotten.
+	require.True(t, r.IsContainerUnresolved("pod-uid", c2))
+	r.ForgetUnresolvedContainer("pod-uid", c2, cid2)
+	require.Len(t, r.UnresolvedContainers(0), 1)
+	require.False(t, r.IsContainerUnresolved("pod-uid", c2))
+	r.MarkContainerCreated("pod-uid", "test-ns", "test-pod", c2, cid2)
+
+	// the containers of a removed pod are forgotten.
+	r.ForgetUnresolvedContainers("pod-uid")
+	unresolved = r.UnresolvedContainers(0)
+	require.Len(t, unresolved, 1)
+	require.Equal(t, "other-pod", unresolved[0].PodName)
+}
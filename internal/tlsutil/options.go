@@ -0,0 +1,61 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultMinVersion is the default value of the flags setting the minimum TLS version of a server.
+const DefaultMinVersion = "1.3"
+
+// Options restrict the TLS versions and the cipher suites a server negotiates.
+type Options struct {
+	// MinVersion is the minimum TLS version accepted, e.g. tls.VersionTLS12.
+	MinVersion uint16
+	// CipherSuites are the cipher suites offered with TLS 1.2, nil means the Go defaults.
+	// The TLS 1.3 cipher suites cannot be configured.
+	CipherSuites []uint16
+}
+
+// ParseOptions parses a minimum TLS version, "1.2" or "1.3", and a comma separated list of
+// cipher suite names, e.g. "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256". Only the cipher suites
+// without known security issues are accepted. An empty list keeps the Go defaults.
+func ParseOptions(minVersion, cipherSuites string) (Options, error) {
+	var opts Options
+	switch minVersion {
+	case "1.2":
+		opts.MinVersion = tls.VersionTLS12
+	case "1.3":
+		opts.MinVersion = tls.VersionTLS13
+	default:
+		return Options{}, fmt.Errorf("unsupported TLS version %q, must be one of: 1.2, 1.3", minVersion)
+	}
+
+	if cipherSuites == "" {
+		return opts, nil
+	}
+	if opts.MinVersion == tls.VersionTLS13 {
+		return Options{}, errors.New("cipher suites only apply to TLS 1.2, they require the minimum TLS version 1.2")
+	}
+	supported := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		supported[suite.Name] = suite.ID
+	}
+	for name := range strings.SplitSeq(cipherSuites, ",") {
+		name = strings.TrimSpace(name)
+		id, ok := supported[name]
+		if !ok {
+			return Options{}, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		opts.CipherSuites = append(opts.CipherSuites, id)
+	}
+	return opts, nil
+}
+
+// Apply sets the options on the TLS configuration.
+func (o Options) Apply(cfg *tls.Config) {
+	cfg.MinVersion = o.MinVersion
+	cfg.CipherSuites = o.CipherSuites
+}
@@ -0,0 +1,116 @@
+package tlsutil_test
+
+import (
+	"crypto/tls"
+	"net"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/internal/tlsutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		minVersion   string
+		cipherSuites string
+		expected     tlsutil.Options
+		expectErr    bool
+	}{
+		{
+			name:       "default",
+			minVersion: tlsutil.DefaultMinVersion,
+			expected:   tlsutil.Options{MinVersion: tls.VersionTLS13},
+		},
+		{
+			name:         "TLS 1.2 with cipher suites",
+			minVersion:   "1.2",
+			cipherSuites: "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384, TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+			expected: tlsutil.Options{
+				MinVersion: tls.VersionTLS12,
+				CipherSuites: []uint16{
+					tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+					tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+				},
+			},
+		},
+		{
+			name:       "unsupported version",
+			minVersion: "1.1",
+			expectErr:  true,
+		},
+		{
+			name:         "cipher suites with TLS 1.3",
+			minVersion:   "1.3",
+			cipherSuites: "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+			expectErr:    true,
+		},
+		{
+			name:         "insecure cipher suite",
+			minVersion:   "1.2",
+			cipherSuites: "TLS_RSA_WITH_RC4_128_SHA",
+			expectErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := tlsutil.ParseOptions(tt.minVersion, tt.cipherSuites)
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, opts)
+		})
+	}
+}
+
+func TestOptionsApply(t *testing.T) {
+	certPath, keyPath := generateTestKeyPair(t, t.TempDir())
+	cert, err := tlsutil.LoadKeyPair(certPath, keyPath)
+	require.NoError(t, err)
+
+	handshake := func(opts tlsutil.Options, client *tls.Config) (uint16, error) {
+		server := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS13}
+		opts.Apply(server)
+		client.InsecureSkipVerify = true //nolint:gosec // the test certificate is self-signed.
+
+		serverConn, clientConn := net.Pipe()
+		defer serverConn.Close()
+		defer clientConn.Close()
+		serverErr := make(chan error, 1)
+		go func() {
+			tlsConn := tls.Server(serverConn, server)
+			serverErr <- tlsConn.HandshakeContext(t.Context())
+			_ = tlsConn.Close()
+		}()
+		tlsConn := tls.Client(clientConn, client)
+		if err := tlsConn.HandshakeContext(t.Context()); err != nil {
+			return 0, err
+		}
+		if err := <-serverErr; err != nil {
+			return 0, err
+		}
+		return tlsConn.ConnectionState().CipherSuite, nil
+	}
+
+	tls12, err := tlsutil.ParseOptions("1.2", "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384")
+	require.NoError(t, err)
+	tls13, err := tlsutil.ParseOptions("1.3", "")
+	require.NoError(t, err)
+
+	// a TLS 1.2 client is rejected when the server requires TLS 1.3.
+	_, err = handshake(tls13, &tls.Config{MaxVersion: tls.VersionTLS12})
+	require.Error(t, err)
+
+	// only the allowed cipher suites are negotiated.
+	suite, err := handshake(tls12, &tls.Config{MaxVersion: tls.VersionTLS12})
+	require.NoError(t, err)
+	require.Equal(t, tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384, suite)
+	_, err = handshake(tls12, &tls.Config{
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+	})
+	require.Error(t, err)
+}
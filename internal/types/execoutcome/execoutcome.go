@@ -0,0 +1,69 @@
+package execoutcome
+
+import (
+	"errors"
+	"strings"
+)
+
+type Outcome string
+
+const (
+	// Succeeded means the command ran and exited with 0.
+	Succeeded Outcome = "Succeeded"
+	// Blocked means the exec was denied, e.g. by a policy in protect mode: the command never ran.
+	Blocked Outcome = "Blocked"
+	// Failed means the command ran but exited with an error, e.g. `mkdir: missing operand`.
+	Failed Outcome = "Failed"
+)
+
+// UnknownExitCode is the exit code of a command whose exit status is not known,
+// e.g. because the exec failed before the command could run.
+const UnknownExitCode = -1
+
+const (
+	// cannotExecuteExitCode is used by the shells and the container runtimes when the executable
+	// is found but cannot be executed.
+	cannotExecuteExitCode = 126
+
+	deniedMsg = "operation not permitted"
+	// execFailedMsg is reported by the OCI runtimes when the process of the exec cannot be started.
+	execFailedMsg = "exec failed"
+)
+
+func (o Outcome) String() string { return string(o) }
+
+// ClassifyExecResult tells a command blocked by the enforcement apart from a command that ran and failed,
+// from its stderr and its exit code.
+// A denied exec fails with EPERM, which the runtime or the shell report with the cannot-execute exit code.
+// A command that ran can print EPERM too, e.g. `mkdir /proc/x`, but it exits with its own code.
+func ClassifyExecResult(stderr string, exitCode int) Outcome {
+	if exitCode == 0 {
+		return Succeeded
+	}
+	stderr = strings.ToLower(stderr)
+	if !strings.Contains(stderr, deniedMsg) {
+		return Failed
+	}
+	if exitCode == cannotExecuteExitCode || exitCode == UnknownExitCode || strings.Contains(stderr, execFailedMsg) {
+		return Blocked
+	}
+	return Failed
+}
+
+// ExitCode returns the exit code carried by the error of an exec, 0 for a nil error
+// and UnknownExitCode when the error has no exit status, e.g. a connection error.
+// The errors of client-go remote commands and of os/exec carry one.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var withStatus interface{ ExitStatus() int }
+	if errors.As(err, &withStatus) {
+		return withStatus.ExitStatus()
+	}
+	var withCode interface{ ExitCode() int }
+	if errors.As(err, &withCode) {
+		return withCode.ExitCode()
+	}
+	return UnknownExitCode
+}
@@ -0,0 +1,58 @@
+package execoutcome
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+func TestClassifyExecResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		stderr   string
+		exitCode int
+		want     Outcome
+	}{
+		{name: "success", exitCode: 0, want: Succeeded},
+		{name: "missing operand", stderr: "mkdir: missing operand\n", exitCode: 1, want: Failed},
+		{
+			name: "denied by the runtime",
+			stderr: "OCI runtime exec failed: exec failed: unable to start container process: " +
+				"exec: \"mkdir\": operation not permitted: unknown",
+			exitCode: 126,
+			want:     Blocked,
+		},
+		{
+			name:     "denied in a shell",
+			stderr:   "bash: line 1: /usr/bin/mkdir: Operation not permitted",
+			exitCode: 126,
+			want:     Blocked,
+		},
+		{
+			name:     "denied with an unknown exit code",
+			stderr:   "operation not permitted",
+			exitCode: UnknownExitCode,
+			want:     Blocked,
+		},
+		{
+			name:     "command printing EPERM",
+			stderr:   "mkdir: cannot create directory '/proc/x': Operation not permitted",
+			exitCode: 1,
+			want:     Failed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ClassifyExecResult(tt.stderr, tt.exitCode))
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	require.Equal(t, 0, ExitCode(nil))
+	require.Equal(t, 126, ExitCode(fmt.Errorf("exec: %w", utilexec.CodeExitError{Err: errors.New("failed"), Code: 126})))
+	require.Equal(t, UnknownExitCode, ExitCode(errors.New("connection refused")))
+}
@@ -0,0 +1,53 @@
+package severity
+
+import "fmt"
+
+const (
+	LowString      = "low"
+	MediumString   = "medium"
+	HighString     = "high"
+	CriticalString = "critical"
+)
+
+// Level is the severity of the violations of a policy, levels are ordered from the least severe.
+type Level uint8
+
+const (
+	_ Level = iota
+	Low
+	Medium
+	High
+	Critical
+)
+
+func (l Level) String() string {
+	switch l {
+	case Low:
+		return LowString
+	case Medium:
+		return MediumString
+	case High:
+		return HighString
+	case Critical:
+		return CriticalString
+	default:
+		panic("unknown severity level")
+	}
+}
+
+// Parse parses a severity level. The empty string is the default severity of the policies, Medium.
+func Parse(s string) (Level, error) {
+	switch s {
+	case LowString:
+		return Low, nil
+	case MediumString, "":
+		return Medium, nil
+	case HighString:
+		return High, nil
+	case CriticalString:
+		return Critical, nil
+	default:
+		return 0, fmt.Errorf("unknown severity %q, must be one of: %s, %s, %s, %s",
+			s, LowString, MediumString, HighString, CriticalString)
+	}
+}
@@ -0,0 +1,71 @@
+package workloadpolicyhandler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+)
+
+// NamespaceHandler propagates the default policy annotation of the namespaces to the resolver.
+type NamespaceHandler struct {
+	client.Client
+
+	logger   *slog.Logger
+	resolver *resolver.Resolver
+}
+
+func NewNamespaceHandler(
+	client client.Client,
+	logger *slog.Logger,
+	resolver *resolver.Resolver,
+) *NamespaceHandler {
+	return &NamespaceHandler{
+		Client:   client,
+		logger:   logger,
+		resolver: resolver,
+	}
+}
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+func (r *NamespaceHandler) Reconcile(
+	ctx context.Context,
+	req ctrl.Request,
+) (ctrl.Result, error) {
+	var ns corev1.Namespace
+	if err := r.Get(ctx, req.NamespacedName, &ns); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("failed to get Namespace '%s': %w", req.Name, err)
+		}
+		// The namespace has been removed, so is its default policy.
+		ns.Name = req.Name
+	}
+
+	policyName := ns.GetAnnotations()[v1alpha1.DefaultPolicyAnnotationKey]
+	if err := r.resolver.SetNamespaceDefaultPolicy(ns.Name, policyName); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to set default policy of Namespace '%s': %w", ns.Name, err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NamespaceHandler) SetupWithManager(mgr ctrl.Manager) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Namespace{}).
+		Named("namespace").
+		WithEventFilter(predicate.AnnotationChangedPredicate{}).
+		Complete(r)
+	if err != nil {
+		return fmt.Errorf("unable to set up Namespace handler: %w", err)
+	}
+	return nil
+}
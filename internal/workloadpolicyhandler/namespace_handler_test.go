@@ -0,0 +1,61 @@
+package workloadpolicyhandler_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/workloadpolicyhandler"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestNamespaceHandler(t *testing.T) {
+	const testNamespace = "default"
+	const cgroupID = 100
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        testNamespace,
+			Annotations: map[string]string{v1alpha1.DefaultPolicyAnnotationKey: "baseline"},
+		},
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ns).Build()
+
+	r := resolver.NewTestResolver(t)
+	require.NoError(t, r.AddPodContainerFromNri(resolver.PodInput{
+		Meta: resolver.PodMeta{ID: "pod-uid", Namespace: testNamespace, Name: "unlabeled"},
+		Containers: map[resolver.ContainerID]resolver.ContainerInput{
+			"container-id": {ContainerMeta: resolver.ContainerMeta{CgroupID: cgroupID, Name: "main", ID: "container-id"}},
+		},
+	}))
+
+	nsHandler := workloadpolicyhandler.NewNamespaceHandler(fakeClient, slog.New(slog.DiscardHandler), r)
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: testNamespace}}
+	policyName := func() string {
+		view, err := r.GetContainerView(cgroupID)
+		require.NoError(t, err)
+		return view.PolicyName
+	}
+
+	// 1. the annotation sets the default policy of the pods without label.
+	_, err := nsHandler.Reconcile(t.Context(), req)
+	require.NoError(t, err)
+	require.Equal(t, "baseline", policyName())
+
+	// 2. removing the annotation removes the default policy.
+	ns.Annotations = nil
+	require.NoError(t, fakeClient.Update(t.Context(), ns))
+	_, err = nsHandler.Reconcile(t.Context(), req)
+	require.NoError(t, err)
+	require.Empty(t, policyName())
+}
@@ -4,12 +4,19 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync/atomic"
+	"time"
 
-	"k8s.io/apimachinery/pkg/api/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
 	"github.com/rancher-sandbox/runtime-enforcer/internal/resolver"
@@ -25,18 +32,46 @@ type WorkloadPolicyHandler struct {
 	logger    *slog.Logger
 	resolver  *resolver.Resolver
 	hasSynced atomic.Bool
+
+	resyncRequests <-chan struct{}
+	resyncDelay    time.Duration
+	// resyncEvents feeds the controller workqueue with the policies to reconcile after a resync.
+	resyncEvents chan event.GenericEvent
+}
+
+const (
+	// defaultResyncDelay leaves the informer the time to re-list after a watch error
+	// before comparing its content with the resolver state.
+	defaultResyncDelay = 10 * time.Second
+)
+
+type Option func(*WorkloadPolicyHandler)
+
+// WithResyncRequests makes the handler re-synchronize the resolver each time a value is received,
+// e.g. when the watch of an informer breaks and the informer has to re-list.
+func WithResyncRequests(requests <-chan struct{}) Option {
+	return func(r *WorkloadPolicyHandler) {
+		r.resyncRequests = requests
+	}
 }
 
 func NewWorkloadPolicyHandler(
 	client client.Client,
 	logger *slog.Logger,
 	resolver *resolver.Resolver,
+	opts ...Option,
 ) *WorkloadPolicyHandler {
-	return &WorkloadPolicyHandler{
-		Client:   client,
-		logger:   logger,
-		resolver: resolver,
+	r := &WorkloadPolicyHandler{
+		Client:       client,
+		logger:       logger,
+		resolver:     resolver,
+		resyncDelay:  defaultResyncDelay,
+		resyncEvents: make(chan event.GenericEvent),
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
 // +kubebuilder:rbac:groups=security.rancher.io,resources=workloadpolicies,verbs=get;list;watch
@@ -49,7 +84,7 @@ func (r *WorkloadPolicyHandler) Reconcile(
 
 	var wp v1alpha1.WorkloadPolicy
 	if err = r.Get(ctx, req.NamespacedName, &wp); err != nil {
-		if !errors.IsNotFound(err) {
+		if !apierrors.IsNotFound(err) {
 			return ctrl.Result{}, fmt.Errorf("failed to get WorkloadPolicy '%s': %w", req.NamespacedName, err)
 		}
 		// The item has been removed.
@@ -115,15 +150,105 @@ func (r *WorkloadPolicyHandler) IsSynchronized() bool {
 	return r.hasSynced.Load()
 }
 
+// Resync returns the requests reconciling the resolver with all the WorkloadPolicies currently in the cache.
+// Policies created or updated while the watch was broken are reconciled again,
+// policies deleted in the meantime are reconciled as well so that they are removed from the resolver.
+// The requests go through the controller workqueue, so they are never reconciled concurrently with
+// the watch events of the same policy and a stale listed object can't overwrite a newer one.
+func (r *WorkloadPolicyHandler) Resync(ctx context.Context) ([]reconcile.Request, error) {
+	var wps v1alpha1.WorkloadPolicyList
+	if err := r.List(ctx, &wps); err != nil {
+		return nil, fmt.Errorf("failed to list WorkloadPolicies during resync: %w", err)
+	}
+
+	keys := make(map[resolver.NamespacedPolicyName]struct{}, len(wps.Items))
+	for i := range wps.Items {
+		keys[wps.Items[i].NamespacedName()] = struct{}{}
+	}
+	for key := range r.resolver.GetPolicyStatuses() {
+		keys[key] = struct{}{}
+	}
+
+	requests := make([]reconcile.Request, 0, len(keys))
+	for key := range keys {
+		namespace, name, _ := strings.Cut(key, "/")
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: namespace, Name: name},
+		})
+	}
+	return requests, nil
+}
+
+// enqueue sends the requests to the controller workqueue.
+func (r *WorkloadPolicyHandler) enqueue(ctx context.Context, requests []reconcile.Request) {
+	for _, req := range requests {
+		evt := event.GenericEvent{Object: &v1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      req.Name,
+				Namespace: req.Namespace,
+			},
+		}}
+		select {
+		case <-ctx.Done():
+			return
+		case r.resyncEvents <- evt:
+		}
+	}
+}
+
+// Start implements manager.Runnable, it re-synchronizes the resolver on every resync request.
+func (r *WorkloadPolicyHandler) Start(ctx context.Context) error {
+	if r.resyncRequests == nil {
+		<-ctx.Done()
+		return nil
+	}
+
+	retry := false
+	for {
+		if !retry {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-r.resyncRequests:
+			}
+		}
+
+		// Requests received while waiting are served by the same resync.
+		timer := time.NewTimer(r.resyncDelay)
+		for waiting := true; waiting; {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil
+			case <-r.resyncRequests:
+			case <-timer.C:
+				waiting = false
+			}
+		}
+
+		r.logger.InfoContext(ctx, "re-synchronizing WorkloadPolicies after watch disruption")
+		requests, err := r.Resync(ctx)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "failed to re-synchronize WorkloadPolicies, retrying", "error", err)
+		}
+		r.enqueue(ctx, requests)
+		retry = err != nil
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *WorkloadPolicyHandler) SetupWithManager(mgr ctrl.Manager) error {
 	err := ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.WorkloadPolicy{}).
 		Named("workloadpolicy").
 		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		WatchesRawSource(source.Channel(r.resyncEvents, &handler.EnqueueRequestForObject{})).
 		Complete(r)
 	if err != nil {
 		return fmt.Errorf("unable to set up WorkloadPolicy handler: %w", err)
 	}
+	if err = mgr.Add(r); err != nil {
+		return fmt.Errorf("unable to add WorkloadPolicy resync to the manager: %w", err)
+	}
 	return nil
 }
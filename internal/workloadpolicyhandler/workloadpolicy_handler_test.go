@@ -1,6 +1,7 @@
 package workloadpolicyhandler_test
 
 import (
+	"context"
 	"log/slog"
 	"os"
 	"testing"
@@ -13,7 +14,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -94,3 +97,111 @@ func TestWorkloadPolicyHandler(t *testing.T) {
 	_, exists = policyStatus[policy.NamespacedName()]
 	require.False(t, exists)
 }
+
+func TestWorkloadPolicyHandlerResync(t *testing.T) {
+	newPolicy := func(name, mode string) *v1alpha1.WorkloadPolicy {
+		return &v1alpha1.WorkloadPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1alpha1.WorkloadPolicySpec{
+				Mode: mode,
+				RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+					"main": {
+						Executables: v1alpha1.WorkloadPolicyExecutables{
+							Allowed: []string{"/usr/bin/sleep"},
+						},
+					},
+				},
+			},
+		}
+	}
+	updated := newPolicy("updated", "monitor")
+	deleted := newPolicy("deleted", "monitor")
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(updated, deleted).Build()
+
+	resolver := resolver.NewTestResolver(t)
+	wpHandler := workloadpolicyhandler.NewWorkloadPolicyHandler(
+		fakeClient,
+		slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		resolver,
+	)
+
+	for _, wp := range []*v1alpha1.WorkloadPolicy{updated, deleted} {
+		_, err := wpHandler.Reconcile(t.Context(), reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: wp.Name, Namespace: wp.Namespace},
+		})
+		require.NoError(t, err)
+	}
+
+	// Simulate changes missed while the watch was broken: no event reaches the handler.
+	require.NoError(t, fakeClient.Get(t.Context(), types.NamespacedName{Name: "updated", Namespace: "default"}, updated))
+	updated.Spec.Mode = "protect"
+	require.NoError(t, fakeClient.Update(t.Context(), updated))
+	require.NoError(t, fakeClient.Delete(t.Context(), deleted))
+	created := newPolicy("created", "protect")
+	require.NoError(t, fakeClient.Create(t.Context(), created))
+
+	statuses := resolver.GetPolicyStatuses()
+	require.Equal(t, agentv1.PolicyMode_POLICY_MODE_MONITOR, statuses[updated.NamespacedName()].Mode)
+	require.Contains(t, statuses, deleted.NamespacedName())
+	require.NotContains(t, statuses, created.NamespacedName())
+
+	// Once the informer re-listed, the resync brings the resolver up to date.
+	requests, err := wpHandler.Resync(t.Context())
+	require.NoError(t, err)
+	require.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "updated", Namespace: "default"}},
+		{NamespacedName: types.NamespacedName{Name: "deleted", Namespace: "default"}},
+		{NamespacedName: types.NamespacedName{Name: "created", Namespace: "default"}},
+	}, requests)
+	for _, req := range requests {
+		_, err = wpHandler.Reconcile(t.Context(), req)
+		require.NoError(t, err)
+	}
+
+	statuses = resolver.GetPolicyStatuses()
+	require.Len(t, statuses, 2)
+	require.Equal(t, agentv1.PolicyMode_POLICY_MODE_PROTECT, statuses[updated.NamespacedName()].Mode)
+	require.Equal(t, agentv1.PolicyState_POLICY_STATE_READY, statuses[created.NamespacedName()].State)
+	require.NotContains(t, statuses, deleted.NamespacedName())
+}
+
+func TestWorkloadPolicyHandlerResyncConcurrentReconcile(t *testing.T) {
+	concurrent := &v1alpha1.WorkloadPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "concurrent", Namespace: "default"},
+		Spec:       v1alpha1.WorkloadPolicySpec{Mode: "monitor"},
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	resolver := resolver.NewTestResolver(t)
+	// The policy is created and reconciled right after the list of the resync.
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			if err := c.List(ctx, list, opts...); err != nil {
+				return err
+			}
+			if err := c.Create(ctx, concurrent.DeepCopy()); err != nil {
+				return err
+			}
+			return resolver.ReconcileWP(concurrent)
+		},
+	}).Build()
+	wpHandler := workloadpolicyhandler.NewWorkloadPolicyHandler(
+		fakeClient,
+		slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		resolver,
+	)
+
+	requests, err := wpHandler.Resync(t.Context())
+	require.NoError(t, err)
+	for _, req := range requests {
+		_, err = wpHandler.Reconcile(t.Context(), req)
+		require.NoError(t, err)
+	}
+	require.Contains(t, resolver.GetPolicyStatuses(), concurrent.NamespacedName())
+}
@@ -0,0 +1,26 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// EnforcementOverrideSpecApplyConfiguration represents a declarative configuration of the EnforcementOverrideSpec type for use
+// with apply.
+//
+// EnforcementOverrideSpec defines the desired state of EnforcementOverride.
+type EnforcementOverrideSpecApplyConfiguration struct {
+	// reason explains why enforcement has been suspended, e.g. a link to the incident.
+	Reason *string `json:"reason,omitempty"`
+}
+
+// EnforcementOverrideSpecApplyConfiguration constructs a declarative configuration of the EnforcementOverrideSpec type for use with
+// apply.
+func EnforcementOverrideSpec() *EnforcementOverrideSpecApplyConfiguration {
+	return &EnforcementOverrideSpecApplyConfiguration{}
+}
+
+// WithReason sets the Reason field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Reason field is set to the value of the last call.
+func (b *EnforcementOverrideSpecApplyConfiguration) WithReason(value string) *EnforcementOverrideSpecApplyConfiguration {
+	b.Reason = &value
+	return b
+}
@@ -0,0 +1,36 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ExecutableHashApplyConfiguration represents a declarative configuration of the ExecutableHash type for use
+// with apply.
+//
+// ExecutableHash is an executable allowed only with a specific content.
+type ExecutableHashApplyConfiguration struct {
+	// path is the executable allowed to run.
+	Path *string `json:"path,omitempty"`
+	// sha256 is the hex encoded SHA-256 digest of the executable.
+	SHA256 *string `json:"sha256,omitempty"`
+}
+
+// ExecutableHashApplyConfiguration constructs a declarative configuration of the ExecutableHash type for use with
+// apply.
+func ExecutableHash() *ExecutableHashApplyConfiguration {
+	return &ExecutableHashApplyConfiguration{}
+}
+
+// WithPath sets the Path field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Path field is set to the value of the last call.
+func (b *ExecutableHashApplyConfiguration) WithPath(value string) *ExecutableHashApplyConfiguration {
+	b.Path = &value
+	return b
+}
+
+// WithSHA256 sets the SHA256 field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SHA256 field is set to the value of the last call.
+func (b *ExecutableHashApplyConfiguration) WithSHA256(value string) *ExecutableHashApplyConfiguration {
+	b.SHA256 = &value
+	return b
+}
@@ -0,0 +1,38 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ExecutableWithParentApplyConfiguration represents a declarative configuration of the ExecutableWithParent type for use
+// with apply.
+//
+// ExecutableWithParent is an executable allowed only under specific parent executables.
+type ExecutableWithParentApplyConfiguration struct {
+	// path is the executable allowed to run.
+	Path *string `json:"path,omitempty"`
+	// parents is the list of executables allowed to run path.
+	Parents []string `json:"parents,omitempty"`
+}
+
+// ExecutableWithParentApplyConfiguration constructs a declarative configuration of the ExecutableWithParent type for use with
+// apply.
+func ExecutableWithParent() *ExecutableWithParentApplyConfiguration {
+	return &ExecutableWithParentApplyConfiguration{}
+}
+
+// WithPath sets the Path field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Path field is set to the value of the last call.
+func (b *ExecutableWithParentApplyConfiguration) WithPath(value string) *ExecutableWithParentApplyConfiguration {
+	b.Path = &value
+	return b
+}
+
+// WithParents adds the given value to the Parents field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Parents field.
+func (b *ExecutableWithParentApplyConfiguration) WithParents(values ...string) *ExecutableWithParentApplyConfiguration {
+	for i := range values {
+		b.Parents = append(b.Parents, values[i])
+	}
+	return b
+}
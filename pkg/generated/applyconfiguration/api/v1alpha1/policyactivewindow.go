@@ -0,0 +1,49 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// PolicyActiveWindowApplyConfiguration represents a declarative configuration of the PolicyActiveWindow type for use
+// with apply.
+//
+// PolicyActiveWindow is a daily time window, evaluated in UTC, during which a policy is enforced.
+type PolicyActiveWindowApplyConfiguration struct {
+	// start is the beginning of the window in the 24-hour "HH:MM" format.
+	Start *string `json:"start,omitempty"`
+	// end is the end of the window in the 24-hour "HH:MM" format, excluded from the window.
+	// When end is earlier than start, the window goes past midnight.
+	End *string `json:"end,omitempty"`
+	// days are the days of the week on which the window starts. When empty, the window starts every day.
+	Days []string `json:"days,omitempty"`
+}
+
+// PolicyActiveWindowApplyConfiguration constructs a declarative configuration of the PolicyActiveWindow type for use with
+// apply.
+func PolicyActiveWindow() *PolicyActiveWindowApplyConfiguration {
+	return &PolicyActiveWindowApplyConfiguration{}
+}
+
+// WithStart sets the Start field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Start field is set to the value of the last call.
+func (b *PolicyActiveWindowApplyConfiguration) WithStart(value string) *PolicyActiveWindowApplyConfiguration {
+	b.Start = &value
+	return b
+}
+
+// WithEnd sets the End field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the End field is set to the value of the last call.
+func (b *PolicyActiveWindowApplyConfiguration) WithEnd(value string) *PolicyActiveWindowApplyConfiguration {
+	b.End = &value
+	return b
+}
+
+// WithDays adds the given value to the Days field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Days field.
+func (b *PolicyActiveWindowApplyConfiguration) WithDays(values ...string) *PolicyActiveWindowApplyConfiguration {
+	for i := range values {
+		b.Days = append(b.Days, values[i])
+	}
+	return b
+}
@@ -0,0 +1,37 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// WorkloadPolicyAutoProtectApplyConfiguration represents a declarative configuration of the WorkloadPolicyAutoProtect type for use
+// with apply.
+//
+// WorkloadPolicyAutoProtect links a policy in "monitor" mode to the proposal learning its workload.
+type WorkloadPolicyAutoProtectApplyConfiguration struct {
+	// proposal is the name of the WorkloadPolicyProposal of the workload, in the namespace of the policy.
+	Proposal *string `json:"proposal,omitempty"`
+	// stableObservations is the number of consecutive observations of the proposal, made by the controller
+	// at a regular interval, without a new learned executable before the policy is switched to "protect".
+	StableObservations *int32 `json:"stableObservations,omitempty"`
+}
+
+// WorkloadPolicyAutoProtectApplyConfiguration constructs a declarative configuration of the WorkloadPolicyAutoProtect type for use with
+// apply.
+func WorkloadPolicyAutoProtect() *WorkloadPolicyAutoProtectApplyConfiguration {
+	return &WorkloadPolicyAutoProtectApplyConfiguration{}
+}
+
+// WithProposal sets the Proposal field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Proposal field is set to the value of the last call.
+func (b *WorkloadPolicyAutoProtectApplyConfiguration) WithProposal(value string) *WorkloadPolicyAutoProtectApplyConfiguration {
+	b.Proposal = &value
+	return b
+}
+
+// WithStableObservations sets the StableObservations field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the StableObservations field is set to the value of the last call.
+func (b *WorkloadPolicyAutoProtectApplyConfiguration) WithStableObservations(value int32) *WorkloadPolicyAutoProtectApplyConfiguration {
+	b.StableObservations = &value
+	return b
+}
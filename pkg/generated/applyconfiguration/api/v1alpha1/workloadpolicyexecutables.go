@@ -7,6 +7,24 @@ package v1alpha1
 type WorkloadPolicyExecutablesApplyConfiguration struct {
 	// allowed defines a list of executables that are allowed to run
 	Allowed []string `json:"allowed,omitempty"`
+	// allowedPrefixes defines path prefixes under which every executable is allowed to run,
+	// e.g. "/opt/app/" allows /opt/app/v1.2.3/bin/worker. A prefix is matched as a string,
+	// so it should end with "/" to only match the executables of a directory tree.
+	AllowedPrefixes []string `json:"allowedPrefixes,omitempty"`
+	// allowedWithParent defines executables that are allowed to run only
+	// when they are executed by one of the given parent executables.
+	// The parent condition is evaluated on the reported violations, so it
+	// only applies to policies in "monitor" mode: the policies in "protect"
+	// mode or with autoProtect are rejected when they have these rules, their
+	// own or inherited. The parent of a script is its interpreter.
+	AllowedWithParent []ExecutableWithParentApplyConfiguration `json:"allowedWithParent,omitempty"`
+	// allowedHashes defines executables that are allowed to run only when the
+	// content of the file matches the given SHA-256 digest. The files are hashed
+	// in each container and only the matching files are allowed, as long as
+	// they are not modified or replaced.
+	// Hash matching must be enabled, otherwise the policies with allowedHashes
+	// are rejected.
+	AllowedHashes []ExecutableHashApplyConfiguration `json:"allowedHashes,omitempty"`
 }
 
 // WorkloadPolicyExecutablesApplyConfiguration constructs a declarative configuration of the WorkloadPolicyExecutables type for use with
@@ -24,3 +42,39 @@ func (b *WorkloadPolicyExecutablesApplyConfiguration) WithAllowed(values ...stri
 	}
 	return b
 }
+
+// WithAllowedPrefixes adds the given value to the AllowedPrefixes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AllowedPrefixes field.
+func (b *WorkloadPolicyExecutablesApplyConfiguration) WithAllowedPrefixes(values ...string) *WorkloadPolicyExecutablesApplyConfiguration {
+	for i := range values {
+		b.AllowedPrefixes = append(b.AllowedPrefixes, values[i])
+	}
+	return b
+}
+
+// WithAllowedWithParent adds the given value to the AllowedWithParent field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AllowedWithParent field.
+func (b *WorkloadPolicyExecutablesApplyConfiguration) WithAllowedWithParent(values ...*ExecutableWithParentApplyConfiguration) *WorkloadPolicyExecutablesApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithAllowedWithParent")
+		}
+		b.AllowedWithParent = append(b.AllowedWithParent, *values[i])
+	}
+	return b
+}
+
+// WithAllowedHashes adds the given value to the AllowedHashes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AllowedHashes field.
+func (b *WorkloadPolicyExecutablesApplyConfiguration) WithAllowedHashes(values ...*ExecutableHashApplyConfiguration) *WorkloadPolicyExecutablesApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithAllowedHashes")
+		}
+		b.AllowedHashes = append(b.AllowedHashes, *values[i])
+	}
+	return b
+}
@@ -18,7 +18,8 @@ import (
 type WorkloadPolicyProposalApplyConfiguration struct {
 	v1.TypeMetaApplyConfiguration    `json:",inline"`
 	*v1.ObjectMetaApplyConfiguration `json:"metadata,omitempty"`
-	Spec                             *WorkloadPolicyProposalSpecApplyConfiguration `json:"spec,omitempty"`
+	Spec                             *WorkloadPolicyProposalSpecApplyConfiguration   `json:"spec,omitempty"`
+	Status                           *WorkloadPolicyProposalStatusApplyConfiguration `json:"status,omitempty"`
 }
 
 // WorkloadPolicyProposal constructs a declarative configuration of the WorkloadPolicyProposal type for use with
@@ -67,6 +68,12 @@ func ExtractWorkloadPolicyProposal(workloadPolicyProposal *apiv1alpha1.WorkloadP
 	return ExtractWorkloadPolicyProposalFrom(workloadPolicyProposal, fieldManager, "")
 }
 
+// ExtractWorkloadPolicyProposalStatus extracts the applied configuration owned by fieldManager from
+// workloadPolicyProposal for the status subresource.
+func ExtractWorkloadPolicyProposalStatus(workloadPolicyProposal *apiv1alpha1.WorkloadPolicyProposal, fieldManager string) (*WorkloadPolicyProposalApplyConfiguration, error) {
+	return ExtractWorkloadPolicyProposalFrom(workloadPolicyProposal, fieldManager, "status")
+}
+
 func (b WorkloadPolicyProposalApplyConfiguration) IsApplyConfiguration() {}
 
 // WithKind sets the Kind field in the declarative configuration to the given value
@@ -235,6 +242,14 @@ func (b *WorkloadPolicyProposalApplyConfiguration) WithSpec(value *WorkloadPolic
 	return b
 }
 
+// WithStatus sets the Status field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Status field is set to the value of the last call.
+func (b *WorkloadPolicyProposalApplyConfiguration) WithStatus(value *WorkloadPolicyProposalStatusApplyConfiguration) *WorkloadPolicyProposalApplyConfiguration {
+	b.Status = value
+	return b
+}
+
 // GetKind retrieves the value of the Kind field in the declarative configuration.
 func (b *WorkloadPolicyProposalApplyConfiguration) GetKind() *string {
 	return b.TypeMetaApplyConfiguration.Kind
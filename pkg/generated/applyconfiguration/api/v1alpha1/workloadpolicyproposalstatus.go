@@ -0,0 +1,93 @@
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WorkloadPolicyProposalStatusApplyConfiguration represents a declarative configuration of the WorkloadPolicyProposalStatus type for use
+// with apply.
+//
+// WorkloadPolicyProposalStatus defines the observed state of WorkloadPolicyProposal.
+type WorkloadPolicyProposalStatusApplyConfiguration struct {
+	// processCountByContainer is the number of distinct executables learned for each container.
+	ProcessCountByContainer map[string]int `json:"processCountByContainer,omitempty"`
+	// contributingNodes are the nodes whose agent learned executables into this proposal, sorted.
+	// A workload running on several nodes is fully learned once each of its nodes is listed.
+	ContributingNodes []string `json:"contributingNodes,omitempty"`
+	// commonExecutables are the learned executables, sorted, common to all the containers of the proposal
+	// when it has at least two: the candidates for a set of rules shared by the containers.
+	CommonExecutables []string `json:"commonExecutables,omitempty"`
+	// stableObservations is the number of consecutive observations, made for the WorkloadPolicy
+	// referencing this proposal in its autoProtect, without a new learned executable.
+	StableObservations *int32 `json:"stableObservations,omitempty"`
+	// observedGeneration is the generation of the proposal at the last of these observations.
+	ObservedGeneration *int64 `json:"observedGeneration,omitempty"`
+	// lastObservationTime is the time of the last of these observations.
+	LastObservationTime *v1.Time `json:"lastObservationTime,omitempty"`
+}
+
+// WorkloadPolicyProposalStatusApplyConfiguration constructs a declarative configuration of the WorkloadPolicyProposalStatus type for use with
+// apply.
+func WorkloadPolicyProposalStatus() *WorkloadPolicyProposalStatusApplyConfiguration {
+	return &WorkloadPolicyProposalStatusApplyConfiguration{}
+}
+
+// WithProcessCountByContainer puts the entries into the ProcessCountByContainer field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the ProcessCountByContainer field,
+// overwriting an existing map entries in ProcessCountByContainer field with the same key.
+func (b *WorkloadPolicyProposalStatusApplyConfiguration) WithProcessCountByContainer(entries map[string]int) *WorkloadPolicyProposalStatusApplyConfiguration {
+	if b.ProcessCountByContainer == nil && len(entries) > 0 {
+		b.ProcessCountByContainer = make(map[string]int, len(entries))
+	}
+	for k, v := range entries {
+		b.ProcessCountByContainer[k] = v
+	}
+	return b
+}
+
+// WithContributingNodes adds the given value to the ContributingNodes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ContributingNodes field.
+func (b *WorkloadPolicyProposalStatusApplyConfiguration) WithContributingNodes(values ...string) *WorkloadPolicyProposalStatusApplyConfiguration {
+	for i := range values {
+		b.ContributingNodes = append(b.ContributingNodes, values[i])
+	}
+	return b
+}
+
+// WithCommonExecutables adds the given value to the CommonExecutables field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the CommonExecutables field.
+func (b *WorkloadPolicyProposalStatusApplyConfiguration) WithCommonExecutables(values ...string) *WorkloadPolicyProposalStatusApplyConfiguration {
+	for i := range values {
+		b.CommonExecutables = append(b.CommonExecutables, values[i])
+	}
+	return b
+}
+
+// WithStableObservations sets the StableObservations field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the StableObservations field is set to the value of the last call.
+func (b *WorkloadPolicyProposalStatusApplyConfiguration) WithStableObservations(value int32) *WorkloadPolicyProposalStatusApplyConfiguration {
+	b.StableObservations = &value
+	return b
+}
+
+// WithObservedGeneration sets the ObservedGeneration field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ObservedGeneration field is set to the value of the last call.
+func (b *WorkloadPolicyProposalStatusApplyConfiguration) WithObservedGeneration(value int64) *WorkloadPolicyProposalStatusApplyConfiguration {
+	b.ObservedGeneration = &value
+	return b
+}
+
+// WithLastObservationTime sets the LastObservationTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastObservationTime field is set to the value of the last call.
+func (b *WorkloadPolicyProposalStatusApplyConfiguration) WithLastObservationTime(value v1.Time) *WorkloadPolicyProposalStatusApplyConfiguration {
+	b.LastObservationTime = &value
+	return b
+}
@@ -16,6 +16,47 @@ type WorkloadPolicySpecApplyConfiguration struct {
 	Mode *string `json:"mode,omitempty"`
 	// rulesByContainer specifies for each container the list of rules to apply.
 	RulesByContainer map[string]*apiv1alpha1.WorkloadPolicyRules `json:"rulesByContainer,omitempty"`
+	// basePolicy is the name of a WorkloadPolicy in the same namespace whose
+	// rulesByContainer are inherited by this policy. Containers defined in this
+	// policy override the rules of the same container in the base policy.
+	// The basePolicy of the base policy itself is not followed.
+	BasePolicy *string `json:"basePolicy,omitempty"`
+	// priority orders this policy and its basePolicy. For a container defined in both,
+	// the rules of the policy with the higher priority are enforced. On a tie, the rules
+	// of this policy override the inherited ones.
+	// When empty, the priority is 0.
+	Priority *int32 `json:"priority,omitempty"`
+	// unlistedContainerPolicy defines how containers of the pod that are not
+	// listed in rulesByContainer are handled. With "allow" (the default)
+	// they are not enforced, with "deny" no executable is allowed to run in them.
+	// The ephemeral containers, e.g. attached with kubectl debug, are still not enforced
+	// with "deny" unless the agent runs with --enforce-ephemeral-containers.
+	UnlistedContainerPolicy *string `json:"unlistedContainerPolicy,omitempty"`
+	// onLoadFailure defines how the containers enforced by this policy are handled on the nodes
+	// where the policy fails to load, e.g. because the agent cannot populate its maps or attach a starting pod to them.
+	// With "fail-open" (the default) they are not enforced until the policy loads, with "fail-closed"
+	// no executable is allowed in them, except the agent global allow list, following the mode and
+	// the active windows of the policy: in monitor mode the executions are reported instead of blocked.
+	OnLoadFailure *string `json:"onLoadFailure,omitempty"`
+	// activeWindows restricts the "protect" mode to the given time windows.
+	// Outside of every window, the policy only reports violations as in "monitor" mode.
+	// When empty, the mode applies at any time.
+	ActiveWindows []PolicyActiveWindowApplyConfiguration `json:"activeWindows,omitempty"`
+	// podIndexes restricts the policy to the pods whose "apps.kubernetes.io/pod-index"
+	// label, set by StatefulSets, is one of the given ordinals. The other pods
+	// referencing this policy are not enforced.
+	// When empty, the policy applies to every pod referencing it.
+	PodIndexes []int32 `json:"podIndexes,omitempty"`
+	// severity is the severity of the violations of this policy. Agents can be configured
+	// to not export the violations below a minimum severity.
+	// When empty, the severity is "medium".
+	Severity *string `json:"severity,omitempty"`
+	// autoProtect switches the policy from "monitor" to "protect" once the WorkloadPolicyProposal
+	// learning its workload stopped changing. While the policy is in "monitor" mode, the executables
+	// of the pods bound to it keep being learned into the proposal. Once the proposal is unchanged
+	// for stableObservations consecutive observations, its executables are added to the rules of
+	// the policy, the mode is set to "protect" and the proposal is deleted.
+	AutoProtect *WorkloadPolicyAutoProtectApplyConfiguration `json:"autoProtect,omitempty"`
 }
 
 // WorkloadPolicySpecApplyConfiguration constructs a declarative configuration of the WorkloadPolicySpec type for use with
@@ -45,3 +86,74 @@ func (b *WorkloadPolicySpecApplyConfiguration) WithRulesByContainer(entries map[
 	}
 	return b
 }
+
+// WithBasePolicy sets the BasePolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the BasePolicy field is set to the value of the last call.
+func (b *WorkloadPolicySpecApplyConfiguration) WithBasePolicy(value string) *WorkloadPolicySpecApplyConfiguration {
+	b.BasePolicy = &value
+	return b
+}
+
+// WithPriority sets the Priority field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Priority field is set to the value of the last call.
+func (b *WorkloadPolicySpecApplyConfiguration) WithPriority(value int32) *WorkloadPolicySpecApplyConfiguration {
+	b.Priority = &value
+	return b
+}
+
+// WithUnlistedContainerPolicy sets the UnlistedContainerPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the UnlistedContainerPolicy field is set to the value of the last call.
+func (b *WorkloadPolicySpecApplyConfiguration) WithUnlistedContainerPolicy(value string) *WorkloadPolicySpecApplyConfiguration {
+	b.UnlistedContainerPolicy = &value
+	return b
+}
+
+// WithOnLoadFailure sets the OnLoadFailure field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the OnLoadFailure field is set to the value of the last call.
+func (b *WorkloadPolicySpecApplyConfiguration) WithOnLoadFailure(value string) *WorkloadPolicySpecApplyConfiguration {
+	b.OnLoadFailure = &value
+	return b
+}
+
+// WithActiveWindows adds the given value to the ActiveWindows field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ActiveWindows field.
+func (b *WorkloadPolicySpecApplyConfiguration) WithActiveWindows(values ...*PolicyActiveWindowApplyConfiguration) *WorkloadPolicySpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithActiveWindows")
+		}
+		b.ActiveWindows = append(b.ActiveWindows, *values[i])
+	}
+	return b
+}
+
+// WithPodIndexes adds the given value to the PodIndexes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the PodIndexes field.
+func (b *WorkloadPolicySpecApplyConfiguration) WithPodIndexes(values ...int32) *WorkloadPolicySpecApplyConfiguration {
+	for i := range values {
+		b.PodIndexes = append(b.PodIndexes, values[i])
+	}
+	return b
+}
+
+// WithSeverity sets the Severity field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Severity field is set to the value of the last call.
+func (b *WorkloadPolicySpecApplyConfiguration) WithSeverity(value string) *WorkloadPolicySpecApplyConfiguration {
+	b.Severity = &value
+	return b
+}
+
+// WithAutoProtect sets the AutoProtect field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AutoProtect field is set to the value of the last call.
+func (b *WorkloadPolicySpecApplyConfiguration) WithAutoProtect(value *WorkloadPolicyAutoProtectApplyConfiguration) *WorkloadPolicySpecApplyConfiguration {
+	b.AutoProtect = value
+	return b
+}
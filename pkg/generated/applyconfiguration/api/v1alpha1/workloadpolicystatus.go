@@ -4,6 +4,7 @@ package v1alpha1
 
 import (
 	apiv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	v1 "k8s.io/client-go/applyconfigurations/meta/v1"
 )
 
 // WorkloadPolicyStatusApplyConfiguration represents a declarative configuration of the WorkloadPolicyStatus type for use
@@ -22,8 +23,21 @@ type WorkloadPolicyStatusApplyConfiguration struct {
 	TransitioningNodes *int `json:"transitioningNodes,omitempty"`
 	// nodesTransitioning contains the names of the nodes that are transitioning.
 	NodesTransitioning []string `json:"nodesTransitioning,omitempty"`
+	// pendingNodes is the number of nodes where the policy is not loaded yet, e.g. during a rollout.
+	// They are reported as failed once the policy stays missing longer than the grace period of the controller.
+	PendingNodes *int `json:"pendingNodes,omitempty"`
 	// phase indicates the current phase of the workload policy.
 	Phase *apiv1alpha1.Phase `json:"phase,omitempty"`
+	// allowedExecutables is the number of executable rules enforced by the policy, summed over all the containers,
+	// including the rules inherited from its base policy.
+	AllowedExecutables *int `json:"allowedExecutables,omitempty"`
+	// matchedContainers is the number of containers of the pods bound to the policy, summed over all the nodes.
+	MatchedContainers *int `json:"matchedContainers,omitempty"`
+	// enforcedContainers is the number of matched containers with the policy applied,
+	// i.e. with a resolved cgroup on a node where the policy is loaded.
+	EnforcedContainers *int `json:"enforcedContainers,omitempty"`
+	// enforcement summarizes the enforced containers, e.g. "48/50 (96%)". It is empty when no container is matched.
+	Enforcement *string `json:"enforcement,omitempty"`
 	// violationCount is the total number of violation records,
 	// including those no longer retained in violations.
 	//
@@ -35,6 +49,8 @@ type WorkloadPolicyStatusApplyConfiguration struct {
 	// violations is the list of the most recent violation records (max MaxViolationRecords).
 	// Oldest entries are dropped when the limit is reached.
 	Violations []ViolationRecordApplyConfiguration `json:"violations,omitempty"`
+	// conditions are the observations of the state of the policy, see PolicyLabelConflictCondition.
+	Conditions []v1.ConditionApplyConfiguration `json:"conditions,omitempty"`
 }
 
 // WorkloadPolicyStatusApplyConfiguration constructs a declarative configuration of the WorkloadPolicyStatus type for use with
@@ -107,6 +123,14 @@ func (b *WorkloadPolicyStatusApplyConfiguration) WithNodesTransitioning(values .
 	return b
 }
 
+// WithPendingNodes sets the PendingNodes field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PendingNodes field is set to the value of the last call.
+func (b *WorkloadPolicyStatusApplyConfiguration) WithPendingNodes(value int) *WorkloadPolicyStatusApplyConfiguration {
+	b.PendingNodes = &value
+	return b
+}
+
 // WithPhase sets the Phase field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the Phase field is set to the value of the last call.
@@ -115,6 +139,38 @@ func (b *WorkloadPolicyStatusApplyConfiguration) WithPhase(value apiv1alpha1.Pha
 	return b
 }
 
+// WithAllowedExecutables sets the AllowedExecutables field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AllowedExecutables field is set to the value of the last call.
+func (b *WorkloadPolicyStatusApplyConfiguration) WithAllowedExecutables(value int) *WorkloadPolicyStatusApplyConfiguration {
+	b.AllowedExecutables = &value
+	return b
+}
+
+// WithMatchedContainers sets the MatchedContainers field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MatchedContainers field is set to the value of the last call.
+func (b *WorkloadPolicyStatusApplyConfiguration) WithMatchedContainers(value int) *WorkloadPolicyStatusApplyConfiguration {
+	b.MatchedContainers = &value
+	return b
+}
+
+// WithEnforcedContainers sets the EnforcedContainers field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the EnforcedContainers field is set to the value of the last call.
+func (b *WorkloadPolicyStatusApplyConfiguration) WithEnforcedContainers(value int) *WorkloadPolicyStatusApplyConfiguration {
+	b.EnforcedContainers = &value
+	return b
+}
+
+// WithEnforcement sets the Enforcement field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Enforcement field is set to the value of the last call.
+func (b *WorkloadPolicyStatusApplyConfiguration) WithEnforcement(value string) *WorkloadPolicyStatusApplyConfiguration {
+	b.Enforcement = &value
+	return b
+}
+
 // WithViolationCount sets the ViolationCount field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the ViolationCount field is set to the value of the last call.
@@ -135,3 +191,16 @@ func (b *WorkloadPolicyStatusApplyConfiguration) WithViolations(values ...*Viola
 	}
 	return b
 }
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *WorkloadPolicyStatusApplyConfiguration) WithConditions(values ...*v1.ConditionApplyConfiguration) *WorkloadPolicyStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithConditions")
+		}
+		b.Conditions = append(b.Conditions, *values[i])
+	}
+	return b
+}
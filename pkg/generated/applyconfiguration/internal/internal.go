@@ -23,6 +23,53 @@ func Parser() *typed.Parser {
 var parserOnce sync.Once
 var parser *typed.Parser
 var schemaYAML = typed.YAMLObject(`types:
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.EnforcementOverride
+  map:
+    fields:
+    - name: apiVersion
+      type:
+        scalar: string
+    - name: kind
+      type:
+        scalar: string
+    - name: metadata
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta
+      default: {}
+    - name: spec
+      type:
+        namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.EnforcementOverrideSpec
+      default: {}
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.EnforcementOverrideSpec
+  map:
+    fields:
+    - name: reason
+      type:
+        scalar: string
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ExecutableHash
+  map:
+    fields:
+    - name: path
+      type:
+        scalar: string
+      default: ""
+    - name: sha256
+      type:
+        scalar: string
+      default: ""
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ExecutableWithParent
+  map:
+    fields:
+    - name: parents
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: path
+      type:
+        scalar: string
+      default: ""
 - name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.NodeIssue
   map:
     fields:
@@ -32,6 +79,23 @@ var schemaYAML = typed.YAMLObject(`types:
     - name: message
       type:
         scalar: string
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.PolicyActiveWindow
+  map:
+    fields:
+    - name: days
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: end
+      type:
+        scalar: string
+      default: ""
+    - name: start
+      type:
+        scalar: string
+      default: ""
 - name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ViolationRecord
   map:
     fields:
@@ -79,6 +143,17 @@ var schemaYAML = typed.YAMLObject(`types:
       type:
         namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyStatus
       default: {}
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyAutoProtect
+  map:
+    fields:
+    - name: proposal
+      type:
+        scalar: string
+      default: ""
+    - name: stableObservations
+      type:
+        scalar: numeric
+      default: 0
 - name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyExecutables
   map:
     fields:
@@ -88,6 +163,24 @@ var schemaYAML = typed.YAMLObject(`types:
           elementType:
             scalar: string
           elementRelationship: atomic
+    - name: allowedHashes
+      type:
+        list:
+          elementType:
+            namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ExecutableHash
+          elementRelationship: atomic
+    - name: allowedPrefixes
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: allowedWithParent
+      type:
+        list:
+          elementType:
+            namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ExecutableWithParent
+          elementRelationship: atomic
 - name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyProposal
   map:
     fields:
@@ -105,6 +198,10 @@ var schemaYAML = typed.YAMLObject(`types:
       type:
         namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyProposalSpec
       default: {}
+    - name: status
+      type:
+        namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyProposalStatus
+      default: {}
 - name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyProposalSpec
   map:
     fields:
@@ -113,6 +210,35 @@ var schemaYAML = typed.YAMLObject(`types:
         map:
           elementType:
             namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyRules
+- name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyProposalStatus
+  map:
+    fields:
+    - name: commonExecutables
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: associative
+    - name: contributingNodes
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: associative
+    - name: lastObservationTime
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+    - name: observedGeneration
+      type:
+        scalar: numeric
+    - name: processCountByContainer
+      type:
+        map:
+          elementType:
+            scalar: numeric
+    - name: stableObservations
+      type:
+        scalar: numeric
 - name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyRules
   map:
     fields:
@@ -123,20 +249,70 @@ var schemaYAML = typed.YAMLObject(`types:
 - name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicySpec
   map:
     fields:
+    - name: activeWindows
+      type:
+        list:
+          elementType:
+            namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.PolicyActiveWindow
+          elementRelationship: atomic
+    - name: autoProtect
+      type:
+        namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyAutoProtect
+    - name: basePolicy
+      type:
+        scalar: string
     - name: mode
       type:
         scalar: string
+    - name: onLoadFailure
+      type:
+        scalar: string
+    - name: podIndexes
+      type:
+        list:
+          elementType:
+            scalar: numeric
+          elementRelationship: atomic
+    - name: priority
+      type:
+        scalar: numeric
     - name: rulesByContainer
       type:
         map:
           elementType:
             namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyRules
+    - name: severity
+      type:
+        scalar: string
+    - name: unlistedContainerPolicy
+      type:
+        scalar: string
 - name: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.WorkloadPolicyStatus
   map:
     fields:
+    - name: allowedExecutables
+      type:
+        scalar: numeric
+    - name: conditions
+      type:
+        list:
+          elementType:
+            namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Condition
+          elementRelationship: associative
+          keys:
+          - type
+    - name: enforcedContainers
+      type:
+        scalar: numeric
+    - name: enforcement
+      type:
+        scalar: string
     - name: failedNodes
       type:
         scalar: numeric
+    - name: matchedContainers
+      type:
+        scalar: numeric
     - name: nodesTransitioning
       type:
         list:
@@ -151,6 +327,9 @@ var schemaYAML = typed.YAMLObject(`types:
     - name: observedGeneration
       type:
         scalar: numeric
+    - name: pendingNodes
+      type:
+        scalar: numeric
     - name: phase
       type:
         scalar: string
@@ -172,6 +351,31 @@ var schemaYAML = typed.YAMLObject(`types:
           elementType:
             namedType: com.github.rancher-sandbox.runtime-enforcer.api.v1alpha1.ViolationRecord
           elementRelationship: atomic
+- name: io.k8s.apimachinery.pkg.apis.meta.v1.Condition
+  map:
+    fields:
+    - name: lastTransitionTime
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+    - name: message
+      type:
+        scalar: string
+      default: ""
+    - name: observedGeneration
+      type:
+        scalar: numeric
+    - name: reason
+      type:
+        scalar: string
+      default: ""
+    - name: status
+      type:
+        scalar: string
+      default: ""
+    - name: type
+      type:
+        scalar: string
+      default: ""
 - name: io.k8s.apimachinery.pkg.apis.meta.v1.FieldsV1
   map:
     elementType:
@@ -16,18 +16,32 @@ import (
 func ForKind(kind schema.GroupVersionKind) interface{} {
 	switch kind {
 	// Group=security.rancher.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithKind("EnforcementOverride"):
+		return &apiv1alpha1.EnforcementOverrideApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("EnforcementOverrideSpec"):
+		return &apiv1alpha1.EnforcementOverrideSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ExecutableHash"):
+		return &apiv1alpha1.ExecutableHashApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ExecutableWithParent"):
+		return &apiv1alpha1.ExecutableWithParentApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("NodeIssue"):
 		return &apiv1alpha1.NodeIssueApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("PolicyActiveWindow"):
+		return &apiv1alpha1.PolicyActiveWindowApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("ViolationRecord"):
 		return &apiv1alpha1.ViolationRecordApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicy"):
 		return &apiv1alpha1.WorkloadPolicyApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicyAutoProtect"):
+		return &apiv1alpha1.WorkloadPolicyAutoProtectApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicyExecutables"):
 		return &apiv1alpha1.WorkloadPolicyExecutablesApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicyProposal"):
 		return &apiv1alpha1.WorkloadPolicyProposalApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicyProposalSpec"):
 		return &apiv1alpha1.WorkloadPolicyProposalSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicyProposalStatus"):
+		return &apiv1alpha1.WorkloadPolicyProposalStatusApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicyRules"):
 		return &apiv1alpha1.WorkloadPolicyRulesApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadPolicySpec"):
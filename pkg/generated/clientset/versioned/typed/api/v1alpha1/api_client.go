@@ -12,6 +12,7 @@ import (
 
 type SecurityV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	EnforcementOverridesGetter
 	WorkloadPoliciesGetter
 	WorkloadPolicyProposalsGetter
 }
@@ -21,6 +22,10 @@ type SecurityV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *SecurityV1alpha1Client) EnforcementOverrides() EnforcementOverrideInterface {
+	return newEnforcementOverrides(c)
+}
+
 func (c *SecurityV1alpha1Client) WorkloadPolicies(namespace string) WorkloadPolicyInterface {
 	return newWorkloadPolicies(c, namespace)
 }
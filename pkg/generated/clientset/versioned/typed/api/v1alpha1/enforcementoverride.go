@@ -0,0 +1,54 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	context "context"
+
+	apiv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	applyconfigurationapiv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/pkg/generated/applyconfiguration/api/v1alpha1"
+	scheme "github.com/rancher-sandbox/runtime-enforcer/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// EnforcementOverridesGetter has a method to return a EnforcementOverrideInterface.
+// A group's client should implement this interface.
+type EnforcementOverridesGetter interface {
+	EnforcementOverrides() EnforcementOverrideInterface
+}
+
+// EnforcementOverrideInterface has methods to work with EnforcementOverride resources.
+type EnforcementOverrideInterface interface {
+	Create(ctx context.Context, enforcementOverride *apiv1alpha1.EnforcementOverride, opts v1.CreateOptions) (*apiv1alpha1.EnforcementOverride, error)
+	Update(ctx context.Context, enforcementOverride *apiv1alpha1.EnforcementOverride, opts v1.UpdateOptions) (*apiv1alpha1.EnforcementOverride, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*apiv1alpha1.EnforcementOverride, error)
+	List(ctx context.Context, opts v1.ListOptions) (*apiv1alpha1.EnforcementOverrideList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *apiv1alpha1.EnforcementOverride, err error)
+	Apply(ctx context.Context, enforcementOverride *applyconfigurationapiv1alpha1.EnforcementOverrideApplyConfiguration, opts v1.ApplyOptions) (result *apiv1alpha1.EnforcementOverride, err error)
+	EnforcementOverrideExpansion
+}
+
+// enforcementOverrides implements EnforcementOverrideInterface
+type enforcementOverrides struct {
+	*gentype.ClientWithListAndApply[*apiv1alpha1.EnforcementOverride, *apiv1alpha1.EnforcementOverrideList, *applyconfigurationapiv1alpha1.EnforcementOverrideApplyConfiguration]
+}
+
+// newEnforcementOverrides returns a EnforcementOverrides
+func newEnforcementOverrides(c *SecurityV1alpha1Client) *enforcementOverrides {
+	return &enforcementOverrides{
+		gentype.NewClientWithListAndApply[*apiv1alpha1.EnforcementOverride, *apiv1alpha1.EnforcementOverrideList, *applyconfigurationapiv1alpha1.EnforcementOverrideApplyConfiguration](
+			"enforcementoverrides",
+			c.RESTClient(),
+			scheme.ParameterCodec,
+			"",
+			func() *apiv1alpha1.EnforcementOverride { return &apiv1alpha1.EnforcementOverride{} },
+			func() *apiv1alpha1.EnforcementOverrideList { return &apiv1alpha1.EnforcementOverrideList{} },
+		),
+	}
+}
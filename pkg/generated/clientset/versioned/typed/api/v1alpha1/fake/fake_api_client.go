@@ -12,6 +12,10 @@ type FakeSecurityV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeSecurityV1alpha1) EnforcementOverrides() v1alpha1.EnforcementOverrideInterface {
+	return newFakeEnforcementOverrides(c)
+}
+
 func (c *FakeSecurityV1alpha1) WorkloadPolicies(namespace string) v1alpha1.WorkloadPolicyInterface {
 	return newFakeWorkloadPolicies(c, namespace)
 }
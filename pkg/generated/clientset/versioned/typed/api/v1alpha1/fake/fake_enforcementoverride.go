@@ -0,0 +1,37 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	apiv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/pkg/generated/applyconfiguration/api/v1alpha1"
+	typedapiv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/pkg/generated/clientset/versioned/typed/api/v1alpha1"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// fakeEnforcementOverrides implements EnforcementOverrideInterface
+type fakeEnforcementOverrides struct {
+	*gentype.FakeClientWithListAndApply[*v1alpha1.EnforcementOverride, *v1alpha1.EnforcementOverrideList, *apiv1alpha1.EnforcementOverrideApplyConfiguration]
+	Fake *FakeSecurityV1alpha1
+}
+
+func newFakeEnforcementOverrides(fake *FakeSecurityV1alpha1) typedapiv1alpha1.EnforcementOverrideInterface {
+	return &fakeEnforcementOverrides{
+		gentype.NewFakeClientWithListAndApply[*v1alpha1.EnforcementOverride, *v1alpha1.EnforcementOverrideList, *apiv1alpha1.EnforcementOverrideApplyConfiguration](
+			fake.Fake,
+			"",
+			v1alpha1.SchemeGroupVersion.WithResource("enforcementoverrides"),
+			v1alpha1.SchemeGroupVersion.WithKind("EnforcementOverride"),
+			func() *v1alpha1.EnforcementOverride { return &v1alpha1.EnforcementOverride{} },
+			func() *v1alpha1.EnforcementOverrideList { return &v1alpha1.EnforcementOverrideList{} },
+			func(dst, src *v1alpha1.EnforcementOverrideList) { dst.ListMeta = src.ListMeta },
+			func(list *v1alpha1.EnforcementOverrideList) []*v1alpha1.EnforcementOverride {
+				return gentype.ToPointerSlice(list.Items)
+			},
+			func(list *v1alpha1.EnforcementOverrideList, items []*v1alpha1.EnforcementOverride) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
@@ -2,6 +2,8 @@
 
 package v1alpha1
 
+type EnforcementOverrideExpansion interface{}
+
 type WorkloadPolicyExpansion interface{}
 
 type WorkloadPolicyProposalExpansion interface{}
@@ -0,0 +1,99 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	context "context"
+	time "time"
+
+	runtimeenforcerapiv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	versioned "github.com/rancher-sandbox/runtime-enforcer/pkg/generated/clientset/versioned"
+	internalinterfaces "github.com/rancher-sandbox/runtime-enforcer/pkg/generated/informers/externalversions/internalinterfaces"
+	apiv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/pkg/generated/listers/api/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// EnforcementOverrideInformer provides access to a shared informer and lister for
+// EnforcementOverrides.
+type EnforcementOverrideInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() apiv1alpha1.EnforcementOverrideLister
+}
+
+type enforcementOverrideInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewEnforcementOverrideInformer constructs a new informer for EnforcementOverride type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewEnforcementOverrideInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewEnforcementOverrideInformerWithOptions(client, internalinterfaces.InformerOptions{ResyncPeriod: resyncPeriod, Indexers: indexers})
+}
+
+// NewFilteredEnforcementOverrideInformer constructs a new informer for EnforcementOverride type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredEnforcementOverrideInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return NewEnforcementOverrideInformerWithOptions(client, internalinterfaces.InformerOptions{ResyncPeriod: resyncPeriod, Indexers: indexers, TweakListOptions: tweakListOptions})
+}
+
+// NewEnforcementOverrideInformerWithOptions constructs a new informer for EnforcementOverride type with additional options.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewEnforcementOverrideInformerWithOptions(client versioned.Interface, options internalinterfaces.InformerOptions) cache.SharedIndexInformer {
+	gvr := schema.GroupVersionResource{Group: "security.rancher.io", Version: "v1alpha1", Resource: "enforcementoverrides"}
+	identifier := options.InformerName.WithResource(gvr)
+	tweakListOptions := options.TweakListOptions
+	return cache.NewSharedIndexInformerWithOptions(
+		cache.ToListWatcherWithWatchListSemantics(&cache.ListWatch{
+			ListFunc: func(opts v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&opts)
+				}
+				return client.SecurityV1alpha1().EnforcementOverrides().List(context.Background(), opts)
+			},
+			WatchFunc: func(opts v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&opts)
+				}
+				return client.SecurityV1alpha1().EnforcementOverrides().Watch(context.Background(), opts)
+			},
+			ListWithContextFunc: func(ctx context.Context, opts v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&opts)
+				}
+				return client.SecurityV1alpha1().EnforcementOverrides().List(ctx, opts)
+			},
+			WatchFuncWithContext: func(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&opts)
+				}
+				return client.SecurityV1alpha1().EnforcementOverrides().Watch(ctx, opts)
+			},
+		}, client),
+		&runtimeenforcerapiv1alpha1.EnforcementOverride{},
+		cache.SharedIndexInformerOptions{
+			ResyncPeriod: options.ResyncPeriod,
+			Indexers:     options.Indexers,
+			Identifier:   identifier,
+		},
+	)
+}
+
+func (f *enforcementOverrideInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewEnforcementOverrideInformerWithOptions(client, internalinterfaces.InformerOptions{ResyncPeriod: resyncPeriod, Indexers: cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, InformerName: f.factory.InformerName(), TweakListOptions: f.tweakListOptions})
+}
+
+func (f *enforcementOverrideInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&runtimeenforcerapiv1alpha1.EnforcementOverride{}, f.defaultInformer)
+}
+
+func (f *enforcementOverrideInformer) Lister() apiv1alpha1.EnforcementOverrideLister {
+	return apiv1alpha1.NewEnforcementOverrideLister(f.Informer().GetIndexer())
+}
@@ -8,6 +8,8 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// EnforcementOverrides returns a EnforcementOverrideInformer.
+	EnforcementOverrides() EnforcementOverrideInformer
 	// WorkloadPolicies returns a WorkloadPolicyInformer.
 	WorkloadPolicies() WorkloadPolicyInformer
 	// WorkloadPolicyProposals returns a WorkloadPolicyProposalInformer.
@@ -25,6 +27,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// EnforcementOverrides returns a EnforcementOverrideInformer.
+func (v *version) EnforcementOverrides() EnforcementOverrideInformer {
+	return &enforcementOverrideInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // WorkloadPolicies returns a WorkloadPolicyInformer.
 func (v *version) WorkloadPolicies() WorkloadPolicyInformer {
 	return &workloadPolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -37,6 +37,8 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=security.rancher.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("enforcementoverrides"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Security().V1alpha1().EnforcementOverrides().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("workloadpolicies"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Security().V1alpha1().WorkloadPolicies().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("workloadpolicyproposals"):
@@ -0,0 +1,32 @@
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	apiv1alpha1 "github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	listers "k8s.io/client-go/listers"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// EnforcementOverrideLister helps list EnforcementOverrides.
+// All objects returned here must be treated as read-only.
+type EnforcementOverrideLister interface {
+	// List lists all EnforcementOverrides in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*apiv1alpha1.EnforcementOverride, err error)
+	// Get retrieves the EnforcementOverride from the index for a given name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*apiv1alpha1.EnforcementOverride, error)
+	EnforcementOverrideListerExpansion
+}
+
+// enforcementOverrideLister implements the EnforcementOverrideLister interface.
+type enforcementOverrideLister struct {
+	listers.ResourceIndexer[*apiv1alpha1.EnforcementOverride]
+}
+
+// NewEnforcementOverrideLister returns a new EnforcementOverrideLister.
+func NewEnforcementOverrideLister(indexer cache.Indexer) EnforcementOverrideLister {
+	return &enforcementOverrideLister{listers.New[*apiv1alpha1.EnforcementOverride](indexer, apiv1alpha1.Resource("enforcementoverride"))}
+}
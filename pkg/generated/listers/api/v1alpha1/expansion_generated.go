@@ -2,6 +2,10 @@
 
 package v1alpha1
 
+// EnforcementOverrideListerExpansion allows custom methods to be added to
+// EnforcementOverrideLister.
+type EnforcementOverrideListerExpansion interface{}
+
 // WorkloadPolicyListerExpansion allows custom methods to be added to
 // WorkloadPolicyLister.
 type WorkloadPolicyListerExpansion interface{}
@@ -17,72 +17,257 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		v1alpha1.NodeIssue{}.OpenAPIModelName():                  schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_NodeIssue(ref),
-		v1alpha1.ViolationRecord{}.OpenAPIModelName():            schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ViolationRecord(ref),
-		v1alpha1.WorkloadPolicy{}.OpenAPIModelName():             schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicy(ref),
-		v1alpha1.WorkloadPolicyExecutables{}.OpenAPIModelName():  schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyExecutables(ref),
-		v1alpha1.WorkloadPolicyList{}.OpenAPIModelName():         schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyList(ref),
-		v1alpha1.WorkloadPolicyProposal{}.OpenAPIModelName():     schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposal(ref),
-		v1alpha1.WorkloadPolicyProposalList{}.OpenAPIModelName(): schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposalList(ref),
-		v1alpha1.WorkloadPolicyProposalSpec{}.OpenAPIModelName(): schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposalSpec(ref),
-		v1alpha1.WorkloadPolicyRules{}.OpenAPIModelName():        schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyRules(ref),
-		v1alpha1.WorkloadPolicySpec{}.OpenAPIModelName():         schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicySpec(ref),
-		v1alpha1.WorkloadPolicyStatus{}.OpenAPIModelName():       schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyStatus(ref),
-		resource.Quantity{}.OpenAPIModelName():                   schema_apimachinery_pkg_api_resource_Quantity(ref),
-		v1.APIGroup{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_APIGroup(ref),
-		v1.APIGroupList{}.OpenAPIModelName():                     schema_pkg_apis_meta_v1_APIGroupList(ref),
-		v1.APIResource{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_APIResource(ref),
-		v1.APIResourceList{}.OpenAPIModelName():                  schema_pkg_apis_meta_v1_APIResourceList(ref),
-		v1.APIVersions{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_APIVersions(ref),
-		v1.ApplyOptions{}.OpenAPIModelName():                     schema_pkg_apis_meta_v1_ApplyOptions(ref),
-		v1.Condition{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_Condition(ref),
-		v1.CreateOptions{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_CreateOptions(ref),
-		v1.DeleteOptions{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_DeleteOptions(ref),
-		v1.Duration{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_Duration(ref),
-		v1.FieldSelectorRequirement{}.OpenAPIModelName():         schema_pkg_apis_meta_v1_FieldSelectorRequirement(ref),
-		v1.FieldsV1{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_FieldsV1(ref),
-		v1.GetOptions{}.OpenAPIModelName():                       schema_pkg_apis_meta_v1_GetOptions(ref),
-		v1.GroupKind{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_GroupKind(ref),
-		v1.GroupResource{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_GroupResource(ref),
-		v1.GroupVersion{}.OpenAPIModelName():                     schema_pkg_apis_meta_v1_GroupVersion(ref),
-		v1.GroupVersionForDiscovery{}.OpenAPIModelName():         schema_pkg_apis_meta_v1_GroupVersionForDiscovery(ref),
-		v1.GroupVersionKind{}.OpenAPIModelName():                 schema_pkg_apis_meta_v1_GroupVersionKind(ref),
-		v1.GroupVersionResource{}.OpenAPIModelName():             schema_pkg_apis_meta_v1_GroupVersionResource(ref),
-		v1.InternalEvent{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_InternalEvent(ref),
-		v1.LabelSelector{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_LabelSelector(ref),
-		v1.LabelSelectorRequirement{}.OpenAPIModelName():         schema_pkg_apis_meta_v1_LabelSelectorRequirement(ref),
-		v1.List{}.OpenAPIModelName():                             schema_pkg_apis_meta_v1_List(ref),
-		v1.ListMeta{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_ListMeta(ref),
-		v1.ListOptions{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_ListOptions(ref),
-		v1.ManagedFieldsEntry{}.OpenAPIModelName():               schema_pkg_apis_meta_v1_ManagedFieldsEntry(ref),
-		v1.MicroTime{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_MicroTime(ref),
-		v1.ObjectMeta{}.OpenAPIModelName():                       schema_pkg_apis_meta_v1_ObjectMeta(ref),
-		v1.OwnerReference{}.OpenAPIModelName():                   schema_pkg_apis_meta_v1_OwnerReference(ref),
-		v1.PartialObjectMetadata{}.OpenAPIModelName():            schema_pkg_apis_meta_v1_PartialObjectMetadata(ref),
-		v1.PartialObjectMetadataList{}.OpenAPIModelName():        schema_pkg_apis_meta_v1_PartialObjectMetadataList(ref),
-		v1.Patch{}.OpenAPIModelName():                            schema_pkg_apis_meta_v1_Patch(ref),
-		v1.PatchOptions{}.OpenAPIModelName():                     schema_pkg_apis_meta_v1_PatchOptions(ref),
-		v1.Preconditions{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_Preconditions(ref),
-		v1.RootPaths{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_RootPaths(ref),
-		v1.ServerAddressByClientCIDR{}.OpenAPIModelName():        schema_pkg_apis_meta_v1_ServerAddressByClientCIDR(ref),
-		v1.ShardInfo{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_ShardInfo(ref),
-		v1.Status{}.OpenAPIModelName():                           schema_pkg_apis_meta_v1_Status(ref),
-		v1.StatusCause{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_StatusCause(ref),
-		v1.StatusDetails{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_StatusDetails(ref),
-		v1.Table{}.OpenAPIModelName():                            schema_pkg_apis_meta_v1_Table(ref),
-		v1.TableColumnDefinition{}.OpenAPIModelName():            schema_pkg_apis_meta_v1_TableColumnDefinition(ref),
-		v1.TableOptions{}.OpenAPIModelName():                     schema_pkg_apis_meta_v1_TableOptions(ref),
-		v1.TableRow{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_TableRow(ref),
-		v1.TableRowCondition{}.OpenAPIModelName():                schema_pkg_apis_meta_v1_TableRowCondition(ref),
-		v1.Time{}.OpenAPIModelName():                             schema_pkg_apis_meta_v1_Time(ref),
-		v1.Timestamp{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_Timestamp(ref),
-		v1.TypeMeta{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_TypeMeta(ref),
-		v1.UpdateOptions{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_UpdateOptions(ref),
-		v1.WatchEvent{}.OpenAPIModelName():                       schema_pkg_apis_meta_v1_WatchEvent(ref),
-		runtime.RawExtension{}.OpenAPIModelName():                schema_k8sio_apimachinery_pkg_runtime_RawExtension(ref),
-		runtime.TypeMeta{}.OpenAPIModelName():                    schema_k8sio_apimachinery_pkg_runtime_TypeMeta(ref),
-		runtime.Unknown{}.OpenAPIModelName():                     schema_k8sio_apimachinery_pkg_runtime_Unknown(ref),
-		version.Info{}.OpenAPIModelName():                        schema_k8sio_apimachinery_pkg_version_Info(ref),
+		v1alpha1.EnforcementOverride{}.OpenAPIModelName():          schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_EnforcementOverride(ref),
+		v1alpha1.EnforcementOverrideList{}.OpenAPIModelName():      schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_EnforcementOverrideList(ref),
+		v1alpha1.EnforcementOverrideSpec{}.OpenAPIModelName():      schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_EnforcementOverrideSpec(ref),
+		v1alpha1.ExecutableHash{}.OpenAPIModelName():               schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ExecutableHash(ref),
+		v1alpha1.ExecutableWithParent{}.OpenAPIModelName():         schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ExecutableWithParent(ref),
+		v1alpha1.NodeIssue{}.OpenAPIModelName():                    schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_NodeIssue(ref),
+		v1alpha1.PolicyActiveWindow{}.OpenAPIModelName():           schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_PolicyActiveWindow(ref),
+		v1alpha1.ViolationRecord{}.OpenAPIModelName():              schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ViolationRecord(ref),
+		v1alpha1.WorkloadPolicy{}.OpenAPIModelName():               schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicy(ref),
+		v1alpha1.WorkloadPolicyAutoProtect{}.OpenAPIModelName():    schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyAutoProtect(ref),
+		v1alpha1.WorkloadPolicyExecutables{}.OpenAPIModelName():    schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyExecutables(ref),
+		v1alpha1.WorkloadPolicyList{}.OpenAPIModelName():           schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyList(ref),
+		v1alpha1.WorkloadPolicyProposal{}.OpenAPIModelName():       schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposal(ref),
+		v1alpha1.WorkloadPolicyProposalList{}.OpenAPIModelName():   schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposalList(ref),
+		v1alpha1.WorkloadPolicyProposalSpec{}.OpenAPIModelName():   schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposalSpec(ref),
+		v1alpha1.WorkloadPolicyProposalStatus{}.OpenAPIModelName(): schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposalStatus(ref),
+		v1alpha1.WorkloadPolicyRules{}.OpenAPIModelName():          schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyRules(ref),
+		v1alpha1.WorkloadPolicySpec{}.OpenAPIModelName():           schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicySpec(ref),
+		v1alpha1.WorkloadPolicyStatus{}.OpenAPIModelName():         schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyStatus(ref),
+		resource.Quantity{}.OpenAPIModelName():                     schema_apimachinery_pkg_api_resource_Quantity(ref),
+		v1.APIGroup{}.OpenAPIModelName():                           schema_pkg_apis_meta_v1_APIGroup(ref),
+		v1.APIGroupList{}.OpenAPIModelName():                       schema_pkg_apis_meta_v1_APIGroupList(ref),
+		v1.APIResource{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_APIResource(ref),
+		v1.APIResourceList{}.OpenAPIModelName():                    schema_pkg_apis_meta_v1_APIResourceList(ref),
+		v1.APIVersions{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_APIVersions(ref),
+		v1.ApplyOptions{}.OpenAPIModelName():                       schema_pkg_apis_meta_v1_ApplyOptions(ref),
+		v1.Condition{}.OpenAPIModelName():                          schema_pkg_apis_meta_v1_Condition(ref),
+		v1.CreateOptions{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_CreateOptions(ref),
+		v1.DeleteOptions{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_DeleteOptions(ref),
+		v1.Duration{}.OpenAPIModelName():                           schema_pkg_apis_meta_v1_Duration(ref),
+		v1.FieldSelectorRequirement{}.OpenAPIModelName():           schema_pkg_apis_meta_v1_FieldSelectorRequirement(ref),
+		v1.FieldsV1{}.OpenAPIModelName():                           schema_pkg_apis_meta_v1_FieldsV1(ref),
+		v1.GetOptions{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_GetOptions(ref),
+		v1.GroupKind{}.OpenAPIModelName():                          schema_pkg_apis_meta_v1_GroupKind(ref),
+		v1.GroupResource{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_GroupResource(ref),
+		v1.GroupVersion{}.OpenAPIModelName():                       schema_pkg_apis_meta_v1_GroupVersion(ref),
+		v1.GroupVersionForDiscovery{}.OpenAPIModelName():           schema_pkg_apis_meta_v1_GroupVersionForDiscovery(ref),
+		v1.GroupVersionKind{}.OpenAPIModelName():                   schema_pkg_apis_meta_v1_GroupVersionKind(ref),
+		v1.GroupVersionResource{}.OpenAPIModelName():               schema_pkg_apis_meta_v1_GroupVersionResource(ref),
+		v1.InternalEvent{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_InternalEvent(ref),
+		v1.LabelSelector{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_LabelSelector(ref),
+		v1.LabelSelectorRequirement{}.OpenAPIModelName():           schema_pkg_apis_meta_v1_LabelSelectorRequirement(ref),
+		v1.List{}.OpenAPIModelName():                               schema_pkg_apis_meta_v1_List(ref),
+		v1.ListMeta{}.OpenAPIModelName():                           schema_pkg_apis_meta_v1_ListMeta(ref),
+		v1.ListOptions{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_ListOptions(ref),
+		v1.ManagedFieldsEntry{}.OpenAPIModelName():                 schema_pkg_apis_meta_v1_ManagedFieldsEntry(ref),
+		v1.MicroTime{}.OpenAPIModelName():                          schema_pkg_apis_meta_v1_MicroTime(ref),
+		v1.ObjectMeta{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_ObjectMeta(ref),
+		v1.OwnerReference{}.OpenAPIModelName():                     schema_pkg_apis_meta_v1_OwnerReference(ref),
+		v1.PartialObjectMetadata{}.OpenAPIModelName():              schema_pkg_apis_meta_v1_PartialObjectMetadata(ref),
+		v1.PartialObjectMetadataList{}.OpenAPIModelName():          schema_pkg_apis_meta_v1_PartialObjectMetadataList(ref),
+		v1.Patch{}.OpenAPIModelName():                              schema_pkg_apis_meta_v1_Patch(ref),
+		v1.PatchOptions{}.OpenAPIModelName():                       schema_pkg_apis_meta_v1_PatchOptions(ref),
+		v1.Preconditions{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_Preconditions(ref),
+		v1.RootPaths{}.OpenAPIModelName():                          schema_pkg_apis_meta_v1_RootPaths(ref),
+		v1.ServerAddressByClientCIDR{}.OpenAPIModelName():          schema_pkg_apis_meta_v1_ServerAddressByClientCIDR(ref),
+		v1.ShardInfo{}.OpenAPIModelName():                          schema_pkg_apis_meta_v1_ShardInfo(ref),
+		v1.Status{}.OpenAPIModelName():                             schema_pkg_apis_meta_v1_Status(ref),
+		v1.StatusCause{}.OpenAPIModelName():                        schema_pkg_apis_meta_v1_StatusCause(ref),
+		v1.StatusDetails{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_StatusDetails(ref),
+		v1.Table{}.OpenAPIModelName():                              schema_pkg_apis_meta_v1_Table(ref),
+		v1.TableColumnDefinition{}.OpenAPIModelName():              schema_pkg_apis_meta_v1_TableColumnDefinition(ref),
+		v1.TableOptions{}.OpenAPIModelName():                       schema_pkg_apis_meta_v1_TableOptions(ref),
+		v1.TableRow{}.OpenAPIModelName():                           schema_pkg_apis_meta_v1_TableRow(ref),
+		v1.TableRowCondition{}.OpenAPIModelName():                  schema_pkg_apis_meta_v1_TableRowCondition(ref),
+		v1.Time{}.OpenAPIModelName():                               schema_pkg_apis_meta_v1_Time(ref),
+		v1.Timestamp{}.OpenAPIModelName():                          schema_pkg_apis_meta_v1_Timestamp(ref),
+		v1.TypeMeta{}.OpenAPIModelName():                           schema_pkg_apis_meta_v1_TypeMeta(ref),
+		v1.UpdateOptions{}.OpenAPIModelName():                      schema_pkg_apis_meta_v1_UpdateOptions(ref),
+		v1.WatchEvent{}.OpenAPIModelName():                         schema_pkg_apis_meta_v1_WatchEvent(ref),
+		runtime.RawExtension{}.OpenAPIModelName():                  schema_k8sio_apimachinery_pkg_runtime_RawExtension(ref),
+		runtime.TypeMeta{}.OpenAPIModelName():                      schema_k8sio_apimachinery_pkg_runtime_TypeMeta(ref),
+		runtime.Unknown{}.OpenAPIModelName():                       schema_k8sio_apimachinery_pkg_runtime_Unknown(ref),
+		version.Info{}.OpenAPIModelName():                          schema_k8sio_apimachinery_pkg_version_Info(ref),
+	}
+}
+
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_EnforcementOverride(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "EnforcementOverride is an emergency switch: while at least one exists in the cluster, every agent enforces all the policies in monitor mode, so that no executable is blocked. Deleting all of them restores the mode of each policy.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1.ObjectMeta{}.OpenAPIModelName()),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1alpha1.EnforcementOverrideSpec{}.OpenAPIModelName()),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			v1alpha1.EnforcementOverrideSpec{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
+	}
+}
+
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_EnforcementOverrideList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "EnforcementOverrideList contains a list of EnforcementOverride.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1.ListMeta{}.OpenAPIModelName()),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1alpha1.EnforcementOverride{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			v1alpha1.EnforcementOverride{}.OpenAPIModelName(), v1.ListMeta{}.OpenAPIModelName()},
+	}
+}
+
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_EnforcementOverrideSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "EnforcementOverrideSpec defines the desired state of EnforcementOverride.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "reason explains why enforcement has been suspended, e.g. a link to the incident.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ExecutableHash(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ExecutableHash is an executable allowed only with a specific content.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "path is the executable allowed to run.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"sha256": {
+						SchemaProps: spec.SchemaProps{
+							Description: "sha256 is the hex encoded SHA-256 digest of the executable.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"path", "sha256"},
+			},
+		},
+	}
+}
+
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ExecutableWithParent(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ExecutableWithParent is an executable allowed only under specific parent executables.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "path is the executable allowed to run.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"parents": {
+						SchemaProps: spec.SchemaProps{
+							Description: "parents is the list of executables allowed to run path.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"path", "parents"},
+			},
+		},
 	}
 }
 
@@ -113,6 +298,51 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_NodeIssue(ref common.R
 	}
 }
 
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_PolicyActiveWindow(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PolicyActiveWindow is a daily time window, evaluated in UTC, during which a policy is enforced.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"start": {
+						SchemaProps: spec.SchemaProps{
+							Description: "start is the beginning of the window in the 24-hour \"HH:MM\" format.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"end": {
+						SchemaProps: spec.SchemaProps{
+							Description: "end is the end of the window in the 24-hour \"HH:MM\" format, excluded from the window. When end is earlier than start, the window goes past midnight.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"days": {
+						SchemaProps: spec.SchemaProps{
+							Description: "days are the days of the week on which the window starts. When empty, the window starts every day.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"start", "end"},
+			},
+		},
+	}
+}
+
 func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_ViolationRecord(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -222,6 +452,36 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicy(ref com
 	}
 }
 
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyAutoProtect(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WorkloadPolicyAutoProtect links a policy in \"monitor\" mode to the proposal learning its workload.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"proposal": {
+						SchemaProps: spec.SchemaProps{
+							Description: "proposal is the name of the WorkloadPolicyProposal of the workload, in the namespace of the policy.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"stableObservations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "stableObservations is the number of consecutive observations of the proposal, made by the controller at a regular interval, without a new learned executable before the policy is switched to \"protect\".",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"proposal", "stableObservations"},
+			},
+		},
+	}
+}
+
 func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyExecutables(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -243,9 +503,54 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyExecutab
 							},
 						},
 					},
+					"allowedPrefixes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "allowedPrefixes defines path prefixes under which every executable is allowed to run, e.g. \"/opt/app/\" allows /opt/app/v1.2.3/bin/worker. A prefix is matched as a string, so it should end with \"/\" to only match the executables of a directory tree.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"allowedWithParent": {
+						SchemaProps: spec.SchemaProps{
+							Description: "allowedWithParent defines executables that are allowed to run only when they are executed by one of the given parent executables. The parent condition is evaluated on the reported violations, so it only applies to policies in \"monitor\" mode: the policies in \"protect\" mode or with autoProtect are rejected when they have these rules, their own or inherited. The parent of a script is its interpreter.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1alpha1.ExecutableWithParent{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
+					"allowedHashes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "allowedHashes defines executables that are allowed to run only when the content of the file matches the given SHA-256 digest. The files are hashed in each container and only the matching files are allowed, as long as they are not modified or replaced. Hash matching must be enabled, otherwise the policies with allowedHashes are rejected.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1alpha1.ExecutableHash{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			v1alpha1.ExecutableHash{}.OpenAPIModelName(), v1alpha1.ExecutableWithParent{}.OpenAPIModelName()},
 	}
 }
 
@@ -331,11 +636,17 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposal
 							Ref:     ref(v1alpha1.WorkloadPolicyProposalSpec{}.OpenAPIModelName()),
 						},
 					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1alpha1.WorkloadPolicyProposalStatus{}.OpenAPIModelName()),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			v1alpha1.WorkloadPolicyProposalSpec{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
+			v1alpha1.WorkloadPolicyProposalSpec{}.OpenAPIModelName(), v1alpha1.WorkloadPolicyProposalStatus{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
 	}
 }
 
@@ -417,6 +728,97 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposal
 	}
 }
 
+func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyProposalStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WorkloadPolicyProposalStatus defines the observed state of WorkloadPolicyProposal.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"processCountByContainer": {
+						SchemaProps: spec.SchemaProps{
+							Description: "processCountByContainer is the number of distinct executables learned for each container.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: 0,
+										Type:    []string{"integer"},
+										Format:  "int32",
+									},
+								},
+							},
+						},
+					},
+					"contributingNodes": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "contributingNodes are the nodes whose agent learned executables into this proposal, sorted. A workload running on several nodes is fully learned once each of its nodes is listed.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"commonExecutables": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "commonExecutables are the learned executables, sorted, common to all the containers of the proposal when it has at least two: the candidates for a set of rules shared by the containers.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"stableObservations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "stableObservations is the number of consecutive observations, made for the WorkloadPolicy referencing this proposal in its autoProtect, without a new learned executable.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "observedGeneration is the generation of the proposal at the last of these observations.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"lastObservationTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "lastObservationTime is the time of the last of these observations.",
+							Ref:         ref(v1.Time{}.OpenAPIModelName()),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			v1.Time{}.OpenAPIModelName()},
+	}
+}
+
 func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyRules(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -465,11 +867,81 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicySpec(ref
 							},
 						},
 					},
+					"basePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "basePolicy is the name of a WorkloadPolicy in the same namespace whose rulesByContainer are inherited by this policy. Containers defined in this policy override the rules of the same container in the base policy. The basePolicy of the base policy itself is not followed.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"priority": {
+						SchemaProps: spec.SchemaProps{
+							Description: "priority orders this policy and its basePolicy. For a container defined in both, the rules of the policy with the higher priority are enforced. On a tie, the rules of this policy override the inherited ones. When empty, the priority is 0.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"unlistedContainerPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "unlistedContainerPolicy defines how containers of the pod that are not listed in rulesByContainer are handled. With \"allow\" (the default) they are not enforced, with \"deny\" no executable is allowed to run in them. The ephemeral containers, e.g. attached with kubectl debug, are still not enforced with \"deny\" unless the agent runs with --enforce-ephemeral-containers.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"onLoadFailure": {
+						SchemaProps: spec.SchemaProps{
+							Description: "onLoadFailure defines how the containers enforced by this policy are handled on the nodes where the policy fails to load, e.g. because the agent cannot populate its maps or attach a starting pod to them. With \"fail-open\" (the default) they are not enforced until the policy loads, with \"fail-closed\" no executable is allowed in them, except the agent global allow list, following the mode and the active windows of the policy: in monitor mode the executions are reported instead of blocked.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"activeWindows": {
+						SchemaProps: spec.SchemaProps{
+							Description: "activeWindows restricts the \"protect\" mode to the given time windows. Outside of every window, the policy only reports violations as in \"monitor\" mode. When empty, the mode applies at any time.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1alpha1.PolicyActiveWindow{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
+					"podIndexes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "podIndexes restricts the policy to the pods whose \"apps.kubernetes.io/pod-index\" label, set by StatefulSets, is one of the given ordinals. The other pods referencing this policy are not enforced. When empty, the policy applies to every pod referencing it.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: 0,
+										Type:    []string{"integer"},
+										Format:  "int32",
+									},
+								},
+							},
+						},
+					},
+					"severity": {
+						SchemaProps: spec.SchemaProps{
+							Description: "severity is the severity of the violations of this policy. Agents can be configured to not export the violations below a minimum severity. When empty, the severity is \"medium\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"autoProtect": {
+						SchemaProps: spec.SchemaProps{
+							Description: "autoProtect switches the policy from \"monitor\" to \"protect\" once the WorkloadPolicyProposal learning its workload stopped changing. While the policy is in \"monitor\" mode, the executables of the pods bound to it keep being learned into the proposal. Once the proposal is unchanged for stableObservations consecutive observations, its executables are added to the rules of the policy, the mode is set to \"protect\" and the proposal is deleted.",
+							Ref:         ref(v1alpha1.WorkloadPolicyAutoProtect{}.OpenAPIModelName()),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			v1alpha1.WorkloadPolicyRules{}.OpenAPIModelName()},
+			v1alpha1.PolicyActiveWindow{}.OpenAPIModelName(), v1alpha1.WorkloadPolicyAutoProtect{}.OpenAPIModelName(), v1alpha1.WorkloadPolicyRules{}.OpenAPIModelName()},
 	}
 }
 
@@ -543,6 +1015,13 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyStatus(r
 							},
 						},
 					},
+					"pendingNodes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "pendingNodes is the number of nodes where the policy is not loaded yet, e.g. during a rollout. They are reported as failed once the policy stays missing longer than the grace period of the controller.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 					"phase": {
 						SchemaProps: spec.SchemaProps{
 							Description: "phase indicates the current phase of the workload policy.",
@@ -550,6 +1029,34 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyStatus(r
 							Format:      "",
 						},
 					},
+					"allowedExecutables": {
+						SchemaProps: spec.SchemaProps{
+							Description: "allowedExecutables is the number of executable rules enforced by the policy, summed over all the containers, including the rules inherited from its base policy.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"matchedContainers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "matchedContainers is the number of containers of the pods bound to the policy, summed over all the nodes.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"enforcedContainers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "enforcedContainers is the number of matched containers with the policy applied, i.e. with a resolved cgroup on a node where the policy is loaded.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"enforcement": {
+						SchemaProps: spec.SchemaProps{
+							Description: "enforcement summarizes the enforced containers, e.g. \"48/50 (96%)\". It is empty when no container is matched.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"violationCount": {
 						SchemaProps: spec.SchemaProps{
 							Description: "violationCount is the total number of violation records, including those no longer retained in violations.\n\nNote: This value is maintained by the reconciler and reflects its best-effort view of the system. It is not guaranteed to be strongly consistent and may be temporarily outdated depending on reconciliation.",
@@ -571,11 +1078,33 @@ func schema_rancher_sandbox_runtime_enforcer_api_v1alpha1_WorkloadPolicyStatus(r
 							},
 						},
 					},
+					"conditions": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"type",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "conditions are the observations of the state of the policy, see PolicyLabelConflictCondition.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1.Condition{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			v1alpha1.NodeIssue{}.OpenAPIModelName(), v1alpha1.ViolationRecord{}.OpenAPIModelName()},
+			v1alpha1.NodeIssue{}.OpenAPIModelName(), v1alpha1.ViolationRecord{}.OpenAPIModelName(), v1.Condition{}.OpenAPIModelName()},
 	}
 }
 
@@ -11,6 +11,7 @@ package agentv1
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -442,12 +443,16 @@ func (*ListPoliciesStatusRequest) Descriptor() ([]byte, []int) {
 }
 
 type PolicyStatus struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	State         PolicyState            `protobuf:"varint,1,opt,name=state,proto3,enum=runtimeenforcer.agent.v1.PolicyState" json:"state,omitempty"`
-	Mode          PolicyMode             `protobuf:"varint,2,opt,name=mode,proto3,enum=runtimeenforcer.agent.v1.PolicyMode" json:"mode,omitempty"`
-	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	State   PolicyState            `protobuf:"varint,1,opt,name=state,proto3,enum=runtimeenforcer.agent.v1.PolicyState" json:"state,omitempty"`
+	Mode    PolicyMode             `protobuf:"varint,2,opt,name=mode,proto3,enum=runtimeenforcer.agent.v1.PolicyMode" json:"mode,omitempty"`
+	Message string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// Number of containers of the pods of the node the policy applies to.
+	MatchedContainers uint32 `protobuf:"varint,4,opt,name=matched_containers,json=matchedContainers,proto3" json:"matched_containers,omitempty"`
+	// Number of matched containers with the policy applied to their cgroup.
+	EnforcedContainers uint32 `protobuf:"varint,5,opt,name=enforced_containers,json=enforcedContainers,proto3" json:"enforced_containers,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *PolicyStatus) Reset() {
@@ -501,6 +506,20 @@ func (x *PolicyStatus) GetMessage() string {
 	return ""
 }
 
+func (x *PolicyStatus) GetMatchedContainers() uint32 {
+	if x != nil {
+		return x.MatchedContainers
+	}
+	return 0
+}
+
+func (x *PolicyStatus) GetEnforcedContainers() uint32 {
+	if x != nil {
+		return x.EnforcedContainers
+	}
+	return 0
+}
+
 type ListPoliciesStatusResponse struct {
 	state         protoimpl.MessageState   `protogen:"open.v1"`
 	Policies      map[string]*PolicyStatus `protobuf:"bytes,1,rep,name=policies,proto3" json:"policies,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
@@ -717,11 +736,655 @@ func (x *ScrapeViolationsResponse) GetViolations() []*ViolationRecord {
 	return nil
 }
 
+type GetAgentInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAgentInfoRequest) Reset() {
+	*x = GetAgentInfoRequest{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAgentInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAgentInfoRequest) ProtoMessage() {}
+
+func (x *GetAgentInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAgentInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetAgentInfoRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{11}
+}
+
+type KernelFeature struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Name      string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Supported bool                   `protobuf:"varint,2,opt,name=supported,proto3" json:"supported,omitempty"`
+	// Reason why the feature is not supported, empty otherwise.
+	Error         string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KernelFeature) Reset() {
+	*x = KernelFeature{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KernelFeature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KernelFeature) ProtoMessage() {}
+
+func (x *KernelFeature) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KernelFeature.ProtoReflect.Descriptor instead.
+func (*KernelFeature) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *KernelFeature) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *KernelFeature) GetSupported() bool {
+	if x != nil {
+		return x.Supported
+	}
+	return false
+}
+
+func (x *KernelFeature) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Configuration the eBPF programs of the agent were loaded with.
+type BpfLoadConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Magic number of the cgroup filesystem, it tells cgroupv1 from cgroupv2.
+	CgroupFsMagic uint64 `protobuf:"varint,1,opt,name=cgroup_fs_magic,json=cgroupFsMagic,proto3" json:"cgroup_fs_magic,omitempty"`
+	// Index of the memory controller used to resolve the cgroups on cgroupv1, 0 on cgroupv2.
+	Cgroupv1SubsysIdx uint32 `protobuf:"varint,2,opt,name=cgroupv1_subsys_idx,json=cgroupv1SubsysIdx,proto3" json:"cgroupv1_subsys_idx,omitempty"`
+	DebugMode         bool   `protobuf:"varint,3,opt,name=debug_mode,json=debugMode,proto3" json:"debug_mode,omitempty"`
+	LearningEnabled   bool   `protobuf:"varint,4,opt,name=learning_enabled,json=learningEnabled,proto3" json:"learning_enabled,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *BpfLoadConfig) Reset() {
+	*x = BpfLoadConfig{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BpfLoadConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BpfLoadConfig) ProtoMessage() {}
+
+func (x *BpfLoadConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BpfLoadConfig.ProtoReflect.Descriptor instead.
+func (*BpfLoadConfig) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *BpfLoadConfig) GetCgroupFsMagic() uint64 {
+	if x != nil {
+		return x.CgroupFsMagic
+	}
+	return 0
+}
+
+func (x *BpfLoadConfig) GetCgroupv1SubsysIdx() uint32 {
+	if x != nil {
+		return x.Cgroupv1SubsysIdx
+	}
+	return 0
+}
+
+func (x *BpfLoadConfig) GetDebugMode() bool {
+	if x != nil {
+		return x.DebugMode
+	}
+	return false
+}
+
+func (x *BpfLoadConfig) GetLearningEnabled() bool {
+	if x != nil {
+		return x.LearningEnabled
+	}
+	return false
+}
+
+// Outcome of the last cgroup resolutions of the containers started on the node.
+type CgroupResolution struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of resolutions that failed since the last successful one.
+	ConsecutiveFailures uint64 `protobuf:"varint,1,opt,name=consecutive_failures,json=consecutiveFailures,proto3" json:"consecutive_failures,omitempty"`
+	// Error of the last failed resolution.
+	LastError     string `protobuf:"bytes,2,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CgroupResolution) Reset() {
+	*x = CgroupResolution{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CgroupResolution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CgroupResolution) ProtoMessage() {}
+
+func (x *CgroupResolution) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CgroupResolution.ProtoReflect.Descriptor instead.
+func (*CgroupResolution) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CgroupResolution) GetConsecutiveFailures() uint64 {
+	if x != nil {
+		return x.ConsecutiveFailures
+	}
+	return 0
+}
+
+func (x *CgroupResolution) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+// Container runtime the agent is connected to through NRI.
+type ContainerRuntime struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Endpoint      string                 `protobuf:"bytes,1,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContainerRuntime) Reset() {
+	*x = ContainerRuntime{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContainerRuntime) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerRuntime) ProtoMessage() {}
+
+func (x *ContainerRuntime) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerRuntime.ProtoReflect.Descriptor instead.
+func (*ContainerRuntime) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ContainerRuntime) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *ContainerRuntime) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ContainerRuntime) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type GetAgentInfoResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	KernelVersion  string                 `protobuf:"bytes,1,opt,name=kernel_version,json=kernelVersion,proto3" json:"kernel_version,omitempty"`
+	KernelFeatures []*KernelFeature       `protobuf:"bytes,2,rep,name=kernel_features,json=kernelFeatures,proto3" json:"kernel_features,omitempty"`
+	// Empty until the agent is connected to the container runtime.
+	ContainerRuntime *ContainerRuntime `protobuf:"bytes,3,opt,name=container_runtime,json=containerRuntime,proto3" json:"container_runtime,omitempty"`
+	BpfLoadConfig    *BpfLoadConfig    `protobuf:"bytes,4,opt,name=bpf_load_config,json=bpfLoadConfig,proto3" json:"bpf_load_config,omitempty"`
+	CgroupResolution *CgroupResolution `protobuf:"bytes,5,opt,name=cgroup_resolution,json=cgroupResolution,proto3" json:"cgroup_resolution,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GetAgentInfoResponse) Reset() {
+	*x = GetAgentInfoResponse{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAgentInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAgentInfoResponse) ProtoMessage() {}
+
+func (x *GetAgentInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAgentInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetAgentInfoResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetAgentInfoResponse) GetKernelVersion() string {
+	if x != nil {
+		return x.KernelVersion
+	}
+	return ""
+}
+
+func (x *GetAgentInfoResponse) GetKernelFeatures() []*KernelFeature {
+	if x != nil {
+		return x.KernelFeatures
+	}
+	return nil
+}
+
+func (x *GetAgentInfoResponse) GetContainerRuntime() *ContainerRuntime {
+	if x != nil {
+		return x.ContainerRuntime
+	}
+	return nil
+}
+
+func (x *GetAgentInfoResponse) GetBpfLoadConfig() *BpfLoadConfig {
+	if x != nil {
+		return x.BpfLoadConfig
+	}
+	return nil
+}
+
+func (x *GetAgentInfoResponse) GetCgroupResolution() *CgroupResolution {
+	if x != nil {
+		return x.CgroupResolution
+	}
+	return nil
+}
+
+type SelfCheckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SelfCheckRequest) Reset() {
+	*x = SelfCheckRequest{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SelfCheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelfCheckRequest) ProtoMessage() {}
+
+func (x *SelfCheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelfCheckRequest.ProtoReflect.Descriptor instead.
+func (*SelfCheckRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{17}
+}
+
+type SelfCheckResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Empty when the internal state is consistent.
+	Anomalies     []string `protobuf:"bytes,1,rep,name=anomalies,proto3" json:"anomalies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SelfCheckResponse) Reset() {
+	*x = SelfCheckResponse{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SelfCheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelfCheckResponse) ProtoMessage() {}
+
+func (x *SelfCheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelfCheckResponse.ProtoReflect.Descriptor instead.
+func (*SelfCheckResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SelfCheckResponse) GetAnomalies() []string {
+	if x != nil {
+		return x.Anomalies
+	}
+	return nil
+}
+
+type GrantExecBypassRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	PodName       string                 `protobuf:"bytes,2,opt,name=pod_name,json=podName,proto3" json:"pod_name,omitempty"`
+	ContainerName string                 `protobuf:"bytes,3,opt,name=container_name,json=containerName,proto3" json:"container_name,omitempty"`
+	// At most one hour.
+	Ttl           *durationpb.Duration `protobuf:"bytes,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GrantExecBypassRequest) Reset() {
+	*x = GrantExecBypassRequest{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GrantExecBypassRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GrantExecBypassRequest) ProtoMessage() {}
+
+func (x *GrantExecBypassRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GrantExecBypassRequest.ProtoReflect.Descriptor instead.
+func (*GrantExecBypassRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GrantExecBypassRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *GrantExecBypassRequest) GetPodName() string {
+	if x != nil {
+		return x.PodName
+	}
+	return ""
+}
+
+func (x *GrantExecBypassRequest) GetContainerName() string {
+	if x != nil {
+		return x.ContainerName
+	}
+	return ""
+}
+
+func (x *GrantExecBypassRequest) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+type GrantExecBypassResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GrantExecBypassResponse) Reset() {
+	*x = GrantExecBypassResponse{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GrantExecBypassResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GrantExecBypassResponse) ProtoMessage() {}
+
+func (x *GrantExecBypassResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GrantExecBypassResponse.ProtoReflect.Descriptor instead.
+func (*GrantExecBypassResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GrantExecBypassResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type SetEnforcementOverrideRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enabled       bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetEnforcementOverrideRequest) Reset() {
+	*x = SetEnforcementOverrideRequest{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetEnforcementOverrideRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetEnforcementOverrideRequest) ProtoMessage() {}
+
+func (x *SetEnforcementOverrideRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetEnforcementOverrideRequest.ProtoReflect.Descriptor instead.
+func (*SetEnforcementOverrideRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SetEnforcementOverrideRequest) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+type SetEnforcementOverrideResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Whether the agent switched to or from the override with this call.
+	Changed       bool `protobuf:"varint,1,opt,name=changed,proto3" json:"changed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetEnforcementOverrideResponse) Reset() {
+	*x = SetEnforcementOverrideResponse{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetEnforcementOverrideResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetEnforcementOverrideResponse) ProtoMessage() {}
+
+func (x *SetEnforcementOverrideResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetEnforcementOverrideResponse.ProtoReflect.Descriptor instead.
+func (*SetEnforcementOverrideResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SetEnforcementOverrideResponse) GetChanged() bool {
+	if x != nil {
+		return x.Changed
+	}
+	return false
+}
+
 var File_proto_agent_v1_agent_proto protoreflect.FileDescriptor
 
 const file_proto_agent_v1_agent_proto_rawDesc = "" +
 	"\n" +
-	"\x1aproto/agent/v1/agent.proto\x12\x18runtimeenforcer.agent.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"P\n" +
+	"\x1aproto/agent/v1/agent.proto\x12\x18runtimeenforcer.agent.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"P\n" +
 	"\rContainerMeta\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1b\n" +
@@ -747,11 +1410,13 @@ const file_proto_agent_v1_agent_proto_rawDesc = "" +
 	"\x13ListPodCacheRequest\"M\n" +
 	"\x14ListPodCacheResponse\x125\n" +
 	"\x04pods\x18\x01 \x03(\v2!.runtimeenforcer.agent.v1.PodViewR\x04pods\"\x1b\n" +
-	"\x19ListPoliciesStatusRequest\"\x9f\x01\n" +
+	"\x19ListPoliciesStatusRequest\"\xff\x01\n" +
 	"\fPolicyStatus\x12;\n" +
 	"\x05state\x18\x01 \x01(\x0e2%.runtimeenforcer.agent.v1.PolicyStateR\x05state\x128\n" +
 	"\x04mode\x18\x02 \x01(\x0e2$.runtimeenforcer.agent.v1.PolicyModeR\x04mode\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\"\xe1\x01\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12-\n" +
+	"\x12matched_containers\x18\x04 \x01(\rR\x11matchedContainers\x12/\n" +
+	"\x13enforced_containers\x18\x05 \x01(\rR\x12enforcedContainers\"\xe1\x01\n" +
 	"\x1aListPoliciesStatusResponse\x12^\n" +
 	"\bpolicies\x18\x01 \x03(\v2B.runtimeenforcer.agent.v1.ListPoliciesStatusResponse.PoliciesEntryR\bpolicies\x1ac\n" +
 	"\rPoliciesEntry\x12\x10\n" +
@@ -770,7 +1435,47 @@ const file_proto_agent_v1_agent_proto_rawDesc = "" +
 	"\x18ScrapeViolationsResponse\x12I\n" +
 	"\n" +
 	"violations\x18\x01 \x03(\v2).runtimeenforcer.agent.v1.ViolationRecordR\n" +
-	"violations*[\n" +
+	"violations\"\x15\n" +
+	"\x13GetAgentInfoRequest\"W\n" +
+	"\rKernelFeature\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
+	"\tsupported\x18\x02 \x01(\bR\tsupported\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xb1\x01\n" +
+	"\rBpfLoadConfig\x12&\n" +
+	"\x0fcgroup_fs_magic\x18\x01 \x01(\x04R\rcgroupFsMagic\x12.\n" +
+	"\x13cgroupv1_subsys_idx\x18\x02 \x01(\rR\x11cgroupv1SubsysIdx\x12\x1d\n" +
+	"\n" +
+	"debug_mode\x18\x03 \x01(\bR\tdebugMode\x12)\n" +
+	"\x10learning_enabled\x18\x04 \x01(\bR\x0flearningEnabled\"d\n" +
+	"\x10CgroupResolution\x121\n" +
+	"\x14consecutive_failures\x18\x01 \x01(\x04R\x13consecutiveFailures\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x02 \x01(\tR\tlastError\"\\\n" +
+	"\x10ContainerRuntime\x12\x1a\n" +
+	"\bendpoint\x18\x01 \x01(\tR\bendpoint\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\"\x92\x03\n" +
+	"\x14GetAgentInfoResponse\x12%\n" +
+	"\x0ekernel_version\x18\x01 \x01(\tR\rkernelVersion\x12P\n" +
+	"\x0fkernel_features\x18\x02 \x03(\v2'.runtimeenforcer.agent.v1.KernelFeatureR\x0ekernelFeatures\x12W\n" +
+	"\x11container_runtime\x18\x03 \x01(\v2*.runtimeenforcer.agent.v1.ContainerRuntimeR\x10containerRuntime\x12O\n" +
+	"\x0fbpf_load_config\x18\x04 \x01(\v2'.runtimeenforcer.agent.v1.BpfLoadConfigR\rbpfLoadConfig\x12W\n" +
+	"\x11cgroup_resolution\x18\x05 \x01(\v2*.runtimeenforcer.agent.v1.CgroupResolutionR\x10cgroupResolution\"\x12\n" +
+	"\x10SelfCheckRequest\"1\n" +
+	"\x11SelfCheckResponse\x12\x1c\n" +
+	"\tanomalies\x18\x01 \x03(\tR\tanomalies\"\xa5\x01\n" +
+	"\x16GrantExecBypassRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x19\n" +
+	"\bpod_name\x18\x02 \x01(\tR\apodName\x12%\n" +
+	"\x0econtainer_name\x18\x03 \x01(\tR\rcontainerName\x12+\n" +
+	"\x03ttl\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\x03ttl\"T\n" +
+	"\x17GrantExecBypassResponse\x129\n" +
+	"\n" +
+	"expires_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"9\n" +
+	"\x1dSetEnforcementOverrideRequest\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\":\n" +
+	"\x1eSetEnforcementOverrideResponse\x12\x18\n" +
+	"\achanged\x18\x01 \x01(\bR\achanged*[\n" +
 	"\vPolicyState\x12\x1c\n" +
 	"\x18POLICY_STATE_UNSPECIFIED\x10\x00\x12\x16\n" +
 	"\x12POLICY_STATE_READY\x10\x01\x12\x16\n" +
@@ -779,11 +1484,15 @@ const file_proto_agent_v1_agent_proto_rawDesc = "" +
 	"PolicyMode\x12\x1b\n" +
 	"\x17POLICY_MODE_UNSPECIFIED\x10\x00\x12\x17\n" +
 	"\x13POLICY_MODE_MONITOR\x10\x01\x12\x17\n" +
-	"\x13POLICY_MODE_PROTECT\x10\x022\x81\x03\n" +
+	"\x13POLICY_MODE_PROTECT\x10\x022\xe4\x06\n" +
 	"\rAgentObserver\x12\x81\x01\n" +
 	"\x12ListPoliciesStatus\x123.runtimeenforcer.agent.v1.ListPoliciesStatusRequest\x1a4.runtimeenforcer.agent.v1.ListPoliciesStatusResponse\"\x00\x12o\n" +
 	"\fListPodCache\x12-.runtimeenforcer.agent.v1.ListPodCacheRequest\x1a..runtimeenforcer.agent.v1.ListPodCacheResponse\"\x00\x12{\n" +
-	"\x10ScrapeViolations\x121.runtimeenforcer.agent.v1.ScrapeViolationsRequest\x1a2.runtimeenforcer.agent.v1.ScrapeViolationsResponse\"\x00B>Z<github.com/neuvector/runtime-enforcer/proto/agent/v1;agentv1b\x06proto3"
+	"\x10ScrapeViolations\x121.runtimeenforcer.agent.v1.ScrapeViolationsRequest\x1a2.runtimeenforcer.agent.v1.ScrapeViolationsResponse\"\x00\x12o\n" +
+	"\fGetAgentInfo\x12-.runtimeenforcer.agent.v1.GetAgentInfoRequest\x1a..runtimeenforcer.agent.v1.GetAgentInfoResponse\"\x00\x12f\n" +
+	"\tSelfCheck\x12*.runtimeenforcer.agent.v1.SelfCheckRequest\x1a+.runtimeenforcer.agent.v1.SelfCheckResponse\"\x00\x12x\n" +
+	"\x0fGrantExecBypass\x120.runtimeenforcer.agent.v1.GrantExecBypassRequest\x1a1.runtimeenforcer.agent.v1.GrantExecBypassResponse\"\x00\x12\x8d\x01\n" +
+	"\x16SetEnforcementOverride\x127.runtimeenforcer.agent.v1.SetEnforcementOverrideRequest\x1a8.runtimeenforcer.agent.v1.SetEnforcementOverrideResponse\"\x00B>Z<github.com/neuvector/runtime-enforcer/proto/agent/v1;agentv1b\x06proto3"
 
 var (
 	file_proto_agent_v1_agent_proto_rawDescOnce sync.Once
@@ -798,49 +1507,76 @@ func file_proto_agent_v1_agent_proto_rawDescGZIP() []byte {
 }
 
 var file_proto_agent_v1_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_proto_agent_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_proto_agent_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
 var file_proto_agent_v1_agent_proto_goTypes = []any{
-	(PolicyState)(0),                   // 0: runtimeenforcer.agent.v1.PolicyState
-	(PolicyMode)(0),                    // 1: runtimeenforcer.agent.v1.PolicyMode
-	(*ContainerMeta)(nil),              // 2: runtimeenforcer.agent.v1.ContainerMeta
-	(*PodMeta)(nil),                    // 3: runtimeenforcer.agent.v1.PodMeta
-	(*PodView)(nil),                    // 4: runtimeenforcer.agent.v1.PodView
-	(*ListPodCacheRequest)(nil),        // 5: runtimeenforcer.agent.v1.ListPodCacheRequest
-	(*ListPodCacheResponse)(nil),       // 6: runtimeenforcer.agent.v1.ListPodCacheResponse
-	(*ListPoliciesStatusRequest)(nil),  // 7: runtimeenforcer.agent.v1.ListPoliciesStatusRequest
-	(*PolicyStatus)(nil),               // 8: runtimeenforcer.agent.v1.PolicyStatus
-	(*ListPoliciesStatusResponse)(nil), // 9: runtimeenforcer.agent.v1.ListPoliciesStatusResponse
-	(*ScrapeViolationsRequest)(nil),    // 10: runtimeenforcer.agent.v1.ScrapeViolationsRequest
-	(*ViolationRecord)(nil),            // 11: runtimeenforcer.agent.v1.ViolationRecord
-	(*ScrapeViolationsResponse)(nil),   // 12: runtimeenforcer.agent.v1.ScrapeViolationsResponse
-	nil,                                // 13: runtimeenforcer.agent.v1.PodMeta.LabelsEntry
-	nil,                                // 14: runtimeenforcer.agent.v1.PodView.ContainersEntry
-	nil,                                // 15: runtimeenforcer.agent.v1.ListPoliciesStatusResponse.PoliciesEntry
-	(*timestamppb.Timestamp)(nil),      // 16: google.protobuf.Timestamp
+	(PolicyState)(0),                       // 0: runtimeenforcer.agent.v1.PolicyState
+	(PolicyMode)(0),                        // 1: runtimeenforcer.agent.v1.PolicyMode
+	(*ContainerMeta)(nil),                  // 2: runtimeenforcer.agent.v1.ContainerMeta
+	(*PodMeta)(nil),                        // 3: runtimeenforcer.agent.v1.PodMeta
+	(*PodView)(nil),                        // 4: runtimeenforcer.agent.v1.PodView
+	(*ListPodCacheRequest)(nil),            // 5: runtimeenforcer.agent.v1.ListPodCacheRequest
+	(*ListPodCacheResponse)(nil),           // 6: runtimeenforcer.agent.v1.ListPodCacheResponse
+	(*ListPoliciesStatusRequest)(nil),      // 7: runtimeenforcer.agent.v1.ListPoliciesStatusRequest
+	(*PolicyStatus)(nil),                   // 8: runtimeenforcer.agent.v1.PolicyStatus
+	(*ListPoliciesStatusResponse)(nil),     // 9: runtimeenforcer.agent.v1.ListPoliciesStatusResponse
+	(*ScrapeViolationsRequest)(nil),        // 10: runtimeenforcer.agent.v1.ScrapeViolationsRequest
+	(*ViolationRecord)(nil),                // 11: runtimeenforcer.agent.v1.ViolationRecord
+	(*ScrapeViolationsResponse)(nil),       // 12: runtimeenforcer.agent.v1.ScrapeViolationsResponse
+	(*GetAgentInfoRequest)(nil),            // 13: runtimeenforcer.agent.v1.GetAgentInfoRequest
+	(*KernelFeature)(nil),                  // 14: runtimeenforcer.agent.v1.KernelFeature
+	(*BpfLoadConfig)(nil),                  // 15: runtimeenforcer.agent.v1.BpfLoadConfig
+	(*CgroupResolution)(nil),               // 16: runtimeenforcer.agent.v1.CgroupResolution
+	(*ContainerRuntime)(nil),               // 17: runtimeenforcer.agent.v1.ContainerRuntime
+	(*GetAgentInfoResponse)(nil),           // 18: runtimeenforcer.agent.v1.GetAgentInfoResponse
+	(*SelfCheckRequest)(nil),               // 19: runtimeenforcer.agent.v1.SelfCheckRequest
+	(*SelfCheckResponse)(nil),              // 20: runtimeenforcer.agent.v1.SelfCheckResponse
+	(*GrantExecBypassRequest)(nil),         // 21: runtimeenforcer.agent.v1.GrantExecBypassRequest
+	(*GrantExecBypassResponse)(nil),        // 22: runtimeenforcer.agent.v1.GrantExecBypassResponse
+	(*SetEnforcementOverrideRequest)(nil),  // 23: runtimeenforcer.agent.v1.SetEnforcementOverrideRequest
+	(*SetEnforcementOverrideResponse)(nil), // 24: runtimeenforcer.agent.v1.SetEnforcementOverrideResponse
+	nil,                                    // 25: runtimeenforcer.agent.v1.PodMeta.LabelsEntry
+	nil,                                    // 26: runtimeenforcer.agent.v1.PodView.ContainersEntry
+	nil,                                    // 27: runtimeenforcer.agent.v1.ListPoliciesStatusResponse.PoliciesEntry
+	(*timestamppb.Timestamp)(nil),          // 28: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),            // 29: google.protobuf.Duration
 }
 var file_proto_agent_v1_agent_proto_depIdxs = []int32{
-	13, // 0: runtimeenforcer.agent.v1.PodMeta.labels:type_name -> runtimeenforcer.agent.v1.PodMeta.LabelsEntry
+	25, // 0: runtimeenforcer.agent.v1.PodMeta.labels:type_name -> runtimeenforcer.agent.v1.PodMeta.LabelsEntry
 	3,  // 1: runtimeenforcer.agent.v1.PodView.meta:type_name -> runtimeenforcer.agent.v1.PodMeta
-	14, // 2: runtimeenforcer.agent.v1.PodView.containers:type_name -> runtimeenforcer.agent.v1.PodView.ContainersEntry
+	26, // 2: runtimeenforcer.agent.v1.PodView.containers:type_name -> runtimeenforcer.agent.v1.PodView.ContainersEntry
 	4,  // 3: runtimeenforcer.agent.v1.ListPodCacheResponse.pods:type_name -> runtimeenforcer.agent.v1.PodView
 	0,  // 4: runtimeenforcer.agent.v1.PolicyStatus.state:type_name -> runtimeenforcer.agent.v1.PolicyState
 	1,  // 5: runtimeenforcer.agent.v1.PolicyStatus.mode:type_name -> runtimeenforcer.agent.v1.PolicyMode
-	15, // 6: runtimeenforcer.agent.v1.ListPoliciesStatusResponse.policies:type_name -> runtimeenforcer.agent.v1.ListPoliciesStatusResponse.PoliciesEntry
-	16, // 7: runtimeenforcer.agent.v1.ViolationRecord.timestamp:type_name -> google.protobuf.Timestamp
+	27, // 6: runtimeenforcer.agent.v1.ListPoliciesStatusResponse.policies:type_name -> runtimeenforcer.agent.v1.ListPoliciesStatusResponse.PoliciesEntry
+	28, // 7: runtimeenforcer.agent.v1.ViolationRecord.timestamp:type_name -> google.protobuf.Timestamp
 	11, // 8: runtimeenforcer.agent.v1.ScrapeViolationsResponse.violations:type_name -> runtimeenforcer.agent.v1.ViolationRecord
-	2,  // 9: runtimeenforcer.agent.v1.PodView.ContainersEntry.value:type_name -> runtimeenforcer.agent.v1.ContainerMeta
-	8,  // 10: runtimeenforcer.agent.v1.ListPoliciesStatusResponse.PoliciesEntry.value:type_name -> runtimeenforcer.agent.v1.PolicyStatus
-	7,  // 11: runtimeenforcer.agent.v1.AgentObserver.ListPoliciesStatus:input_type -> runtimeenforcer.agent.v1.ListPoliciesStatusRequest
-	5,  // 12: runtimeenforcer.agent.v1.AgentObserver.ListPodCache:input_type -> runtimeenforcer.agent.v1.ListPodCacheRequest
-	10, // 13: runtimeenforcer.agent.v1.AgentObserver.ScrapeViolations:input_type -> runtimeenforcer.agent.v1.ScrapeViolationsRequest
-	9,  // 14: runtimeenforcer.agent.v1.AgentObserver.ListPoliciesStatus:output_type -> runtimeenforcer.agent.v1.ListPoliciesStatusResponse
-	6,  // 15: runtimeenforcer.agent.v1.AgentObserver.ListPodCache:output_type -> runtimeenforcer.agent.v1.ListPodCacheResponse
-	12, // 16: runtimeenforcer.agent.v1.AgentObserver.ScrapeViolations:output_type -> runtimeenforcer.agent.v1.ScrapeViolationsResponse
-	14, // [14:17] is the sub-list for method output_type
-	11, // [11:14] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	14, // 9: runtimeenforcer.agent.v1.GetAgentInfoResponse.kernel_features:type_name -> runtimeenforcer.agent.v1.KernelFeature
+	17, // 10: runtimeenforcer.agent.v1.GetAgentInfoResponse.container_runtime:type_name -> runtimeenforcer.agent.v1.ContainerRuntime
+	15, // 11: runtimeenforcer.agent.v1.GetAgentInfoResponse.bpf_load_config:type_name -> runtimeenforcer.agent.v1.BpfLoadConfig
+	16, // 12: runtimeenforcer.agent.v1.GetAgentInfoResponse.cgroup_resolution:type_name -> runtimeenforcer.agent.v1.CgroupResolution
+	29, // 13: runtimeenforcer.agent.v1.GrantExecBypassRequest.ttl:type_name -> google.protobuf.Duration
+	28, // 14: runtimeenforcer.agent.v1.GrantExecBypassResponse.expires_at:type_name -> google.protobuf.Timestamp
+	2,  // 15: runtimeenforcer.agent.v1.PodView.ContainersEntry.value:type_name -> runtimeenforcer.agent.v1.ContainerMeta
+	8,  // 16: runtimeenforcer.agent.v1.ListPoliciesStatusResponse.PoliciesEntry.value:type_name -> runtimeenforcer.agent.v1.PolicyStatus
+	7,  // 17: runtimeenforcer.agent.v1.AgentObserver.ListPoliciesStatus:input_type -> runtimeenforcer.agent.v1.ListPoliciesStatusRequest
+	5,  // 18: runtimeenforcer.agent.v1.AgentObserver.ListPodCache:input_type -> runtimeenforcer.agent.v1.ListPodCacheRequest
+	10, // 19: runtimeenforcer.agent.v1.AgentObserver.ScrapeViolations:input_type -> runtimeenforcer.agent.v1.ScrapeViolationsRequest
+	13, // 20: runtimeenforcer.agent.v1.AgentObserver.GetAgentInfo:input_type -> runtimeenforcer.agent.v1.GetAgentInfoRequest
+	19, // 21: runtimeenforcer.agent.v1.AgentObserver.SelfCheck:input_type -> runtimeenforcer.agent.v1.SelfCheckRequest
+	21, // 22: runtimeenforcer.agent.v1.AgentObserver.GrantExecBypass:input_type -> runtimeenforcer.agent.v1.GrantExecBypassRequest
+	23, // 23: runtimeenforcer.agent.v1.AgentObserver.SetEnforcementOverride:input_type -> runtimeenforcer.agent.v1.SetEnforcementOverrideRequest
+	9,  // 24: runtimeenforcer.agent.v1.AgentObserver.ListPoliciesStatus:output_type -> runtimeenforcer.agent.v1.ListPoliciesStatusResponse
+	6,  // 25: runtimeenforcer.agent.v1.AgentObserver.ListPodCache:output_type -> runtimeenforcer.agent.v1.ListPodCacheResponse
+	12, // 26: runtimeenforcer.agent.v1.AgentObserver.ScrapeViolations:output_type -> runtimeenforcer.agent.v1.ScrapeViolationsResponse
+	18, // 27: runtimeenforcer.agent.v1.AgentObserver.GetAgentInfo:output_type -> runtimeenforcer.agent.v1.GetAgentInfoResponse
+	20, // 28: runtimeenforcer.agent.v1.AgentObserver.SelfCheck:output_type -> runtimeenforcer.agent.v1.SelfCheckResponse
+	22, // 29: runtimeenforcer.agent.v1.AgentObserver.GrantExecBypass:output_type -> runtimeenforcer.agent.v1.GrantExecBypassResponse
+	24, // 30: runtimeenforcer.agent.v1.AgentObserver.SetEnforcementOverride:output_type -> runtimeenforcer.agent.v1.SetEnforcementOverrideResponse
+	24, // [24:31] is the sub-list for method output_type
+	17, // [17:24] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_proto_agent_v1_agent_proto_init() }
@@ -854,7 +1590,7 @@ func file_proto_agent_v1_agent_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_agent_v1_agent_proto_rawDesc), len(file_proto_agent_v1_agent_proto_rawDesc)),
 			NumEnums:      2,
-			NumMessages:   14,
+			NumMessages:   26,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -21,9 +21,13 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AgentObserver_ListPoliciesStatus_FullMethodName = "/runtimeenforcer.agent.v1.AgentObserver/ListPoliciesStatus"
-	AgentObserver_ListPodCache_FullMethodName       = "/runtimeenforcer.agent.v1.AgentObserver/ListPodCache"
-	AgentObserver_ScrapeViolations_FullMethodName   = "/runtimeenforcer.agent.v1.AgentObserver/ScrapeViolations"
+	AgentObserver_ListPoliciesStatus_FullMethodName     = "/runtimeenforcer.agent.v1.AgentObserver/ListPoliciesStatus"
+	AgentObserver_ListPodCache_FullMethodName           = "/runtimeenforcer.agent.v1.AgentObserver/ListPodCache"
+	AgentObserver_ScrapeViolations_FullMethodName       = "/runtimeenforcer.agent.v1.AgentObserver/ScrapeViolations"
+	AgentObserver_GetAgentInfo_FullMethodName           = "/runtimeenforcer.agent.v1.AgentObserver/GetAgentInfo"
+	AgentObserver_SelfCheck_FullMethodName              = "/runtimeenforcer.agent.v1.AgentObserver/SelfCheck"
+	AgentObserver_GrantExecBypass_FullMethodName        = "/runtimeenforcer.agent.v1.AgentObserver/GrantExecBypass"
+	AgentObserver_SetEnforcementOverride_FullMethodName = "/runtimeenforcer.agent.v1.AgentObserver/SetEnforcementOverride"
 )
 
 // AgentObserverClient is the client API for AgentObserver service.
@@ -39,6 +43,16 @@ type AgentObserverClient interface {
 	// ScrapeViolations drains the agent's in-memory violation buffer and
 	// returns all accumulated records since the last scrape.
 	ScrapeViolations(ctx context.Context, in *ScrapeViolationsRequest, opts ...grpc.CallOption) (*ScrapeViolationsResponse, error)
+	// GetAgentInfo returns the kernel version and the eBPF features probed by the agent at startup.
+	GetAgentInfo(ctx context.Context, in *GetAgentInfoRequest, opts ...grpc.CallOption) (*GetAgentInfoResponse, error)
+	// SelfCheck verifies the consistency of the agent internal state and returns the anomalies found.
+	SelfCheck(ctx context.Context, in *SelfCheckRequest, opts ...grpc.CallOption) (*SelfCheckResponse, error)
+	// GrantExecBypass temporarily allows any execution in a container, e.g. to troubleshoot it with kubectl exec.
+	// The container is enforced again once the TTL expires.
+	GrantExecBypass(ctx context.Context, in *GrantExecBypassRequest, opts ...grpc.CallOption) (*GrantExecBypassResponse, error)
+	// SetEnforcementOverride switches all the policies to monitor mode while enabled, and restores their mode otherwise.
+	// The controller calls it at each status sync, following the EnforcementOverrides of the cluster.
+	SetEnforcementOverride(ctx context.Context, in *SetEnforcementOverrideRequest, opts ...grpc.CallOption) (*SetEnforcementOverrideResponse, error)
 }
 
 type agentObserverClient struct {
@@ -79,6 +93,46 @@ func (c *agentObserverClient) ScrapeViolations(ctx context.Context, in *ScrapeVi
 	return out, nil
 }
 
+func (c *agentObserverClient) GetAgentInfo(ctx context.Context, in *GetAgentInfoRequest, opts ...grpc.CallOption) (*GetAgentInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAgentInfoResponse)
+	err := c.cc.Invoke(ctx, AgentObserver_GetAgentInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentObserverClient) SelfCheck(ctx context.Context, in *SelfCheckRequest, opts ...grpc.CallOption) (*SelfCheckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SelfCheckResponse)
+	err := c.cc.Invoke(ctx, AgentObserver_SelfCheck_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentObserverClient) GrantExecBypass(ctx context.Context, in *GrantExecBypassRequest, opts ...grpc.CallOption) (*GrantExecBypassResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GrantExecBypassResponse)
+	err := c.cc.Invoke(ctx, AgentObserver_GrantExecBypass_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentObserverClient) SetEnforcementOverride(ctx context.Context, in *SetEnforcementOverrideRequest, opts ...grpc.CallOption) (*SetEnforcementOverrideResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetEnforcementOverrideResponse)
+	err := c.cc.Invoke(ctx, AgentObserver_SetEnforcementOverride_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AgentObserverServer is the server API for AgentObserver service.
 // All implementations must embed UnimplementedAgentObserverServer
 // for forward compatibility.
@@ -92,6 +146,16 @@ type AgentObserverServer interface {
 	// ScrapeViolations drains the agent's in-memory violation buffer and
 	// returns all accumulated records since the last scrape.
 	ScrapeViolations(context.Context, *ScrapeViolationsRequest) (*ScrapeViolationsResponse, error)
+	// GetAgentInfo returns the kernel version and the eBPF features probed by the agent at startup.
+	GetAgentInfo(context.Context, *GetAgentInfoRequest) (*GetAgentInfoResponse, error)
+	// SelfCheck verifies the consistency of the agent internal state and returns the anomalies found.
+	SelfCheck(context.Context, *SelfCheckRequest) (*SelfCheckResponse, error)
+	// GrantExecBypass temporarily allows any execution in a container, e.g. to troubleshoot it with kubectl exec.
+	// The container is enforced again once the TTL expires.
+	GrantExecBypass(context.Context, *GrantExecBypassRequest) (*GrantExecBypassResponse, error)
+	// SetEnforcementOverride switches all the policies to monitor mode while enabled, and restores their mode otherwise.
+	// The controller calls it at each status sync, following the EnforcementOverrides of the cluster.
+	SetEnforcementOverride(context.Context, *SetEnforcementOverrideRequest) (*SetEnforcementOverrideResponse, error)
 	mustEmbedUnimplementedAgentObserverServer()
 }
 
@@ -111,6 +175,18 @@ func (UnimplementedAgentObserverServer) ListPodCache(context.Context, *ListPodCa
 func (UnimplementedAgentObserverServer) ScrapeViolations(context.Context, *ScrapeViolationsRequest) (*ScrapeViolationsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ScrapeViolations not implemented")
 }
+func (UnimplementedAgentObserverServer) GetAgentInfo(context.Context, *GetAgentInfoRequest) (*GetAgentInfoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetAgentInfo not implemented")
+}
+func (UnimplementedAgentObserverServer) SelfCheck(context.Context, *SelfCheckRequest) (*SelfCheckResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SelfCheck not implemented")
+}
+func (UnimplementedAgentObserverServer) GrantExecBypass(context.Context, *GrantExecBypassRequest) (*GrantExecBypassResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GrantExecBypass not implemented")
+}
+func (UnimplementedAgentObserverServer) SetEnforcementOverride(context.Context, *SetEnforcementOverrideRequest) (*SetEnforcementOverrideResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetEnforcementOverride not implemented")
+}
 func (UnimplementedAgentObserverServer) mustEmbedUnimplementedAgentObserverServer() {}
 func (UnimplementedAgentObserverServer) testEmbeddedByValue()                       {}
 
@@ -186,6 +262,78 @@ func _AgentObserver_ScrapeViolations_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentObserver_GetAgentInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAgentInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentObserverServer).GetAgentInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentObserver_GetAgentInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentObserverServer).GetAgentInfo(ctx, req.(*GetAgentInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentObserver_SelfCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SelfCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentObserverServer).SelfCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentObserver_SelfCheck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentObserverServer).SelfCheck(ctx, req.(*SelfCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentObserver_GrantExecBypass_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GrantExecBypassRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentObserverServer).GrantExecBypass(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentObserver_GrantExecBypass_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentObserverServer).GrantExecBypass(ctx, req.(*GrantExecBypassRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentObserver_SetEnforcementOverride_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetEnforcementOverrideRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentObserverServer).SetEnforcementOverride(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentObserver_SetEnforcementOverride_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentObserverServer).SetEnforcementOverride(ctx, req.(*SetEnforcementOverrideRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AgentObserver_ServiceDesc is the grpc.ServiceDesc for AgentObserver service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -205,6 +353,22 @@ var AgentObserver_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ScrapeViolations",
 			Handler:    _AgentObserver_ScrapeViolations_Handler,
 		},
+		{
+			MethodName: "GetAgentInfo",
+			Handler:    _AgentObserver_GetAgentInfo_Handler,
+		},
+		{
+			MethodName: "SelfCheck",
+			Handler:    _AgentObserver_SelfCheck_Handler,
+		},
+		{
+			MethodName: "GrantExecBypass",
+			Handler:    _AgentObserver_GrantExecBypass_Handler,
+		},
+		{
+			MethodName: "SetEnforcementOverride",
+			Handler:    _AgentObserver_SetEnforcementOverride_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/agent/v1/agent.proto",
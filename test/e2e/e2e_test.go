@@ -47,6 +47,18 @@ func TestPolicyPerContainer(t *testing.T) {
 	testEnv.Test(t, getPolicyPerContainerTest())
 }
 
+func TestUnlistedContainerPolicy(t *testing.T) {
+	t.Log("test unlisted container policy")
+
+	testEnv.Test(t, getUnlistedContainerPolicyTest())
+}
+
+func TestShareProcessNamespace(t *testing.T) {
+	t.Log("test shared process namespace")
+
+	testEnv.Test(t, getShareProcessNamespaceTest())
+}
+
 func TestValidatingAdmissionPolicyPodPolicyLabel(t *testing.T) {
 	t.Log("test ValidatingAdmissionPolicy pod policy label")
 
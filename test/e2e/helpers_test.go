@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/execoutcome"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -30,7 +31,6 @@ const (
 	testFolder                 = "./testdata"
 	opensuseDeploymentManifest = "opensuse-deployment.yaml"
 	opensuseDeploymentName     = "opensuse-deployment"
-	operationNotPermittedMsg   = "operation not permitted"
 )
 
 type key string
@@ -291,7 +291,8 @@ func requireExecBlockedInCurrentNamespace(
 	stdout, stderr, err := execInCurrentNamespace(ctx, podName, containerName, command)
 	require.Error(t, err)
 	require.Empty(t, stdout)
-	require.Contains(t, stderr, operationNotPermittedMsg)
+	require.Equal(t, execoutcome.Blocked, execoutcome.ClassifyExecResult(stderr, execoutcome.ExitCode(err)),
+		"exec was not blocked: %s", stderr)
 }
 
 func verifyOpensuseLearnedProcesses(values []string) bool {
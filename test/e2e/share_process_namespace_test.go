@@ -0,0 +1,140 @@
+package e2e_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+	"sigs.k8s.io/e2e-framework/pkg/types"
+)
+
+func getShareProcessNamespaceTest() types.Feature {
+	policyName := "share-process-namespace-policy"
+	podName := "test-pod-share-process-namespace"
+
+	return features.New("shared process namespace").
+		Setup(SetupSharedK8sClient).
+		Setup(SetupTestNamespace).
+		Setup(func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			policy := v1alpha1.WorkloadPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      policyName,
+					Namespace: getNamespace(ctx),
+				},
+				Spec: v1alpha1.WorkloadPolicySpec{
+					Mode: policymode.ProtectString,
+					RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+						"ls-container": {
+							Executables: v1alpha1.WorkloadPolicyExecutables{
+								Allowed: []string{
+									"/usr/bin/ls",
+									"/usr/bin/sleep",
+								},
+							},
+						},
+						"cat-container": {
+							Executables: v1alpha1.WorkloadPolicyExecutables{
+								Allowed: []string{
+									"/usr/bin/cat",
+									"/usr/bin/sleep",
+								},
+							},
+						},
+					},
+				},
+			}
+			createAndWaitWP(ctx, t, policy.DeepCopy())
+			return ctx
+		}).
+		Assess("required resources become available", IfRequiredResourcesAreCreated).
+		Assess("pod sharing its process namespace becomes ready",
+			func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+				r := getClient(ctx)
+
+				pod := corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      podName,
+						Namespace: getNamespace(ctx),
+						Labels: map[string]string{
+							v1alpha1.PolicyLabelKey: policyName,
+						},
+					},
+					Spec: corev1.PodSpec{
+						// all the containers see each other processes, but each of them keeps its own cgroup.
+						ShareProcessNamespace: new(true),
+						Containers: []corev1.Container{
+							{
+								Name:    "ls-container",
+								Image:   "registry.opensuse.org/opensuse/bci/bci-ci:3",
+								Command: []string{"sleep", "3600"},
+							},
+							{
+								Name:    "cat-container",
+								Image:   "registry.opensuse.org/opensuse/bci/bci-ci:3",
+								Command: []string{"sleep", "3600"},
+							},
+						},
+						RestartPolicy: corev1.RestartPolicyNever,
+					},
+				}
+
+				err := r.Create(ctx, &pod)
+				require.NoError(t, err, "failed to create pod")
+
+				err = wait.For(
+					conditions.New(r).PodReady(&pod),
+					wait.WithTimeout(defaultOperationTimeout),
+				)
+				require.NoError(t, err, "pod did not become ready")
+
+				return ctx
+			}).
+		Assess("each container is enforced with its own rules",
+			func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+				requireExecAllowedInCurrentNamespace(ctx, t, podName, "ls-container", []string{"ls", "/"})
+				requireExecBlockedInCurrentNamespace(ctx, t, podName, "ls-container", []string{"cat", "/etc/hostname"})
+
+				requireExecAllowedInCurrentNamespace(ctx, t, podName, "cat-container", []string{"cat", "/etc/hostname"})
+				requireExecBlockedInCurrentNamespace(ctx, t, podName, "cat-container", []string{"ls", "/"})
+
+				return ctx
+			}).
+		Teardown(func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			t.Log("cleaning up test resources")
+
+			r := getClient(ctx)
+
+			pod := corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      podName,
+					Namespace: getNamespace(ctx),
+				},
+			}
+			err := r.Delete(ctx, &pod)
+			require.NoError(t, err, "failed to delete pod")
+
+			err = wait.For(
+				conditions.New(r).ResourceDeleted(&pod),
+				wait.WithTimeout(defaultOperationTimeout),
+			)
+			require.NoError(t, err, "pod was not deleted within timeout")
+
+			policy := v1alpha1.WorkloadPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      policyName,
+					Namespace: getNamespace(ctx),
+				},
+			}
+			deleteAndWaitWP(ctx, t, &policy)
+
+			return ctx
+		}).Feature()
+}
@@ -0,0 +1,146 @@
+package e2e_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rancher-sandbox/runtime-enforcer/api/v1alpha1"
+	"github.com/rancher-sandbox/runtime-enforcer/internal/types/policymode"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/e2e-framework/klient/k8s"
+	"sigs.k8s.io/e2e-framework/klient/wait"
+	"sigs.k8s.io/e2e-framework/klient/wait/conditions"
+	"sigs.k8s.io/e2e-framework/pkg/envconf"
+	"sigs.k8s.io/e2e-framework/pkg/features"
+	"sigs.k8s.io/e2e-framework/pkg/types"
+)
+
+func getUnlistedContainerPolicyTest() types.Feature {
+	policyName := "unlisted-container-deny-policy"
+	podName := "test-pod-unlisted-container"
+
+	return features.New("unlisted container policy").
+		Setup(SetupSharedK8sClient).
+		Setup(SetupTestNamespace).
+		Setup(func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			policy := v1alpha1.WorkloadPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      policyName,
+					Namespace: getNamespace(ctx),
+				},
+				Spec: v1alpha1.WorkloadPolicySpec{
+					Mode:                    policymode.ProtectString,
+					UnlistedContainerPolicy: v1alpha1.UnlistedContainerDeny,
+					RulesByContainer: map[string]*v1alpha1.WorkloadPolicyRules{
+						"main-container": {
+							Executables: v1alpha1.WorkloadPolicyExecutables{
+								Allowed: []string{
+									"/usr/bin/ls",
+									"/usr/bin/sleep",
+								},
+							},
+						},
+					},
+				},
+			}
+			createAndWaitWP(ctx, t, policy.DeepCopy())
+			return ctx
+		}).
+		Assess("required resources become available", IfRequiredResourcesAreCreated).
+		Assess("unlisted container cannot run its command",
+			func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+				t.Log("creating pod with a container not listed in the policy")
+
+				r := getClient(ctx)
+
+				pod := corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      podName,
+						Namespace: getNamespace(ctx),
+						Labels: map[string]string{
+							v1alpha1.PolicyLabelKey: policyName,
+						},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:    "main-container",
+								Image:   "registry.opensuse.org/opensuse/bci/bci-ci:3",
+								Command: []string{"sleep", "3600"},
+							},
+							{
+								Name:    "unlisted-container",
+								Image:   "registry.opensuse.org/opensuse/bci/bci-ci:3",
+								Command: []string{"sleep", "3600"},
+							},
+						},
+						RestartPolicy: corev1.RestartPolicyNever,
+					},
+				}
+
+				err := r.Create(ctx, &pod)
+				require.NoError(t, err, "failed to create pod")
+
+				// the unlisted container is denied every executable, so its command must fail.
+				err = wait.For(conditions.New(r).ResourceMatch(&pod, func(obj k8s.Object) bool {
+					p, ok := obj.(*corev1.Pod)
+					if !ok {
+						return false
+					}
+					for _, status := range p.Status.ContainerStatuses {
+						if status.Name != "unlisted-container" {
+							continue
+						}
+						return status.State.Terminated != nil && status.State.Terminated.ExitCode != 0
+					}
+					return false
+				}), wait.WithTimeout(defaultOperationTimeout))
+				require.NoError(t, err, "unlisted container should fail because no executable is allowed")
+
+				return ctx
+			}).
+		Assess("ls is allowed in the listed container",
+			func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+				_, _ = requireExecAllowedInCurrentNamespace(
+					ctx,
+					t,
+					podName,
+					"main-container",
+					[]string{"ls", "/"},
+				)
+
+				return ctx
+			}).
+		Teardown(func(ctx context.Context, t *testing.T, _ *envconf.Config) context.Context {
+			t.Log("cleaning up test resources")
+
+			r := getClient(ctx)
+
+			pod := corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      podName,
+					Namespace: getNamespace(ctx),
+				},
+			}
+			err := r.Delete(ctx, &pod)
+			require.NoError(t, err, "failed to delete pod")
+
+			err = wait.For(
+				conditions.New(r).ResourceDeleted(&pod),
+				wait.WithTimeout(defaultOperationTimeout),
+			)
+			require.NoError(t, err, "pod was not deleted within timeout")
+
+			policy := v1alpha1.WorkloadPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      policyName,
+					Namespace: getNamespace(ctx),
+				},
+			}
+			deleteAndWaitWP(ctx, t, &policy)
+
+			return ctx
+		}).Feature()
+}